@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
 )
 
 // AnimationDataRecord represents a single entry in the animation data dictionary file
@@ -19,6 +20,26 @@ type AnimationDataRecord struct {
 	Flags []byte
 }
 
+// FrameEvent returns the event triggered on a frame of the animation (eg: the frame an attack hits on), or
+// AnimationFrameNoEvent if the frame does not trigger one
+func (r *AnimationDataRecord) FrameEvent(frameIndex int) d2enum.AnimationFrame {
+	if frameIndex < 0 || frameIndex >= r.FramesPerDirection || frameIndex >= len(r.Flags) {
+		return d2enum.AnimationFrameNoEvent
+	}
+	return d2enum.AnimationFrame(r.Flags[frameIndex])
+}
+
+// ActionFrame returns the first frame of the animation that triggers an event, and the event it triggers. Found is
+// false if no frame triggers an event.
+func (r *AnimationDataRecord) ActionFrame() (frameIndex int, event d2enum.AnimationFrame, found bool) {
+	for frameIndex = 0; frameIndex < r.FramesPerDirection; frameIndex++ {
+		if event = r.FrameEvent(frameIndex); event != d2enum.AnimationFrameNoEvent {
+			return frameIndex, event, true
+		}
+	}
+	return 0, d2enum.AnimationFrameNoEvent, false
+}
+
 // AnimationData represents all of the animation data records, mapped by the COF index
 var AnimationData map[string][]*AnimationDataRecord
 
@@ -31,7 +52,7 @@ func LoadAnimationData(rawData []byte) {
 		for i := 0; i < dataCount; i++ {
 			cofNameBytes := streamReader.ReadBytes(8)
 			data := &AnimationDataRecord{
-				COFName:            strings.ReplaceAll(string(cofNameBytes), string(0), ""),
+				COFName:            strings.ReplaceAll(string(cofNameBytes), "\x00", ""),
 				FramesPerDirection: int(streamReader.GetInt32()),
 				AnimationSpeed:     int(streamReader.GetInt32()),
 			}
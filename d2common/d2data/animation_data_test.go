@@ -0,0 +1,76 @@
+package d2data
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+)
+
+// createTestAnimationData encodes a block of animation data records in the animdata.d2 layout
+func createTestAnimationData(records ...AnimationDataRecord) []byte {
+	writer := d2common.CreateStreamWriter()
+	writer.PushUint32(uint32(len(records)))
+	for _, record := range records {
+		name := make([]byte, 8)
+		copy(name, record.COFName)
+		for _, b := range name {
+			writer.PushByte(b)
+		}
+		writer.PushUint32(uint32(record.FramesPerDirection))
+		writer.PushUint32(uint32(record.AnimationSpeed))
+		flags := make([]byte, 144)
+		copy(flags, record.Flags)
+		for _, b := range flags {
+			writer.PushByte(b)
+		}
+	}
+	return writer.GetBytes()
+}
+
+func TestLoadAnimationDataActionFrame(t *testing.T) {
+	assert := testify.New(t)
+
+	// A one handed swing that hits on frame 8 of 16, and a cast that releases its missile on frame 5 of 14
+	attackFlags := make([]byte, 16)
+	attackFlags[8] = byte(d2enum.AnimationFrameAttack)
+	castFlags := make([]byte, 14)
+	castFlags[5] = byte(d2enum.AnimationFrameMissile)
+	LoadAnimationData(createTestAnimationData(
+		AnimationDataRecord{COFName: "PAA11HS", FramesPerDirection: 16, AnimationSpeed: 256, Flags: attackFlags},
+		AnimationDataRecord{COFName: "SOSCHTH", FramesPerDirection: 14, AnimationSpeed: 256, Flags: castFlags},
+		AnimationDataRecord{COFName: "PANUHTH", FramesPerDirection: 8, AnimationSpeed: 128},
+	))
+
+	attack := AnimationData["paa11hs"][0]
+	frameIndex, event, found := attack.ActionFrame()
+	assert.True(found)
+	assert.Equal(8, frameIndex)
+	assert.Equal(d2enum.AnimationFrameAttack, event)
+	assert.Equal(d2enum.AnimationFrameAttack, attack.FrameEvent(8))
+	assert.Equal(d2enum.AnimationFrameNoEvent, attack.FrameEvent(7))
+
+	frameIndex, event, found = AnimationData["soschth"][0].ActionFrame()
+	assert.True(found)
+	assert.Equal(5, frameIndex)
+	assert.Equal(d2enum.AnimationFrameMissile, event)
+
+	_, _, found = AnimationData["panuhth"][0].ActionFrame()
+	assert.False(found)
+}
+
+func TestAnimationDataFrameEventOutsideAnimation(t *testing.T) {
+	assert := testify.New(t)
+
+	// Flags past the last frame of the animation are padding, and do not count
+	flags := make([]byte, 144)
+	flags[20] = byte(d2enum.AnimationFrameSound)
+	record := &AnimationDataRecord{FramesPerDirection: 10, Flags: flags}
+
+	assert.Equal(d2enum.AnimationFrameNoEvent, record.FrameEvent(20))
+	assert.Equal(d2enum.AnimationFrameNoEvent, record.FrameEvent(-1))
+	_, _, found := record.ActionFrame()
+	assert.False(found)
+}
@@ -2,6 +2,7 @@ package d2datadict
 
 import (
 	"log"
+	"strings"
 )
 
 type ObjectType int
@@ -15,7 +16,7 @@ type ObjectLookupRecord struct {
 	Act           int
 	Type          ObjectType
 	Id            int
-	Description   string
+	Description   string // Free text naming and describing the object, as written in the lookup data
 	ObjectsTxtId  int
 	MonstatsTxtId int
 	Direction     int
@@ -43,6 +44,20 @@ type ObjectLookupRecord struct {
 	Index         int
 }
 
+// Name returns the human readable name of the object (eg: "Zombie"), taken from its description. Descriptions are
+// either "code-name-note" (characters), "name-note" or just "name", where the note is free text.
+func (r *ObjectLookupRecord) Name() string {
+	parts := strings.Split(r.Description, "-")
+	switch len(parts) {
+	case 2:
+		return parts[0]
+	case 3:
+		return parts[1]
+	default:
+		return r.Description
+	}
+}
+
 func LookupObject(act, typ, id int) *ObjectLookupRecord {
 	object := lookupObject(act, typ, id, indexedObjects)
 	if object == nil {
@@ -39,3 +39,25 @@ func TestIndexObjects(t *testing.T) {
 	assert.Equal("Act2CharId3", lookupObject(2, typeCharacter, 3, indexedTestObjects).Description)
 	assert.Equal("Act2ItemId1", lookupObject(2, typeItem, 1, indexedTestObjects).Description)
 }
+
+func TestObjectLookupName(t *testing.T) {
+	assert := testify.New(t)
+
+	// Other tests replace the global index, so index the real lookups here
+	objects := indexObjects(objectLookups)
+
+	zombie := lookupObject(1, int(ObjectTypeCharacter), 52, objects)
+	assert.Equal("Zombie", zombie.Name())
+
+	akara := lookupObject(1, int(ObjectTypeCharacter), 2, objects)
+	assert.Equal("akara", akara.Name())
+
+	record := ObjectLookupRecord{Description: "Altar-inside of temple"}
+	assert.Equal("Altar", record.Name())
+
+	record = ObjectLookupRecord{Description: "act2guard2-Kaelan-JarJar"}
+	assert.Equal("Kaelan", record.Name())
+
+	record = ObjectLookupRecord{Description: "Dummy"}
+	assert.Equal("Dummy", record.Name())
+}
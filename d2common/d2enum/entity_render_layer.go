@@ -0,0 +1,11 @@
+package d2enum
+
+// EntityRenderLayer determines which map render pass an entity is drawn in
+type EntityRenderLayer int
+
+const (
+	EntityRenderLayerNormal    EntityRenderLayer = iota // Drawn with the upper walls (pass 2)
+	EntityRenderLayerBelow                              // Drawn with the floors and shadows, below walls (pass 1)
+	EntityRenderLayerAboveRoof                          // Drawn after the roofs (pass 3), eg: flying creatures
+	EntityRenderLayerCorpse                             // Drawn with the upper walls (pass 2), beneath the normal entities
+)
@@ -0,0 +1,12 @@
+package d2enum
+
+// SpecialTileType classifies the special (orientation 10 and 11) wall tiles used by the level logic
+type SpecialTileType int
+
+const (
+	SpecialTileUnknown       SpecialTileType = iota // A special tile that is not (yet) understood
+	SpecialTileEntrance                             // Where the player enters the level
+	SpecialTileExit                                 // Where the player leaves the level (eg: town portal location)
+	SpecialTileGroupBoundary                        // Marks the boundary of a tile group
+	SpecialTileObjectTarget                         // Marks the location an object (eg: a waypoint or warp) targets
+)
@@ -63,3 +63,30 @@ func LoadCOF(fileData []byte) (*COF, error) {
 	}
 	return result, nil
 }
+
+// GetLayerDrawOrder returns the layers of a direction in the order they are drawn, back to front, as set for the first
+// frame of the direction. Returns nil if the direction is not one of the COF's directions.
+func (c *COF) GetLayerDrawOrder(direction int) []d2enum.CompositeType {
+	return c.GetFrameLayerDrawOrder(direction, 0)
+}
+
+// GetFrameLayerDrawOrder returns the layers of a frame of a direction in the order they are drawn, back to front (eg:
+// a weapon swung behind the body is drawn before the torso). Layer types the COF does not have are left out. Returns
+// nil if the direction or frame is not in the COF.
+func (c *COF) GetFrameLayerDrawOrder(direction, frame int) []d2enum.CompositeType {
+	if direction < 0 || direction >= c.NumberOfDirections || direction >= len(c.Priority) {
+		return nil
+	}
+	if frame < 0 || frame >= len(c.Priority[direction]) {
+		return nil
+	}
+
+	priority := c.Priority[direction][frame]
+	result := make([]d2enum.CompositeType, 0, len(priority))
+	for _, layerType := range priority {
+		if _, found := c.CompositeLayers[layerType]; found {
+			result = append(result, layerType)
+		}
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package d2cof
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+)
+
+// createTestCOFData creates a COF with head, torso and right hand layers, 2 directions and 2 frames per direction.
+// The right hand is drawn behind the body in the first direction, and in front of it in the second, except for its
+// last frame.
+func createTestCOFData() []byte {
+	sw := d2common.CreateStreamWriter()
+	sw.PushByte(3) // Layers
+	sw.PushByte(2) // Frames per direction
+	sw.PushByte(2) // Directions
+	for i := 0; i < 21; i++ {
+		sw.PushByte(0)
+	}
+	sw.PushByte(8) // Speed
+	for i := 0; i < 3; i++ {
+		sw.PushByte(0)
+	}
+
+	for _, layerType := range []d2enum.CompositeType{d2enum.CompositeTypeHead, d2enum.CompositeTypeTorso,
+		d2enum.CompositeTypeRightHand} {
+		sw.PushByte(byte(layerType))
+		for _, b := range []byte{1, 1, 0, 0, 'h', 't', 'h', 0} {
+			sw.PushByte(b)
+		}
+	}
+
+	sw.PushByte(0) // Animation frames
+	sw.PushByte(0)
+
+	priority := [][][]d2enum.CompositeType{
+		{
+			{d2enum.CompositeTypeRightHand, d2enum.CompositeTypeTorso, d2enum.CompositeTypeHead},
+			{d2enum.CompositeTypeRightHand, d2enum.CompositeTypeTorso, d2enum.CompositeTypeHead},
+		},
+		{
+			{d2enum.CompositeTypeTorso, d2enum.CompositeTypeHead, d2enum.CompositeTypeRightHand},
+			{d2enum.CompositeTypeRightHand, d2enum.CompositeTypeTorso, d2enum.CompositeTypeHead},
+		},
+	}
+	for _, direction := range priority {
+		for _, frame := range direction {
+			for _, layerType := range frame {
+				sw.PushByte(byte(layerType))
+			}
+		}
+	}
+	return sw.GetBytes()
+}
+
+func TestGetLayerDrawOrder(t *testing.T) {
+	assert := testify.New(t)
+	cof, err := LoadCOF(createTestCOFData())
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Equal([]d2enum.CompositeType{d2enum.CompositeTypeRightHand, d2enum.CompositeTypeTorso,
+		d2enum.CompositeTypeHead}, cof.GetLayerDrawOrder(0))
+	assert.Equal([]d2enum.CompositeType{d2enum.CompositeTypeTorso, d2enum.CompositeTypeHead,
+		d2enum.CompositeTypeRightHand}, cof.GetLayerDrawOrder(1))
+	assert.Equal([]d2enum.CompositeType{d2enum.CompositeTypeRightHand, d2enum.CompositeTypeTorso,
+		d2enum.CompositeTypeHead}, cof.GetFrameLayerDrawOrder(1, 1))
+}
+
+func TestGetLayerDrawOrderValidatesDirection(t *testing.T) {
+	assert := testify.New(t)
+	cof, err := LoadCOF(createTestCOFData())
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.Nil(cof.GetLayerDrawOrder(-1))
+	assert.Nil(cof.GetLayerDrawOrder(2))
+	assert.Nil(cof.GetFrameLayerDrawOrder(0, 2))
+}
+
+func TestGetLayerDrawOrderSkipsMissingLayers(t *testing.T) {
+	assert := testify.New(t)
+	cof, err := LoadCOF(createTestCOFData())
+	if !assert.NoError(err) {
+		return
+	}
+
+	cof.Priority[0][0][1] = d2enum.CompositeTypeShield
+	assert.Equal([]d2enum.CompositeType{d2enum.CompositeTypeRightHand, d2enum.CompositeTypeHead},
+		cof.GetLayerDrawOrder(0))
+}
@@ -1,5 +1,7 @@
 package d2dat
 
+import "errors"
+
 type DATColor struct {
 	R uint8
 	G uint8
@@ -7,10 +9,20 @@ type DATColor struct {
 }
 
 type DATPalette struct {
-	Colors [256]DATColor
+	Colors           [256]DATColor
+	TransparentIndex byte // The color index that is drawn as transparent
+}
+
+// Returns true if the color index is drawn as transparent with this palette
+func (p *DATPalette) IsTransparent(index byte) bool {
+	return index == p.TransparentIndex
 }
 
 func LoadDAT(data []byte) (*DATPalette, error) {
+	if len(data) < 256*3 {
+		return nil, errors.New("palette data is too short")
+	}
+
 	palette := &DATPalette{}
 
 	for i := 0; i < 256; i++ {
@@ -2,6 +2,7 @@ package d2dc6
 
 import (
 	"encoding/binary"
+	"fmt"
 
 	"github.com/go-restruct/restruct"
 )
@@ -16,7 +17,7 @@ type DC6File struct {
 	FramesPerDirection uint32 `struct:"uint32"`
 
 	FramePointers []uint32    `struct:"[]uint32,size=Directions*FramesPerDirection"`
-	Frames        []*DC6Frame `struct-size:"Directions*FramesPerDirection"`
+	Frames        []*DC6Frame `struct:"-"` // Read from the frame pointers, as frames can be followed by padding
 }
 
 type DC6Header struct {
@@ -53,6 +54,8 @@ type DC6Frame struct {
 }
 
 // LoadDC6 uses restruct to read the binary dc6 data into structs then parses image data from the frame data.
+// Each frame is read from its frame pointer, rather than directly after the previous frame, because the terminator
+// of a frame block can be followed by padding that would shift all of the frames after it.
 func LoadDC6(data []byte) (*DC6File, error) {
 	result := &DC6File{}
 
@@ -62,5 +65,18 @@ func LoadDC6(data []byte) (*DC6File, error) {
 		return nil, err
 	}
 
-	return result, err
+	result.Frames = make([]*DC6Frame, len(result.FramePointers))
+	for i, pointer := range result.FramePointers {
+		if uint64(pointer) >= uint64(len(data)) {
+			return nil, fmt.Errorf("frame %d starts at %d, outside of the %d byte file", i, pointer, len(data))
+		}
+
+		frame := &DC6Frame{}
+		if err := restruct.Unpack(data[pointer:], binary.LittleEndian, frame); err != nil {
+			return nil, fmt.Errorf("frame %d: %v", i, err)
+		}
+		result.Frames[i] = frame
+	}
+
+	return result, nil
 }
@@ -0,0 +1,88 @@
+package d2dc6
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+)
+
+// FrameImage decodes a single frame (counted across all of the directions) into an image with the palette, without
+// going through an animation (eg: for an icon in the UI). Pixels of the palette's transparent index (0 by default) and
+// pixels skipped by the frame are transparent.
+func (dc6 *DC6File) FrameImage(frameIndex int, palette *d2dat.DATPalette) (image.Image, error) {
+	if frameIndex < 0 || frameIndex >= len(dc6.Frames) {
+		return nil, fmt.Errorf("frame %d is out of range, the file has %d frames", frameIndex, len(dc6.Frames))
+	}
+
+	frame := dc6.Frames[frameIndex]
+	pixels, err := frame.DecodePixels(palette)
+	if err != nil {
+		return nil, fmt.Errorf("frame %d: %v", frameIndex, err)
+	}
+
+	// The decoded pixels are either opaque or fully transparent, so they are already alpha premultiplied
+	result := image.NewRGBA(image.Rect(0, 0, int(frame.Width), int(frame.Height)))
+	copy(result.Pix, pixels)
+	return result, nil
+}
+
+// DecodePixels decodes the run length encoded frame data into RGBA pixels with the palette, top row first. Pixels of
+// the palette's transparent index and pixels skipped by the frame are left transparent.
+func (f *DC6Frame) DecodePixels(palette *d2dat.DATPalette) ([]byte, error) {
+	width, height := int(f.Width), int(f.Height)
+	indexData := make([]int, width*height)
+	for i := range indexData {
+		indexData[i] = -1
+	}
+
+	errOverrun := errors.New("frame data runs past the frame")
+	x := 0
+	y := height - 1
+	offset := 0
+
+	for height > 0 {
+		if offset >= len(f.FrameData) {
+			return nil, errors.New("frame data ends before the last row")
+		}
+		b := int(f.FrameData[offset])
+		offset++
+
+		if b == 0x80 {
+			if y == 0 {
+				break
+			}
+			y--
+			x = 0
+		} else if b&0x80 > 0 {
+			transparentPixels := b & 0x7f
+			if x+transparentPixels > width {
+				return nil, errOverrun
+			}
+			x += transparentPixels
+		} else {
+			if x+b > width || offset+b > len(f.FrameData) {
+				return nil, errOverrun
+			}
+			for i := 0; i < b; i++ {
+				indexData[x+y*width+i] = int(f.FrameData[offset])
+				offset++
+			}
+			x += b
+		}
+	}
+
+	colorData := make([]byte, width*height*4)
+	for i, index := range indexData {
+		if index < 0 || palette.IsTransparent(byte(index)) {
+			continue
+		}
+		colorData[i*4] = palette.Colors[index].R
+		colorData[i*4+1] = palette.Colors[index].G
+		colorData[i*4+2] = palette.Colors[index].B
+		colorData[i*4+3] = 0xff
+	}
+
+	return colorData, nil
+}
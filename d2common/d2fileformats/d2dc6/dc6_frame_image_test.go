@@ -0,0 +1,108 @@
+package d2dc6
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+)
+
+func TestDecodePixels(t *testing.T) {
+	assert := testify.New(t)
+	palette := &d2dat.DATPalette{}
+	palette.Colors[5] = d2dat.DATColor{R: 10, G: 20, B: 30}
+
+	// A 2x2 frame: the bottom row is one transparent pixel and one opaque pixel, the top row is opaque then transparent
+	frame := &DC6Frame{
+		Width:     2,
+		Height:    2,
+		FrameData: []byte{0x81, 0x01, 5, 0x80, 0x01, 5, 0x80},
+	}
+
+	pixels, err := frame.DecodePixels(palette)
+	assert.Nil(err)
+	assert.Equal([]byte{
+		10, 20, 30, 0xff, 0, 0, 0, 0,
+		0, 0, 0, 0, 10, 20, 30, 0xff,
+	}, pixels)
+}
+
+func TestDecodePixelsWithTransparentIndex(t *testing.T) {
+	assert := testify.New(t)
+	palette := &d2dat.DATPalette{TransparentIndex: 7}
+	palette.Colors[0] = d2dat.DATColor{R: 1, G: 2, B: 3}
+	palette.Colors[7] = d2dat.DATColor{R: 255, G: 0, B: 255}
+
+	// A 2x1 frame of index 0 followed by the palette's transparent index
+	frame := &DC6Frame{
+		Width:     2,
+		Height:    1,
+		FrameData: []byte{0x02, 0, 7, 0x80},
+	}
+
+	pixels, err := frame.DecodePixels(palette)
+	assert.Nil(err)
+	assert.Equal([]byte{
+		1, 2, 3, 0xff, 0, 0, 0, 0,
+	}, pixels)
+}
+
+func TestDecodePixelsRejectsOverrun(t *testing.T) {
+	assert := testify.New(t)
+	palette := &d2dat.DATPalette{}
+
+	// Three pixels in a row of a 2x1 frame
+	_, err := (&DC6Frame{Width: 2, Height: 1, FrameData: []byte{0x03, 1, 2, 3, 0x80}}).DecodePixels(palette)
+	assert.NotNil(err)
+
+	// A row that is never terminated
+	_, err = (&DC6Frame{Width: 2, Height: 1, FrameData: []byte{0x02, 1, 2}}).DecodePixels(palette)
+	assert.NotNil(err)
+}
+
+// Returns a palette where each index is its own gray
+func createTestGrayPalette() *d2dat.DATPalette {
+	palette := &d2dat.DATPalette{}
+	for i := range palette.Colors {
+		palette.Colors[i] = d2dat.DATColor{R: uint8(i), G: uint8(i), B: uint8(i)}
+	}
+	return palette
+}
+
+func TestFrameImageDecodesRequestedFrame(t *testing.T) {
+	assert := testify.New(t)
+	dc6 := loadTestDC6(t, "multiframe.dc6")
+
+	// The fourth frame (the second frame of the second direction) is 4x1, with the pixels 40-43
+	frameImage, err := dc6.FrameImage(3, createTestGrayPalette())
+	assert.Nil(err)
+	assert.Equal(image.Rect(0, 0, 4, 1), frameImage.Bounds())
+	for x := 0; x < 4; x++ {
+		gray := uint8(40 + x)
+		assert.Equal(color.RGBA{R: gray, G: gray, B: gray, A: 0xff}, frameImage.At(x, 0))
+	}
+}
+
+func TestFrameImageTransparentIndex(t *testing.T) {
+	assert := testify.New(t)
+	dc6 := &DC6File{Frames: []*DC6Frame{{Width: 3, Height: 1, FrameData: []byte{0x02, 0, 9, 0x80}}}}
+
+	frameImage, err := dc6.FrameImage(0, createTestGrayPalette())
+	assert.Nil(err)
+	assert.Equal(color.RGBA{}, frameImage.At(0, 0))
+	assert.Equal(color.RGBA{R: 9, G: 9, B: 9, A: 0xff}, frameImage.At(1, 0))
+	assert.Equal(color.RGBA{}, frameImage.At(2, 0))
+}
+
+func TestFrameImageOutOfRange(t *testing.T) {
+	assert := testify.New(t)
+	dc6 := loadTestDC6(t, "multiframe.dc6")
+
+	_, err := dc6.FrameImage(4, createTestGrayPalette())
+	assert.EqualError(err, "frame 4 is out of range, the file has 4 frames")
+	_, err = dc6.FrameImage(-1, createTestGrayPalette())
+	assert.NotNil(err)
+}
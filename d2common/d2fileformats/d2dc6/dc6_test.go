@@ -0,0 +1,65 @@
+package d2dc6
+
+import (
+	"io/ioutil"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func loadTestDC6(t *testing.T, fileName string) *DC6File {
+	data, err := ioutil.ReadFile("testdata/" + fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dc6, err := LoadDC6(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dc6
+}
+
+// The fixture has 2 directions of 2 frames. Frame i is i+1 pixels wide and 1 pixel high, with pixel j set to palette
+// index 10*(i+1)+j. The frames are followed by padding of varying length after their terminators, as in some of the
+// original files, so they can only be found through the frame pointers.
+func TestLoadDC6DecodesEveryFrameOfMultiFrameFile(t *testing.T) {
+	assert := testify.New(t)
+	dc6 := loadTestDC6(t, "multiframe.dc6")
+
+	assert.Equal(uint32(2), dc6.Directions)
+	assert.Equal(uint32(2), dc6.FramesPerDirection)
+	assert.Equal([]uint32{40, 79, 118, 164}, dc6.FramePointers)
+	assert.Len(dc6.Frames, 4)
+
+	for i, frame := range dc6.Frames {
+		width := i + 1
+		expectedData := []byte{byte(width)}
+		for j := 0; j < width; j++ {
+			expectedData = append(expectedData, byte(10*(i+1)+j))
+		}
+		expectedData = append(expectedData, 0x80)
+
+		assert.Equal(uint32(width), frame.Width, "frame %d", i)
+		assert.Equal(uint32(1), frame.Height, "frame %d", i)
+		assert.Equal(int32(i*2-3), frame.OffsetX, "frame %d", i)
+		assert.Equal(int32(i-5), frame.OffsetY, "frame %d", i)
+		assert.Equal(uint32(len(expectedData)), frame.Length, "frame %d", i)
+		assert.Equal(expectedData, frame.FrameData, "frame %d", i)
+		assert.Equal([]byte{0xee, 0xee, 0xee}, frame.Terminator, "frame %d", i)
+	}
+}
+
+func TestLoadDC6RejectsFramePointerOutsideFile(t *testing.T) {
+	assert := testify.New(t)
+	data, err := ioutil.ReadFile("testdata/multiframe.dc6")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Point the last frame past the end of the file
+	data[36] = 0xff
+
+	_, err = LoadDC6(data)
+	assert.NotNil(err)
+}
@@ -2,6 +2,7 @@ package d2dcc
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/OpenDiablo2/OpenDiablo2/d2common"
 )
@@ -14,27 +15,52 @@ type DCC struct {
 	Directions         []DCCDirection
 }
 
+// The size of the file header before the direction offsets, in bytes
+const dccHeaderSize = 15
+
 func LoadDCC(fileData []byte) (*DCC, error) {
+	result, directionOffsets, err := loadDCCHeader(fileData)
+	if err != nil {
+		return nil, err
+	}
+	result.Directions = make([]DCCDirection, result.NumberOfDirections)
+	for i := 0; i < result.NumberOfDirections; i++ {
+		result.Directions[i] = CreateDCCDirection(d2common.CreateBitMuncher(fileData, directionOffsets[i]*8), *result)
+	}
+	return result, nil
+}
+
+// Reads the file header and the byte offset of each direction, which both LoadDCC and Probe start from
+func loadDCCHeader(fileData []byte) (*DCC, []int, error) {
+	if len(fileData) < dccHeaderSize {
+		return nil, nil, errors.New("file is too short to contain a DCC header")
+	}
 	result := &DCC{}
 	var bm = d2common.CreateBitMuncher(fileData, 0)
 	result.Signature = int(bm.GetByte())
 	if result.Signature != 0x74 {
-		return nil, errors.New("signature expected to be 0x74 but it is not")
+		return nil, nil, errors.New("signature expected to be 0x74 but it is not")
 	}
 	result.Version = int(bm.GetByte())
 	result.NumberOfDirections = int(bm.GetByte())
 	result.FramesPerDirection = int(bm.GetInt32())
+	// Every frame header takes at least a bit, so a file cannot hold more frames than it has bits
+	if result.FramesPerDirection <= 0 || result.FramesPerDirection > len(fileData)*8 {
+		return nil, nil, fmt.Errorf("invalid number of frames per direction: %d", result.FramesPerDirection)
+	}
 	if bm.GetInt32() != 1 {
-		return nil, errors.New("this value isn't 1. It has to be 1")
+		return nil, nil, errors.New("this value isn't 1. It has to be 1")
 	}
 	bm.GetInt32() // TotalSizeCoded
+	if len(fileData) < dccHeaderSize+result.NumberOfDirections*4 {
+		return nil, nil, errors.New("file is too short to contain the direction offsets")
+	}
 	directionOffsets := make([]int, result.NumberOfDirections)
 	for i := 0; i < result.NumberOfDirections; i++ {
 		directionOffsets[i] = int(bm.GetInt32())
+		if directionOffsets[i] < 0 || directionOffsets[i] >= len(fileData) {
+			return nil, nil, fmt.Errorf("direction %d starts outside of the file", i)
+		}
 	}
-	result.Directions = make([]DCCDirection, result.NumberOfDirections)
-	for i := 0; i < result.NumberOfDirections; i++ {
-		result.Directions[i] = CreateDCCDirection(d2common.CreateBitMuncher(fileData, directionOffsets[i]*8), *result)
-	}
-	return result, nil
+	return result, directionOffsets, nil
 }
@@ -31,30 +31,7 @@ type DCCDirection struct {
 }
 
 func CreateDCCDirection(bm *d2common.BitMuncher, file DCC) DCCDirection {
-	result := DCCDirection{}
-	result.OutSizeCoded = int(bm.GetUInt32())
-	result.CompressionFlags = int(bm.GetBits(2))
-	result.Variable0Bits = int(crazyBitTable[bm.GetBits(4)])
-	result.WidthBits = int(crazyBitTable[bm.GetBits(4)])
-	result.HeightBits = int(crazyBitTable[bm.GetBits(4)])
-	result.XOffsetBits = int(crazyBitTable[bm.GetBits(4)])
-	result.YOffsetBits = int(crazyBitTable[bm.GetBits(4)])
-	result.OptionalDataBits = int(crazyBitTable[bm.GetBits(4)])
-	result.CodedBytesBits = int(crazyBitTable[bm.GetBits(4)])
-	result.Frames = make([]*DCCDirectionFrame, file.FramesPerDirection)
-	minx := 100000
-	miny := 100000
-	maxx := -100000
-	maxy := -100000
-	// Load the frame headers
-	for frameIdx := 0; frameIdx < file.FramesPerDirection; frameIdx++ {
-		result.Frames[frameIdx] = CreateDCCDirectionFrame(bm, result)
-		minx = int(d2common.MinInt32(int32(result.Frames[frameIdx].Box.Left), int32(minx)))
-		miny = int(d2common.MinInt32(int32(result.Frames[frameIdx].Box.Top), int32(miny)))
-		maxx = int(d2common.MaxInt32(int32(result.Frames[frameIdx].Box.Right()), int32(maxx)))
-		maxy = int(d2common.MaxInt32(int32(result.Frames[frameIdx].Box.Bottom()), int32(maxy)))
-	}
-	result.Box = d2common.Rectangle{Left: minx, Top: miny, Width: maxx - minx, Height: maxy - miny}
+	result := readDCCDirectionHeader(bm, file)
 	if result.OptionalDataBits > 0 {
 		log.Panic("Optional bits in DCC data is not currently supported.")
 	}
@@ -358,3 +335,33 @@ func (v *DCCDirection) CalculateCells() {
 		yOffset += 4
 	}
 }
+
+// Reads the direction header and the frame headers that follow it, which both CreateDCCDirection and Probe start
+// from. The bit muncher is left at the start of the bitstream sizes.
+func readDCCDirectionHeader(bm *d2common.BitMuncher, file DCC) DCCDirection {
+	result := DCCDirection{}
+	result.OutSizeCoded = int(bm.GetUInt32())
+	result.CompressionFlags = int(bm.GetBits(2))
+	result.Variable0Bits = int(crazyBitTable[bm.GetBits(4)])
+	result.WidthBits = int(crazyBitTable[bm.GetBits(4)])
+	result.HeightBits = int(crazyBitTable[bm.GetBits(4)])
+	result.XOffsetBits = int(crazyBitTable[bm.GetBits(4)])
+	result.YOffsetBits = int(crazyBitTable[bm.GetBits(4)])
+	result.OptionalDataBits = int(crazyBitTable[bm.GetBits(4)])
+	result.CodedBytesBits = int(crazyBitTable[bm.GetBits(4)])
+	result.Frames = make([]*DCCDirectionFrame, file.FramesPerDirection)
+	minx := 100000
+	miny := 100000
+	maxx := -100000
+	maxy := -100000
+	// Load the frame headers
+	for frameIdx := 0; frameIdx < file.FramesPerDirection; frameIdx++ {
+		result.Frames[frameIdx] = CreateDCCDirectionFrame(bm, result)
+		minx = int(d2common.MinInt32(int32(result.Frames[frameIdx].Box.Left), int32(minx)))
+		miny = int(d2common.MinInt32(int32(result.Frames[frameIdx].Box.Top), int32(miny)))
+		maxx = int(d2common.MaxInt32(int32(result.Frames[frameIdx].Box.Right()), int32(maxx)))
+		maxy = int(d2common.MaxInt32(int32(result.Frames[frameIdx].Box.Bottom()), int32(maxy)))
+	}
+	result.Box = d2common.Rectangle{Left: minx, Top: miny, Width: maxx - minx, Height: maxy - miny}
+	return result
+}
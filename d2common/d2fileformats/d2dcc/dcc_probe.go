@@ -0,0 +1,81 @@
+package d2dcc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+)
+
+// DCCInfo is the layout of the animation in a DCC file, read without decoding its pixels
+type DCCInfo struct {
+	NumberOfDirections int
+	FramesPerDirection int
+	Directions         []DCCDirectionInfo
+}
+
+// DCCDirectionInfo is the layout of one direction of a DCC animation
+type DCCDirectionInfo struct {
+	Box    d2common.Rectangle // The bounds of all of the frames of the direction
+	Frames []DCCFrameInfo
+}
+
+// DCCFrameInfo is the size and offset of one frame of a DCC animation
+type DCCFrameInfo struct {
+	Width   int
+	Height  int
+	XOffset int
+	YOffset int
+}
+
+// Probe reads the number of directions and frames of a DCC file, and the size and offset of each frame, without
+// decoding the pixel data (eg: to list the dimensions of animations in an asset browser). The headers are read by the
+// same code as LoadDCC.
+func Probe(data []byte) (*DCCInfo, error) {
+	file, directionOffsets, err := loadDCCHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DCCInfo{
+		NumberOfDirections: file.NumberOfDirections,
+		FramesPerDirection: file.FramesPerDirection,
+		Directions:         make([]DCCDirectionInfo, file.NumberOfDirections),
+	}
+	for i, offset := range directionOffsets {
+		if err := checkDCCDirectionHeader(data, offset, *file); err != nil {
+			return nil, fmt.Errorf("direction %d: %v", i, err)
+		}
+		direction := readDCCDirectionHeader(d2common.CreateBitMuncher(data, offset*8), *file)
+		frames := make([]DCCFrameInfo, len(direction.Frames))
+		for j, frame := range direction.Frames {
+			frames[j] = DCCFrameInfo{Width: frame.Width, Height: frame.Height, XOffset: frame.XOffset, YOffset: frame.YOffset}
+		}
+		result.Directions[i] = DCCDirectionInfo{Box: direction.Box, Frames: frames}
+	}
+	return result, nil
+}
+
+// The size of the fields of a direction header before its frame headers, in bits: the coded size, the compression
+// flags and the seven field sizes
+const dccDirectionFieldsBits = 32 + 2 + 7*4
+
+// Returns an error if the headers of the direction starting at the byte offset, and of its frames, run past the end of
+// the data, so they can be read without running out of bits
+func checkDCCDirectionHeader(data []byte, offset int, file DCC) error {
+	available := (len(data) - offset) * 8
+	if available < dccDirectionFieldsBits {
+		return errors.New("the direction header is truncated")
+	}
+
+	bm := d2common.CreateBitMuncher(data, offset*8)
+	bm.SkipBits(32 + 2)
+	frameBits := 1 // FrameIsBottomUp
+	for field := 0; field < 7; field++ {
+		frameBits += int(crazyBitTable[bm.GetBits(4)])
+	}
+	if available < dccDirectionFieldsBits+frameBits*file.FramesPerDirection {
+		return errors.New("the frame headers are truncated")
+	}
+	return nil
+}
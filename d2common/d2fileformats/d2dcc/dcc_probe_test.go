@@ -0,0 +1,132 @@
+package d2dcc
+
+import (
+	"encoding/binary"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+)
+
+// testBitWriter writes values least significant bit first, as the bit muncher reads them
+type testBitWriter struct {
+	data []byte
+	bits int
+}
+
+func (w *testBitWriter) write(value uint32, bits int) {
+	for i := 0; i < bits; i++ {
+		if w.bits%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		w.data[w.bits/8] |= byte((value>>uint(i))&1) << uint(w.bits%8)
+		w.bits++
+	}
+}
+
+// The index of 8 bits in the table of field sizes
+const testFieldSizeIndex = 5
+
+// Returns the headers of a DCC file with a direction for each list of frames. Each frame is {width, height, x, y}, with
+// the fields stored in 8 bits. Only the headers are written, so the file can be probed but not decoded.
+func createTestDCCHeaders(directions ...[][4]int) []byte {
+	header := []byte{0x74, 6, byte(len(directions))}
+	header = append(header, make([]byte, 12+4*len(directions))...)
+	binary.LittleEndian.PutUint32(header[3:], uint32(len(directions[0])))
+	binary.LittleEndian.PutUint32(header[7:], 1)
+
+	data := header
+	for i, frames := range directions {
+		binary.LittleEndian.PutUint32(data[dccHeaderSize+4*i:], uint32(len(data)))
+		writer := &testBitWriter{}
+		writer.write(0, 32) // OutSizeCoded
+		writer.write(0, 2)  // CompressionFlags
+		writer.write(0, 4)  // Variable0Bits
+		for field := 0; field < 4; field++ {
+			writer.write(testFieldSizeIndex, 4)
+		}
+		writer.write(0, 4) // OptionalDataBits
+		writer.write(0, 4) // CodedBytesBits
+		for _, frame := range frames {
+			for _, field := range frame {
+				writer.write(uint32(field), 8)
+			}
+			writer.write(0, 1) // FrameIsBottomUp
+		}
+		data = append(data, writer.data...)
+	}
+	return data
+}
+
+func TestProbeReadsDirectionsAndFrames(t *testing.T) {
+	assert := testify.New(t)
+	data := createTestDCCHeaders(
+		[][4]int{{10, 20, 1, 2}, {12, 4, 3, 0xFE}},
+		[][4]int{{5, 6, 0xFF, 7}, {8, 9, 0, 0}},
+	)
+
+	info, err := Probe(data)
+	assert.Nil(err)
+	assert.Equal(2, info.NumberOfDirections)
+	assert.Equal(2, info.FramesPerDirection)
+	assert.Len(info.Directions, 2)
+
+	assert.Equal([]DCCFrameInfo{{Width: 10, Height: 20, XOffset: 1, YOffset: 2}, {Width: 12, Height: 4, XOffset: 3, YOffset: -2}},
+		info.Directions[0].Frames)
+	assert.Equal([]DCCFrameInfo{{Width: 5, Height: 6, XOffset: -1, YOffset: 7}, {Width: 8, Height: 9, XOffset: 0, YOffset: 0}},
+		info.Directions[1].Frames)
+
+	// The frames hang up from their offset, so the first direction spans x 1-15 and y -17 to 2
+	assert.Equal(d2common.Rectangle{Left: 1, Top: -17, Width: 14, Height: 20}, info.Directions[0].Box)
+}
+
+func TestProbeRejectsInvalidFiles(t *testing.T) {
+	assert := testify.New(t)
+	data := createTestDCCHeaders([][4]int{{1, 1, 0, 0}})
+
+	_, err := Probe(data[:dccHeaderSize-1])
+	assert.NotNil(err)
+
+	_, err = Probe(data[:dccHeaderSize+2])
+	assert.NotNil(err)
+
+	badSignature := append([]byte{}, data...)
+	badSignature[0] = 0x75
+	_, err = Probe(badSignature)
+	assert.NotNil(err)
+
+	_, err = Probe(data[:len(data)-1])
+	assert.NotNil(err)
+
+	for _, frames := range []uint32{0, 0xFFFFFFFF, 0x7FFFFFFF} {
+		badFrames := append([]byte{}, data...)
+		binary.LittleEndian.PutUint32(badFrames[3:], frames)
+		assert.NotPanics(func() {
+			_, err = Probe(badFrames)
+			assert.NotNil(err)
+		})
+	}
+
+	badOffset := append([]byte{}, data...)
+	binary.LittleEndian.PutUint32(badOffset[dccHeaderSize:], uint32(len(data)))
+	_, err = Probe(badOffset)
+	assert.NotNil(err)
+}
+
+func TestLoadDCCRejectsTruncatedHeader(t *testing.T) {
+	_, err := LoadDCC([]byte{0x74, 6})
+	testify.NotNil(t, err)
+}
+
+func TestProbeRejectsTruncatedDirection(t *testing.T) {
+	assert := testify.New(t)
+
+	// The only direction starts at the last byte of the file, partway through its header
+	data := createTestDCCHeaders([][4]int{{1, 1, 0, 0}})[:dccHeaderSize+4]
+	binary.LittleEndian.PutUint32(data[dccHeaderSize:], uint32(len(data)-1))
+	assert.NotPanics(func() {
+		_, err := Probe(data)
+		assert.NotNil(err)
+	})
+}
@@ -11,7 +11,7 @@ type DS1 struct {
 	Version                    int32           // The version of the DS1
 	Width                      int32           // Width of map, in # of tiles
 	Height                     int32           // Height of map, in # of tiles
-	Act                        int32           // Act, from 1 to 5. This tells which act table to use for the Objects list
+	Act                        int32           // Act, from 1 to 5 (higher acts use the act 5 table), which tells which act table the Objects are in
 	SubstitutionType           int32           // SubstitutionType (layer type): 0 if no layer, else type 1 or type 2
 	Files                      []string        // FilePtr table of file string pointers
 	NumberOfWalls              int32           // WallNum number of wall & orientation layers used
@@ -19,9 +19,12 @@ type DS1 struct {
 	NumberOfShadowLayers       int32           // ShadowNum number of shadow layer used
 	NumberOfSubstitutionLayers int32           // SubstitutionNum number of substitution layer used
 	SubstitutionGroupsNum      int32           // SubstitutionGroupsNum number of substitution groups, datas between objects & NPC paths
+	UnknownHeaderData          []byte          // The two dwords before the layer counts in versions 9 to 13, preserved for Marshal
+	SubstitutionGroupsUnknown  uint32          // The dword before the substitution groups in versions 18 and up
 	Objects                    []d2data.Object // Objects
 	Tiles                      [][]TileRecord
 	SubstitutionGroups         []SubstitutionGroup
+	UnknownTrailingData        []byte // Unrecognized data after the NPC paths, preserved verbatim so the file can be re-saved losslessly
 }
 
 func LoadDS1(fileData []byte) (*DS1, error) {
@@ -37,7 +40,7 @@ func LoadDS1(fileData []byte) (*DS1, error) {
 	ds1.Width = br.GetInt32() + 1
 	ds1.Height = br.GetInt32() + 1
 	if ds1.Version >= 8 {
-		ds1.Act = d2common.MinInt32(5, br.GetInt32()+1)
+		ds1.Act = br.GetInt32() + 1
 	}
 	if ds1.Version >= 10 {
 		ds1.SubstitutionType = br.GetInt32()
@@ -61,8 +64,9 @@ func LoadDS1(fileData []byte) (*DS1, error) {
 		}
 	}
 	if ds1.Version >= 9 && ds1.Version <= 13 {
-		// Skipping two dwords because they are "meaningless"?
-		br.SkipBytes(8)
+		// Two dwords that seem to be meaningless, kept so that the file can be re-saved as it was
+		ds1.UnknownHeaderData = make([]byte, 8)
+		copy(ds1.UnknownHeaderData, br.ReadBytes(8))
 	}
 	if ds1.Version >= 4 {
 		ds1.NumberOfWalls = br.GetInt32()
@@ -72,36 +76,7 @@ func LoadDS1(fileData []byte) (*DS1, error) {
 			ds1.NumberOfFloors = 1
 		}
 	}
-	var layerStream []d2enum.LayerStreamType
-	if ds1.Version < 4 {
-		layerStream = []d2enum.LayerStreamType{
-			d2enum.LayerStreamWall1,
-			d2enum.LayerStreamFloor1,
-			d2enum.LayerStreamOrientation1,
-			d2enum.LayerStreamSubstitute,
-			d2enum.LayerStreamShadow,
-		}
-	} else {
-		layerStream = make([]d2enum.LayerStreamType, (ds1.NumberOfWalls*2)+ds1.NumberOfFloors+ds1.NumberOfShadowLayers+ds1.NumberOfSubstitutionLayers)
-		layerIdx := 0
-		for i := 0; i < int(ds1.NumberOfWalls); i++ {
-			layerStream[layerIdx] = d2enum.LayerStreamType(int(d2enum.LayerStreamWall1) + i)
-			layerStream[layerIdx+1] = d2enum.LayerStreamType(int(d2enum.LayerStreamOrientation1) + i)
-			layerIdx += 2
-		}
-		for i := 0; i < int(ds1.NumberOfFloors); i++ {
-			layerStream[layerIdx] = d2enum.LayerStreamType(int(d2enum.LayerStreamFloor1) + i)
-			layerIdx++
-		}
-		if ds1.NumberOfShadowLayers > 0 {
-			layerStream[layerIdx] = d2enum.LayerStreamShadow
-			layerIdx++
-		}
-		if ds1.NumberOfSubstitutionLayers > 0 {
-			layerStream[layerIdx] = d2enum.LayerStreamSubstitute
-			layerIdx++
-		}
-	}
+	layerStream := ds1.layerStreams()
 	ds1.Tiles = make([][]TileRecord, ds1.Height)
 	for y := range ds1.Tiles {
 		ds1.Tiles[y] = make([]TileRecord, ds1.Width)
@@ -146,7 +121,7 @@ func LoadDS1(fileData []byte) (*DS1, error) {
 						}
 					}
 					ds1.Tiles[y][x].Walls[wallIndex].Type = d2enum.TileType(c)
-					ds1.Tiles[y][x].Walls[wallIndex].Zero = byte((dw & 0xFFFFFF00) >> 8)
+					ds1.Tiles[y][x].Walls[wallIndex].Zero = (dw & 0xFFFFFF00) >> 8
 				case d2enum.LayerStreamFloor1:
 					fallthrough
 				case d2enum.LayerStreamFloor2:
@@ -180,7 +155,7 @@ func LoadDS1(fileData []byte) (*DS1, error) {
 			newObject.X = int(br.GetInt32())
 			newObject.Y = int(br.GetInt32())
 			newObject.Flags = int(br.GetInt32())
-			newObject.Lookup = d2datadict.LookupObject(int(ds1.Act), newObject.Type, newObject.Id)
+			newObject.Lookup = d2datadict.LookupObject(int(d2common.MinInt32(5, ds1.Act)), newObject.Type, newObject.Id)
 			if newObject.Lookup != nil && newObject.Lookup.ObjectsTxtId != -1 {
 				newObject.ObjectInfo = d2datadict.Objects[newObject.Lookup.ObjectsTxtId]
 			}
@@ -191,7 +166,7 @@ func LoadDS1(fileData []byte) (*DS1, error) {
 	}
 	if ds1.Version >= 12 && (ds1.SubstitutionType == 1 || ds1.SubstitutionType == 2) {
 		if ds1.Version >= 18 {
-			br.GetUInt32()
+			ds1.SubstitutionGroupsUnknown = br.GetUInt32()
 		}
 		numberOfSubGroups := br.GetInt32()
 		ds1.SubstitutionGroups = make([]SubstitutionGroup, numberOfSubGroups)
@@ -236,12 +211,51 @@ func LoadDS1(fileData []byte) (*DS1, error) {
 				}
 			} else {
 				if ds1.Version >= 15 {
-					br.SkipBytes(int(numPaths) * 3)
+					br.SkipBytes(int(numPaths) * 12) // Three dwords per path point
 				} else {
-					br.SkipBytes(int(numPaths) * 2)
+					br.SkipBytes(int(numPaths) * 8) // Two dwords per path point
 				}
 			}
 		}
 	}
+	if !br.Eof() {
+		trailingData := br.ReadBytes(int(br.GetSize() - br.GetPosition()))
+		ds1.UnknownTrailingData = make([]byte, len(trailingData))
+		copy(ds1.UnknownTrailingData, trailingData)
+	}
 	return ds1, nil
 }
+
+// Returns the order the tile layers are stored in, which depends on the version and the layer counts. The layers are
+// read and written in this order, so LoadDS1 and Marshal cannot disagree about it.
+func (ds1 *DS1) layerStreams() []d2enum.LayerStreamType {
+	if ds1.Version < 4 {
+		return []d2enum.LayerStreamType{
+			d2enum.LayerStreamWall1,
+			d2enum.LayerStreamFloor1,
+			d2enum.LayerStreamOrientation1,
+			d2enum.LayerStreamSubstitute,
+			d2enum.LayerStreamShadow,
+		}
+	}
+
+	layerStream := make([]d2enum.LayerStreamType, (ds1.NumberOfWalls*2)+ds1.NumberOfFloors+ds1.NumberOfShadowLayers+ds1.NumberOfSubstitutionLayers)
+	layerIdx := 0
+	for i := 0; i < int(ds1.NumberOfWalls); i++ {
+		layerStream[layerIdx] = d2enum.LayerStreamType(int(d2enum.LayerStreamWall1) + i)
+		layerStream[layerIdx+1] = d2enum.LayerStreamType(int(d2enum.LayerStreamOrientation1) + i)
+		layerIdx += 2
+	}
+	for i := 0; i < int(ds1.NumberOfFloors); i++ {
+		layerStream[layerIdx] = d2enum.LayerStreamType(int(d2enum.LayerStreamFloor1) + i)
+		layerIdx++
+	}
+	if ds1.NumberOfShadowLayers > 0 {
+		layerStream[layerIdx] = d2enum.LayerStreamShadow
+		layerIdx++
+	}
+	if ds1.NumberOfSubstitutionLayers > 0 {
+		layerStream[layerIdx] = d2enum.LayerStreamSubstitute
+	}
+	return layerStream
+}
@@ -0,0 +1,211 @@
+package d2ds1
+
+import (
+	"fmt"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+)
+
+// Marshal encodes the DS1 back into the file format read by LoadDS1 (eg: to save a map after editing it). The layers
+// are written in the same order they are read, and the bits of each tile record are packed as they are unpacked, so a
+// file that is loaded and marshalled again is unchanged, except that NPC paths that do not start at an object are
+// dropped, as LoadDS1 does not keep them, and that versions before 7 may store an orientation as another entry of
+// their orientation lookup table.
+func (ds1 *DS1) Marshal() ([]byte, error) {
+	if err := ds1.validateLayout(); err != nil {
+		return nil, err
+	}
+
+	sw := d2common.CreateStreamWriter()
+	sw.PushUint32(uint32(ds1.Version))
+	sw.PushUint32(uint32(ds1.Width - 1))
+	sw.PushUint32(uint32(ds1.Height - 1))
+	if ds1.Version >= 8 {
+		sw.PushUint32(uint32(ds1.Act - 1))
+	}
+	if ds1.Version >= 10 {
+		sw.PushUint32(uint32(ds1.SubstitutionType))
+	}
+	if ds1.Version >= 3 {
+		sw.PushUint32(uint32(len(ds1.Files)))
+		for _, file := range ds1.Files {
+			for i := 0; i < len(file); i++ {
+				sw.PushByte(file[i])
+			}
+			sw.PushByte(0)
+		}
+	}
+	if ds1.Version >= 9 && ds1.Version <= 13 {
+		for i := 0; i < 8; i++ {
+			if i < len(ds1.UnknownHeaderData) {
+				sw.PushByte(ds1.UnknownHeaderData[i])
+			} else {
+				sw.PushByte(0)
+			}
+		}
+	}
+	if ds1.Version >= 4 {
+		sw.PushUint32(uint32(ds1.NumberOfWalls))
+		if ds1.Version >= 16 {
+			sw.PushUint32(uint32(ds1.NumberOfFloors))
+		}
+	}
+
+	for _, layerStreamType := range ds1.layerStreams() {
+		for y := 0; y < int(ds1.Height); y++ {
+			for x := 0; x < int(ds1.Width); x++ {
+				sw.PushUint32(ds1.encodeLayer(&ds1.Tiles[y][x], layerStreamType))
+			}
+		}
+	}
+
+	if ds1.Version >= 2 {
+		sw.PushUint32(uint32(len(ds1.Objects)))
+		for _, object := range ds1.Objects {
+			sw.PushUint32(uint32(object.Type))
+			sw.PushUint32(uint32(object.Id))
+			sw.PushUint32(uint32(object.X))
+			sw.PushUint32(uint32(object.Y))
+			sw.PushUint32(uint32(object.Flags))
+		}
+	}
+	if ds1.Version >= 12 && (ds1.SubstitutionType == 1 || ds1.SubstitutionType == 2) {
+		if ds1.Version >= 18 {
+			sw.PushUint32(ds1.SubstitutionGroupsUnknown)
+		}
+		sw.PushUint32(uint32(len(ds1.SubstitutionGroups)))
+		for _, group := range ds1.SubstitutionGroups {
+			sw.PushUint32(uint32(group.TileX))
+			sw.PushUint32(uint32(group.TileY))
+			sw.PushUint32(uint32(group.WidthInTiles))
+			sw.PushUint32(uint32(group.HeightInTiles))
+			sw.PushUint32(uint32(group.Unknown))
+		}
+	}
+	if ds1.Version >= 14 {
+		ds1.marshalNpcPaths(sw)
+	}
+
+	data := sw.GetBytes()
+	return append(data, ds1.UnknownTrailingData...), nil
+}
+
+// Returns an error if the tiles do not have the size and number of layers given by the header
+func (ds1 *DS1) validateLayout() error {
+	if ds1.Width < 1 || ds1.Height < 1 {
+		return fmt.Errorf("map size %dx%d is invalid", ds1.Width, ds1.Height)
+	}
+	if len(ds1.Tiles) != int(ds1.Height) {
+		return fmt.Errorf("map is %d tiles high but has %d rows of tiles", ds1.Height, len(ds1.Tiles))
+	}
+	for y, row := range ds1.Tiles {
+		if len(row) != int(ds1.Width) {
+			return fmt.Errorf("map is %d tiles wide but row %d has %d tiles", ds1.Width, y, len(row))
+		}
+		for x := range row {
+			tile := &row[x]
+			if len(tile.Walls) < int(ds1.NumberOfWalls) || len(tile.Floors) < int(ds1.NumberOfFloors) ||
+				len(tile.Shadows) < int(ds1.NumberOfShadowLayers) || len(tile.Substitutions) < int(ds1.NumberOfSubstitutionLayers) {
+				return fmt.Errorf("tile %d,%d has fewer layers than the map", x, y)
+			}
+		}
+	}
+	for i, file := range ds1.Files {
+		for j := 0; j < len(file); j++ {
+			if file[j] == 0 {
+				return fmt.Errorf("file %d contains a NUL character", i)
+			}
+		}
+	}
+	return nil
+}
+
+// Packs one layer of a tile into the dword it is stored as. Layers missing from the tile (which only happens in
+// versions before 4, which always store every layer) are written as empty.
+func (ds1 *DS1) encodeLayer(tile *TileRecord, layerStreamType d2enum.LayerStreamType) uint32 {
+	switch layerStreamType {
+	case d2enum.LayerStreamWall1, d2enum.LayerStreamWall2, d2enum.LayerStreamWall3, d2enum.LayerStreamWall4:
+		wallIndex := int(layerStreamType) - int(d2enum.LayerStreamWall1)
+		if wallIndex < len(tile.Walls) {
+			wall := &tile.Walls[wallIndex]
+			return encodeTileProps(wall.Prop1, wall.Sequence, wall.Unknown1, wall.Style, wall.Unknown2, wall.Hidden)
+		}
+	case d2enum.LayerStreamOrientation1, d2enum.LayerStreamOrientation2, d2enum.LayerStreamOrientation3,
+		d2enum.LayerStreamOrientation4:
+		wallIndex := int(layerStreamType) - int(d2enum.LayerStreamOrientation1)
+		if wallIndex < len(tile.Walls) {
+			wall := &tile.Walls[wallIndex]
+			return uint32(ds1.encodeOrientation(wall.Type)) | wall.Zero<<8
+		}
+	case d2enum.LayerStreamFloor1, d2enum.LayerStreamFloor2:
+		floorIndex := int(layerStreamType) - int(d2enum.LayerStreamFloor1)
+		if floorIndex < len(tile.Floors) {
+			floor := &tile.Floors[floorIndex]
+			return encodeTileProps(floor.Prop1, floor.Sequence, floor.Unknown1, floor.Style, floor.Unknown2, floor.Hidden)
+		}
+	case d2enum.LayerStreamShadow:
+		if len(tile.Shadows) > 0 {
+			shadow := &tile.Shadows[0]
+			return encodeTileProps(shadow.Prop1, shadow.Sequence, shadow.Unknown1, shadow.Style, shadow.Unknown2, shadow.Hidden)
+		}
+	case d2enum.LayerStreamSubstitute:
+		if len(tile.Substitutions) > 0 {
+			return tile.Substitutions[0].Unknown
+		}
+	}
+	return 0
+}
+
+// Packs the properties of a wall, floor or shadow record into the dword it is stored as
+func encodeTileProps(prop1, sequence, unknown1, style, unknown2 byte, hidden bool) uint32 {
+	dw := uint32(prop1) |
+		uint32(sequence&0x3F)<<8 |
+		uint32(unknown1&0x3F)<<14 |
+		uint32(style&0x3F)<<20 |
+		uint32(unknown2&0x1F)<<26
+	if hidden {
+		dw |= 0x80000000
+	}
+	return dw
+}
+
+// Returns the orientation as it is stored. Versions before 7 store the orientations through a lookup table, so the
+// first stored value that loads as the orientation is used.
+func (ds1 *DS1) encodeOrientation(tileType d2enum.TileType) byte {
+	if ds1.Version < 7 {
+		for stored, orientation := range dirLookup {
+			if orientation == int32(tileType) {
+				return byte(stored)
+			}
+		}
+	}
+	return byte(tileType)
+}
+
+// Writes the paths of the objects that have them, each keyed by the position of its object
+func (ds1 *DS1) marshalNpcPaths(sw *d2common.StreamWriter) {
+	numberOfNpcs := 0
+	for _, object := range ds1.Objects {
+		if object.Paths != nil {
+			numberOfNpcs++
+		}
+	}
+
+	sw.PushUint32(uint32(numberOfNpcs))
+	for _, object := range ds1.Objects {
+		if object.Paths == nil {
+			continue
+		}
+		sw.PushUint32(uint32(len(object.Paths)))
+		sw.PushUint32(uint32(object.X))
+		sw.PushUint32(uint32(object.Y))
+		for _, path := range object.Paths {
+			sw.PushUint32(uint32(path.X))
+			sw.PushUint32(uint32(path.Y))
+			if ds1.Version >= 15 {
+				sw.PushUint32(uint32(path.Action))
+			}
+		}
+	}
+}
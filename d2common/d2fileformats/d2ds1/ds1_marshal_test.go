@@ -0,0 +1,231 @@
+package d2ds1
+
+import (
+	"encoding/binary"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+)
+
+// testDS1Layout describes a DS1 file built by createTestDS1File
+type testDS1Layout struct {
+	version          uint32
+	width, height    int
+	walls, floors    int
+	substitutionType uint32
+	orphanNpc        bool // Whether the paths of an NPC that stands on no object come before those of the first object
+	trailingData     []byte
+}
+
+// Returns a different dword for each tile of each layer, using every bit of the dword
+func testLayerDword(layer, tileIndex int) uint32 {
+	return uint32(layer*0x9E3779B1) ^ uint32(tileIndex*0x85EBCA77) ^ 0xA5A5A5A5
+}
+
+// createTestDS1File builds a DS1 with every section its version stores: files, layers, two objects, a substitution
+// group, and the paths of an NPC standing on the first object, followed by the trailing data
+func createTestDS1File(layout testDS1Layout) []byte {
+	sw := d2common.CreateStreamWriter()
+	version := layout.version
+	sw.PushUint32(version)
+	sw.PushUint32(uint32(layout.width - 1))
+	sw.PushUint32(uint32(layout.height - 1))
+	if version >= 8 {
+		sw.PushUint32(2) // Act 3
+	}
+	if version >= 10 {
+		sw.PushUint32(layout.substitutionType)
+	}
+	sw.PushUint32(2) // Number of files
+	for _, name := range []string{"tiles\\act3\\jungle.tg1", "x"} {
+		for i := 0; i < len(name); i++ {
+			sw.PushByte(name[i])
+		}
+		sw.PushByte(0)
+	}
+	if version >= 9 && version <= 13 {
+		sw.PushUint32(0x11223344)
+		sw.PushUint32(0x55667788)
+	}
+	sw.PushUint32(uint32(layout.walls))
+	if version >= 16 {
+		sw.PushUint32(uint32(layout.floors))
+	}
+
+	substitutionLayers := 0
+	if version >= 10 && (layout.substitutionType == 1 || layout.substitutionType == 2) {
+		substitutionLayers = 1
+	}
+	layers := layout.walls*2 + layout.floors + 1 + substitutionLayers
+	for layer := 0; layer < layers; layer++ {
+		for i := 0; i < layout.width*layout.height; i++ {
+			sw.PushUint32(testLayerDword(layer, i))
+		}
+	}
+
+	sw.PushUint32(2) // Number of objects
+	for _, object := range [][5]uint32{{1, 17, 12, 34, 0}, {2, 5, 7, 8, 0x80}} {
+		for _, field := range object {
+			sw.PushUint32(field)
+		}
+	}
+
+	if version >= 12 && substitutionLayers > 0 {
+		if version >= 18 {
+			sw.PushUint32(0xCAFE)
+		}
+		sw.PushUint32(1) // Number of substitution groups
+		for _, field := range []uint32{1, 2, 3, 4, 5} {
+			sw.PushUint32(field)
+		}
+	}
+
+	if version >= 14 {
+		npcs := [][2]uint32{{12, 34}} // The position of the first object
+		if layout.orphanNpc {
+			npcs = [][2]uint32{{99, 99}, {12, 34}}
+		}
+		sw.PushUint32(uint32(len(npcs)))
+		for _, npc := range npcs {
+			pushTestNpcPaths(sw, version, npc[0], npc[1])
+		}
+	}
+
+	for _, b := range layout.trailingData {
+		sw.PushByte(b)
+	}
+	return sw.GetBytes()
+}
+
+// Writes the two paths of an NPC standing at the position
+func pushTestNpcPaths(sw *d2common.StreamWriter, version, x, y uint32) {
+	sw.PushUint32(2) // Number of paths
+	sw.PushUint32(x)
+	sw.PushUint32(y)
+	for _, path := range [][3]uint32{{13, 35, 1}, {20, 40, 2}} {
+		sw.PushUint32(path[0])
+		sw.PushUint32(path[1])
+		if version >= 15 {
+			sw.PushUint32(path[2])
+		}
+	}
+}
+
+// Loads the file, marshals it and loads the result again, checking that both loads read the same structure
+func roundTripDS1(t *testing.T, fileData []byte) (*DS1, []byte) {
+	assert := testify.New(t)
+	ds1, err := LoadDS1(fileData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	marshalled, err := ds1.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := LoadDS1(marshalled)
+	assert.Nil(err)
+	assert.Equal(ds1, reloaded)
+	return ds1, marshalled
+}
+
+func TestMarshalRoundTripsEverySection(t *testing.T) {
+	assert := testify.New(t)
+	fileData := createTestDS1File(testDS1Layout{
+		version: 18, width: 3, height: 2, walls: 2, floors: 2, substitutionType: 2,
+		trailingData: []byte{0xDE, 0xAD, 0xBE, 0xEF},
+	})
+
+	ds1, marshalled := roundTripDS1(t, fileData)
+	assert.Equal(fileData, marshalled)
+
+	assert.Equal(int32(3), ds1.Act)
+	assert.Equal([]string{"tiles\\act3\\jungle.tg1", "x"}, ds1.Files)
+	assert.Len(ds1.Tiles[1][2].Walls, 2)
+	assert.Len(ds1.Tiles[1][2].Floors, 2)
+	assert.Len(ds1.Tiles[1][2].Substitutions, 1)
+	assert.Equal(uint32(0xCAFE), ds1.SubstitutionGroupsUnknown)
+	assert.Equal([]SubstitutionGroup{{TileX: 1, TileY: 2, WidthInTiles: 3, HeightInTiles: 4, Unknown: 5}}, ds1.SubstitutionGroups)
+	assert.Len(ds1.Objects[0].Paths, 2)
+	assert.Equal(2, ds1.Objects[0].Paths[1].Action)
+	assert.Nil(ds1.Objects[1].Paths)
+	assert.Equal([]byte{0xDE, 0xAD, 0xBE, 0xEF}, ds1.UnknownTrailingData)
+}
+
+func TestMarshalRoundTripsOlderVersions(t *testing.T) {
+	assert := testify.New(t)
+	for _, version := range []uint32{7, 8, 12, 14, 15, 16} {
+		fileData := createTestDS1File(testDS1Layout{version: version, width: 2, height: 3, walls: 1, floors: 1, substitutionType: 1})
+		_, marshalled := roundTripDS1(t, fileData)
+		assert.Equal(fileData, marshalled, "version %d", version)
+	}
+}
+
+func TestMarshalPreservesActAboveFive(t *testing.T) {
+	assert := testify.New(t)
+	fileData := createTestDS1File(testDS1Layout{version: 18, width: 1, height: 1, walls: 1, floors: 1})
+	binary.LittleEndian.PutUint32(fileData[12:], 6) // Act 7
+
+	ds1, marshalled := roundTripDS1(t, fileData)
+	assert.Equal(fileData, marshalled)
+	assert.Equal(int32(7), ds1.Act)
+}
+
+func TestMarshalPreservesVersion12HeaderData(t *testing.T) {
+	assert := testify.New(t)
+	ds1, _ := roundTripDS1(t, createTestDS1File(testDS1Layout{version: 12, width: 1, height: 1, walls: 1, floors: 1}))
+	assert.Equal([]byte{0x44, 0x33, 0x22, 0x11, 0x88, 0x77, 0x66, 0x55}, ds1.UnknownHeaderData)
+}
+
+func TestMarshalRoundTripsLookedUpOrientations(t *testing.T) {
+	// Versions before 7 store orientations through a lookup table that maps several stored values to the same
+	// orientation, so only the structure read back is the same
+	roundTripDS1(t, createTestDS1File(testDS1Layout{version: 6, width: 4, height: 4, walls: 2, floors: 1}))
+}
+
+func TestMarshalWritesEdits(t *testing.T) {
+	assert := testify.New(t)
+	ds1, err := LoadDS1(createTestDS1Data(2, 2, nil))
+	assert.Nil(err)
+
+	floor := &ds1.Tiles[1][0].Floors[0]
+	floor.Style = 7
+	floor.Sequence = 12
+	floor.Unknown2 = 0x1F
+	ds1.Tiles[0][1].Walls[0].Hidden = true
+
+	marshalled, err := ds1.Marshal()
+	assert.Nil(err)
+	reloaded, err := LoadDS1(marshalled)
+	assert.Nil(err)
+
+	assert.Equal(byte(7), reloaded.Tiles[1][0].Floors[0].Style)
+	assert.Equal(byte(12), reloaded.Tiles[1][0].Floors[0].Sequence)
+	assert.Equal(byte(0x1F), reloaded.Tiles[1][0].Floors[0].Unknown2)
+	assert.False(reloaded.Tiles[1][0].Floors[0].Hidden)
+	assert.True(reloaded.Tiles[0][1].Walls[0].Hidden)
+	assert.False(reloaded.Tiles[0][0].Walls[0].Hidden)
+}
+
+func TestMarshalRejectsInconsistentTiles(t *testing.T) {
+	assert := testify.New(t)
+	ds1, err := LoadDS1(createTestDS1Data(2, 2, nil))
+	assert.Nil(err)
+
+	ds1.Tiles[1] = ds1.Tiles[1][:1]
+	_, err = ds1.Marshal()
+	assert.NotNil(err)
+
+	ds1, _ = LoadDS1(createTestDS1Data(2, 2, nil))
+	ds1.Tiles[0][0].Walls = nil
+	_, err = ds1.Marshal()
+	assert.NotNil(err)
+
+	ds1, _ = LoadDS1(createTestDS1Data(2, 2, nil))
+	ds1.Height = 3
+	_, err = ds1.Marshal()
+	assert.NotNil(err)
+}
@@ -0,0 +1,101 @@
+package d2ds1
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+)
+
+// createTestDS1Data builds a minimal version 7 DS1 with one wall layer, one floor layer and a shadow layer
+func createTestDS1Data(width, height int, trailingData []byte) []byte {
+	return createTestDS1DataWithFlags(width, height, 0, trailingData)
+}
+
+// createTestDS1DataWithFlags builds a minimal DS1 whose wall, floor and shadow records have the given flag bits set
+func createTestDS1DataWithFlags(width, height int, flags uint32, trailingData []byte) []byte {
+	sw := d2common.CreateStreamWriter()
+	sw.PushUint32(7)                    // Version
+	sw.PushUint32(uint32(width - 1))    // Width
+	sw.PushUint32(uint32(height - 1))   // Height
+	sw.PushUint32(1)                    // Number of files
+	for _, ch := range []byte("test") { // File name
+		sw.PushByte(ch)
+	}
+	sw.PushByte(0)
+	sw.PushUint32(1) // Number of walls
+
+	layerCount := 4 // Wall, orientation, floor, shadow
+	for layer := 0; layer < layerCount; layer++ {
+		for i := 0; i < width*height; i++ {
+			dw := uint32(layer+1)<<8 | 1
+			if layer != 1 {
+				dw |= flags
+			}
+			sw.PushUint32(dw)
+		}
+	}
+
+	sw.PushUint32(0) // Number of objects
+	for _, b := range trailingData {
+		sw.PushByte(b)
+	}
+
+	return sw.GetBytes()
+}
+
+func TestLoadDS1WithoutTrailingData(t *testing.T) {
+	assert := testify.New(t)
+	ds1, err := LoadDS1(createTestDS1Data(2, 2, nil))
+	assert.Nil(err)
+	assert.Equal(int32(2), ds1.Width)
+	assert.Equal(int32(2), ds1.Height)
+	assert.Empty(ds1.UnknownTrailingData)
+}
+
+func TestLoadDS1PreservesTrailingData(t *testing.T) {
+	assert := testify.New(t)
+	trailingData := []byte{0xDE, 0xAD, 0xBE, 0xEF, 0x01}
+	fileData := createTestDS1Data(2, 2, trailingData)
+
+	ds1, err := LoadDS1(fileData)
+	assert.Nil(err)
+	assert.Equal(trailingData, ds1.UnknownTrailingData)
+
+	// The preserved data must not alias the source buffer
+	fileData[len(fileData)-1] = 0xFF
+	assert.Equal(trailingData, ds1.UnknownTrailingData)
+}
+
+func TestLoadDS1KeepsUnknownTileBits(t *testing.T) {
+	assert := testify.New(t)
+
+	// No DS1 format documentation defines these bits, so they are read as they are
+	ds1, err := LoadDS1(createTestDS1DataWithFlags(2, 2, 0x40000000, nil))
+	assert.Nil(err)
+	assert.Equal(byte(0x10), ds1.Tiles[1][1].Walls[0].Unknown2)
+	assert.Equal(byte(0x10), ds1.Tiles[1][1].Floors[0].Unknown2)
+	assert.Equal(byte(0x10), ds1.Tiles[1][1].Shadows[0].Unknown2)
+	assert.False(ds1.Tiles[1][1].Walls[0].Hidden)
+	assert.Equal(byte(3), ds1.Tiles[1][1].Floors[0].Sequence)
+}
+
+func TestLoadDS1SkipsPathsOfNpcWithoutObject(t *testing.T) {
+	assert := testify.New(t)
+	trailingData := []byte{0xDE, 0xAD, 0xBE, 0xEF}
+	for _, version := range []uint32{14, 15} {
+		ds1, err := LoadDS1(createTestDS1File(testDS1Layout{
+			version: version, width: 1, height: 1, walls: 1, floors: 1, orphanNpc: true, trailingData: trailingData,
+		}))
+		assert.Nil(err)
+
+		// The paths of the NPC standing on no object are skipped whole, so the data after them is read as it was written
+		if assert.Len(ds1.Objects[0].Paths, 2, "version %d", version) {
+			assert.Equal(20, ds1.Objects[0].Paths[1].X, "version %d", version)
+			assert.Equal(40, ds1.Objects[0].Paths[1].Y, "version %d", version)
+		}
+		assert.Nil(ds1.Objects[1].Paths, "version %d", version)
+		assert.Equal(trailingData, ds1.UnknownTrailingData, "version %d", version)
+	}
+}
@@ -9,5 +9,6 @@ type FloorShadowRecord struct {
 	Hidden      bool
 	RandomIndex byte
 	Animated    bool
+	FrameCount  byte // The number of animation frames, for animated tiles
 	YAdjust     int
 }
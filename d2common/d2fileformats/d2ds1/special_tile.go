@@ -0,0 +1,31 @@
+package d2ds1
+
+import "github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+
+const (
+	specialTileStyleMarker   = 30 // Style of the entrance/exit/target markers
+	specialTileStyleBoundary = 31 // Style of the tile group boundary markers
+)
+
+// SpecialType classifies a special wall tile by its style and sequence. Returns false if the wall is not a special tile.
+func (w *WallRecord) SpecialType() (d2enum.SpecialTileType, bool) {
+	if !w.Type.Special() {
+		return d2enum.SpecialTileUnknown, false
+	}
+
+	switch w.Style {
+	case specialTileStyleMarker:
+		switch w.Sequence {
+		case 0:
+			return d2enum.SpecialTileEntrance, true
+		case 1:
+			return d2enum.SpecialTileExit, true
+		default:
+			return d2enum.SpecialTileObjectTarget, true
+		}
+	case specialTileStyleBoundary:
+		return d2enum.SpecialTileGroupBoundary, true
+	}
+
+	return d2enum.SpecialTileUnknown, true
+}
@@ -4,7 +4,7 @@ import "github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
 
 type WallRecord struct {
 	Type        d2enum.TileType
-	Zero        byte
+	Zero        uint32 // The bits of the orientation dword above the type (always 0 in known files), kept for Marshal
 	Prop1       byte
 	Sequence    byte
 	Unknown1    byte
@@ -12,5 +12,8 @@ type WallRecord struct {
 	Unknown2    byte
 	Hidden      bool
 	RandomIndex byte
+	Animated    bool
+	FrameCount  byte // The number of animation frames, for animated tiles
+	XAdjust     int
 	YAdjust     int
 }
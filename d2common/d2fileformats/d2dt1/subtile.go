@@ -52,3 +52,15 @@ func NewSubTileFlags(data byte) SubTileFlags {
 		Unknown3:        data&128 == 128,
 	}
 }
+
+// Combine packs the flags back into the byte they were read from
+func (s *SubTileFlags) Combine() byte {
+	var result byte
+	bits := []bool{s.BlockWalk, s.BlockLOS, s.BlockJump, s.BlockPlayerWalk, s.Unknown1, s.BlockLight, s.Unknown2, s.Unknown3}
+	for i, set := range bits {
+		if set {
+			result |= 1 << uint(i)
+		}
+	}
+	return result
+}
@@ -23,3 +23,11 @@ func TestNewSubTile(t *testing.T) {
 	}
 
 }
+
+func TestSubTileFlagsCombine(t *testing.T) {
+	assert := testify.New(t)
+	for i := 0; i < 256; i++ {
+		flags := NewSubTileFlags(byte(i))
+		assert.Equal(byte(i), flags.Combine())
+	}
+}
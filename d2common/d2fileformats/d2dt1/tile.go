@@ -16,6 +16,9 @@ type Tile struct {
 	Blocks             []Block
 }
 
+// subtileLookup maps a sub tile position to its index in Tile.SubTileFlags. The file stores the 25 sub tiles as five
+// rows of five, left to right (increasing x), starting with the row at y = 4 and ending with the row at y = 0, so index
+// i holds the sub tile at x = i % 5, y = 4 - i / 5
 var subtileLookup = [5][5]int{
 	{20, 21, 22, 23, 24},
 	{15, 16, 17, 18, 19},
@@ -24,7 +27,26 @@ var subtileLookup = [5][5]int{
 	{0, 1, 2, 3, 4},
 }
 
+// GetSubTileFlags returns the flags of the sub tile at x, y (0-4) within the tile
 func (t *Tile) GetSubTileFlags(x, y int) *SubTileFlags {
-
 	return &t.SubTileFlags[subtileLookup[y][x]]
 }
+
+// SubTileFlagBytes returns the raw flag byte of each sub tile, in the order they are stored in the file (see
+// subtileLookup for how to index it)
+func (t *Tile) SubTileFlagBytes() [25]byte {
+	var result [25]byte
+	for i := range t.SubTileFlags {
+		result[i] = t.SubTileFlags[i].Combine()
+	}
+	return result
+}
+
+// IsSubTileWalkable returns true if the sub tile at x, y (0-4) within the tile doesn't block walking. Positions
+// outside of the tile are never walkable
+func (t *Tile) IsSubTileWalkable(x, y int) bool {
+	if x < 0 || x > 4 || y < 0 || y > 4 {
+		return false
+	}
+	return !t.GetSubTileFlags(x, y).BlockWalk
+}
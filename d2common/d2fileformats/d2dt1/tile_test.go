@@ -0,0 +1,34 @@
+package d2dt1
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestTileSubTileFlagBytes(t *testing.T) {
+	assert := testify.New(t)
+	tile := &Tile{}
+	for i := range tile.SubTileFlags {
+		tile.SubTileFlags[i] = NewSubTileFlags(byte(i))
+	}
+
+	flagBytes := tile.SubTileFlagBytes()
+	for i, b := range flagBytes {
+		assert.Equal(byte(i), b)
+	}
+}
+
+func TestTileIsSubTileWalkable(t *testing.T) {
+	assert := testify.New(t)
+	tile := &Tile{}
+	// The first byte in the file is the sub tile at the left of the y = 4 row
+	tile.SubTileFlags[0] = NewSubTileFlags(1)
+	tile.SubTileFlags[9] = NewSubTileFlags(2)
+
+	assert.False(tile.IsSubTileWalkable(0, 4))
+	assert.True(tile.IsSubTileWalkable(4, 0))
+	assert.True(tile.IsSubTileWalkable(4, 3), "only BlockWalk makes a sub tile unwalkable")
+	assert.False(tile.IsSubTileWalkable(-1, 0))
+	assert.False(tile.IsSubTileWalkable(0, 5))
+}
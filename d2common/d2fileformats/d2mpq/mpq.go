@@ -1,7 +1,6 @@
 package d2mpq
 
 import (
-	"bufio"
 	"encoding/binary"
 	"errors"
 	"io/ioutil"
@@ -297,18 +296,47 @@ func (v *BlockTableEntry) calculateEncryptionSeed() {
 	v.EncryptionSeed = (v.EncryptionSeed + v.FilePosition) ^ v.UncompressedFileSize
 }
 
-// GetFileList returns the list of files in this MPQ
+// GetFileList returns the list of files in this MPQ, as listed by its "(listfile)". Archives without a listfile
+// return an empty list. Listed names that do not resolve to a file in the archive (eg: stale entries, or names whose
+// hash is taken by another file) are skipped.
 func (v MPQ) GetFileList() ([]string, error) {
+	if !v.FileExists("(listfile)") {
+		return []string{}, nil
+	}
+
 	data, err := v.ReadFile("(listfile)")
 	if err != nil {
 		return nil, err
 	}
+
+	fileNames := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, fileName := range parseListFile(data) {
+		key := strings.ToLower(fileName)
+		if seen[key] {
+			continue
+		}
+		if _, err := v.getFileBlockData(fileName); err != nil {
+			continue
+		}
+		seen[key] = true
+		fileNames = append(fileNames, fileName)
+	}
+	return fileNames, nil
+}
+
+// Splits the contents of a listfile into the names it lists. Names are separated by line breaks or semicolons.
+func parseListFile(data []byte) []string {
 	raw := strings.TrimRight(string(data), "\x00")
-	s := bufio.NewScanner(strings.NewReader(raw))
-	var filePaths []string
-	for s.Scan() {
-		filePath := s.Text()
-		filePaths = append(filePaths, filePath)
+	fields := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == '\r' || r == '\n' || r == ';'
+	})
+
+	fileNames := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if fileName := strings.TrimSpace(field); fileName != "" {
+			fileNames = append(fileNames, fileName)
+		}
 	}
-	return filePaths, nil
+	return fileNames
 }
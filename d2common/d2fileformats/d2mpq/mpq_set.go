@@ -0,0 +1,87 @@
+package d2mpq
+
+import "fmt"
+
+// ErrFileNotFound is returned when a file is not in any archive of a set
+type ErrFileNotFound struct {
+	FileName string // The name of the file that was requested
+}
+
+func (e *ErrFileNotFound) Error() string {
+	return fmt.Sprintf("file not found: %s", e.FileName)
+}
+
+// MPQSet is an ordered list of archives read as one. Each file is read from the first archive in the list that
+// contains it, so archives earlier in the list override the files of later ones (eg: patch_d2.mpq before d2data.mpq,
+// or a mod's archive before both).
+type MPQSet struct {
+	archives []*MPQ
+}
+
+// CreateMPQSet creates a set of archives, from the highest precedence to the lowest
+func CreateMPQSet(archives ...*MPQ) *MPQSet {
+	return &MPQSet{archives: archives}
+}
+
+// LoadMPQSet loads the archives at the paths into a set, from the highest precedence to the lowest. If any archive
+// fails to load, the archives already loaded are closed.
+func LoadMPQSet(fileNames ...string) (*MPQSet, error) {
+	result := &MPQSet{}
+	for _, fileName := range fileNames {
+		archive, err := Load(fileName)
+		if err != nil {
+			result.Close()
+			return nil, err
+		}
+		result.archives = append(result.archives, archive)
+	}
+	return result, nil
+}
+
+// Archives returns the archives of the set, from the highest precedence to the lowest
+func (s *MPQSet) Archives() []*MPQ {
+	return s.archives
+}
+
+// ArchiveFor returns the archive a file is read from: the highest precedence archive that contains it
+func (s *MPQSet) ArchiveFor(fileName string) (*MPQ, bool) {
+	for _, archive := range s.archives {
+		if archive.FileExists(fileName) {
+			return archive, true
+		}
+	}
+	return nil, false
+}
+
+// FileExists returns true if any archive of the set contains the file
+func (s *MPQSet) FileExists(fileName string) bool {
+	_, found := s.ArchiveFor(fileName)
+	return found
+}
+
+// ReadFile reads a file from the highest precedence archive that contains it. A file no archive contains returns an
+// *ErrFileNotFound.
+func (s *MPQSet) ReadFile(fileName string) ([]byte, error) {
+	archive, found := s.ArchiveFor(fileName)
+	if !found {
+		return []byte{}, &ErrFileNotFound{FileName: fileName}
+	}
+	return archive.ReadFile(fileName)
+}
+
+// ReadTextFile reads a file from the highest precedence archive that contains it, and returns it as a string
+func (s *MPQSet) ReadTextFile(fileName string) (string, error) {
+	data, err := s.ReadFile(fileName)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Close closes every archive of the set
+func (s *MPQSet) Close() {
+	for _, archive := range s.archives {
+		archive.Close()
+	}
+	s.archives = nil
+}
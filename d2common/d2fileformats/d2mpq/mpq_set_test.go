@@ -0,0 +1,65 @@
+package d2mpq
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestMPQSetPrecedence(t *testing.T) {
+	assert := testify.New(t)
+	patch, removePatch := createTestMPQ(t, map[string][]byte{
+		"data\\global\\excel\\levels.txt": []byte("patched"),
+	}, "data\\global\\excel\\levels.txt")
+	defer removePatch()
+	base, removeBase := createTestMPQ(t, map[string][]byte{
+		"data\\global\\excel\\levels.txt": []byte("original"),
+		"data\\local\\font.dc6":           []byte("font"),
+	}, "data\\global\\excel\\levels.txt", "data\\local\\font.dc6")
+	defer removeBase()
+
+	set := CreateMPQSet(patch, base)
+	assert.Equal([]*MPQ{patch, base}, set.Archives())
+
+	// The patch overrides the base archive, which still provides the files the patch does not have
+	text, err := set.ReadTextFile("DATA\\GLOBAL\\EXCEL\\LEVELS.TXT")
+	assert.Nil(err)
+	assert.Equal("patched", text)
+	data, err := set.ReadFile("data\\local\\font.dc6")
+	assert.Nil(err)
+	assert.Equal([]byte("font"), data)
+
+	archive, found := set.ArchiveFor("data\\global\\excel\\levels.txt")
+	assert.True(found)
+	assert.True(archive == patch)
+	archive, found = set.ArchiveFor("data\\local\\font.dc6")
+	assert.True(found)
+	assert.True(archive == base)
+
+	// Reversing the order reverses the precedence
+	text, err = CreateMPQSet(base, patch).ReadTextFile("data\\global\\excel\\levels.txt")
+	assert.Nil(err)
+	assert.Equal("original", text)
+}
+
+func TestMPQSetMissingFile(t *testing.T) {
+	assert := testify.New(t)
+	base, remove := createTestMPQ(t, map[string][]byte{"data\\local\\font.dc6": []byte("font")}, "data\\local\\font.dc6")
+	defer remove()
+
+	set := CreateMPQSet(base)
+	assert.False(set.FileExists("data\\local\\missing.dc6"))
+	_, found := set.ArchiveFor("data\\local\\missing.dc6")
+	assert.False(found)
+	_, err := set.ReadFile("data\\local\\missing.dc6")
+	notFound, ok := err.(*ErrFileNotFound)
+	assert.True(ok)
+	assert.Equal("data\\local\\missing.dc6", notFound.FileName)
+}
+
+func TestLoadMPQSetWithMissingArchive(t *testing.T) {
+	assert := testify.New(t)
+	set, err := LoadMPQSet("missing_patch.mpq")
+	assert.Nil(set)
+	assert.NotNil(err)
+}
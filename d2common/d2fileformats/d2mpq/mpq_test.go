@@ -0,0 +1,89 @@
+package d2mpq
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+// createTestMPQ creates an MPQ whose files are stored uncompressed and unencrypted in a temporary file, one after
+// another. Each name in hashedNames is added to the hash table pointing at the block of the matching file, or at a
+// missing block for names without a file. Returns a function that removes the temporary file.
+func createTestMPQ(t *testing.T, files map[string][]byte, hashedNames ...string) (*MPQ, func()) {
+	file, err := ioutil.TempFile("", "d2mpq")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := &MPQ{FileName: file.Name(), File: file}
+	blockIndexes := make(map[string]uint32)
+	position := uint32(0)
+	for name, data := range files {
+		if _, err := file.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		blockIndexes[name] = uint32(len(result.BlockTableEntries))
+		result.BlockTableEntries = append(result.BlockTableEntries, BlockTableEntry{
+			FilePosition:         position,
+			CompressedFileSize:   uint32(len(data)),
+			UncompressedFileSize: uint32(len(data)),
+			Flags:                FileExists,
+		})
+		position += uint32(len(data))
+	}
+
+	for _, name := range hashedNames {
+		blockIndex, found := blockIndexes[name]
+		if !found {
+			blockIndex = 0xFFFFFFFF
+		}
+		result.HashEntryMap.Insert(&HashTableEntry{
+			NamePartA:  hashString(name, 1),
+			NamePartB:  hashString(name, 2),
+			BlockIndex: blockIndex,
+		})
+	}
+
+	return result, func() {
+		file.Close()
+		os.Remove(file.Name())
+	}
+}
+
+func TestGetFileList(t *testing.T) {
+	assert := testify.New(t)
+	mpq, remove := createTestMPQ(t, map[string][]byte{
+		"(listfile)":                      []byte("data\\global\\excel\\levels.txt\r\nDATA\\GLOBAL\\EXCEL\\LEVELS.TXT\r\nmissing.txt;data\\local\\font.dc6\r\n\r\n\x00"),
+		"data\\global\\excel\\levels.txt": []byte("levels"),
+		"data\\local\\font.dc6":           []byte("font"),
+	}, "(listfile)", "data\\global\\excel\\levels.txt", "data\\local\\font.dc6", "missing.txt")
+	defer remove()
+
+	fileNames, err := mpq.GetFileList()
+	assert.Nil(err)
+	// The duplicate listing and the name without a file are skipped
+	assert.Equal([]string{"data\\global\\excel\\levels.txt", "data\\local\\font.dc6"}, fileNames)
+
+	data, err := mpq.ReadFile(fileNames[1])
+	assert.Nil(err)
+	assert.Equal([]byte("font"), data)
+}
+
+func TestGetFileListWithoutListFile(t *testing.T) {
+	assert := testify.New(t)
+	mpq, remove := createTestMPQ(t, map[string][]byte{"data\\local\\font.dc6": []byte("font")}, "data\\local\\font.dc6")
+	defer remove()
+
+	fileNames, err := mpq.GetFileList()
+	assert.Nil(err)
+	assert.NotNil(fileNames)
+	assert.Empty(fileNames)
+}
+
+func TestParseListFile(t *testing.T) {
+	assert := testify.New(t)
+	assert.Equal([]string{"a.txt", "b\\c.txt", "d.txt"}, parseListFile([]byte(" a.txt \nb\\c.txt;d.txt\r\n\x00\x00")))
+	assert.Empty(parseListFile(nil))
+}
@@ -57,6 +57,11 @@ func MinInt32(a, b int32) int32 {
 	return b
 }
 
+// ClampFloat64 limits a value to the range [min, max]
+func ClampFloat64(value, min, max float64) float64 {
+	return math.Max(min, math.Min(max, value))
+}
+
 // BytesToInt32 converts 4 bytes to int32
 
 // IsoToScreen converts isometric coordinates to screenspace coordinates
@@ -0,0 +1,150 @@
+package d2common
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// The markers a key is wrapped in when it has no string, so missing translations are visible on screen
+const (
+	missingStringPrefix = "["
+	missingStringSuffix = "]"
+)
+
+// StringTableLoader returns the contents of the string table (.tbl) at a path
+type StringTableLoader func(path string) ([]byte, error)
+
+// StringTableManager resolves string codes to localized text from one or more string tables. When a key is in more
+// than one table, the string of the table loaded last is used (eg: a patch or mod table overrides the base table).
+type StringTableManager struct {
+	loadFile StringTableLoader            // Loads the contents of a table
+	parsed   map[string]map[string]string // The parsed tables, by path
+	order    []string                     // The paths of the loaded tables, in load order
+	lookup   map[string]string            // The strings of the loaded tables, with the later tables applied over the earlier
+	mutex    sync.RWMutex
+}
+
+// The manager the strings of TranslateString are looked up in
+var defaultStringTables = CreateStringTableManager(nil)
+
+// Creates a string table manager that loads its tables with loadFile
+func CreateStringTableManager(loadFile StringTableLoader) *StringTableManager {
+	return &StringTableManager{
+		loadFile: loadFile,
+		parsed:   make(map[string]map[string]string),
+		lookup:   make(map[string]string),
+	}
+}
+
+// Sets the manager the strings of TranslateString are looked up in
+func SetStringTableManager(manager *StringTableManager) {
+	defaultStringTables = manager
+}
+
+// Returns the manager the strings of TranslateString are looked up in
+func GetStringTableManager() *StringTableManager {
+	return defaultStringTables
+}
+
+// Loads the string table at a path, applying its strings over those of the tables loaded before it. A table is only
+// parsed once; loading it again moves it after the other tables.
+func (m *StringTableManager) LoadTable(path string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, found := m.parsed[path]; !found {
+		if m.loadFile == nil {
+			return errors.New("the string table manager has no loader")
+		}
+		data, err := m.loadFile(path)
+		if err != nil {
+			return err
+		}
+		table, err := parseStringTable(data)
+		if err != nil {
+			return err
+		}
+		m.parsed[path] = table
+	}
+
+	for i := range m.order {
+		if m.order[i] == path {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+	m.order = append(m.order, path)
+	m.rebuildLookup()
+	return nil
+}
+
+// Unloads the string table at a path. The parsed table is kept, so loading it again does not parse it again.
+func (m *StringTableManager) UnloadTable(path string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i := range m.order {
+		if m.order[i] == path {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			m.rebuildLookup()
+			return
+		}
+	}
+}
+
+// Returns the paths of the loaded tables, in load order
+func (m *StringTableManager) Tables() []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return append([]string(nil), m.order...)
+}
+
+// Returns the string for a key, and whether any loaded table has it
+func (m *StringTableManager) Lookup(key string) (string, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	result, ok := m.lookup[key]
+	return result, ok
+}
+
+// Returns the string for a key, or the key wrapped in markers when no loaded table has it
+func (m *StringTableManager) Translate(key string) string {
+	if result, ok := m.Lookup(key); ok {
+		return result
+	}
+	return missingStringPrefix + key + missingStringSuffix
+}
+
+// Returns every key of the loaded tables, sorted (eg: for tools listing the strings of a mod)
+func (m *StringTableManager) Keys() []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	keys := make([]string, 0, len(m.lookup))
+	for key := range m.lookup {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Returns the number of keys of the loaded tables
+func (m *StringTableManager) Count() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return len(m.lookup)
+}
+
+// Merges the loaded tables in load order, so the later tables override the earlier
+func (m *StringTableManager) rebuildLookup() {
+	m.lookup = make(map[string]string)
+	for _, path := range m.order {
+		for key, value := range m.parsed[path] {
+			m.lookup[key] = value
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package d2common
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// Builds a string table (.tbl) holding the strings of pairs, given as key, string, key, string...
+func createTestStringTable(pairs ...string) []byte {
+	count := len(pairs) / 2
+	const hashEntrySize = 17
+	stringsOffset := stringTableHeaderSize + count*2 + count*hashEntrySize
+
+	var text []byte
+	sw := CreateStreamWriter()
+	sw.PushUint16(0) // CRC
+	sw.PushUint16(uint16(count))
+	sw.PushUint32(uint32(count))
+	sw.PushByte(0) // Version
+	sw.PushUint32(uint32(stringsOffset))
+	sw.PushUint32(0) // Max tries
+	sw.PushUint32(0) // File size
+	for i := 0; i < count; i++ {
+		sw.PushUint16(uint16(i))
+	}
+	for i := 0; i < count; i++ {
+		key, value := pairs[i*2], pairs[i*2+1]
+		keyOffset := stringsOffset + len(text)
+		text = append(append(text, key...), 0)
+		valueOffset := stringsOffset + len(text)
+		text = append(append(text, value...), 0)
+
+		sw.PushByte(1)
+		sw.PushUint16(uint16(i))
+		sw.PushUint32(0) // Hash
+		sw.PushUint32(uint32(keyOffset))
+		sw.PushUint32(uint32(valueOffset))
+		sw.PushUint16(uint16(len(value) + 1))
+	}
+	return append(sw.GetBytes(), text...)
+}
+
+// Returns a loader for the tables of files, counting the loads of each path in loads
+func createTestStringTableLoader(files map[string][]byte, loads map[string]int) StringTableLoader {
+	return func(path string) ([]byte, error) {
+		loads[path]++
+		data, found := files[path]
+		if !found {
+			return nil, errors.New("file not found")
+		}
+		return data, nil
+	}
+}
+
+func TestStringTableLookup(t *testing.T) {
+	files := map[string][]byte{"string.tbl": createTestStringTable("cancel", "Cancel", "ok", "OK")}
+	manager := CreateStringTableManager(createTestStringTableLoader(files, make(map[string]int)))
+	if err := manager.LoadTable("string.tbl"); err != nil {
+		t.Fatalf("LoadTable returned an error: %v", err)
+	}
+
+	if result, ok := manager.Lookup("cancel"); !ok || result != "Cancel" {
+		t.Fatalf("Lookup(\"cancel\") was expected to return \"Cancel\", but returned %q, %v", result, ok)
+	}
+	if result, ok := manager.Lookup("missing"); ok || result != "" {
+		t.Fatalf("Lookup(\"missing\") was expected to fail, but returned %q, %v", result, ok)
+	}
+	if result := manager.Translate("missing"); result != "[missing]" {
+		t.Fatalf("Translate(\"missing\") was expected to return \"[missing]\", but returned %q", result)
+	}
+	if result := manager.Translate("ok"); result != "OK" {
+		t.Fatalf("Translate(\"ok\") was expected to return \"OK\", but returned %q", result)
+	}
+}
+
+func TestStringTableLaterTablesOverride(t *testing.T) {
+	files := map[string][]byte{
+		"string.tbl":      createTestStringTable("cancel", "Cancel", "ok", "OK"),
+		"patchstring.tbl": createTestStringTable("cancel", "Abort", "new", "New"),
+	}
+	manager := CreateStringTableManager(createTestStringTableLoader(files, make(map[string]int)))
+	manager.LoadTable("string.tbl")
+	manager.LoadTable("patchstring.tbl")
+
+	if result := manager.Translate("cancel"); result != "Abort" {
+		t.Fatalf("the patch table was expected to override \"cancel\", but it was %q", result)
+	}
+	if result := manager.Translate("ok"); result != "OK" {
+		t.Fatalf("the base table was expected to keep \"ok\", but it was %q", result)
+	}
+	if keys := manager.Keys(); !reflect.DeepEqual(keys, []string{"cancel", "new", "ok"}) {
+		t.Fatalf("Keys returned %v", keys)
+	}
+
+	// Loading the base table again moves it after the patch
+	manager.LoadTable("string.tbl")
+	if result := manager.Translate("cancel"); result != "Cancel" {
+		t.Fatalf("the reloaded base table was expected to override \"cancel\", but it was %q", result)
+	}
+	if tables := manager.Tables(); !reflect.DeepEqual(tables, []string{"patchstring.tbl", "string.tbl"}) {
+		t.Fatalf("Tables returned %v", tables)
+	}
+
+	manager.UnloadTable("string.tbl")
+	if result := manager.Translate("ok"); result != "[ok]" {
+		t.Fatalf("the unloaded table was expected to have no strings, but \"ok\" was %q", result)
+	}
+}
+
+func TestStringTableParsesEachTableOnce(t *testing.T) {
+	loads := make(map[string]int)
+	files := map[string][]byte{"string.tbl": createTestStringTable("ok", "OK")}
+	manager := CreateStringTableManager(createTestStringTableLoader(files, loads))
+
+	manager.LoadTable("string.tbl")
+	manager.UnloadTable("string.tbl")
+	manager.LoadTable("string.tbl")
+
+	if loads["string.tbl"] != 1 {
+		t.Fatalf("the table was expected to be loaded once, but was loaded %d times", loads["string.tbl"])
+	}
+	if manager.Count() != 1 {
+		t.Fatalf("Count was expected to return 1, but returned %d", manager.Count())
+	}
+}
+
+func TestStringTableLoadErrors(t *testing.T) {
+	files := map[string][]byte{"short.tbl": {0, 0, 0}}
+	manager := CreateStringTableManager(createTestStringTableLoader(files, make(map[string]int)))
+
+	if err := manager.LoadTable("missing.tbl"); err == nil {
+		t.Fatal("loading a missing table was expected to fail")
+	}
+	if err := manager.LoadTable("short.tbl"); err == nil {
+		t.Fatal("loading a truncated table was expected to fail")
+	}
+	if tables := manager.Tables(); len(tables) != 0 {
+		t.Fatalf("the failed tables were expected to not be loaded, but Tables returned %v", tables)
+	}
+	if err := CreateStringTableManager(nil).LoadTable("string.tbl"); err == nil {
+		t.Fatal("loading a table without a loader was expected to fail")
+	}
+}
+
+func TestTranslateStringUsesDefaultManager(t *testing.T) {
+	previous := GetStringTableManager()
+	defer SetStringTableManager(previous)
+
+	files := map[string][]byte{"string.tbl": createTestStringTable("ok", "OK")}
+	manager := CreateStringTableManager(createTestStringTableLoader(files, make(map[string]int)))
+	manager.LoadTable("string.tbl")
+	SetStringTableManager(manager)
+
+	if result := TranslateString("ok"); result != "OK" {
+		t.Fatalf("TranslateString(\"ok\") was expected to return \"OK\", but returned %q", result)
+	}
+	if result := TranslateString("#123"); result != "#123" {
+		t.Fatalf("TranslateString(\"#123\") was expected to return the key, but returned %q", result)
+	}
+	if GetDictionaryEntryCount() != 1 {
+		t.Fatalf("GetDictionaryEntryCount was expected to return 1, but returned %d", GetDictionaryEntryCount())
+	}
+}
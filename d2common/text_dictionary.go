@@ -1,7 +1,7 @@
 package d2common
 
 import (
-	"log"
+	"errors"
 	"strconv"
 )
 
@@ -14,10 +14,12 @@ type textDictionaryHashEntry struct {
 	NameLength  uint16
 }
 
-var lookupTable map[string]string
+// The size of the header of a string table, in bytes
+const stringTableHeaderSize = 21
 
+// Returns the string for a key in the string tables of the default manager, or the key itself when it has no string
 func TranslateString(key string) string {
-	result, ok := lookupTable[key]
+	result, ok := defaultStringTables.Lookup(key)
 	if !ok {
 		// Fix to allow v.setDescLabels("#123") to be bypassed for a patch in issue #360. Reenable later.
 		// log.Panicf("Could not find a string for the key '%s'", key)
@@ -26,17 +28,19 @@ func TranslateString(key string) string {
 	return result
 }
 
+// Returns the number of keys in the string tables of the default manager
 func GetDictionaryEntryCount() int {
-	if lookupTable == nil {
-		return 0
-	}
-	return len(lookupTable)
+	return defaultStringTables.Count()
 }
 
-func LoadDictionary(dictionaryData []byte) {
-	if lookupTable == nil {
-		lookupTable = make(map[string]string)
+// Parses a string table (.tbl) into its strings, keyed by string code. When a key appears more than once, the first
+// string is kept.
+func parseStringTable(dictionaryData []byte) (map[string]string, error) {
+	if len(dictionaryData) < stringTableHeaderSize {
+		return nil, errors.New("the string table is too short to hold its header")
 	}
+
+	table := make(map[string]string)
 	br := CreateStreamReader(dictionaryData)
 	// CRC
 	br.ReadBytes(2)
@@ -44,7 +48,7 @@ func LoadDictionary(dictionaryData []byte) {
 	hashTableSize := br.GetUInt32()
 	// Version (always 0)
 	if _, err := br.ReadByte(); err != nil {
-		log.Fatal("Error reading Version record")
+		return nil, errors.New("error reading Version record")
 	}
 	br.GetUInt32() // StringOffset
 	br.GetUInt32() // When the number of times you have missed a match with a hash key equals this value, you give up because it is not there.
@@ -86,10 +90,9 @@ func LoadDictionary(dictionaryData []byte) {
 		if key == "x" || key == "X" {
 			key = "#" + strconv.Itoa(idx)
 		}
-		_, exists := lookupTable[key]
+		_, exists := table[key]
 		if !exists {
-			lookupTable[key] = value
-
+			table[key] = value
 		}
 		// Use the following code to write out the values
 		/*=
@@ -104,4 +107,6 @@ func LoadDictionary(dictionaryData []byte) {
 		}
 		*/
 	}
+
+	return table, nil
 }
@@ -2,6 +2,7 @@ package d2asset
 
 import (
 	"errors"
+	"image"
 	"image/color"
 	"math"
 
@@ -19,6 +20,7 @@ const (
 	playModePause playMode = iota
 	playModeForward
 	playModeBackward
+	playModePingPong
 )
 
 type animationFrame struct {
@@ -27,7 +29,9 @@ type animationFrame struct {
 	offsetX int
 	offsetY int
 
-	image d2render.Surface
+	image   d2render.Surface
+	decode  func() (d2render.Surface, error)                  // Decodes the image on demand, for streamed animations
+	recolor func(*d2dat.DATPalette) (d2render.Surface, error) // Decodes the image with another palette
 }
 
 type animationDirection struct {
@@ -51,12 +55,22 @@ type Animation struct {
 	hasSubLoop       bool // runs after first animation ends
 	subStartingFrame int
 	subEndingFrame   int
+	pingPongReverse  bool // Whether ping-pong playback is stepping back towards the first frame
+
+	streamedFrame *animationFrame  // The frame whose image is currently decoded, for streamed animations
+	streamedImage d2render.Surface // The decoded image of the streamed frame, kept by each clone for its own frame
+
+	frameEvents map[int][]func() // The callbacks to run when a frame is reached, by frame index
+
+	palette      *d2dat.DATPalette  // The palette the frames are decoded with
+	paletteCycle *paletteCycleState // The rotation of the palette colors (nil=none)
 }
 
 func createAnimationFromDCC(dcc *d2dcc.DCC, palette *d2dat.DATPalette, transparency int) (*Animation, error) {
 	animation := &Animation{
 		playLength: 1.0,
 		playLoop:   true,
+		palette:    palette,
 	}
 
 	for directionIndex, dccDirection := range dcc.Directions {
@@ -73,29 +87,12 @@ func createAnimationFromDCC(dcc *d2dcc.DCC, palette *d2dat.DATPalette, transpare
 			frameWidth := maxX - minX
 			frameHeight := maxY - minY
 
-			pixels := make([]byte, frameWidth*frameHeight*4)
-			for y := 0; y < frameHeight; y++ {
-				for x := 0; x < frameWidth; x++ {
-					if paletteIndex := dccFrame.PixelData[y*frameWidth+x]; paletteIndex != 0 {
-						palColor := palette.Colors[paletteIndex]
-						offset := (x + y*frameWidth) * 4
-						pixels[offset] = palColor.R
-						pixels[offset+1] = palColor.G
-						pixels[offset+2] = palColor.B
-						pixels[offset+3] = byte(transparency)
-					}
-				}
-			}
-
-			image, err := d2render.NewSurface(frameWidth, frameHeight, d2render.FilterNearest)
+			decode := createDCCFrameDecoder(dccFrame.PixelData, frameWidth, frameHeight, transparency)
+			image, err := decode(palette)
 			if err != nil {
 				return nil, err
 			}
 
-			if err := image.ReplacePixels(pixels); err != nil {
-				return nil, err
-			}
-
 			if directionIndex >= len(animation.directions) {
 				animation.directions = append(animation.directions, new(animationDirection))
 			}
@@ -107,6 +104,7 @@ func createAnimationFromDCC(dcc *d2dcc.DCC, palette *d2dat.DATPalette, transpare
 				offsetX: minX,
 				offsetY: minY,
 				image:   image,
+				recolor: decode,
 			})
 
 		}
@@ -115,66 +113,64 @@ func createAnimationFromDCC(dcc *d2dcc.DCC, palette *d2dat.DATPalette, transpare
 	return animation, nil
 }
 
-func createAnimationFromDC6(dc6 *d2dc6.DC6File, palette *d2dat.DATPalette) (*Animation, error) {
-	animation := &Animation{
-		playLength:     1.0,
-		playLoop:       true,
-		originAtBottom: true,
-	}
+// Returns a function that decodes the pixels of a DCC frame into a new surface with a palette
+func createDCCFrameDecoder(pixelData []byte, frameWidth, frameHeight, transparency int) func(*d2dat.DATPalette) (d2render.Surface, error) {
+	return func(palette *d2dat.DATPalette) (d2render.Surface, error) {
+		pixels := make([]byte, frameWidth*frameHeight*4)
+		for y := 0; y < frameHeight; y++ {
+			for x := 0; x < frameWidth; x++ {
+				if paletteIndex := pixelData[y*frameWidth+x]; !palette.IsTransparent(paletteIndex) {
+					palColor := palette.Colors[paletteIndex]
+					offset := (x + y*frameWidth) * 4
+					pixels[offset] = palColor.R
+					pixels[offset+1] = palColor.G
+					pixels[offset+2] = palColor.B
+					pixels[offset+3] = byte(transparency)
+				}
+			}
+		}
 
-	for frameIndex, dc6Frame := range dc6.Frames {
-		image, err := d2render.NewSurface(int(dc6Frame.Width), int(dc6Frame.Height), d2render.FilterNearest)
+		image, err := d2render.NewSurface(frameWidth, frameHeight, d2render.FilterNearest)
 		if err != nil {
 			return nil, err
 		}
 
-		indexData := make([]int, dc6Frame.Width*dc6Frame.Height)
-		for i := range indexData {
-			indexData[i] = -1
+		if err := image.ReplacePixels(pixels); err != nil {
+			return nil, err
 		}
 
-		x := 0
-		y := int(dc6Frame.Height) - 1
-		offset := 0
+		return image, nil
+	}
+}
 
-		for {
-			b := int(dc6Frame.FrameData[offset])
-			offset++
+func createAnimationFromDC6(dc6 *d2dc6.DC6File, palette *d2dat.DATPalette, streamed bool) (*Animation, error) {
+	animation := &Animation{
+		playLength:     1.0,
+		playLoop:       true,
+		originAtBottom: true,
+		palette:        palette,
+	}
 
-			if b == 0x80 {
-				if y == 0 {
-					break
-				}
-				y--
-				x = 0
-			} else if b&0x80 > 0 {
-				transparentPixels := b & 0x7f
-				for i := 0; i < transparentPixels; i++ {
-					indexData[x+y*int(dc6Frame.Width)+i] = -1
-				}
-				x += transparentPixels
-			} else {
-				for i := 0; i < b; i++ {
-					indexData[x+y*int(dc6Frame.Width)+i] = int(dc6Frame.FrameData[offset])
-					offset++
-				}
-				x += b
-			}
+	for frameIndex, dc6Frame := range dc6.Frames {
+		frame := &animationFrame{
+			width:   int(dc6Frame.Width),
+			height:  int(dc6Frame.Height),
+			offsetX: int(dc6Frame.OffsetX),
+			offsetY: int(dc6Frame.OffsetY),
 		}
-
-		colorData := make([]byte, dc6Frame.Width*dc6Frame.Height*4)
-		for i := 0; i < int(dc6Frame.Width*dc6Frame.Height); i++ {
-			if indexData[i] < 1 { // TODO: Is this == -1 or < 1?
-				continue
-			}
-			colorData[i*4] = palette.Colors[indexData[i]].R
-			colorData[i*4+1] = palette.Colors[indexData[i]].G
-			colorData[i*4+2] = palette.Colors[indexData[i]].B
-			colorData[i*4+3] = 0xff
+		frame.recolor = func(palette *d2dat.DATPalette) (d2render.Surface, error) {
+			return createDC6FrameDecoder(dc6Frame, palette)()
 		}
 
-		if err := image.ReplacePixels(colorData); err != nil {
-			return nil, err
+		decode := createDC6FrameDecoder(dc6Frame, palette)
+		if streamed {
+			frame.decode = decode
+		} else {
+			image, err := decode()
+			if err != nil {
+				return nil, err
+			}
+			frame.image = image
 		}
 
 		directionIndex := frameIndex / int(dc6.FramesPerDirection)
@@ -183,23 +179,99 @@ func createAnimationFromDC6(dc6 *d2dc6.DC6File, palette *d2dat.DATPalette) (*Ani
 		}
 
 		direction := animation.directions[directionIndex]
-		direction.frames = append(direction.frames, &animationFrame{
-			width:   int(dc6Frame.Width),
-			height:  int(dc6Frame.Height),
-			offsetX: int(dc6Frame.OffsetX),
-			offsetY: int(dc6Frame.OffsetY),
-			image:   image,
-		})
+		direction.frames = append(direction.frames, frame)
 	}
 
 	return animation, nil
 }
 
+// CreateAnimationFromDC6 creates an animation from a DC6 file that was not loaded through the asset manager (eg: one
+// built in memory), decoding every frame with the palette
+func CreateAnimationFromDC6(dc6 *d2dc6.DC6File, palette *d2dat.DATPalette) (*Animation, error) {
+	return createAnimationFromDC6(dc6, palette, false)
+}
+
+// Returns a function that decodes a DC6 frame into a new surface
+func createDC6FrameDecoder(dc6Frame *d2dc6.DC6Frame, palette *d2dat.DATPalette) func() (d2render.Surface, error) {
+	return func() (d2render.Surface, error) {
+		image, err := d2render.NewSurface(int(dc6Frame.Width), int(dc6Frame.Height), d2render.FilterNearest)
+		if err != nil {
+			return nil, err
+		}
+
+		pixels, err := dc6Frame.DecodePixels(palette)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := image.ReplacePixels(pixels); err != nil {
+			return nil, err
+		}
+
+		return image, nil
+	}
+}
+
+// Returns the image of a frame. Frames of a streamed animation are decoded on demand, and only the most recently
+// decoded frame is retained. The frames are shared by clones, so the retained image is kept on the animation rather
+// than the frame, leaving clones showing other frames unaffected.
+func (a *Animation) getFrameImage(frame *animationFrame) (d2render.Surface, error) {
+	if image, err := a.getCycledFrameImage(frame); image != nil || err != nil {
+		return image, err
+	}
+
+	if frame.image != nil || frame.decode == nil {
+		return frame.image, nil
+	}
+
+	if a.streamedFrame == frame {
+		return a.streamedImage, nil
+	}
+
+	image, err := frame.decode()
+	if err != nil {
+		return nil, err
+	}
+
+	a.streamedFrame, a.streamedImage = frame, image
+
+	return image, nil
+}
+
 func (a *Animation) Clone() *Animation {
 	animation := *a
+	if a.frameEvents != nil {
+		animation.frameEvents = make(map[int][]func(), len(a.frameEvents))
+		for frameIndex, events := range a.frameEvents {
+			animation.frameEvents[frameIndex] = append([]func(){}, events...)
+		}
+	}
+	if a.paletteCycle != nil {
+		animation.paletteCycle = &paletteCycleState{cycle: a.paletteCycle.cycle, elapsed: a.paletteCycle.elapsed}
+	}
 	return &animation
 }
 
+// OnFrame registers a callback that runs each time playback advances onto the specified frame (eg: the release frame
+// of an attack)
+func (a *Animation) OnFrame(frameIndex int, callback func()) {
+	if a.frameEvents == nil {
+		a.frameEvents = make(map[int][]func())
+	}
+	a.frameEvents[frameIndex] = append(a.frameEvents[frameIndex], callback)
+}
+
+// ClearFrameEvents removes all of the frame callbacks
+func (a *Animation) ClearFrameEvents() {
+	a.frameEvents = nil
+}
+
+func (a *Animation) fireFrameEvents() {
+	for _, callback := range a.frameEvents[a.frameIndex] {
+		callback()
+	}
+}
+
 func (a *Animation) SetSubLoop(startFrame, EndFrame int) {
 	a.subStartingFrame = startFrame
 	a.subEndingFrame = EndFrame
@@ -207,6 +279,10 @@ func (a *Animation) SetSubLoop(startFrame, EndFrame int) {
 }
 
 func (a *Animation) Advance(elapsed float64) error {
+	if a.paletteCycle != nil {
+		a.paletteCycle.elapsed += elapsed
+	}
+
 	if a.playMode == playModePause {
 		return nil
 	}
@@ -218,6 +294,7 @@ func (a *Animation) Advance(elapsed float64) error {
 	a.lastFrameTime -= float64(framesAdvanced) * frameLength
 
 	for i := 0; i < framesAdvanced; i++ {
+		previousIndex := a.frameIndex
 		startIndex := 0
 		endIndex := frameCount
 		if a.hasSubLoop && a.playedCount > 0 {
@@ -248,21 +325,57 @@ func (a *Animation) Advance(elapsed float64) error {
 					break
 				}
 			}
+		case playModePingPong:
+			a.advancePingPong(startIndex, endIndex)
+		}
+
+		if a.frameIndex != previousIndex {
+			a.fireFrameEvents()
 		}
 	}
 
 	return nil
 }
 
+// Steps ping-pong playback one frame towards the end it is heading for, turning around at both ends. A play is counted
+// each time playback returns to the first frame, where it stops unless the animation loops.
+func (a *Animation) advancePingPong(startIndex, endIndex int) {
+	if endIndex-startIndex < 2 {
+		return
+	}
+
+	if !a.pingPongReverse {
+		a.frameIndex++
+		if a.frameIndex >= endIndex-1 {
+			a.frameIndex = endIndex - 1
+			a.pingPongReverse = true
+		}
+		return
+	}
+
+	if a.frameIndex > startIndex {
+		a.frameIndex--
+		if a.frameIndex == startIndex {
+			a.playedCount++
+			a.pingPongReverse = !a.playLoop
+		}
+	}
+}
+
 func (a *Animation) Render(target d2render.Surface) error {
 	direction := a.directions[a.directionIndex]
 	frame := direction.frames[a.frameIndex]
 
+	image, err := a.getFrameImage(frame)
+	if err != nil {
+		return err
+	}
+
 	target.PushTranslation(frame.offsetX, frame.offsetY)
 	target.PushCompositeMode(a.compositeMode)
 	target.PushColor(a.colorMod)
 	defer target.PopN(3)
-	return target.Render(frame.image)
+	return target.Render(image)
 }
 
 func (a *Animation) RenderFromOrigin(target d2render.Surface) error {
@@ -276,6 +389,15 @@ func (a *Animation) RenderFromOrigin(target d2render.Surface) error {
 	return a.Render(target)
 }
 
+// FrameKey returns a value identifying the current frame image. Clones of an animation share their frames, so they
+// return equal keys while they show the same frame, unless they have a palette cycle.
+func (a *Animation) FrameKey() interface{} {
+	if a.paletteCycle != nil {
+		return a.paletteCycle
+	}
+	return a.directions[a.directionIndex].frames[a.frameIndex]
+}
+
 func (a *Animation) GetFrameSize(frameIndex int) (int, int, error) {
 	direction := a.directions[a.directionIndex]
 	if frameIndex >= len(direction.frames) {
@@ -291,6 +413,12 @@ func (a *Animation) GetCurrentFrameSize() (int, int) {
 	return width, height
 }
 
+// GetCurrentFrameRect returns the rectangle the current frame covers, relative to the point the animation is rendered at
+func (a *Animation) GetCurrentFrameRect() image.Rectangle {
+	frame := a.directions[a.directionIndex].frames[a.frameIndex]
+	return image.Rect(frame.offsetX, frame.offsetY, frame.offsetX+frame.width, frame.offsetY+frame.height)
+}
+
 func (a *Animation) GetFrameBounds() (int, int) {
 	maxWidth, maxHeight := 0, 0
 
@@ -362,6 +490,14 @@ func (a *Animation) PlayBackward() {
 	a.lastFrameTime = 0
 }
 
+// PlayPingPong plays the animation forward to the last frame and then backward to the first (eg: a door that opens
+// and closes with the same frames)
+func (a *Animation) PlayPingPong() {
+	a.playMode = playModePingPong
+	a.pingPongReverse = false
+	a.lastFrameTime = 0
+}
+
 func (a *Animation) Pause() {
 	a.playMode = playModePause
 	a.lastFrameTime = 0
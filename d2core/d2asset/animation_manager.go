@@ -20,8 +20,8 @@ func createAnimationManager() *animationManager {
 	return &animationManager{d2common.CreateCache(animationBudget)}
 }
 
-func (am *animationManager) loadAnimation(animationPath, palettePath string, transparency int) (*Animation, error) {
-	cachePath := fmt.Sprintf("%s;%s;%d", animationPath, palettePath, transparency)
+func (am *animationManager) loadAnimation(animationPath, palettePath string, transparency int, streamed bool) (*Animation, error) {
+	cachePath := fmt.Sprintf("%s;%s;%d;%t", animationPath, palettePath, transparency, streamed)
 	if animation, found := am.cache.Retrieve(cachePath); found {
 		return animation.(*Animation).Clone(), nil
 	}
@@ -40,7 +40,7 @@ func (am *animationManager) loadAnimation(animationPath, palettePath string, tra
 			return nil, err
 		}
 
-		animation, err = createAnimationFromDC6(dc6, palette)
+		animation, err = createAnimationFromDC6(dc6, palette, streamed)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,216 @@
+package d2asset
+
+import (
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// testSurface is a d2render.Surface that records the images rendered onto it
+type testSurface struct {
+	d2render.Surface
+	rendered []d2render.Surface
+}
+
+func (s *testSurface) PushTranslation(x, y int)                      {}
+func (s *testSurface) PushCompositeMode(mode d2render.CompositeMode) {}
+func (s *testSurface) PushColor(c color.Color)                       {}
+func (s *testSurface) PopN(n int)                                    {}
+
+func (s *testSurface) Render(surface d2render.Surface) error {
+	s.rendered = append(s.rendered, surface)
+	return nil
+}
+
+// createTestStreamedAnimation creates a single direction streamed animation, recording each frame decode
+func createTestStreamedAnimation(frameCount int, decoded *[]int) *Animation {
+	animation := &Animation{playLength: float64(frameCount), playLoop: true}
+	direction := new(animationDirection)
+	for i := 0; i < frameCount; i++ {
+		frameIndex := i
+		direction.frames = append(direction.frames, &animationFrame{
+			decode: func() (d2render.Surface, error) {
+				*decoded = append(*decoded, frameIndex)
+				return &testSurface{}, nil
+			},
+		})
+	}
+	animation.directions = append(animation.directions, direction)
+	return animation
+}
+
+func TestStreamedAnimationDecodesOnlyAccessedFrames(t *testing.T) {
+	assert := testify.New(t)
+	var decoded []int
+	animation := createTestStreamedAnimation(10, &decoded)
+	target := &testSurface{}
+
+	assert.Empty(decoded)
+
+	animation.PlayForward()
+	assert.Nil(animation.Render(target))
+	assert.Nil(animation.Advance(3))
+	assert.Nil(animation.Render(target))
+
+	assert.Equal(3, animation.GetCurrentFrame())
+	assert.Equal([]int{0, 3}, decoded)
+	assert.Len(target.rendered, 2)
+	assert.NotNil(target.rendered[1])
+}
+
+func TestStreamedAnimationRetainsOnlyCurrentFrame(t *testing.T) {
+	assert := testify.New(t)
+	var decoded []int
+	animation := createTestStreamedAnimation(3, &decoded)
+	target := &testSurface{}
+	frames := animation.directions[0].frames
+
+	assert.Nil(animation.Render(target))
+	assert.Nil(animation.Render(target))
+	assert.Equal([]int{0}, decoded)
+
+	assert.Nil(animation.SetCurrentFrame(1))
+	assert.Nil(animation.Render(target))
+	assert.Same(frames[1], animation.streamedFrame)
+	assert.Same(target.rendered[2], animation.streamedImage)
+
+	assert.Nil(animation.SetCurrentFrame(0))
+	assert.Nil(animation.Render(target))
+	assert.Equal([]int{0, 1, 0}, decoded)
+	assert.Same(frames[0], animation.streamedFrame)
+
+	// The shared frames never hold a decoded image
+	for _, frame := range frames {
+		assert.Nil(frame.image)
+	}
+}
+
+func TestStreamedAnimationClonesRetainTheirOwnFrame(t *testing.T) {
+	assert := testify.New(t)
+	var decoded []int
+	animation := createTestStreamedAnimation(3, &decoded)
+	clone := animation.Clone()
+	target := &testSurface{}
+
+	assert.Nil(animation.Render(target))
+	assert.Nil(clone.SetCurrentFrame(2))
+	assert.Nil(clone.Render(target))
+
+	// Each clone keeps the frame it decoded, so drawing them in turn does not decode them again
+	assert.Nil(animation.Render(target))
+	assert.Nil(clone.Render(target))
+	assert.Equal([]int{0, 2}, decoded)
+	assert.Same(target.rendered[0], target.rendered[2])
+	assert.Same(target.rendered[1], target.rendered[3])
+}
+
+func TestAnimationFrameEventFiresOnReleaseFrame(t *testing.T) {
+	assert := testify.New(t)
+	var decoded []int
+	animation := createTestStreamedAnimation(6, &decoded)
+	fired := 0
+	animation.OnFrame(3, func() { fired++ })
+
+	animation.PlayForward()
+	assert.Nil(animation.Advance(2))
+	assert.Equal(2, animation.GetCurrentFrame())
+	assert.Equal(0, fired)
+
+	assert.Nil(animation.Advance(1))
+	assert.Equal(3, animation.GetCurrentFrame())
+	assert.Equal(1, fired)
+
+	assert.Nil(animation.Advance(2))
+	assert.Equal(1, fired)
+
+	assert.Nil(animation.Advance(4))
+	assert.Equal(2, fired)
+}
+
+func TestAnimationFrameEventNotRepeatedOnHeldFrame(t *testing.T) {
+	assert := testify.New(t)
+	var decoded []int
+	animation := createTestStreamedAnimation(3, &decoded)
+	animation.playLoop = false
+	fired := 0
+	animation.OnFrame(2, func() { fired++ })
+
+	animation.PlayForward()
+	assert.Nil(animation.Advance(5))
+	assert.Equal(2, animation.GetCurrentFrame())
+	assert.Equal(1, fired)
+}
+
+func TestAnimationCloneCopiesFrameEvents(t *testing.T) {
+	assert := testify.New(t)
+	var decoded []int
+	animation := createTestStreamedAnimation(3, &decoded)
+	fired := 0
+	animation.OnFrame(1, func() { fired++ })
+
+	clone := animation.Clone()
+	clone.OnFrame(1, func() { fired += 10 })
+	clone.ClearFrameEvents()
+
+	animation.PlayForward()
+	assert.Nil(animation.Advance(1))
+	assert.Equal(1, fired)
+}
+
+func TestAnimationFrameKeyIsSharedByClones(t *testing.T) {
+	assert := testify.New(t)
+	var decoded []int
+	animation := createTestStreamedAnimation(3, &decoded)
+	clone := animation.Clone()
+
+	assert.Equal(animation.FrameKey(), clone.FrameKey())
+
+	assert.Nil(clone.SetCurrentFrame(1))
+	assert.NotEqual(animation.FrameKey(), clone.FrameKey())
+
+	assert.Nil(animation.SetCurrentFrame(1))
+	assert.Equal(animation.FrameKey(), clone.FrameKey())
+}
+
+// playedFrames advances the animation one frame at a time, returning the frame shown after each step
+func playedFrames(animation *Animation, steps int) []int {
+	frames := make([]int, 0, steps)
+	for i := 0; i < steps; i++ {
+		_ = animation.Advance(1)
+		frames = append(frames, animation.GetCurrentFrame())
+	}
+	return frames
+}
+
+func TestAnimationPlayBackwardWalksFramesHighToLow(t *testing.T) {
+	assert := testify.New(t)
+	var decoded []int
+	animation := createTestStreamedAnimation(4, &decoded)
+	assert.Nil(animation.SetCurrentFrame(3))
+
+	animation.PlayBackward()
+	assert.Equal([]int{2, 1, 0, 3, 2}, playedFrames(animation, 5))
+}
+
+func TestAnimationPlayPingPongBouncesAtBothEnds(t *testing.T) {
+	assert := testify.New(t)
+	var decoded []int
+	animation := createTestStreamedAnimation(4, &decoded)
+
+	animation.PlayPingPong()
+	assert.Equal([]int{1, 2, 3, 2, 1, 0, 1, 2, 3, 2}, playedFrames(animation, 10))
+	assert.Equal(1, animation.playedCount)
+}
+
+func TestAnimationPlayPingPongWithoutLoopStopsAtFirstFrame(t *testing.T) {
+	assert := testify.New(t)
+	var decoded []int
+	animation := createTestStreamedAnimation(3, &decoded)
+	animation.playLoop = false
+
+	animation.PlayPingPong()
+	assert.Equal([]int{1, 2, 1, 0, 0, 0}, playedFrames(animation, 6))
+}
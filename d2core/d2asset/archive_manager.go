@@ -1,7 +1,6 @@
 package d2asset
 
 import (
-	"errors"
 	"path"
 	"sync"
 
@@ -10,16 +9,11 @@ import (
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2config"
 )
 
-type archiveEntry struct {
-	archivePath  string
-	hashEntryMap d2mpq.HashEntryMap
-}
-
 type archiveManager struct {
-	cache   *d2common.Cache
-	config  d2config.Configuration
-	entries []archiveEntry
-	mutex   sync.Mutex
+	cache  *d2common.Cache
+	config d2config.Configuration
+	set    *d2mpq.MPQSet // The archives of the load order, from the highest precedence to the lowest
+	mutex  sync.Mutex
 }
 
 const (
@@ -30,38 +24,53 @@ func createArchiveManager(config d2config.Configuration) *archiveManager {
 	return &archiveManager{cache: d2common.CreateCache(archiveBudget), config: config}
 }
 
-func (am *archiveManager) loadArchiveForFile(filePath string) (*d2mpq.MPQ, error) {
+// readFile reads a file from the first archive in the load order that contains it. The archives are read through a
+// shared file handle, so every read holds the archive manager's lock, including the reads of background loads.
+func (am *archiveManager) readFile(filePath string) ([]byte, error) {
 	am.mutex.Lock()
 	defer am.mutex.Unlock()
 
-	if err := am.cacheArchiveEntries(); err != nil {
+	if err := am.loadArchiveSet(); err != nil {
 		return nil, err
 	}
 
-	for _, archiveEntry := range am.entries {
-		if archiveEntry.hashEntryMap.Contains(filePath) {
-			return am.loadArchive(archiveEntry.archivePath)
-		}
+	data, err := am.set.ReadFile(filePath)
+	if _, notFound := err.(*d2mpq.ErrFileNotFound); notFound {
+		return nil, &ErrNotFound{Path: filePath}
+	} else if err != nil {
+		return nil, &ErrArchive{Path: filePath, Err: err}
 	}
 
-	return nil, errors.New("file not found")
+	return data, nil
 }
 
-func (am *archiveManager) fileExistsInArchive(filePath string) (bool, error) {
+// archivePathForFile returns the path of the archive a file is loaded from: the first archive in the load order that
+// contains it, so that archives earlier in the load order (eg: the patch) override the files of later ones
+func (am *archiveManager) archivePathForFile(filePath string) (string, error) {
 	am.mutex.Lock()
 	defer am.mutex.Unlock()
 
-	if err := am.cacheArchiveEntries(); err != nil {
-		return false, err
+	if err := am.loadArchiveSet(); err != nil {
+		return "", err
 	}
 
-	for _, archiveEntry := range am.entries {
-		if archiveEntry.hashEntryMap.Contains(filePath) {
-			return true, nil
-		}
+	archive, found := am.set.ArchiveFor(filePath)
+	if !found {
+		return "", &ErrNotFound{Path: filePath}
+	}
+
+	return archive.FileName, nil
+}
+
+func (am *archiveManager) fileExistsInArchive(filePath string) (bool, error) {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	if err := am.loadArchiveSet(); err != nil {
+		return false, err
 	}
 
-	return false, nil
+	return am.set.FileExists(filePath), nil
 }
 
 func (am *archiveManager) loadArchive(archivePath string) (*d2mpq.MPQ, error) {
@@ -71,7 +80,7 @@ func (am *archiveManager) loadArchive(archivePath string) (*d2mpq.MPQ, error) {
 
 	archive, err := d2mpq.Load(archivePath)
 	if err != nil {
-		return nil, err
+		return nil, &ErrArchive{Path: archivePath, Err: err}
 	}
 
 	if err := am.cache.Insert(archivePath, archive, int(archive.Data.ArchiveSize)); err != nil {
@@ -81,25 +90,23 @@ func (am *archiveManager) loadArchive(archivePath string) (*d2mpq.MPQ, error) {
 	return archive, nil
 }
 
-func (am *archiveManager) cacheArchiveEntries() error {
-	if len(am.entries) == len(am.config.MpqLoadOrder) {
+// loadArchiveSet loads the archives of the load order into the set files are read from, if they are not loaded yet
+func (am *archiveManager) loadArchiveSet() error {
+	if am.set != nil {
 		return nil
 	}
 
-	am.entries = nil
-
+	archives := make([]*d2mpq.MPQ, 0, len(am.config.MpqLoadOrder))
 	for _, archiveName := range am.config.MpqLoadOrder {
-		archivePath := path.Join(am.config.MpqPath, archiveName)
-		archive, err := am.loadArchive(archivePath)
+		archive, err := am.loadArchive(path.Join(am.config.MpqPath, archiveName))
 		if err != nil {
 			return err
 		}
 
-		am.entries = append(
-			am.entries,
-			archiveEntry{archivePath, archive.HashEntryMap},
-		)
+		archives = append(archives, archive)
 	}
 
+	am.set = d2mpq.CreateMPQSet(archives...)
+
 	return nil
 }
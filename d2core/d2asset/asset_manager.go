@@ -20,6 +20,7 @@ type assetManager struct {
 	paletteTransformManager *paletteTransformManager
 	animationManager        *animationManager
 	fontManager             *fontManager
+	asyncLoader             *asyncLoader
 }
 
 func loadDC6(dc6Path string) (*d2dc6.DC6File, error) {
@@ -30,7 +31,7 @@ func loadDC6(dc6Path string) (*d2dc6.DC6File, error) {
 
 	dc6, err := d2dc6.LoadDC6(dc6Data)
 	if err != nil {
-		return nil, err
+		return nil, &ErrDecode{Path: dc6Path, Err: err}
 	}
 
 	return dc6, nil
@@ -42,7 +43,12 @@ func loadDCC(dccPath string) (*d2dcc.DCC, error) {
 		return nil, err
 	}
 
-	return d2dcc.LoadDCC(dccData)
+	dcc, err := d2dcc.LoadDCC(dccData)
+	if err != nil {
+		return nil, &ErrDecode{Path: dccPath, Err: err}
+	}
+
+	return dcc, nil
 }
 
 func loadCOF(cofPath string) (*d2cof.COF, error) {
@@ -51,5 +57,10 @@ func loadCOF(cofPath string) (*d2cof.COF, error) {
 		return nil, err
 	}
 
-	return d2cof.LoadCOF(cofData)
+	cof, err := d2cof.LoadCOF(cofData)
+	if err != nil {
+		return nil, &ErrDecode{Path: cofPath, Err: err}
+	}
+
+	return cof, nil
 }
@@ -0,0 +1,129 @@
+package d2asset
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// The error of the loads that were queued when the loader was closed
+var errAsyncLoaderClosed = errors.New("the asset loader was shut down")
+
+// FileResult is the outcome of loading a file in the background
+type FileResult struct {
+	Path string
+	Data []byte
+	Err  error // The load error, or the context's error if the load was cancelled before it started
+}
+
+// asyncLoad is a file queued to be loaded in the background
+type asyncLoad struct {
+	ctx      context.Context
+	filePath string
+	result   chan<- FileResult
+	done     func() // Called once the result is delivered (optional)
+}
+
+// asyncLoader loads queued files one at a time on a background worker. Loads whose context is cancelled before they
+// start are skipped, so the worker moves straight on to the loads that are still wanted.
+type asyncLoader struct {
+	load    func(filePath string) ([]byte, error)
+	mutex   sync.Mutex
+	pending []asyncLoad
+	wake    *sync.Cond
+	closed  bool // Whether the worker has been stopped
+}
+
+func createAsyncLoader(load func(filePath string) ([]byte, error)) *asyncLoader {
+	loader := &asyncLoader{load: load}
+	loader.wake = sync.NewCond(&loader.mutex)
+	go loader.work()
+	return loader
+}
+
+// Queues a file to be loaded, returning the channel its result is delivered on
+func (l *asyncLoader) loadFile(ctx context.Context, filePath string) <-chan FileResult {
+	result := make(chan FileResult, 1)
+	l.enqueue(asyncLoad{ctx: ctx, filePath: filePath, result: result})
+	return result
+}
+
+// Queues files to be loaded in order, returning the channel their results are delivered on, in the same order. The
+// channel is closed once every file has a result.
+func (l *asyncLoader) preloadFiles(ctx context.Context, filePaths []string) <-chan FileResult {
+	results := make(chan FileResult, len(filePaths))
+	if len(filePaths) == 0 {
+		close(results)
+		return results
+	}
+
+	var remaining sync.WaitGroup
+	remaining.Add(len(filePaths))
+	for _, filePath := range filePaths {
+		l.enqueue(asyncLoad{ctx: ctx, filePath: filePath, result: results, done: remaining.Done})
+	}
+	go func() {
+		remaining.Wait()
+		close(results)
+	}()
+	return results
+}
+
+func (l *asyncLoader) enqueue(load asyncLoad) {
+	l.mutex.Lock()
+	if l.closed {
+		l.mutex.Unlock()
+		load.deliver(FileResult{Path: load.filePath, Err: errAsyncLoaderClosed})
+		return
+	}
+	l.pending = append(l.pending, load)
+	l.mutex.Unlock()
+	l.wake.Signal()
+}
+
+// Stops the worker once the load in progress (if any) finishes. The loads still queued, and any queued afterwards,
+// are not loaded and their results hold an error.
+func (l *asyncLoader) close() {
+	l.mutex.Lock()
+	l.closed = true
+	dropped := l.pending
+	l.pending = nil
+	l.mutex.Unlock()
+	l.wake.Broadcast()
+
+	for _, load := range dropped {
+		load.deliver(FileResult{Path: load.filePath, Err: errAsyncLoaderClosed})
+	}
+}
+
+func (l *asyncLoader) work() {
+	for {
+		l.mutex.Lock()
+		for len(l.pending) == 0 && !l.closed {
+			l.wake.Wait()
+		}
+		if l.closed {
+			l.mutex.Unlock()
+			return
+		}
+		load := l.pending[0]
+		l.pending = l.pending[1:]
+		l.mutex.Unlock()
+
+		result := FileResult{Path: load.filePath}
+		if err := load.ctx.Err(); err != nil {
+			result.Err = err
+		} else {
+			result.Data, result.Err = l.load(load.filePath)
+		}
+		load.deliver(result)
+	}
+}
+
+// Delivers the result of a load
+func (load asyncLoad) deliver(result FileResult) {
+	load.result <- result
+	if load.done != nil {
+		load.done()
+	}
+}
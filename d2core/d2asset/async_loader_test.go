@@ -0,0 +1,122 @@
+package d2asset
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+// createBlockingAsyncLoader creates a loader whose loads report their path on started, then wait for release before
+// returning the path as the file data
+func createBlockingAsyncLoader() (loader *asyncLoader, started chan string, release chan struct{}) {
+	started = make(chan string, 10)
+	release = make(chan struct{})
+	loader = createAsyncLoader(func(filePath string) ([]byte, error) {
+		started <- filePath
+		<-release
+		return []byte(filePath), nil
+	})
+	return loader, started, release
+}
+
+// Receives from a channel, failing the test if nothing arrives in time
+func receiveString(t *testing.T, values <-chan string) string {
+	select {
+	case value := <-values:
+		return value
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+		return ""
+	}
+}
+
+func receiveResult(t *testing.T, results <-chan FileResult) FileResult {
+	select {
+	case result := <-results:
+		return result
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+		return FileResult{}
+	}
+}
+
+func TestLoadFileAsync(t *testing.T) {
+	assert := testify.New(t)
+	loader, started, release := createBlockingAsyncLoader()
+
+	result := loader.loadFile(context.Background(), "a")
+	assert.Equal("a", receiveString(t, started))
+	release <- struct{}{}
+	assert.Equal(FileResult{Path: "a", Data: []byte("a")}, receiveResult(t, result))
+}
+
+func TestCancellingPreloadSkipsPendingLoads(t *testing.T) {
+	assert := testify.New(t)
+	loader, started, release := createBlockingAsyncLoader()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := loader.preloadFiles(ctx, []string{"a", "b", "c", "d"})
+	assert.Equal("a", receiveString(t, started))
+	release <- struct{}{}
+	delivered := receiveResult(t, results)
+
+	// Cancel while b is loading, and queue another load that is still wanted
+	assert.Equal("b", receiveString(t, started))
+	cancel()
+	other := loader.loadFile(context.Background(), "e")
+	release <- struct{}{}
+
+	// The loads that had started finish, and the pending ones are skipped
+	assert.Equal(FileResult{Path: "b", Data: []byte("b")}, receiveResult(t, results))
+	for _, filePath := range []string{"c", "d"} {
+		result := receiveResult(t, results)
+		assert.Equal(filePath, result.Path)
+		assert.Nil(result.Data)
+		assert.Equal(context.Canceled, result.Err)
+	}
+	_, open := <-results
+	assert.False(open)
+
+	// The worker moves straight on to the next load
+	assert.Equal("e", receiveString(t, started))
+	release <- struct{}{}
+	assert.Equal(FileResult{Path: "e", Data: []byte("e")}, receiveResult(t, other))
+	assert.Empty(started, "the skipped files were never loaded")
+
+	// The result delivered before cancelling is unaffected
+	assert.Equal(FileResult{Path: "a", Data: []byte("a")}, delivered)
+}
+
+func TestPreloadNoFiles(t *testing.T) {
+	assert := testify.New(t)
+	loader, _, _ := createBlockingAsyncLoader()
+
+	_, open := <-loader.preloadFiles(context.Background(), nil)
+	assert.False(open)
+}
+
+func TestClosingAsyncLoaderDropsPendingLoads(t *testing.T) {
+	assert := testify.New(t)
+	loader, started, release := createBlockingAsyncLoader()
+
+	loading := loader.loadFile(context.Background(), "a")
+	assert.Equal("a", receiveString(t, started))
+	pending := loader.loadFile(context.Background(), "b")
+
+	// The queued load is dropped straight away, and the load in progress still finishes
+	loader.close()
+	assert.Equal(FileResult{Path: "b", Err: errAsyncLoaderClosed}, receiveResult(t, pending))
+	release <- struct{}{}
+	assert.Equal(FileResult{Path: "a", Data: []byte("a")}, receiveResult(t, loading))
+
+	// Loads queued after closing are not loaded
+	assert.Equal(FileResult{Path: "c", Err: errAsyncLoaderClosed}, receiveResult(t, loader.loadFile(context.Background(), "c")))
+	results := loader.preloadFiles(context.Background(), []string{"d"})
+	assert.Equal(errAsyncLoaderClosed, receiveResult(t, results).Err)
+	_, open := <-results
+	assert.False(open)
+	assert.Empty(started)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2data"
 	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2data/d2datadict"
 	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2cof"
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
 )
 
@@ -16,6 +17,8 @@ type Composite struct {
 	object      *d2datadict.ObjectLookupRecord
 	palettePath string
 	mode        *compositeMode
+	onEvent     func(event d2enum.AnimationFrame) // Called when playback reaches a frame marked in the animation data
+	cycle       *PaletteCycle                     // The palette cycle of every layer (nil=none)
 }
 
 func CreateComposite(object *d2datadict.ObjectLookupRecord, palettePath string) *Composite {
@@ -30,6 +33,7 @@ func (c *Composite) Advance(elapsed float64) error {
 	c.mode.lastFrameTime += elapsed
 	framesToAdd := int(c.mode.lastFrameTime / c.mode.animationSpeed)
 	c.mode.lastFrameTime -= float64(framesToAdd) * c.mode.animationSpeed
+	c.fireFrameEvents(framesToAdd)
 	c.mode.frameIndex += framesToAdd
 	c.mode.playedCount += c.mode.frameIndex / c.mode.frameCount
 	c.mode.frameIndex %= c.mode.frameCount
@@ -45,6 +49,62 @@ func (c *Composite) Advance(elapsed float64) error {
 	return nil
 }
 
+// OnFrameEvent sets the callback run each time playback advances onto a frame that the animation data marks with an
+// event (eg: the frame an attack hits on, or a missile is released), so gameplay does not hardcode frame numbers
+func (c *Composite) OnFrameEvent(callback func(event d2enum.AnimationFrame)) {
+	c.onEvent = callback
+}
+
+// SetPaletteCycle rotates the colors of a range of palette indices on every layer of the composite, including the
+// layers of modes set later
+func (c *Composite) SetPaletteCycle(cycle PaletteCycle) error {
+	if cycle.Last <= cycle.First {
+		return errors.New("a palette cycle needs at least two palette indices")
+	}
+
+	c.cycle = &cycle
+	if c.mode == nil {
+		return nil
+	}
+
+	for _, layer := range c.mode.layers {
+		if layer != nil {
+			if err := layer.SetPaletteCycle(cycle); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ClearPaletteCycle stops the palette cycle of the composite's layers
+func (c *Composite) ClearPaletteCycle() {
+	c.cycle = nil
+	if c.mode == nil {
+		return
+	}
+
+	for _, layer := range c.mode.layers {
+		if layer != nil {
+			layer.ClearPaletteCycle()
+		}
+	}
+}
+
+// Runs the frame event callback for each marked frame among the next frames played
+func (c *Composite) fireFrameEvents(framesToAdd int) {
+	if c.onEvent == nil {
+		return
+	}
+
+	for i := 1; i <= framesToAdd; i++ {
+		frameIndex := (c.mode.frameIndex + i) % c.mode.frameCount
+		if frameIndex < len(c.mode.frameEvents) && c.mode.frameEvents[frameIndex] != d2enum.AnimationFrameNoEvent {
+			c.onEvent(c.mode.frameEvents[frameIndex])
+		}
+	}
+}
+
 func (c *Composite) Render(target d2render.Surface) error {
 	if c.mode == nil {
 		return nil
@@ -115,6 +175,7 @@ type compositeMode struct {
 
 	frameCount     int
 	frameIndex     int
+	frameEvents    []d2enum.AnimationFrame // The event triggered on each frame, from the animation data
 	animationSpeed float64
 	lastFrameTime  float64
 }
@@ -152,11 +213,15 @@ func (c *Composite) createMode(animationMode, weaponClass string, direction int)
 		layers:         make([]*Animation, d2enum.CompositeTypeMax),
 		frameCount:     animationData[0].FramesPerDirection,
 		animationSpeed: 1.0 / ((float64(animationData[0].AnimationSpeed) * 25.0) / 256.0),
+		frameEvents:    make([]d2enum.AnimationFrame, animationData[0].FramesPerDirection),
+	}
+	for frame := range mode.frameEvents {
+		mode.frameEvents[frame] = animationData[0].FrameEvent(frame)
 	}
 
-	mode.drawOrder = make([][]d2enum.CompositeType, mode.frameCount)
-	for frame := 0; frame < mode.frameCount; frame++ {
-		mode.drawOrder[frame] = cof.Priority[mode.direction][frame]
+	mode.drawOrder, err = compositeDrawOrder(cof, mode.direction, mode.frameCount)
+	if err != nil {
+		return nil, err
 	}
 
 	for _, cofLayer := range cof.CofLayers {
@@ -235,6 +300,9 @@ func (c *Composite) createMode(animationMode, weaponClass string, direction int)
 			layer.PlayForward()
 			layer.SetBlend(blend)
 			layer.SetDirection(direction)
+			if c.cycle != nil {
+				layer.SetPaletteCycle(*c.cycle)
+			}
 			mode.layers[cofLayer.Type] = layer
 		}
 	}
@@ -242,6 +310,24 @@ func (c *Composite) createMode(animationMode, weaponClass string, direction int)
 	return mode, nil
 }
 
+// Returns the order the layers of each frame of a direction are drawn in, back to front, from the COF. Frames the
+// COF has no order for (when the animation data plays more frames than the COF has) use the direction's order.
+func compositeDrawOrder(cof *d2cof.COF, direction, frameCount int) ([][]d2enum.CompositeType, error) {
+	directionOrder := cof.GetLayerDrawOrder(direction)
+	if directionOrder == nil {
+		return nil, fmt.Errorf("direction %d is not in the COF (it has %d directions)", direction, cof.NumberOfDirections)
+	}
+
+	drawOrder := make([][]d2enum.CompositeType, frameCount)
+	for frame := range drawOrder {
+		drawOrder[frame] = cof.GetFrameLayerDrawOrder(direction, frame)
+		if drawOrder[frame] == nil {
+			drawOrder[frame] = directionOrder
+		}
+	}
+	return drawOrder, nil
+}
+
 func loadCompositeLayer(object *d2datadict.ObjectLookupRecord, layerKey, layerValue, animationMode, weaponClass, palettePath string, transparency int) (*Animation, error) {
 	animationPaths := []string{
 		fmt.Sprintf("%s/%s/%s/%s%s%s%s%s.dcc", object.Base, object.Token, layerKey, object.Token, layerKey, layerValue, animationMode, weaponClass),
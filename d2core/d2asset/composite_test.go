@@ -0,0 +1,81 @@
+package d2asset
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2cof"
+)
+
+// createTestComposite creates a composite playing a mode with no layers, at 10 frames per second, whose animation
+// data marks an attack on frame 3 and a sound on frame 6 of 8
+func createTestComposite() *Composite {
+	frameEvents := make([]d2enum.AnimationFrame, 8)
+	frameEvents[3] = d2enum.AnimationFrameAttack
+	frameEvents[6] = d2enum.AnimationFrameSound
+	return &Composite{mode: &compositeMode{
+		frameCount:     len(frameEvents),
+		frameEvents:    frameEvents,
+		animationSpeed: 0.1,
+		drawOrder:      make([][]d2enum.CompositeType, len(frameEvents)),
+	}}
+}
+
+func TestCompositeFiresFrameEventOnActionFrame(t *testing.T) {
+	assert := testify.New(t)
+	composite := createTestComposite()
+	var events []d2enum.AnimationFrame
+	composite.OnFrameEvent(func(event d2enum.AnimationFrame) {
+		events = append(events, event)
+	})
+
+	assert.NoError(composite.Advance(0.25))
+	assert.Empty(events)
+
+	assert.NoError(composite.Advance(0.1))
+	assert.Equal([]d2enum.AnimationFrame{d2enum.AnimationFrameAttack}, events)
+
+	// Holding on a frame does not fire it again
+	assert.NoError(composite.Advance(0.04))
+	assert.Len(events, 1)
+}
+
+func TestCompositeFiresSkippedFrameEvents(t *testing.T) {
+	assert := testify.New(t)
+	composite := createTestComposite()
+	var events []d2enum.AnimationFrame
+	composite.OnFrameEvent(func(event d2enum.AnimationFrame) {
+		events = append(events, event)
+	})
+
+	// A long frame plays through both marked frames, then wraps around onto the attack again
+	assert.NoError(composite.Advance(1.15))
+	assert.Equal([]d2enum.AnimationFrame{
+		d2enum.AnimationFrameAttack,
+		d2enum.AnimationFrameSound,
+		d2enum.AnimationFrameAttack,
+	}, events)
+	assert.Equal(1, composite.GetPlayedCount())
+}
+
+func TestCompositeDrawOrderFollowsCOFDirection(t *testing.T) {
+	assert := testify.New(t)
+	behind := []d2enum.CompositeType{d2enum.CompositeTypeRightHand, d2enum.CompositeTypeTorso}
+	inFront := []d2enum.CompositeType{d2enum.CompositeTypeTorso, d2enum.CompositeTypeRightHand}
+	cof := &d2cof.COF{
+		NumberOfDirections: 2,
+		FramesPerDirection: 2,
+		CompositeLayers:    map[d2enum.CompositeType]int{d2enum.CompositeTypeTorso: 0, d2enum.CompositeTypeRightHand: 1},
+		Priority:           [][][]d2enum.CompositeType{{behind, behind}, {inFront, behind}},
+	}
+
+	drawOrder, err := compositeDrawOrder(cof, 1, 3)
+	assert.NoError(err)
+	// The third frame is not in the COF, so it uses the direction's order
+	assert.Equal([][]d2enum.CompositeType{inFront, behind, inFront}, drawOrder)
+
+	_, err = compositeDrawOrder(cof, 2, 3)
+	assert.Error(err)
+}
@@ -0,0 +1,80 @@
+package d2asset
+
+import (
+	"image"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// Creates the surface a contact sheet is rendered onto
+var newContactSheetSurface = func(width, height int) (d2render.Surface, error) {
+	return d2render.NewSurface(width, height, d2render.FilterNearest)
+}
+
+// The options used to render a contact sheet
+type ContactSheetOptions struct {
+	AllFrames bool // Whether every frame of each direction is drawn, rather than only the first frame
+	Padding   int  // The space between the cells, in pixels
+}
+
+// Renders the directions of an animation into a contact sheet (eg: for verifying art imports), with one row per
+// direction and one column per frame. Every cell covers the area of all of the frames, so the frames keep their
+// offsets relative to each other. The direction and frame of the animation are restored afterwards.
+func RenderContactSheet(animation *Animation, options ContactSheetOptions) (*image.RGBA, error) {
+	bounds, columns := animation.contactSheetCell(options.AllFrames)
+	if bounds.Empty() {
+		return image.NewRGBA(image.Rectangle{}), nil
+	}
+
+	cellWidth, cellHeight := bounds.Dx()+options.Padding, bounds.Dy()+options.Padding
+	rows := len(animation.directions)
+	target, err := newContactSheetSurface(columns*cellWidth-options.Padding, rows*cellHeight-options.Padding)
+	if err != nil {
+		return nil, err
+	}
+
+	directionIndex, frameIndex := animation.directionIndex, animation.frameIndex
+	defer func() {
+		animation.directionIndex, animation.frameIndex = directionIndex, frameIndex
+	}()
+
+	for row, direction := range animation.directions {
+		for column := 0; column < columns && column < len(direction.frames); column++ {
+			animation.directionIndex, animation.frameIndex = row, column
+
+			target.PushTranslation(column*cellWidth-bounds.Min.X, row*cellHeight-bounds.Min.Y)
+			err := animation.Render(target)
+			target.Pop()
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return target.Screenshot(), nil
+}
+
+// Returns the area covered by the frames drawn on a contact sheet, relative to the animation's origin, and the
+// number of columns on the sheet
+func (a *Animation) contactSheetCell(allFrames bool) (image.Rectangle, int) {
+	var bounds image.Rectangle
+	columns := 0
+	for _, direction := range a.directions {
+		frames := direction.frames
+		if !allFrames && len(frames) > 1 {
+			frames = frames[:1]
+		}
+
+		for _, frame := range frames {
+			frameBounds := image.Rect(frame.offsetX, frame.offsetY, frame.offsetX+frame.width, frame.offsetY+frame.height)
+			bounds = bounds.Union(frameBounds)
+		}
+
+		if len(frames) > columns {
+			columns = len(frames)
+		}
+	}
+
+	return bounds, columns
+}
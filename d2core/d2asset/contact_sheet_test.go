@@ -0,0 +1,113 @@
+package d2asset
+
+import (
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render/software"
+)
+
+// createTestSolidFrame creates a frame of a single color, drawn with the software renderer
+func createTestSolidFrame(renderer *software.Renderer, width, height, offsetX, offsetY int,
+	c color.RGBA) *animationFrame {
+	surface, _ := renderer.NewSurface(width, height, d2render.FilterNearest)
+	_ = surface.Clear(c)
+	return &animationFrame{width: width, height: height, offsetX: offsetX, offsetY: offsetY, image: surface}
+}
+
+// createTestDirectionalAnimation creates an animation with a differently colored 2x2 frame for each direction and frame
+func createTestDirectionalAnimation(renderer *software.Renderer, directionColors [][]color.RGBA) *Animation {
+	animation := &Animation{playLength: 1}
+	for _, frameColors := range directionColors {
+		direction := new(animationDirection)
+		for _, frameColor := range frameColors {
+			direction.frames = append(direction.frames, createTestSolidFrame(renderer, 2, 2, -1, -2, frameColor))
+		}
+		animation.directions = append(animation.directions, direction)
+	}
+	return animation
+}
+
+func useSoftwareContactSheetSurface(renderer *software.Renderer) func() {
+	previous := newContactSheetSurface
+	newContactSheetSurface = func(width, height int) (d2render.Surface, error) {
+		return renderer.NewSurface(width, height, d2render.FilterNearest)
+	}
+	return func() { newContactSheetSurface = previous }
+}
+
+var (
+	testRed    = color.RGBA{R: 255, A: 255}
+	testGreen  = color.RGBA{G: 255, A: 255}
+	testBlue   = color.RGBA{B: 255, A: 255}
+	testWhite  = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	testYellow = color.RGBA{R: 255, G: 255, A: 255}
+)
+
+func TestRenderContactSheetDrawsOneCellPerDirection(t *testing.T) {
+	assert := testify.New(t)
+	renderer, _ := software.CreateRenderer()
+	defer useSoftwareContactSheetSurface(renderer)()
+
+	animation := createTestDirectionalAnimation(renderer, [][]color.RGBA{
+		{testRed, testYellow}, {testGreen, testYellow}, {testBlue, testYellow}, {testWhite, testYellow},
+	})
+	_ = animation.SetCurrentFrame(1)
+
+	sheet, err := RenderContactSheet(animation, ContactSheetOptions{})
+	assert.Nil(err)
+	assert.Equal(2, sheet.Bounds().Dx())
+	assert.Equal(8, sheet.Bounds().Dy())
+
+	for row, expected := range []color.RGBA{testRed, testGreen, testBlue, testWhite} {
+		assert.Equal(expected, sheet.RGBAAt(0, row*2))
+		assert.Equal(expected, sheet.RGBAAt(1, row*2+1))
+	}
+
+	// The animation is left as it was
+	assert.Equal(0, animation.GetDirection())
+	assert.Equal(1, animation.GetCurrentFrame())
+}
+
+func TestRenderContactSheetWithAllFramesAndPadding(t *testing.T) {
+	assert := testify.New(t)
+	renderer, _ := software.CreateRenderer()
+	defer useSoftwareContactSheetSurface(renderer)()
+
+	animation := createTestDirectionalAnimation(renderer, [][]color.RGBA{
+		{testRed, testGreen}, {testBlue, testWhite},
+	})
+
+	sheet, err := RenderContactSheet(animation, ContactSheetOptions{AllFrames: true, Padding: 1})
+	assert.Nil(err)
+	assert.Equal(5, sheet.Bounds().Dx())
+	assert.Equal(5, sheet.Bounds().Dy())
+
+	assert.Equal(testRed, sheet.RGBAAt(1, 1))
+	assert.Equal(testGreen, sheet.RGBAAt(3, 1))
+	assert.Equal(testBlue, sheet.RGBAAt(1, 3))
+	assert.Equal(testWhite, sheet.RGBAAt(4, 4))
+	assert.Equal(color.RGBA{}, sheet.RGBAAt(2, 2))
+}
+
+func TestRenderContactSheetKeepsFrameOffsets(t *testing.T) {
+	assert := testify.New(t)
+	renderer, _ := software.CreateRenderer()
+	defer useSoftwareContactSheetSurface(renderer)()
+
+	animation := &Animation{playLength: 1, directions: []*animationDirection{{frames: []*animationFrame{
+		createTestSolidFrame(renderer, 2, 2, 0, 0, testRed),
+		createTestSolidFrame(renderer, 1, 1, 2, 3, testGreen),
+	}}}}
+
+	sheet, err := RenderContactSheet(animation, ContactSheetOptions{AllFrames: true})
+	assert.Nil(err)
+	assert.Equal(6, sheet.Bounds().Dx())
+	assert.Equal(4, sheet.Bounds().Dy())
+	assert.Equal(testRed, sheet.RGBAAt(0, 0))
+	assert.Equal(color.RGBA{}, sheet.RGBAAt(2, 3))
+	assert.Equal(testGreen, sheet.RGBAAt(3+2, 3))
+}
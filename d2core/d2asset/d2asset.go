@@ -1,6 +1,7 @@
 package d2asset
 
 import (
+	"context"
 	"log"
 
 	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2data/d2datadict"
@@ -25,6 +26,7 @@ func Initialize() error {
 		paletteTransformManager = createPaletteTransformManager()
 		animationManager        = createAnimationManager()
 		fontManager             = createFontManager()
+		asyncLoader             = createAsyncLoader(fileManager.loadFile)
 	)
 
 	singleton = &assetManager{
@@ -34,6 +36,7 @@ func Initialize() error {
 		paletteTransformManager,
 		animationManager,
 		fontManager,
+		asyncLoader,
 	}
 
 	d2term.BindAction("assetspam", "display verbose asset manager logs", func(verbose bool) {
@@ -59,6 +62,16 @@ func Initialize() error {
 		d2term.OutputInfo("font cache: %f", float64(fontManager.cache.GetWeight())/float64(fontManager.cache.GetBudget())*100.0)
 	})
 
+	d2term.BindAction("assetsource", "display the archive a file is loaded from", func(filePath string) {
+		archivePath, err := fileManager.archivePathForFile(filePath)
+		if err != nil {
+			d2term.OutputError("%s", err)
+			return
+		}
+
+		d2term.OutputInfo("%s: %s", filePath, archivePath)
+	})
+
 	d2term.BindAction("assetclear", "clear asset manager cache", func() {
 		archiveManager.cache.Clear()
 		fileManager.cache.Clear()
@@ -72,6 +85,9 @@ func Initialize() error {
 }
 
 func Shutdown() {
+	if singleton != nil {
+		singleton.asyncLoader.close()
+	}
 	singleton = nil
 }
 
@@ -92,11 +108,35 @@ func LoadFile(filePath string) ([]byte, error) {
 	return data, err
 }
 
+// LoadFileAsync loads a file in the background, delivering the result on the returned channel. Files are loaded one
+// at a time in the order they are requested. If the context is cancelled before the load starts, the file is not
+// loaded and the result holds the context's error.
+func LoadFileAsync(ctx context.Context, filePath string) <-chan FileResult {
+	verifyWasInit()
+	return singleton.asyncLoader.loadFile(ctx, filePath)
+}
+
+// PreloadFiles loads files in the background (eg: the assets of a region before it is entered), delivering the
+// results on the returned channel in the same order as the files, and closing it after the last one. Cancelling the
+// context skips the files that have not started loading yet, whose results hold the context's error. Results already
+// delivered are unaffected.
+func PreloadFiles(ctx context.Context, filePaths []string) <-chan FileResult {
+	verifyWasInit()
+	return singleton.asyncLoader.preloadFiles(ctx, filePaths)
+}
+
 func FileExists(filePath string) (bool, error) {
 	verifyWasInit()
 	return singleton.fileManager.fileExists(filePath)
 }
 
+// ArchivePathForFile returns the path of the archive a file is loaded from: the first archive in the load order that
+// contains it (eg: to check which mod or patch provides a file)
+func ArchivePathForFile(filePath string) (string, error) {
+	verifyWasInit()
+	return singleton.fileManager.archivePathForFile(filePath)
+}
+
 func LoadAnimation(animationPath, palettePath string) (*Animation, error) {
 	verifyWasInit()
 	return LoadAnimationWithTransparency(animationPath, palettePath, 255)
@@ -109,7 +149,14 @@ func LoadPaletteTransform(pl2Path string) (*d2pl2.PL2File, error) {
 
 func LoadAnimationWithTransparency(animationPath, palettePath string, transparency int) (*Animation, error) {
 	verifyWasInit()
-	return singleton.animationManager.loadAnimation(animationPath, palettePath, transparency)
+	return singleton.animationManager.loadAnimation(animationPath, palettePath, transparency, false)
+}
+
+// LoadAnimationStreamed loads an animation whose frames are only decoded when they are rendered, with only the current
+// frame retained in memory. This is intended for large or rarely played DC6 animations; other formats are fully decoded.
+func LoadAnimationStreamed(animationPath, palettePath string) (*Animation, error) {
+	verifyWasInit()
+	return singleton.animationManager.loadAnimation(animationPath, palettePath, 255, true)
 }
 
 func LoadComposite(object *d2datadict.ObjectLookupRecord, palettePath string) (*Composite, error) {
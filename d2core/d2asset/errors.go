@@ -0,0 +1,42 @@
+package d2asset
+
+import "fmt"
+
+// ErrNotFound is returned when an asset does not exist in any of the loaded archives
+type ErrNotFound struct {
+	Path string // The path of the asset that was requested
+}
+
+func (e *ErrNotFound) Error() string {
+	return fmt.Sprintf("asset not found: %s", e.Path)
+}
+
+// ErrDecode is returned when an asset was read but its data could not be decoded
+type ErrDecode struct {
+	Path string // The path of the asset that failed to decode
+	Err  error  // The underlying decoder error
+}
+
+func (e *ErrDecode) Error() string {
+	return fmt.Sprintf("failed to decode asset %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying decoder error
+func (e *ErrDecode) Unwrap() error {
+	return e.Err
+}
+
+// ErrArchive is returned when an archive could not be opened or read
+type ErrArchive struct {
+	Path string // The path of the archive, or of the file being read from it
+	Err  error  // The underlying archive error
+}
+
+func (e *ErrArchive) Error() string {
+	return fmt.Sprintf("archive error for %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying archive error
+func (e *ErrArchive) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,56 @@
+package d2asset
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2config"
+)
+
+func TestReadMissingFileReturnsNotFound(t *testing.T) {
+	assert := testify.New(t)
+	archiveManager := createArchiveManager(d2config.Configuration{})
+
+	_, err := archiveManager.readFile(`data\global\palette\act1\pal.dat`)
+	notFound, ok := err.(*ErrNotFound)
+	assert.True(ok)
+	assert.Equal(`data\global\palette\act1\pal.dat`, notFound.Path)
+}
+
+func TestLoadMissingArchiveReturnsArchiveError(t *testing.T) {
+	assert := testify.New(t)
+	archiveManager := createArchiveManager(d2config.Configuration{
+		MpqPath:      "missing",
+		MpqLoadOrder: []string{"missing.mpq"},
+	})
+
+	_, err := archiveManager.readFile(`data\global\palette\act1\pal.dat`)
+	archiveErr, ok := err.(*ErrArchive)
+	assert.True(ok)
+	assert.Contains(archiveErr.Path, "missing.mpq")
+	assert.NotNil(archiveErr.Unwrap())
+}
+
+func TestDecodeInvalidPaletteReturnsDecodeError(t *testing.T) {
+	assert := testify.New(t)
+
+	_, err := decodePalette(`data\global\palette\act1\pal.dat`, []byte{1, 2, 3})
+	decodeErr, ok := err.(*ErrDecode)
+	assert.True(ok)
+	assert.Equal(`data\global\palette\act1\pal.dat`, decodeErr.Path)
+	assert.Contains(decodeErr.Error(), "pal.dat")
+
+	palette, err := decodePalette(`data\global\palette\act1\pal.dat`, make([]byte, 256*3))
+	assert.Nil(err)
+	assert.NotNil(palette)
+}
+
+func TestArchivePathForMissingFileReturnsNotFound(t *testing.T) {
+	assert := testify.New(t)
+	archiveManager := createArchiveManager(d2config.Configuration{})
+
+	_, err := archiveManager.archivePathForFile(`data\global\palette\act1\pal.dat`)
+	_, ok := err.(*ErrNotFound)
+	assert.True(ok)
+}
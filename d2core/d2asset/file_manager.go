@@ -11,44 +11,84 @@ const (
 	fileBudget = 1024 * 1024 * 32
 )
 
+// archiveReader finds and reads the files of the archives in the load order (the archive manager, or a fake in tests)
+type archiveReader interface {
+	readFile(filePath string) ([]byte, error)
+	fileExistsInArchive(filePath string) (bool, error)
+	archivePathForFile(filePath string) (string, error)
+}
+
 type fileManager struct {
 	cache          *d2common.Cache
-	archiveManager *archiveManager
+	archiveManager archiveReader
 	config         d2config.Configuration
 }
 
-func createFileManager(config d2config.Configuration, archiveManager *archiveManager) *fileManager {
+func createFileManager(config d2config.Configuration, archiveManager archiveReader) *fileManager {
 	return &fileManager{d2common.CreateCache(fileBudget), archiveManager, config}
 }
 
+// loadFile returns the data of a file. Files are cached by the path they are read from, so the SD and HD variants of a
+// file are cached apart, and changing the HD asset prefix does not return the variant cached for the previous one.
 func (fm *fileManager) loadFile(filePath string) ([]byte, error) {
-	filePath = fm.fixupFilePath(filePath)
+	filePath = fm.resolveFilePath(fm.fixupFilePath(filePath))
 	if value, found := fm.cache.Retrieve(filePath); found {
 		return value.([]byte), nil
 	}
 
-	archive, err := fm.archiveManager.loadArchiveForFile(filePath)
-	if err != nil {
-		return nil, err
-	}
-
-	data, err := archive.ReadFile(filePath)
+	data, err := fm.archiveManager.readFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 
+	// Files are loaded by the background loader and the foreground at the same time, so another load of the file may
+	// have cached it while this one was reading
 	if err := fm.cache.Insert(filePath, data, len(data)); err != nil {
-		return nil, err
+		if value, found := fm.cache.Retrieve(filePath); found {
+			return value.([]byte), nil
+		}
+		return data, nil
 	}
 
 	return data, nil
 }
 
+// archivePathForFile returns the path of the archive a file is loaded from (eg: to debug the load order of mods)
+func (fm *fileManager) archivePathForFile(filePath string) (string, error) {
+	filePath = fm.resolveFilePath(fm.fixupFilePath(filePath))
+	return fm.archiveManager.archivePathForFile(filePath)
+}
+
 func (fm *fileManager) fileExists(filePath string) (bool, error) {
-	filePath = fm.fixupFilePath(filePath)
+	filePath = fm.resolveFilePath(fm.fixupFilePath(filePath))
 	return fm.archiveManager.fileExistsInArchive(filePath)
 }
 
+// resolveFilePath returns the HD variant of an already fixed up file path if one exists, or the path itself otherwise
+func (fm *fileManager) resolveFilePath(filePath string) string {
+	return resolveHdFilePath(filePath, fm.config.HdAssetPrefix, fm.archiveManager.fileExistsInArchive)
+}
+
+func resolveHdFilePath(filePath, hdPrefix string, exists func(string) (bool, error)) string {
+	if len(hdPrefix) == 0 {
+		return filePath
+	}
+
+	hdPrefix = strings.ToLower(hdPrefix)
+	hdPrefix = strings.ReplaceAll(hdPrefix, `/`, "\\")
+	hdPrefix = strings.Trim(hdPrefix, "\\")
+	if len(hdPrefix) == 0 {
+		return filePath
+	}
+
+	hdFilePath := hdPrefix + "\\" + filePath
+	if found, err := exists(hdFilePath); err == nil && found {
+		return hdFilePath
+	}
+
+	return filePath
+}
+
 func (fm *fileManager) fixupFilePath(filePath string) string {
 	filePath = strings.ReplaceAll(filePath, "{LANG}", fm.config.Language)
 	if fm.config.Language != "ENG" {
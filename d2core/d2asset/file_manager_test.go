@@ -0,0 +1,168 @@
+package d2asset
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2config"
+)
+
+func createTestFileLookup(filePaths ...string) func(string) (bool, error) {
+	return func(filePath string) (bool, error) {
+		for _, existing := range filePaths {
+			if existing == filePath {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+func TestResolveHdFilePathPrefersHd(t *testing.T) {
+	assert := testify.New(t)
+	exists := createTestFileLookup(`hd\data\global\ui\panel.dc6`, `data\global\ui\panel.dc6`)
+
+	assert.Equal(`hd\data\global\ui\panel.dc6`, resolveHdFilePath(`data\global\ui\panel.dc6`, "hd", exists))
+	assert.Equal(`hd\data\global\ui\panel.dc6`, resolveHdFilePath(`data\global\ui\panel.dc6`, "/HD/", exists))
+}
+
+func TestResolveHdFilePathFallsBackToSd(t *testing.T) {
+	assert := testify.New(t)
+	exists := createTestFileLookup(`data\global\ui\panel.dc6`)
+
+	assert.Equal(`data\global\ui\panel.dc6`, resolveHdFilePath(`data\global\ui\panel.dc6`, "hd", exists))
+}
+
+func TestResolveHdFilePathWithoutPrefix(t *testing.T) {
+	assert := testify.New(t)
+	exists := createTestFileLookup(`hd\data\global\ui\panel.dc6`)
+
+	assert.Equal(`data\global\ui\panel.dc6`, resolveHdFilePath(`data\global\ui\panel.dc6`, "", exists))
+}
+
+// testArchiveReader is an archive reader over files held in memory, which counts the reads of each file. Reads report
+// their path on started (if set), then wait for release (if set) before returning.
+type testArchiveReader struct {
+	files   map[string][]byte
+	reads   map[string]int
+	mutex   sync.Mutex
+	started chan string
+	release chan struct{}
+}
+
+func createTestArchiveReader(files map[string][]byte) *testArchiveReader {
+	return &testArchiveReader{files: files, reads: make(map[string]int)}
+}
+
+func (r *testArchiveReader) readFile(filePath string) ([]byte, error) {
+	r.mutex.Lock()
+	r.reads[filePath]++
+	r.mutex.Unlock()
+
+	if r.started != nil {
+		r.started <- filePath
+	}
+	if r.release != nil {
+		<-r.release
+	}
+
+	data, found := r.files[filePath]
+	if !found {
+		return nil, &ErrNotFound{Path: filePath}
+	}
+	return data, nil
+}
+
+func (r *testArchiveReader) fileExistsInArchive(filePath string) (bool, error) {
+	_, found := r.files[filePath]
+	return found, nil
+}
+
+func (r *testArchiveReader) archivePathForFile(filePath string) (string, error) {
+	if _, found := r.files[filePath]; !found {
+		return "", &ErrNotFound{Path: filePath}
+	}
+	return "test.mpq", nil
+}
+
+func TestLoadFileSyncAndAsyncAtOnce(t *testing.T) {
+	assert := testify.New(t)
+	archives := createTestArchiveReader(map[string][]byte{`data\global\ui\panel.dc6`: []byte("panel")})
+	archives.started = make(chan string, 2)
+	archives.release = make(chan struct{})
+	fm := createFileManager(d2config.Configuration{Language: "ENG"}, archives)
+	loader := createAsyncLoader(fm.loadFile)
+	defer loader.close()
+
+	asyncResult := loader.loadFile(context.Background(), "/data/global/ui/panel.dc6")
+	syncResult := make(chan FileResult, 1)
+	go func() {
+		data, err := fm.loadFile("/data/global/ui/panel.dc6")
+		syncResult <- FileResult{Path: "/data/global/ui/panel.dc6", Data: data, Err: err}
+	}()
+
+	// Both loads miss the cache and read the file before either caches it
+	receiveString(t, archives.started)
+	receiveString(t, archives.started)
+	close(archives.release)
+
+	for _, result := range []FileResult{receiveResult(t, asyncResult), receiveResult(t, syncResult)} {
+		assert.Nil(result.Err)
+		assert.Equal([]byte("panel"), result.Data)
+	}
+	assert.Equal(2, archives.reads[`data\global\ui\panel.dc6`])
+}
+
+func TestLoadFilePrefersHdVariant(t *testing.T) {
+	assert := testify.New(t)
+	archives := createTestArchiveReader(map[string][]byte{
+		`data\global\ui\panel.dc6`:    []byte("sd panel"),
+		`hd\data\global\ui\panel.dc6`: []byte("hd panel"),
+		`data\global\ui\cursor.dc6`:   []byte("sd cursor"),
+	})
+	fm := createFileManager(d2config.Configuration{Language: "ENG"}, archives)
+
+	data, err := fm.loadFile("/data/global/ui/panel.dc6")
+	assert.Nil(err)
+	assert.Equal([]byte("sd panel"), data)
+
+	// The SD file cached without a prefix is not returned for the HD variant
+	fm.config.HdAssetPrefix = "hd"
+	data, err = fm.loadFile("/data/global/ui/panel.dc6")
+	assert.Nil(err)
+	assert.Equal([]byte("hd panel"), data)
+	data, err = fm.loadFile("/data/global/ui/panel.dc6")
+	assert.Nil(err)
+	assert.Equal([]byte("hd panel"), data)
+	assert.Equal(1, archives.reads[`hd\data\global\ui\panel.dc6`])
+
+	// Files without an HD variant fall back to the SD one
+	data, err = fm.loadFile("/data/global/ui/cursor.dc6")
+	assert.Nil(err)
+	assert.Equal([]byte("sd cursor"), data)
+
+	exists, err := fm.fileExists("/data/global/ui/cursor.dc6")
+	assert.Nil(err)
+	assert.True(exists)
+	exists, err = fm.fileExists("/data/global/ui/missing.dc6")
+	assert.Nil(err)
+	assert.False(exists)
+}
+
+func TestFileExistsFindsHdOnlyFile(t *testing.T) {
+	assert := testify.New(t)
+	archives := createTestArchiveReader(map[string][]byte{`hd\data\global\ui\hdpanel.dc6`: []byte("hd panel")})
+	fm := createFileManager(d2config.Configuration{Language: "ENG", HdAssetPrefix: "hd"}, archives)
+
+	exists, err := fm.fileExists("/data/global/ui/hdpanel.dc6")
+	assert.Nil(err)
+	assert.True(exists)
+
+	fm.config.HdAssetPrefix = ""
+	exists, err = fm.fileExists("/data/global/ui/hdpanel.dc6")
+	assert.Nil(err)
+	assert.False(exists)
+}
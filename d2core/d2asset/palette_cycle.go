@@ -0,0 +1,91 @@
+package d2asset
+
+import (
+	"errors"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// PaletteCycle rotates the colors of a range of palette indices over time (eg: the shimmer of a magic item)
+type PaletteCycle struct {
+	First          byte    // The first palette index of the range
+	Last           byte    // The last palette index of the range
+	StepsPerSecond float64 // How many times a second the colors move along by one index
+}
+
+// The state of the palette cycle of an animation
+type paletteCycleState struct {
+	cycle   PaletteCycle
+	elapsed float64
+
+	frame *animationFrame  // The frame whose recolored image is retained
+	step  int              // The step of the cycle the retained image was recolored for
+	image d2render.Surface // The frame recolored with the cycled palette
+}
+
+// Returns how far along the range the colors have moved
+func (s *paletteCycleState) currentStep() int {
+	return s.cycle.Step(s.elapsed)
+}
+
+// Step returns how far along the range the colors have moved after the elapsed time (in seconds)
+func (c PaletteCycle) Step(elapsed float64) int {
+	length := int(c.Last) - int(c.First) + 1
+	return int(elapsed*c.StepsPerSecond) % length
+}
+
+// Shift returns a copy of the palette with the colors of the cycled range moved along by the step
+func (c PaletteCycle) Shift(palette *d2dat.DATPalette, step int) *d2dat.DATPalette {
+	shifted := *palette
+	length := int(c.Last) - int(c.First) + 1
+	for i := 0; i < length; i++ {
+		shifted.Colors[int(c.First)+i] = palette.Colors[int(c.First)+(i+step)%length]
+	}
+	return &shifted
+}
+
+// SetPaletteCycle starts rotating the colors of a range of palette indices while the animation is drawn, including
+// while it is paused. The cycle belongs to this animation, so other clones of it keep their colors. Frames are
+// recolored from their palette indices each time the cycle steps, so cycles should be used sparingly.
+func (a *Animation) SetPaletteCycle(cycle PaletteCycle) error {
+	if cycle.Last <= cycle.First {
+		return errors.New("a palette cycle needs at least two palette indices")
+	}
+	if a.palette == nil {
+		return errors.New("the animation cannot be recolored")
+	}
+
+	a.paletteCycle = &paletteCycleState{cycle: cycle}
+	return nil
+}
+
+// ClearPaletteCycle stops the palette cycle of the animation, returning it to its original colors
+func (a *Animation) ClearPaletteCycle() {
+	a.paletteCycle = nil
+}
+
+// Returns the image of a frame recolored for the current step of the palette cycle, or nil when the frame is drawn
+// with its own colors
+func (a *Animation) getCycledFrameImage(frame *animationFrame) (d2render.Surface, error) {
+	state := a.paletteCycle
+	if state == nil || frame.recolor == nil {
+		return nil, nil
+	}
+
+	step := state.currentStep()
+	if step == 0 {
+		return nil, nil
+	}
+	if state.frame == frame && state.step == step {
+		return state.image, nil
+	}
+
+	image, err := frame.recolor(state.cycle.Shift(a.palette, step))
+	if err != nil {
+		return nil, err
+	}
+
+	state.frame, state.step, state.image = frame, step, image
+	return image, nil
+}
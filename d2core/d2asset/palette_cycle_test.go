@@ -0,0 +1,94 @@
+package d2asset
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// recoloredSurface is a test image recording the color of the palette index it was decoded from
+type recoloredSurface struct {
+	testSurface
+	color d2dat.DATColor
+}
+
+// createTestCycledAnimation creates a single frame animation of palette index 1, in a palette whose indices 1-3
+// are red, green and blue
+func createTestCycledAnimation() (animation *Animation, original d2render.Surface) {
+	palette := &d2dat.DATPalette{}
+	palette.Colors[1] = d2dat.DATColor{R: 255}
+	palette.Colors[2] = d2dat.DATColor{G: 255}
+	palette.Colors[3] = d2dat.DATColor{B: 255}
+
+	recolor := func(palette *d2dat.DATPalette) (d2render.Surface, error) {
+		return &recoloredSurface{color: palette.Colors[1]}, nil
+	}
+	original, _ = recolor(palette)
+	direction := &animationDirection{frames: []*animationFrame{{image: original, recolor: recolor}}}
+	animation = &Animation{directions: []*animationDirection{direction}, playLength: 1, playLoop: true, palette: palette}
+	return animation, original
+}
+
+// Returns the color of the last image rendered onto the target
+func lastRenderedColor(target *testSurface) d2dat.DATColor {
+	return target.rendered[len(target.rendered)-1].(*recoloredSurface).color
+}
+
+func TestPaletteCycleRotatesColors(t *testing.T) {
+	assert := testify.New(t)
+	animation, original := createTestCycledAnimation()
+	assert.NoError(animation.SetPaletteCycle(PaletteCycle{First: 1, Last: 3, StepsPerSecond: 2}))
+	target := &testSurface{}
+
+	// The cycle starts on the original colors
+	assert.NoError(animation.Render(target))
+	assert.Equal(original, target.rendered[0])
+
+	assert.NoError(animation.Advance(0.5))
+	assert.NoError(animation.Render(target))
+	assert.Equal(d2dat.DATColor{G: 255}, lastRenderedColor(target))
+
+	assert.NoError(animation.Advance(0.5))
+	assert.NoError(animation.Render(target))
+	assert.Equal(d2dat.DATColor{B: 255}, lastRenderedColor(target))
+
+	// The recolored image is reused until the cycle steps again
+	assert.NoError(animation.Render(target))
+	assert.Same(target.rendered[2], target.rendered[3])
+
+	assert.NoError(animation.Advance(0.5))
+	assert.NoError(animation.Render(target))
+	assert.Equal(original, target.rendered[4])
+
+	animation.ClearPaletteCycle()
+	assert.NoError(animation.Advance(0.5))
+	assert.NoError(animation.Render(target))
+	assert.Equal(original, target.rendered[5])
+}
+
+func TestPaletteCycleOnlyAffectsItsAnimation(t *testing.T) {
+	assert := testify.New(t)
+	animation, original := createTestCycledAnimation()
+	shimmering := animation.Clone()
+	assert.NoError(shimmering.SetPaletteCycle(PaletteCycle{First: 1, Last: 3, StepsPerSecond: 1}))
+	assert.NotEqual(animation.FrameKey(), shimmering.FrameKey())
+
+	assert.NoError(animation.Advance(1))
+	assert.NoError(shimmering.Advance(1))
+	target := &testSurface{}
+	assert.NoError(animation.Render(target))
+	assert.NoError(shimmering.Render(target))
+
+	assert.Equal(original, target.rendered[0])
+	assert.Equal(d2dat.DATColor{G: 255}, lastRenderedColor(target))
+}
+
+func TestPaletteCycleNeedsARange(t *testing.T) {
+	assert := testify.New(t)
+	animation, _ := createTestCycledAnimation()
+	assert.Error(animation.SetPaletteCycle(PaletteCycle{First: 3, Last: 3, StepsPerSecond: 1}))
+	assert.Error((&Animation{}).SetPaletteCycle(PaletteCycle{First: 1, Last: 3, StepsPerSecond: 1}))
+}
@@ -27,7 +27,7 @@ func (pm *paletteManager) loadPalette(palettePath string) (*d2dat.DATPalette, er
 		return nil, err
 	}
 
-	palette, err := d2dat.LoadDAT(paletteData)
+	palette, err := decodePalette(palettePath, paletteData)
 	if err != nil {
 		return nil, err
 	}
@@ -35,3 +35,12 @@ func (pm *paletteManager) loadPalette(palettePath string) (*d2dat.DATPalette, er
 	pm.cache.Insert(palettePath, palette, 1)
 	return palette, nil
 }
+
+func decodePalette(palettePath string, data []byte) (*d2dat.DATPalette, error) {
+	palette, err := d2dat.LoadDAT(data)
+	if err != nil {
+		return nil, &ErrDecode{Path: palettePath, Err: err}
+	}
+
+	return palette, nil
+}
@@ -14,6 +14,7 @@ type Configuration struct {
 	VsyncEnabled    bool
 	MpqPath         string
 	MpqLoadOrder    []string
+	HdAssetPrefix   string // Path prefix of the HD variants of assets, preferred over the SD ones when present
 	SfxVolume       float64
 	BgmVolume       float64
 }
@@ -0,0 +1,33 @@
+package d2mapengine
+
+// Sets the height a tile is raised above the ground plane, in pixels (eg: for bridges and elevated walkways). The
+// renderer draws raised tiles, and the entities standing on them, that far up the screen and above the ground level.
+// An elevation of 0 returns the tile to the ground.
+func (m *MapEngine) SetTileElevation(tileX, tileY, elevation int) {
+	if tileX < 0 || tileX >= m.size.Width || tileY < 0 || tileY >= m.size.Height {
+		return
+	}
+
+	index := tileX + (tileY * m.size.Width)
+	if elevation == 0 {
+		delete(m.elevations, index)
+		return
+	}
+	if m.elevations == nil {
+		m.elevations = make(map[int]int)
+	}
+	m.elevations[index] = elevation
+}
+
+// Returns the height a tile is raised above the ground plane, in pixels (0 for tiles on the ground and outside the map)
+func (m *MapEngine) TileElevation(tileX, tileY int) int {
+	if tileX < 0 || tileX >= m.size.Width || tileY < 0 || tileY >= m.size.Height {
+		return 0
+	}
+	return m.elevations[tileX+(tileY*m.size.Width)]
+}
+
+// Returns true if any tile of the map is raised above the ground plane
+func (m *MapEngine) HasElevatedTiles() bool {
+	return len(m.elevations) > 0
+}
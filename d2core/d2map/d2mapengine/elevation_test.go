@@ -0,0 +1,30 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestTileElevation(t *testing.T) {
+	assert := testify.New(t)
+	engine := CreateMapEngine()
+	engine.ResetMapTiles(4, 4)
+	assert.False(engine.HasElevatedTiles())
+
+	engine.SetTileElevation(2, 1, 48)
+	engine.SetTileElevation(5, 1, 48)
+	assert.Equal(48, engine.TileElevation(2, 1))
+	assert.Equal(0, engine.TileElevation(1, 2))
+	assert.Equal(0, engine.TileElevation(5, 1))
+	assert.Equal(0, engine.TileElevation(-1, 0))
+	assert.True(engine.HasElevatedTiles())
+
+	engine.SetTileElevation(2, 1, 0)
+	assert.Equal(0, engine.TileElevation(2, 1))
+	assert.False(engine.HasElevatedTiles())
+
+	engine.SetTileElevation(3, 3, 16)
+	engine.ResetMapTiles(4, 4)
+	assert.False(engine.HasElevatedTiles(), "resetting the map lowers every tile")
+}
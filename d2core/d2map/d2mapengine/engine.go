@@ -28,6 +28,21 @@ type MapEngine struct {
 	walkMesh      []d2common.PathTile        // The walk mesh
 	startSubTileX int                        // The starting X position
 	startSubTileY int                        // The starting Y position
+	tickLength    float64                    // The length of an entity update tick, in seconds (0=update on every advance)
+	tickTime      float64                    // The time accumulated towards the next entity update tick
+	triggers      []*TriggerRegion           // The regions that fire callbacks as the focus moves across them
+	lights        []*d2mapentity.Light       // The light sources that are not attached to an entity
+	regions       []placedRegion             // The areas covered by each placed stamp or DS1, in placement order
+	buckets       entityBuckets              // The entities standing on each tile
+	entityTiles   entityTiles                // The tile of the bucket each entity is in
+	timedTiles    []*TimedTile               // The tiles that switch between two states on an interval
+	tileChanged   []*func(tileX, tileY int)  // Called with the position of each tile replaced after the map was built
+	pathBudget    int                        // The most sub tiles FindPath expands before giving up (0=unlimited)
+	paused        bool                       // Whether entities are left as they are when the map advances
+	warps         []*Warp                    // The tiles that lead to other levels
+	elevations    map[int]int                // The height of each raised tile above the ground, in pixels, by index
+	samples       entitySamples              // The locations of each entity after its recent ticks
+	sampleLimit   int                        // The number of recent locations kept for each entity
 }
 
 // Creates a new instance of the map engine
@@ -46,12 +61,8 @@ func (m *MapEngine) GetStartingPosition() (int, int) {
 }
 
 func (m *MapEngine) ResetMap(levelType d2enum.RegionIdType, width, height int) {
-	m.entities = make([]d2mapentity.MapEntity, 0)
+	m.ResetMapTiles(width, height)
 	m.levelType = d2datadict.LevelTypes[levelType]
-	m.size = d2common.Size{Width: width, Height: height}
-	m.tiles = make([]d2ds1.TileRecord, width*height)
-	m.dt1TileData = make([]d2dt1.Tile, 0)
-	m.walkMesh = make([]d2common.PathTile, width*height*25)
 
 	for _, dtFileName := range m.levelType.Files {
 		if len(dtFileName) == 0 || dtFileName == "0" {
@@ -66,6 +77,21 @@ func (m *MapEngine) ResetMap(levelType d2enum.RegionIdType, width, height int) {
 	}
 }
 
+// Clears the map to an empty grid of the specified size, without loading any tile data
+func (m *MapEngine) ResetMapTiles(width, height int) {
+	m.entities = make([]d2mapentity.MapEntity, 0)
+	m.buckets = make(entityBuckets)
+	m.entityTiles = make(entityTiles)
+	m.samples = nil
+	m.size = d2common.Size{Width: width, Height: height}
+	m.tiles = make([]d2ds1.TileRecord, width*height)
+	m.dt1TileData = make([]d2dt1.Tile, 0)
+	m.walkMesh = make([]d2common.PathTile, width*height*25)
+	m.regions = nil
+	m.timedTiles = nil
+	m.elevations = nil
+}
+
 func (m *MapEngine) FindTile(style, sequence, tileType int32) d2dt1.Tile {
 	for _, tile := range m.dt1TileData {
 		if tile.Style == style && tile.Sequence == sequence && tile.Type == tileType {
@@ -106,13 +132,17 @@ func (m *MapEngine) PlaceStamp(stamp *d2mapstamp.Stamp, tileOffsetX, tileOffsetY
 	// Copy over the map tile data
 	for y := 0; y < stampSize.Height; y++ {
 		for x := 0; x < stampSize.Width; x++ {
-			mapTileIdx := x + tileOffsetX + ((y + tileOffsetY) * stampSize.Width)
+			mapTileIdx := x + tileOffsetX + ((y + tileOffsetY) * m.size.Width)
 			m.tiles[mapTileIdx] = *stamp.Tile(x, y)
 		}
 	}
 
+	m.addPlacedRegion(stamp.RegionType(), tileOffsetX, tileOffsetY, stampSize.Width, stampSize.Height)
+
 	// Copy over the entities
-	m.entities = append(m.entities, stamp.Entities()...)
+	for _, entity := range stamp.Entities() {
+		m.AddEntity(entity)
+	}
 }
 
 // Returns a reference to a map tile based on the specified tile X and Y coordinate
@@ -124,6 +154,30 @@ func (m *MapEngine) TileAt(tileX, tileY int) *d2ds1.TileRecord {
 	return &m.tiles[idx]
 }
 
+// Returns the tiles in a rectangle (inclusive), indexed by their offset from the top left corner as [y][x]. Cells
+// outside of the map are nil. The tiles are references, so changes to them update the map.
+func (m *MapEngine) TilesInRect(minX, minY, maxX, maxY int) [][]*d2ds1.TileRecord {
+	if maxX < minX || maxY < minY {
+		return nil
+	}
+
+	result := make([][]*d2ds1.TileRecord, maxY-minY+1)
+	for row := range result {
+		result[row] = make([]*d2ds1.TileRecord, maxX-minX+1)
+		tileY := minY + row
+		if tileY < 0 || tileY >= m.size.Height {
+			continue
+		}
+		for column := range result[row] {
+			tileX := minX + column
+			if tileX >= 0 && tileX < m.size.Width {
+				result[row][column] = &m.tiles[tileX+tileY*m.size.Width]
+			}
+		}
+	}
+	return result
+}
+
 // Returns a reference to the map entities
 func (m *MapEngine) Entities() *[]d2mapentity.MapEntity {
 	return &m.entities
@@ -137,6 +191,8 @@ func (m *MapEngine) Seed() int64 {
 // Adds an entity to the map engine
 func (m *MapEngine) AddEntity(entity d2mapentity.MapEntity) {
 	m.entities = append(m.entities, entity)
+	m.indexEntity(entity)
+	m.trackPathFollower(entity)
 }
 
 // Removes an entity from the map engine
@@ -144,8 +200,36 @@ func (m *MapEngine) RemoveEntity(entity d2mapentity.MapEntity) {
 	if entity == nil {
 		return
 	}
-	panic("Removing entities is not currently implemented")
-	//m.entities.Remove(entity)
+
+	// A new slice is built so that entities can be removed while the entities are being advanced
+	entities := make([]d2mapentity.MapEntity, 0, len(m.entities))
+	for _, existing := range m.entities {
+		if existing != entity {
+			entities = append(entities, existing)
+		}
+	}
+	m.entities = entities
+	m.unindexEntity(entity)
+	m.untrackPathFollower(entity)
+	delete(m.samples, entity)
+}
+
+// Kills an entity, replacing it with a corpse at the same location that plays the death animation once and then holds
+// its final frame
+func (m *MapEngine) KillEntity(entity d2mapentity.MapEntity, deathAnimation d2mapentity.DeathAnimation) *d2mapentity.Corpse {
+	var x, y int
+	if locatable, ok := entity.(d2mapentity.Locatable); ok {
+		locationX, locationY := locatable.GetLocation()
+		x, y = int(locationX), int(locationY)
+	} else {
+		tileX, tileY := entity.GetPosition()
+		x, y = int(tileX*5), int(tileY*5)
+	}
+
+	corpse := d2mapentity.CreateCorpse(x, y, deathAnimation)
+	m.RemoveEntity(entity)
+	m.AddEntity(corpse)
+	return corpse
 }
 
 func (m *MapEngine) GetTiles(style, sequence, tileType int32) []d2dt1.Tile {
@@ -163,19 +247,33 @@ func (m *MapEngine) GetTiles(style, sequence, tileType int32) []d2dt1.Tile {
 	return tiles
 }
 
+// Returns the position the player starts at on the map, or the center of the map if it has no start marker
 func (m *MapEngine) GetStartPosition() (float64, float64) {
-	for tileY := 0; tileY < m.size.Height; tileY++ {
-		for tileX := 0; tileX < m.size.Width; tileX++ {
-			tile := m.tiles[tileX+(tileY*m.size.Width)]
-			for _, wall := range tile.Walls {
-				if wall.Type.Special() && wall.Style == 30 {
-					return float64(tileX) + 0.5, float64(tileY) + 0.5
-				}
-			}
+	if x, y, found := m.StartPosition(); found {
+		return x, y
+	}
+	return m.GetCenterPosition()
+}
+
+// Returns the center of the tile the player starts on (eg: the entrance or the waypoint), and whether the map has one.
+// The start is the entrance marker of the level data, or if there is none, the first other marker placed (eg: the
+// tile targeted by a waypoint object).
+func (m *MapEngine) StartPosition() (float64, float64, bool) {
+	tiles := m.SpecialTiles()
+	fallback := -1
+	for i, tile := range tiles {
+		if tile.Type == d2enum.SpecialTileEntrance {
+			return float64(tile.TileX) + 0.5, float64(tile.TileY) + 0.5, true
+		}
+		if fallback < 0 && (tile.Type == d2enum.SpecialTileExit || tile.Type == d2enum.SpecialTileObjectTarget) {
+			fallback = i
 		}
 	}
 
-	return m.GetCenterPosition()
+	if fallback < 0 {
+		return 0, 0, false
+	}
+	return float64(tiles[fallback].TileX) + 0.5, float64(tiles[fallback].TileY) + 0.5, true
 }
 
 // Returns the center of the map
@@ -183,11 +281,80 @@ func (m *MapEngine) GetCenterPosition() (float64, float64) {
 	return float64(m.size.Width) / 2.0, float64(m.size.Height) / 2.0
 }
 
-// Advances time on the map engine
+// Advances time on the map engine: the timed tiles and the entities on the map. When an entity tick rate is set,
+// entities are updated in fixed length ticks regardless of how often this is called, and the location of each entity
+// after its recent ticks is kept to draw it between ticks.
 func (m *MapEngine) Advance(tickTime float64) {
+	m.advanceTimedTiles(tickTime)
+
+	if m.paused {
+		return
+	}
+	if m.tickLength <= 0 {
+		m.advanceEntities(tickTime)
+		return
+	}
+
+	m.tickTime += tickTime
+	for m.tickTime >= m.tickLength {
+		m.tickTime -= m.tickLength
+		m.advanceEntities(m.tickLength)
+	}
+}
+
+func (m *MapEngine) advanceEntities(tickTime float64) {
 	for _, entity := range m.entities {
 		entity.Advance(tickTime)
+		if _, tracked := entity.(tileTracked); !tracked {
+			m.rebucketEntity(entity)
+		}
+		if emitter, ok := entity.(d2mapentity.LightEmitter); ok {
+			emitter.UpdateLight()
+		}
+	}
+	m.sampleEntities()
+
+	for _, entity := range m.entities {
+		if corpse, ok := entity.(*d2mapentity.Corpse); ok && corpse.IsDespawned() {
+			m.RemoveEntity(corpse)
+		}
+	}
+}
+
+// Pauses or resumes advancing the entities (eg: to freeze the world while a menu is open). Timed tiles keep advancing.
+func (m *MapEngine) SetEntitiesPaused(paused bool) {
+	m.paused = paused
+}
+
+// Returns true if the entities are not advanced
+func (m *MapEngine) EntitiesPaused() bool {
+	return m.paused
+}
+
+// Sets the number of entity update ticks per second, or 0 to update entities on every advance
+func (m *MapEngine) SetEntityTickRate(ticksPerSecond float64) {
+	m.tickTime = 0
+	if ticksPerSecond <= 0 {
+		m.tickLength = 0
+		return
+	}
+	m.tickLength = 1 / ticksPerSecond
+}
+
+// Returns the number of entity update ticks per second, or 0 if entities are updated on every advance
+func (m *MapEngine) GetEntityTickRate() float64 {
+	if m.tickLength <= 0 {
+		return 0
+	}
+	return 1 / m.tickLength
+}
+
+// Returns how far (0-1) the time accumulated since the last entity tick is towards the next one, for interpolation
+func (m *MapEngine) EntityTickAlpha() float64 {
+	if m.tickLength <= 0 {
+		return 0
 	}
+	return m.tickTime / m.tickLength
 }
 
 func (m *MapEngine) TileExists(tileX, tileY int) bool {
@@ -0,0 +1,164 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// testEntity is a map entity that records each advance it receives
+type testEntity struct {
+	ticks []float64
+}
+
+func (e *testEntity) Render(target d2render.Surface)           {}
+func (e *testEntity) Advance(tickTime float64)                 { e.ticks = append(e.ticks, tickTime) }
+func (e *testEntity) GetPosition() (float64, float64)          { return 0, 0 }
+func (e *testEntity) GetRenderLayer() d2enum.EntityRenderLayer { return d2enum.EntityRenderLayerNormal }
+func (e *testEntity) GetRenderScale() float64                  { return 1 }
+func (e *testEntity) IsHighlighted() bool                      { return false }
+
+func TestAdvanceWithoutTickRateUpdatesEveryAdvance(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(1, 1)
+	entity := &testEntity{}
+	engine.AddEntity(entity)
+
+	engine.Advance(0.01)
+	engine.Advance(0.5)
+
+	assert.Equal([]float64{0.01, 0.5}, entity.ticks)
+	assert.Equal(0.0, engine.GetEntityTickRate())
+}
+
+func TestEntityTickRateIndependentOfAdvanceRate(t *testing.T) {
+	assert := testify.New(t)
+
+	fast := createTestMapEngine(1, 1)
+	fastEntity := &testEntity{}
+	fast.AddEntity(fastEntity)
+	fast.SetEntityTickRate(4)
+
+	slow := createTestMapEngine(1, 1)
+	slowEntity := &testEntity{}
+	slow.AddEntity(slowEntity)
+	slow.SetEntityTickRate(4)
+
+	for i := 0; i < 16; i++ {
+		fast.Advance(0.0625)
+	}
+	slow.Advance(0.5)
+	slow.Advance(0.5)
+
+	assert.Equal([]float64{0.25, 0.25, 0.25, 0.25}, fastEntity.ticks)
+	assert.Equal(fastEntity.ticks, slowEntity.ticks)
+	assert.Equal(4.0, fast.GetEntityTickRate())
+}
+
+func TestEntityTickAlpha(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(1, 1)
+	entity := &testEntity{}
+	engine.AddEntity(entity)
+	engine.SetEntityTickRate(4)
+
+	engine.Advance(0.125)
+	assert.Empty(entity.ticks)
+	assert.Equal(0.5, engine.EntityTickAlpha())
+
+	engine.Advance(0.1875)
+	assert.Len(entity.ticks, 1)
+	assert.Equal(0.25, engine.EntityTickAlpha())
+}
+
+// testDeathAnimation is a single frame death animation
+type testDeathAnimation struct{}
+
+func (a *testDeathAnimation) Render(target d2render.Surface) error { return nil }
+func (a *testDeathAnimation) Advance(elapsed float64) error        { return nil }
+func (a *testDeathAnimation) SetPlayLoop(loop bool)                {}
+func (a *testDeathAnimation) PlayForward()                         {}
+func (a *testDeathAnimation) IsOnLastFrame() bool                  { return true }
+
+func TestKillEntityReplacesEntityWithCorpse(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(1, 1)
+	living := &testEntity{}
+	killed := &testEntity{}
+	engine.AddEntity(living)
+	engine.AddEntity(killed)
+
+	corpse := engine.KillEntity(killed, &testDeathAnimation{})
+
+	entities := *engine.Entities()
+	assert.Len(entities, 2)
+	assert.Equal(living, entities[0])
+	assert.Equal(corpse, entities[1])
+	assert.Equal(d2enum.EntityRenderLayerCorpse, corpse.GetRenderLayer())
+}
+
+func TestFadedCorpsesAreRemoved(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(1, 1)
+	killed := &testEntity{}
+	engine.AddEntity(killed)
+	corpse := engine.KillEntity(killed, &testDeathAnimation{})
+	corpse.SetFade(0, 1)
+
+	engine.Advance(0.5)
+	assert.Len(*engine.Entities(), 1)
+
+	engine.Advance(0.5)
+	assert.Empty(*engine.Entities())
+}
+
+func TestTilesInRect(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(3, 2)
+
+	block := engine.TilesInRect(1, 0, 2, 1)
+	assert.Len(block, 2)
+	for row := range block {
+		assert.Len(block[row], 2)
+		for column := range block[row] {
+			assert.Same(engine.TileAt(1+column, row), block[row][column])
+		}
+	}
+
+	// Changes made through the block update the map
+	block[1][0].Walls = append(block[1][0].Walls, d2ds1.WallRecord{Style: 7})
+	assert.Equal(byte(7), engine.TileAt(1, 1).Walls[0].Style)
+}
+
+func TestTilesInRectOutsideMap(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(3, 2)
+
+	// The rectangle hangs over the left, right and bottom edges of the map
+	block := engine.TilesInRect(-1, 1, 3, 2)
+	assert.Len(block, 2)
+	assert.Equal([]*d2ds1.TileRecord{nil, engine.TileAt(0, 1), engine.TileAt(1, 1), engine.TileAt(2, 1), nil}, block[0])
+	assert.Equal(make([]*d2ds1.TileRecord, 5), block[1])
+
+	assert.Nil(engine.TilesInRect(2, 0, 1, 0))
+}
+
+func TestPausedEntitiesAreNotAdvanced(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(1, 1)
+	entity := &testEntity{}
+	engine.AddEntity(entity)
+
+	engine.SetEntitiesPaused(true)
+	assert.True(engine.EntitiesPaused())
+	engine.Advance(0.5)
+	assert.Empty(entity.ticks)
+
+	engine.SetEntitiesPaused(false)
+	engine.Advance(0.5)
+	assert.Equal([]float64{0.5}, entity.ticks)
+}
@@ -0,0 +1,93 @@
+package d2mapengine
+
+import (
+	"image"
+	"math"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+// The entities standing on each tile, by tile position
+type entityBuckets map[image.Point][]d2mapentity.MapEntity
+
+// The tile of the bucket each entity is in, which is where it was when it was last bucketed
+type entityTiles map[d2mapentity.MapEntity]image.Point
+
+// tileTracked is implemented by entities that can move, and report when they move onto another tile
+type tileTracked interface {
+	OnTileChanged(listener func(oldTileX, oldTileY int))
+}
+
+// Returns the entities standing on the specified tile. Each entity is in the bucket of the one tile its position lies
+// within, even when its sprite straddles the boundary into the neighbouring tiles.
+func (m *MapEngine) EntitiesAt(tileX, tileY int) []d2mapentity.MapEntity {
+	return m.buckets[image.Point{X: tileX, Y: tileY}]
+}
+
+// Returns the tile an entity is standing on
+func entityTile(entity d2mapentity.MapEntity) image.Point {
+	x, y := entity.GetPosition()
+	return image.Point{X: int(math.Floor(x)), Y: int(math.Floor(y))}
+}
+
+// Adds an entity to the bucket of the tile it is standing on, and keeps it in the right bucket as it moves
+func (m *MapEngine) indexEntity(entity d2mapentity.MapEntity) {
+	m.rebucketEntity(entity)
+
+	if tracked, ok := entity.(tileTracked); ok {
+		tracked.OnTileChanged(func(oldTileX, oldTileY int) {
+			m.rebucketEntity(entity)
+		})
+	}
+}
+
+// Removes an entity from its bucket, and stops tracking its movement
+func (m *MapEngine) unindexEntity(entity d2mapentity.MapEntity) {
+	if tracked, ok := entity.(tileTracked); ok {
+		tracked.OnTileChanged(nil)
+	}
+
+	if tile, found := m.entityTiles[entity]; found {
+		m.removeFromBucket(entity, tile)
+		delete(m.entityTiles, entity)
+	}
+}
+
+// Moves an entity into the bucket of the tile it is standing on, if it is not already there. Entities that do not
+// report their movement are rebucketed after every update, so they may briefly be listed under their previous tile.
+func (m *MapEngine) rebucketEntity(entity d2mapentity.MapEntity) {
+	if m.buckets == nil {
+		m.buckets = make(entityBuckets)
+	}
+	if m.entityTiles == nil {
+		m.entityTiles = make(entityTiles)
+	}
+
+	tile := entityTile(entity)
+	oldTile, found := m.entityTiles[entity]
+	if found && oldTile == tile {
+		return
+	}
+	if found {
+		m.removeFromBucket(entity, oldTile)
+	}
+
+	m.entityTiles[entity] = tile
+	m.buckets[tile] = append(m.buckets[tile], entity)
+}
+
+func (m *MapEngine) removeFromBucket(entity d2mapentity.MapEntity, tile image.Point) {
+	bucket := m.buckets[tile]
+	entities := make([]d2mapentity.MapEntity, 0, len(bucket))
+	for _, existing := range bucket {
+		if existing != entity {
+			entities = append(entities, existing)
+		}
+	}
+
+	if len(entities) == 0 {
+		delete(m.buckets, tile)
+		return
+	}
+	m.buckets[tile] = entities
+}
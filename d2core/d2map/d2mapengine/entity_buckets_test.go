@@ -0,0 +1,112 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+func TestEntitiesAtReturnsEntitiesOnTile(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 4)
+	first := d2mapentity.CreateMultiPartObject(2, 2, nil)
+	second := d2mapentity.CreateMultiPartObject(4, 1, nil)
+	other := d2mapentity.CreateMultiPartObject(12, 7, nil)
+	engine.AddEntity(first)
+	engine.AddEntity(second)
+	engine.AddEntity(other)
+
+	assert.Equal([]d2mapentity.MapEntity{first, second}, engine.EntitiesAt(0, 0))
+	assert.Equal([]d2mapentity.MapEntity{other}, engine.EntitiesAt(2, 1))
+	assert.Empty(engine.EntitiesAt(3, 3))
+}
+
+func TestSetPositionMovesEntityBetweenBuckets(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 4)
+	moving := d2mapentity.CreateMultiPartObject(2, 2, nil)
+	staying := d2mapentity.CreateMultiPartObject(3, 3, nil)
+	engine.AddEntity(moving)
+	engine.AddEntity(staying)
+
+	// Moving within the tile keeps the entity in its bucket
+	moving.SetPosition(4, 4)
+	assert.Equal([]d2mapentity.MapEntity{moving, staying}, engine.EntitiesAt(0, 0))
+
+	moving.SetPosition(7, 2)
+	assert.Equal([]d2mapentity.MapEntity{staying}, engine.EntitiesAt(0, 0))
+	assert.Equal([]d2mapentity.MapEntity{moving}, engine.EntitiesAt(1, 0))
+}
+
+func TestWalkingEntityMovesBetweenBuckets(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 4)
+	walker := d2mapentity.CreateMultiPartObject(2, 2, nil)
+	engine.AddEntity(walker)
+
+	walker.SetTarget(2, 12, nil)
+	walker.Step(1)
+	assert.Equal([]d2mapentity.MapEntity{walker}, engine.EntitiesAt(0, 1))
+	assert.Empty(engine.EntitiesAt(0, 0))
+
+	walker.Step(1)
+	assert.Equal([]d2mapentity.MapEntity{walker}, engine.EntitiesAt(0, 2))
+	assert.Empty(engine.EntitiesAt(0, 1))
+}
+
+func TestRemovedEntityLeavesBuckets(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 4)
+	entity := d2mapentity.CreateMultiPartObject(2, 2, nil)
+	engine.AddEntity(entity)
+
+	engine.RemoveEntity(entity)
+	assert.Empty(engine.EntitiesAt(0, 0))
+
+	// A removed entity is no longer tracked as it moves
+	entity.SetPosition(7, 7)
+	assert.Empty(engine.EntitiesAt(1, 1))
+}
+
+func TestEntityStraddlingTilesIsInOneBucket(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 4)
+	entity := d2mapentity.CreateMultiPartObject(2, 2, nil)
+	engine.AddEntity(entity)
+
+	entity.SetPosition(4.9, 4.9)
+	assert.Equal([]d2mapentity.MapEntity{entity}, engine.EntitiesAt(0, 0))
+
+	entity.SetPosition(5, 4.9)
+	assert.Equal([]d2mapentity.MapEntity{entity}, engine.EntitiesAt(1, 0))
+	assert.Empty(engine.EntitiesAt(0, 0))
+	assert.Empty(engine.EntitiesAt(1, 1))
+	assert.Len(engine.buckets, 1)
+}
+
+// driftingEntity moves one tile along the X axis every update, without reporting its tile changes
+type driftingEntity struct {
+	testEntity
+	x, y float64
+}
+
+func (e *driftingEntity) Advance(tickTime float64)        { e.x++ }
+func (e *driftingEntity) GetPosition() (float64, float64) { return e.x, e.y }
+
+func TestUntrackedEntityIsRebucketedOnAdvance(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 4)
+	entity := &driftingEntity{x: 0.5, y: 2.5}
+	engine.AddEntity(entity)
+	assert.Equal([]d2mapentity.MapEntity{entity}, engine.EntitiesAt(0, 2))
+
+	engine.Advance(0.1)
+	assert.Empty(engine.EntitiesAt(0, 2))
+	assert.Equal([]d2mapentity.MapEntity{entity}, engine.EntitiesAt(1, 2))
+
+	engine.RemoveEntity(entity)
+	assert.Empty(engine.buckets)
+	assert.Empty(engine.entityTiles)
+}
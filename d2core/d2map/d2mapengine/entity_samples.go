@@ -0,0 +1,83 @@
+package d2mapengine
+
+import (
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+// The number of locations kept for each entity by default: its locations after the last two ticks, to interpolate
+// between
+const defaultEntitySampleLimit = 2
+
+// entitySamples are the sub tile locations of the entities after each of their recent ticks, most recent first
+type entitySamples map[d2mapentity.MapEntity][][2]float64
+
+// Returns the sub tile location of an entity, or the location of its tile if it does not report one
+func entityLocation(entity d2mapentity.MapEntity) (float64, float64) {
+	if locatable, ok := entity.(d2mapentity.Locatable); ok {
+		return locatable.GetLocation()
+	}
+	x, y := entity.GetPosition()
+	return x * 5, y * 5
+}
+
+// Sets how many of the recent locations of each entity are kept (eg: to draw a motion trail). At least the locations
+// after the last two ticks are kept, to interpolate between.
+func (m *MapEngine) SetEntitySampleLimit(limit int) {
+	if limit < defaultEntitySampleLimit {
+		limit = defaultEntitySampleLimit
+	}
+	m.sampleLimit = limit
+
+	for entity, samples := range m.samples {
+		if len(samples) > limit {
+			m.samples[entity] = samples[:limit]
+		}
+	}
+}
+
+// Returns how many of the recent locations of each entity are kept
+func (m *MapEngine) GetEntitySampleLimit() int {
+	if m.sampleLimit < defaultEntitySampleLimit {
+		return defaultEntitySampleLimit
+	}
+	return m.sampleLimit
+}
+
+// Returns the sub tile locations of an entity after each of its recent ticks, most recent first
+func (m *MapEngine) EntitySamples(entity d2mapentity.MapEntity) [][2]float64 {
+	return m.samples[entity]
+}
+
+// Returns the sub tile location to draw an entity at. When the entities are updated at a tick rate, this is between
+// its locations after the last two ticks, by how far the time is towards the next tick, so that it moves smoothly
+// however often it is drawn. Otherwise it is the location of the entity.
+func (m *MapEngine) InterpolatedLocation(entity d2mapentity.MapEntity) (float64, float64) {
+	x, y := entityLocation(entity)
+	samples := m.samples[entity]
+	if m.tickLength <= 0 || len(samples) < 2 {
+		return x, y
+	}
+
+	// The entity is drawn behind its location by the part of the last tick's movement that is still to be shown
+	remaining := 1 - m.EntityTickAlpha()
+	return x + (samples[1][0]-samples[0][0])*remaining, y + (samples[1][1]-samples[0][1])*remaining
+}
+
+// Records the location of each entity after a tick, dropping the oldest locations past the limit
+func (m *MapEngine) sampleEntities() {
+	if m.samples == nil {
+		m.samples = make(entitySamples)
+	}
+
+	limit := m.GetEntitySampleLimit()
+	for _, entity := range m.entities {
+		samples := m.samples[entity]
+		if len(samples) < limit {
+			samples = append(samples, [2]float64{})
+		}
+		copy(samples[1:], samples)
+		x, y := entityLocation(entity)
+		samples[0] = [2]float64{x, y}
+		m.samples[entity] = samples
+	}
+}
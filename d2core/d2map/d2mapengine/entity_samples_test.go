@@ -0,0 +1,75 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+// movingEntity is a map entity that moves one sub tile along the x axis on each tick
+type movingEntity struct {
+	testEntity
+	locationX float64
+}
+
+func (e *movingEntity) Advance(tickTime float64) {
+	e.testEntity.Advance(tickTime)
+	e.locationX++
+}
+
+func (e *movingEntity) GetLocation() (float64, float64) { return e.locationX, 0 }
+
+func TestEntitySamplesKeepRecentTickLocations(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(1, 1)
+	entity := &movingEntity{}
+	engine.AddEntity(entity)
+	engine.SetEntityTickRate(10)
+	engine.SetEntitySampleLimit(3)
+
+	// Advancing between ticks does not record a location
+	for i := 0; i < 9; i++ {
+		engine.Advance(0.05)
+	}
+	assert.Equal([][2]float64{{4, 0}, {3, 0}, {2, 0}}, engine.EntitySamples(entity))
+
+	engine.SetEntitySampleLimit(0)
+	assert.Equal(2, engine.GetEntitySampleLimit())
+	assert.Equal([][2]float64{{4, 0}, {3, 0}}, engine.EntitySamples(entity))
+
+	engine.RemoveEntity(entity)
+	assert.Empty(engine.EntitySamples(entity))
+}
+
+func TestInterpolatedLocationBetweenTicks(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(1, 1)
+	entity := &movingEntity{}
+	engine.AddEntity(entity)
+	engine.SetEntityTickRate(4)
+
+	engine.Advance(0.25)
+	engine.Advance(0.25)
+	x, _ := engine.InterpolatedLocation(entity)
+	assert.Equal(1.0, x)
+
+	// The entity is drawn part way from its location after the previous tick towards its location after the last one
+	engine.Advance(0.0625)
+	x, _ = engine.InterpolatedLocation(entity)
+	assert.Equal(1.25, x)
+	engine.Advance(0.125)
+	x, _ = engine.InterpolatedLocation(entity)
+	assert.Equal(1.75, x)
+}
+
+func TestInterpolatedLocationWithoutTickRate(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(1, 1)
+	entity := &movingEntity{}
+	engine.AddEntity(entity)
+
+	engine.Advance(0.01)
+	engine.Advance(0.01)
+	x, y := engine.InterpolatedLocation(entity)
+	assert.Equal([]float64{2, 0}, []float64{x, y})
+}
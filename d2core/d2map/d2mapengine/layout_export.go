@@ -0,0 +1,113 @@
+package d2mapengine
+
+import (
+	"encoding/json"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+// LayoutExport is the read-only JSON description of a map produced by ExportLayout
+type LayoutExport struct {
+	Width     int                  `json:"width"`
+	Height    int                  `json:"height"`
+	LevelType int                  `json:"levelType"`
+	Seed      int64                `json:"seed"`
+	Tiles     []LayoutTileExport   `json:"tiles"`
+	Entities  []LayoutEntityExport `json:"entities"`
+}
+
+// LayoutTileExport describes the layers of a single map tile
+type LayoutTileExport struct {
+	X       int                 `json:"x"`
+	Y       int                 `json:"y"`
+	Floors  []LayoutLayerExport `json:"floors,omitempty"`
+	Walls   []LayoutLayerExport `json:"walls,omitempty"`
+	Shadows []LayoutLayerExport `json:"shadows,omitempty"`
+}
+
+// LayoutLayerExport describes a single floor, wall or shadow record
+type LayoutLayerExport struct {
+	Style       byte `json:"style"`
+	Sequence    byte `json:"sequence"`
+	Type        int  `json:"type"`
+	RandomIndex byte `json:"randomIndex"`
+	Hidden      bool `json:"hidden,omitempty"`
+}
+
+// LayoutEntityExport describes the position of an entity on the map, and the object it was created from
+type LayoutEntityExport struct {
+	X      float64             `json:"x"`
+	Y      float64             `json:"y"`
+	Object *LayoutObjectExport `json:"object,omitempty"`
+}
+
+// LayoutObjectExport identifies the placed object an entity was created from
+type LayoutObjectExport struct {
+	Act  int `json:"act"`
+	Type int `json:"type"`
+	Id   int `json:"id"`
+}
+
+// Exports the map layout (dimensions, tile layers and entities) as JSON for external tooling
+func (m *MapEngine) ExportLayout() ([]byte, error) {
+	layout := LayoutExport{
+		Width:     m.size.Width,
+		Height:    m.size.Height,
+		LevelType: m.levelType.Id,
+		Seed:      m.seed,
+		Tiles:     make([]LayoutTileExport, 0, len(m.tiles)),
+		Entities:  make([]LayoutEntityExport, 0, len(m.entities)),
+	}
+
+	for idx, tile := range m.tiles {
+		tileExport := LayoutTileExport{
+			X: idx % m.size.Width,
+			Y: idx / m.size.Width,
+		}
+
+		for _, floor := range tile.Floors {
+			tileExport.Floors = append(tileExport.Floors, LayoutLayerExport{
+				Style:       floor.Style,
+				Sequence:    floor.Sequence,
+				RandomIndex: floor.RandomIndex,
+				Hidden:      floor.Hidden,
+			})
+		}
+
+		for _, wall := range tile.Walls {
+			tileExport.Walls = append(tileExport.Walls, LayoutLayerExport{
+				Style:       wall.Style,
+				Sequence:    wall.Sequence,
+				Type:        int(wall.Type),
+				RandomIndex: wall.RandomIndex,
+				Hidden:      wall.Hidden,
+			})
+		}
+
+		for _, shadow := range tile.Shadows {
+			tileExport.Shadows = append(tileExport.Shadows, LayoutLayerExport{
+				Style:       shadow.Style,
+				Sequence:    shadow.Sequence,
+				Type:        int(d2enum.Shadow),
+				RandomIndex: shadow.RandomIndex,
+				Hidden:      shadow.Hidden,
+			})
+		}
+
+		layout.Tiles = append(layout.Tiles, tileExport)
+	}
+
+	for _, entity := range m.entities {
+		x, y := entity.GetPosition()
+		entityExport := LayoutEntityExport{X: x, Y: y}
+		if placed, ok := entity.(d2mapentity.Placed); ok {
+			if lookup := placed.GetObjectLookup(); lookup != nil {
+				entityExport.Object = &LayoutObjectExport{Act: lookup.Act, Type: int(lookup.Type), Id: lookup.Id}
+			}
+		}
+		layout.Entities = append(layout.Entities, entityExport)
+	}
+
+	return json.Marshal(layout)
+}
@@ -0,0 +1,74 @@
+package d2mapengine
+
+import (
+	"encoding/json"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2data/d2datadict"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+// createTestMapEngine creates a map engine of the given size without loading any assets
+func createTestMapEngine(width, height int) *MapEngine {
+	engine := CreateMapEngine()
+	engine.ResetMapTiles(width, height)
+	return engine
+}
+
+func TestExportLayout(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(2, 1)
+	engine.TileAt(0, 0).Floors = []d2ds1.FloorShadowRecord{{Style: 1, Sequence: 2, RandomIndex: 3}}
+	engine.TileAt(1, 0).Walls = []d2ds1.WallRecord{{Style: 4, Sequence: 5, Type: d2enum.RightWall}}
+	engine.TileAt(1, 0).Shadows = []d2ds1.FloorShadowRecord{{Style: 6, Sequence: 7}}
+
+	data, err := engine.ExportLayout()
+	assert.Nil(err)
+	assert.True(json.Valid(data))
+
+	var layout LayoutExport
+	assert.Nil(json.Unmarshal(data, &layout))
+	assert.Equal(2, layout.Width)
+	assert.Equal(1, layout.Height)
+	assert.Len(layout.Tiles, 2)
+
+	assert.Equal(0, layout.Tiles[0].X)
+	assert.Equal(LayoutLayerExport{Style: 1, Sequence: 2, RandomIndex: 3}, layout.Tiles[0].Floors[0])
+	assert.Empty(layout.Tiles[0].Walls)
+
+	assert.Equal(1, layout.Tiles[1].X)
+	assert.Equal(LayoutLayerExport{Style: 4, Sequence: 5, Type: int(d2enum.RightWall)}, layout.Tiles[1].Walls[0])
+	assert.Equal(int(d2enum.Shadow), layout.Tiles[1].Shadows[0].Type)
+	assert.Empty(layout.Entities)
+}
+
+// testPlacedEntity is a map entity created from a placed object
+type testPlacedEntity struct {
+	testEntity
+	lookup *d2datadict.ObjectLookupRecord
+}
+
+func (e *testPlacedEntity) GetObjectLookup() *d2datadict.ObjectLookupRecord { return e.lookup }
+
+func TestExportLayoutIdentifiesObjects(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(1, 1)
+	engine.AddEntity(&testPlacedEntity{
+		lookup: &d2datadict.ObjectLookupRecord{Act: 1, Type: d2datadict.ObjectTypeItem, Id: 17},
+	})
+	engine.AddEntity(&testEntity{})
+
+	data, err := engine.ExportLayout()
+	assert.Nil(err)
+
+	var layout LayoutExport
+	assert.Nil(json.Unmarshal(data, &layout))
+	if !assert.Len(layout.Entities, 2) {
+		return
+	}
+	assert.Equal(&LayoutObjectExport{Act: 1, Type: int(d2datadict.ObjectTypeItem), Id: 17}, layout.Entities[0].Object)
+	assert.Nil(layout.Entities[1].Object)
+}
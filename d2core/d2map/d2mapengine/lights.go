@@ -0,0 +1,37 @@
+package d2mapengine
+
+import (
+	"image/color"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+// Adds a light source at the specified world position, in tiles
+func (m *MapEngine) AddLight(x, y, radius float64, color color.RGBA) *d2mapentity.Light {
+	light := &d2mapentity.Light{X: x, Y: y, Radius: radius, Color: color}
+	m.lights = append(m.lights, light)
+	return light
+}
+
+// Removes a light source added with AddLight
+func (m *MapEngine) RemoveLight(light *d2mapentity.Light) {
+	lights := make([]*d2mapentity.Light, 0, len(m.lights))
+	for _, existing := range m.lights {
+		if existing != light {
+			lights = append(lights, existing)
+		}
+	}
+	m.lights = lights
+}
+
+// Returns the light sources on the map, including the lights attached to the entities on the map. Attached lights
+// are positioned on their entity as of the last advance, and disappear with the entity.
+func (m *MapEngine) Lights() []*d2mapentity.Light {
+	lights := append([]*d2mapentity.Light(nil), m.lights...)
+	for _, entity := range m.entities {
+		if emitter, ok := entity.(d2mapentity.LightEmitter); ok && emitter.GetLight() != nil {
+			lights = append(lights, emitter.GetLight())
+		}
+	}
+	return lights
+}
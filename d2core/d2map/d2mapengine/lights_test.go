@@ -0,0 +1,70 @@
+package d2mapengine
+
+import (
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+func TestAddAndRemoveLight(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(1, 1)
+
+	light := engine.AddLight(2, 3, 4, color.RGBA{R: 255, A: 255})
+	assert.Equal([]*d2mapentity.Light{light}, engine.Lights())
+	assert.Equal(2.0, light.X)
+	assert.Equal(3.0, light.Y)
+
+	engine.RemoveLight(light)
+	assert.Empty(engine.Lights())
+}
+
+func TestAttachedLightTracksEntity(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(10, 10)
+	torch := d2mapentity.CreateMultiPartObject(10, 15, nil)
+	engine.AddEntity(torch)
+
+	light := torch.AttachLight(3, color.RGBA{R: 255, G: 160, A: 255})
+	assert.Equal([]*d2mapentity.Light{light}, engine.Lights())
+	assert.Equal(2.0, light.X)
+	assert.Equal(3.0, light.Y)
+
+	torch.SetTarget(20, 15, nil)
+	torch.Step(10)
+	engine.Advance(0.1)
+
+	assert.Equal(4.0, light.X)
+	assert.Equal(3.0, light.Y)
+	assert.Equal(3.0, light.Radius)
+}
+
+func TestAttachedLightRemovedWithEntity(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(1, 1)
+	torch := d2mapentity.CreateMultiPartObject(0, 0, nil)
+	engine.AddEntity(torch)
+	torch.AttachLight(3, color.RGBA{A: 255})
+
+	engine.RemoveEntity(torch)
+	assert.Empty(engine.Lights())
+}
+
+func TestAttachedLightRemovedOnDespawn(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(1, 1)
+	killed := &testEntity{}
+	engine.AddEntity(killed)
+	corpse := engine.KillEntity(killed, &testDeathAnimation{})
+	corpse.AttachLight(1, color.RGBA{G: 255, A: 255})
+	corpse.SetFade(0, 1)
+
+	engine.Advance(0.5)
+	assert.Len(engine.Lights(), 1)
+
+	engine.Advance(0.5)
+	assert.Empty(engine.Lights())
+}
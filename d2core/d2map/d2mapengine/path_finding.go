@@ -0,0 +1,159 @@
+package d2mapengine
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+)
+
+// The cost of a diagonal step between sub tiles, relative to a straight step
+const diagonalStepCost = math.Sqrt2
+
+// The offsets of the eight neighbours of a sub tile, straight steps first
+var pathNeighbourOffsets = [8][2]int{{0, -1}, {1, 0}, {0, 1}, {-1, 0}, {-1, -1}, {1, -1}, {-1, 1}, {1, 1}}
+
+// Sets the maximum number of sub tiles FindPath expands before giving up (0=unlimited), so that a failed search on a
+// large map does not stall a frame
+func (m *MapEngine) SetPathNodeBudget(nodes int) {
+	m.pathBudget = nodes
+}
+
+// Returns the maximum number of sub tiles FindPath expands before giving up (0=unlimited)
+func (m *MapEngine) GetPathNodeBudget() int {
+	return m.pathBudget
+}
+
+// Finds the shortest walkable path between two world positions with A* over the sub tiles of the walk mesh. Returns the
+// sub tiles to walk through, excluding the start and ending with the destination, and whether a path was found. Steps
+// can be diagonal, but only when both of the sub tiles beside the step are walkable, so paths never cut corners.
+func (m *MapEngine) FindPath(startX, startY, endX, endY float64) ([]d2common.PathTile, bool) {
+	start, startFound := m.walkMeshIndexAt(startX, startY)
+	end, endFound := m.walkMeshIndexAt(endX, endY)
+	if !startFound || !endFound || !m.walkMesh[end].Walkable {
+		return nil, false
+	}
+
+	meshWidth := m.size.Width * 5
+	endSubTileX, endSubTileY := end%meshWidth, end/meshWidth
+	estimate := func(index int) float64 {
+		return octileDistance(index%meshWidth-endSubTileX, index/meshWidth-endSubTileY)
+	}
+
+	cost := map[int]float64{start: 0}
+	previous := make(map[int]int)
+	closed := make(map[int]bool)
+	open := &pathQueue{{index: start, estimate: estimate(start)}}
+	expanded := 0
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(pathQueueNode).index
+		if closed[current] {
+			continue
+		}
+		if current == end {
+			return m.pathTo(previous, start, end), true
+		}
+
+		closed[current] = true
+		expanded++
+		if m.pathBudget > 0 && expanded > m.pathBudget {
+			return nil, false
+		}
+
+		subTileX, subTileY := current%meshWidth, current/meshWidth
+		for _, offset := range pathNeighbourOffsets {
+			neighbour, walkable := m.pathStep(subTileX, subTileY, offset[0], offset[1])
+			if !walkable || closed[neighbour] {
+				continue
+			}
+
+			stepCost := 1.0
+			if offset[0] != 0 && offset[1] != 0 {
+				stepCost = diagonalStepCost
+			}
+			neighbourCost := cost[current] + stepCost
+			if existing, found := cost[neighbour]; found && existing <= neighbourCost {
+				continue
+			}
+			cost[neighbour] = neighbourCost
+			previous[neighbour] = current
+			heap.Push(open, pathQueueNode{index: neighbour, estimate: neighbourCost + estimate(neighbour)})
+		}
+	}
+
+	return nil, false
+}
+
+// Returns the walk mesh index of the sub tile at a world position, and false if the position is outside the map
+func (m *MapEngine) walkMeshIndexAt(x, y float64) (int, bool) {
+	subTileX := int(math.Floor(x * 5))
+	subTileY := int(math.Floor(y * 5))
+	meshWidth := m.size.Width * 5
+	if subTileX < 0 || subTileY < 0 || subTileX >= meshWidth || subTileY >= m.size.Height*5 {
+		return 0, false
+	}
+	return subTileX + subTileY*meshWidth, true
+}
+
+// Returns the walk mesh index of the sub tile one step away, and whether the step can be walked. A diagonal step also
+// needs both of the sub tiles beside it to be walkable.
+func (m *MapEngine) pathStep(subTileX, subTileY, offsetX, offsetY int) (int, bool) {
+	meshWidth := m.size.Width * 5
+	walkable := func(x, y int) bool {
+		if x < 0 || y < 0 || x >= meshWidth || y >= m.size.Height*5 {
+			return false
+		}
+		return m.walkMesh[x+y*meshWidth].Walkable
+	}
+
+	toX, toY := subTileX+offsetX, subTileY+offsetY
+	if !walkable(toX, toY) {
+		return 0, false
+	}
+	if offsetX != 0 && offsetY != 0 && (!walkable(toX, subTileY) || !walkable(subTileX, toY)) {
+		return 0, false
+	}
+	return toX + toY*meshWidth, true
+}
+
+// Returns the sub tiles of the path found to the end, excluding the start
+func (m *MapEngine) pathTo(previous map[int]int, start, end int) []d2common.PathTile {
+	var result []d2common.PathTile
+	for index := end; index != start; index = previous[index] {
+		result = append(result, m.walkMesh[index])
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// Returns the length of the shortest path across an open grid with diagonal steps between two sub tiles, the offset
+// apart
+func octileDistance(offsetX, offsetY int) float64 {
+	dx := math.Abs(float64(offsetX))
+	dy := math.Abs(float64(offsetY))
+	return dx + dy + (diagonalStepCost-2)*math.Min(dx, dy)
+}
+
+// pathQueueNode is a sub tile waiting to be expanded by FindPath
+type pathQueueNode struct {
+	index    int     // The walk mesh index of the sub tile
+	estimate float64 // The cost to reach the sub tile, plus the estimated cost from it to the end
+}
+
+// pathQueue is a priority queue of sub tiles, lowest estimate first (implements heap.Interface)
+type pathQueue []pathQueueNode
+
+func (q pathQueue) Len() int            { return len(q) }
+func (q pathQueue) Less(i, j int) bool  { return q[i].estimate < q[j].estimate }
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(pathQueueNode)) }
+
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
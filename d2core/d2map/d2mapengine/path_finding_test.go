@@ -0,0 +1,116 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+)
+
+// createTestOpenMap creates a map whose sub tiles are all walkable
+func createTestOpenMap(width, height int) *MapEngine {
+	engine := createTestMapEngine(width, height)
+	engine.RegenerateWalkPaths()
+	return engine
+}
+
+// blockSubTile marks a sub tile of the walk mesh as blocked
+func blockSubTile(engine *MapEngine, subTileX, subTileY int) {
+	engine.walkMesh[subTileX+subTileY*engine.size.Width*5].Walkable = false
+}
+
+// Returns the sub tile position of each waypoint
+func pathSubTiles(path []d2common.PathTile) [][2]int {
+	result := make([][2]int, 0, len(path))
+	for _, tile := range path {
+		result = append(result, [2]int{int(tile.X*5 + 0.5), int(tile.Y*5 + 0.5)})
+	}
+	return result
+}
+
+func TestFindPathStraightAndDiagonal(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestOpenMap(2, 2)
+
+	path, found := engine.FindPath(0.1, 0.1, 0.9, 0.1)
+	assert.True(found)
+	assert.Equal([][2]int{{1, 0}, {2, 0}, {3, 0}, {4, 0}}, pathSubTiles(path))
+
+	path, found = engine.FindPath(0.1, 0.1, 0.9, 0.9)
+	assert.True(found)
+	assert.Equal([][2]int{{1, 1}, {2, 2}, {3, 3}, {4, 4}}, pathSubTiles(path))
+
+	path, found = engine.FindPath(0.5, 0.5, 0.5, 0.5)
+	assert.True(found)
+	assert.Empty(path)
+}
+
+func TestFindPathDoesNotCutCorners(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestOpenMap(1, 1)
+	blockSubTile(engine, 1, 0)
+
+	path, found := engine.FindPath(0.1, 0.1, 0.3, 0.3)
+	assert.True(found)
+	assert.Equal([][2]int{{0, 1}, {1, 1}}, pathSubTiles(path))
+}
+
+func TestFindPathAroundWall(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestOpenMap(2, 2)
+	for subTileY := 0; subTileY < 9; subTileY++ {
+		blockSubTile(engine, 5, subTileY)
+	}
+
+	path, found := engine.FindPath(0.1, 0.1, 1.9, 0.1)
+	assert.True(found)
+	for _, tile := range path {
+		assert.True(tile.Walkable, "walked through the wall at %v,%v", tile.X, tile.Y)
+	}
+	assert.Equal([2]int{9, 0}, pathSubTiles(path)[len(path)-1])
+	assert.Contains(pathSubTiles(path), [2]int{5, 9})
+
+	// Closing the gap leaves no way through
+	blockSubTile(engine, 5, 9)
+	_, found = engine.FindPath(0.1, 0.1, 1.9, 0.1)
+	assert.False(found)
+}
+
+func TestFindPathToBlockedOrOutsideMap(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestOpenMap(1, 1)
+	blockSubTile(engine, 4, 4)
+
+	_, found := engine.FindPath(0.1, 0.1, 0.9, 0.9)
+	assert.False(found)
+	_, found = engine.FindPath(0.1, 0.1, 1.5, 0.5)
+	assert.False(found)
+	_, found = engine.FindPath(-0.5, 0.1, 0.5, 0.5)
+	assert.False(found)
+}
+
+func TestFindPathNodeBudget(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestOpenMap(20, 20)
+	// Wall off the far corner, so a search for it has to expand the whole map
+	for i := 0; i < 100; i++ {
+		blockSubTile(engine, 95, i)
+		blockSubTile(engine, i, 95)
+	}
+
+	engine.SetPathNodeBudget(50)
+	assert.Equal(50, engine.GetPathNodeBudget())
+	_, found := engine.FindPath(0.1, 0.1, 19.9, 19.9)
+	assert.False(found)
+
+	// A path that needs more expansions than the budget is given up on too
+	_, found = engine.FindPath(0.1, 0.1, 15.1, 0.1)
+	assert.False(found)
+	_, found = engine.FindPath(0.1, 0.1, 1.1, 0.1)
+	assert.True(found)
+
+	engine.SetPathNodeBudget(0)
+	_, found = engine.FindPath(0.1, 0.1, 15.1, 0.1)
+	assert.True(found)
+}
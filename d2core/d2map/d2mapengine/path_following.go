@@ -0,0 +1,25 @@
+package d2mapengine
+
+import (
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+// Returns true if the sub tile at the world position is on the map and can be walked on
+func (m *MapEngine) IsWalkableAt(x, y float64) bool {
+	index, found := m.walkMeshIndexAt(x, y)
+	return found && m.walkMesh[index].Walkable
+}
+
+// Lets an entity that follows paths check its remaining waypoints against the current walk mesh
+func (m *MapEngine) trackPathFollower(entity d2mapentity.MapEntity) {
+	if follower, ok := entity.(d2mapentity.PathFollower); ok {
+		follower.SetWalkableCheck(m.IsWalkableAt)
+	}
+}
+
+// Stops an entity that follows paths from checking its waypoints against this map
+func (m *MapEngine) untrackPathFollower(entity d2mapentity.MapEntity) {
+	if follower, ok := entity.(d2mapentity.PathFollower); ok {
+		follower.SetWalkableCheck(nil)
+	}
+}
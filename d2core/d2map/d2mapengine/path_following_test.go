@@ -0,0 +1,41 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+func TestIsWalkableAt(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestOpenMap(2, 2)
+	blockSubTile(engine, 7, 3)
+
+	assert.True(engine.IsWalkableAt(0, 0))
+	assert.True(engine.IsWalkableAt(1.2, 0.6))
+	assert.False(engine.IsWalkableAt(1.4, 0.6))
+	assert.False(engine.IsWalkableAt(1.5, 0.7))
+	assert.False(engine.IsWalkableAt(-0.1, 0))
+	assert.False(engine.IsWalkableAt(2, 0))
+}
+
+func TestAddedEntityPathBlockedByWalkMesh(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestOpenMap(4, 1)
+	entity := d2mapentity.CreateCorpse(0, 0, &testDeathAnimation{})
+	engine.AddEntity(entity)
+
+	path, found := engine.FindPath(0, 0, 3, 0)
+	assert.True(found)
+	entity.FollowPath(path)
+	assert.False(entity.IsPathBlocked())
+
+	blockSubTile(engine, 10, 0)
+	assert.True(entity.IsPathBlocked())
+
+	// Once removed from the map, only the flags of the waypoints found are used
+	engine.RemoveEntity(entity)
+	assert.False(entity.IsPathBlocked())
+}
@@ -0,0 +1,51 @@
+package d2mapengine
+
+import (
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+// placedRegion is the area of the map covered by a placed stamp or DS1
+type placedRegion struct {
+	rect       d2common.Rectangle  // The area covered by the segment, in tiles
+	regionType d2enum.RegionIdType // The region the segment was loaded from
+}
+
+func (m *MapEngine) addPlacedRegion(regionType d2enum.RegionIdType, tileOffsetX, tileOffsetY, width, height int) {
+	m.regions = append(m.regions, placedRegion{
+		rect:       d2common.Rectangle{Left: tileOffsetX, Top: tileOffsetY, Width: width, Height: height},
+		regionType: regionType,
+	})
+}
+
+// Places the tiles of a DS1 at the specified location, tagging them with the region they were loaded from
+func (m *MapEngine) PlaceDS1(ds1 *d2ds1.DS1, regionType d2enum.RegionIdType, tileOffsetX, tileOffsetY int) {
+	width, height := int(ds1.Width), int(ds1.Height)
+	if (tileOffsetX < 0) || (tileOffsetY < 0) || ((tileOffsetX + width) > m.size.Width) || ((tileOffsetY + height) > m.size.Height) {
+		panic("Tried placing a DS1 outside the bounds of the map")
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			tile := ds1.Tiles[y][x]
+			tile.RegionType = regionType
+			m.tiles[x+tileOffsetX+((y+tileOffsetY)*m.size.Width)] = tile
+		}
+	}
+
+	m.addPlacedRegion(regionType, tileOffsetX, tileOffsetY, width, height)
+}
+
+// Returns the region of the placed segment that covers the world position, in tiles. When segments overlap, the one
+// placed last wins. Returns false if no placed segment covers the position.
+func (m *MapEngine) RegionAtWorld(x, y float64) (d2enum.RegionIdType, bool) {
+	for i := len(m.regions) - 1; i >= 0; i-- {
+		rect := m.regions[i].rect
+		if x >= float64(rect.Left) && x < float64(rect.Right()) && y >= float64(rect.Top) && y < float64(rect.Bottom()) {
+			return m.regions[i].regionType, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,53 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+func createTestDS1(width, height int) *d2ds1.DS1 {
+	ds1 := &d2ds1.DS1{Width: int32(width), Height: int32(height)}
+	ds1.Tiles = make([][]d2ds1.TileRecord, height)
+	for y := range ds1.Tiles {
+		ds1.Tiles[y] = make([]d2ds1.TileRecord, width)
+	}
+	return ds1
+}
+
+func TestRegionAtWorldInStitchedMap(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(10, 4)
+	engine.PlaceDS1(createTestDS1(4, 4), d2enum.RegionAct1Town, 0, 0)
+	engine.PlaceDS1(createTestDS1(6, 4), d2enum.RegionAct1Wilderness, 4, 0)
+
+	region, ok := engine.RegionAtWorld(1.5, 2.5)
+	assert.True(ok)
+	assert.Equal(d2enum.RegionAct1Town, region)
+
+	region, ok = engine.RegionAtWorld(4, 0)
+	assert.True(ok)
+	assert.Equal(d2enum.RegionAct1Wilderness, region)
+
+	region, ok = engine.RegionAtWorld(9.9, 3.9)
+	assert.True(ok)
+	assert.Equal(d2enum.RegionAct1Wilderness, region)
+
+	assert.Equal(d2enum.RegionAct1Wilderness, engine.TileAt(5, 1).RegionType)
+	assert.Equal(d2enum.RegionAct1Town, engine.TileAt(3, 3).RegionType)
+}
+
+func TestRegionAtWorldOutsidePlacedSegments(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(10, 4)
+	engine.PlaceDS1(createTestDS1(4, 4), d2enum.RegionAct1Town, 0, 0)
+
+	_, ok := engine.RegionAtWorld(5, 1)
+	assert.False(ok)
+
+	_, ok = engine.RegionAtWorld(-0.5, 1)
+	assert.False(ok)
+}
@@ -0,0 +1,44 @@
+package d2mapengine
+
+import "github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+
+// SpecialTile is a special wall tile placed on the map, used by the level logic
+type SpecialTile struct {
+	TileX    int
+	TileY    int
+	Type     d2enum.SpecialTileType
+	Style    byte
+	Sequence byte
+}
+
+// Returns all of the special tiles on the map, classified by their subtype
+func (m *MapEngine) SpecialTiles() []SpecialTile {
+	var result []SpecialTile
+	for idx := range m.tiles {
+		for _, wall := range m.tiles[idx].Walls {
+			specialType, isSpecial := wall.SpecialType()
+			if !isSpecial {
+				continue
+			}
+			result = append(result, SpecialTile{
+				TileX:    idx % m.size.Width,
+				TileY:    idx / m.size.Width,
+				Type:     specialType,
+				Style:    wall.Style,
+				Sequence: wall.Sequence,
+			})
+		}
+	}
+	return result
+}
+
+// Returns the special tiles of the specified subtype
+func (m *MapEngine) SpecialTilesOfType(specialType d2enum.SpecialTileType) []SpecialTile {
+	var result []SpecialTile
+	for _, tile := range m.SpecialTiles() {
+		if tile.Type == specialType {
+			result = append(result, tile)
+		}
+	}
+	return result
+}
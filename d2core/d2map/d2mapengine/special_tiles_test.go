@@ -0,0 +1,71 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+func TestSpecialTiles(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(3, 2)
+	engine.TileAt(0, 0).Walls = []d2ds1.WallRecord{
+		{Type: d2enum.LeftWall, Style: 30, Sequence: 0},
+		{Type: d2enum.SpecialTile1, Style: 30, Sequence: 0},
+	}
+	engine.TileAt(1, 0).Walls = []d2ds1.WallRecord{{Type: d2enum.SpecialTile2, Style: 30, Sequence: 1}}
+	engine.TileAt(2, 0).Walls = []d2ds1.WallRecord{{Type: d2enum.SpecialTile1, Style: 31, Sequence: 3}}
+	engine.TileAt(0, 1).Walls = []d2ds1.WallRecord{{Type: d2enum.SpecialTile2, Style: 30, Sequence: 5}}
+	engine.TileAt(1, 1).Walls = []d2ds1.WallRecord{{Type: d2enum.SpecialTile1, Style: 8, Sequence: 2}}
+
+	assert.Equal([]SpecialTile{
+		{TileX: 0, TileY: 0, Type: d2enum.SpecialTileEntrance, Style: 30, Sequence: 0},
+		{TileX: 1, TileY: 0, Type: d2enum.SpecialTileExit, Style: 30, Sequence: 1},
+		{TileX: 2, TileY: 0, Type: d2enum.SpecialTileGroupBoundary, Style: 31, Sequence: 3},
+		{TileX: 0, TileY: 1, Type: d2enum.SpecialTileObjectTarget, Style: 30, Sequence: 5},
+		{TileX: 1, TileY: 1, Type: d2enum.SpecialTileUnknown, Style: 8, Sequence: 2},
+	}, engine.SpecialTiles())
+
+	exits := engine.SpecialTilesOfType(d2enum.SpecialTileExit)
+	assert.Len(exits, 1)
+	assert.Equal(1, exits[0].TileX)
+}
+
+func TestStartPositionIsEntrance(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 4)
+	engine.TileAt(1, 0).Walls = []d2ds1.WallRecord{{Type: d2enum.SpecialTile2, Style: 30, Sequence: 1}}
+	engine.TileAt(2, 3).Walls = []d2ds1.WallRecord{{Type: d2enum.SpecialTile1, Style: 30, Sequence: 0}}
+
+	x, y, found := engine.StartPosition()
+	assert.True(found)
+	assert.Equal(2.5, x)
+	assert.Equal(3.5, y)
+}
+
+func TestStartPositionFallsBackToOtherMarkers(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 4)
+	engine.TileAt(0, 0).Walls = []d2ds1.WallRecord{{Type: d2enum.SpecialTile1, Style: 31, Sequence: 0}}
+	engine.TileAt(3, 1).Walls = []d2ds1.WallRecord{{Type: d2enum.SpecialTile2, Style: 30, Sequence: 5}}
+
+	x, y, found := engine.StartPosition()
+	assert.True(found)
+	assert.Equal(3.5, x)
+	assert.Equal(1.5, y)
+}
+
+func TestStartPositionWithoutMarkers(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 2)
+
+	_, _, found := engine.StartPosition()
+	assert.False(found)
+
+	x, y := engine.GetStartPosition()
+	assert.Equal(2.0, x)
+	assert.Equal(1.0, y)
+}
@@ -0,0 +1,32 @@
+package d2mapengine
+
+import "github.com/OpenDiablo2/OpenDiablo2/d2common"
+
+// MapSummary describes the structure of a map without its tile art (eg: for the region list of an editor)
+type MapSummary struct {
+	Width        int // The width of the map, in tiles
+	Height       int // The height of the map, in tiles
+	FloorLayers  int // The most floor layers on a single tile
+	WallLayers   int // The most wall layers on a single tile
+	ShadowLayers int // The most shadow layers on a single tile
+	Entities     int // The number of entities on the map (eg: the NPCs and objects of the region)
+	Regions      int // The number of stamps and DS1s placed on the map
+}
+
+// Returns the size, layers and entity count of the map
+func (m *MapEngine) Summary() MapSummary {
+	summary := MapSummary{
+		Width:    m.size.Width,
+		Height:   m.size.Height,
+		Entities: len(m.entities),
+		Regions:  len(m.regions),
+	}
+
+	for i := range m.tiles {
+		summary.FloorLayers = d2common.MaxInt(summary.FloorLayers, len(m.tiles[i].Floors))
+		summary.WallLayers = d2common.MaxInt(summary.WallLayers, len(m.tiles[i].Walls))
+		summary.ShadowLayers = d2common.MaxInt(summary.ShadowLayers, len(m.tiles[i].Shadows))
+	}
+
+	return summary
+}
@@ -0,0 +1,32 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+func TestSummaryDescribesMapStructure(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(6, 4)
+	ds1 := createTestDS1(3, 4)
+	ds1.Tiles[1][2].Floors = make([]d2ds1.FloorShadowRecord, 2)
+	ds1.Tiles[3][0].Walls = make([]d2ds1.WallRecord, 3)
+	ds1.Tiles[0][0].Shadows = make([]d2ds1.FloorShadowRecord, 1)
+	engine.PlaceDS1(ds1, d2enum.RegionAct1Town, 0, 0)
+	engine.PlaceDS1(createTestDS1(3, 4), d2enum.RegionAct1Town, 3, 0)
+	engine.AddEntity(&testEntity{})
+
+	assert.Equal(MapSummary{
+		Width:        6,
+		Height:       4,
+		FloorLayers:  2,
+		WallLayers:   3,
+		ShadowLayers: 1,
+		Entities:     1,
+		Regions:      2,
+	}, engine.Summary())
+}
@@ -0,0 +1,104 @@
+package d2mapengine
+
+import (
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dt1"
+)
+
+// TileConflictKind is the kind of conflict between the records of a tile
+type TileConflictKind int
+
+const (
+	// Two walls block walking on the same sub tile
+	TileConflictStackedWalls TileConflictKind = iota
+
+	// A walkable floor is drawn on a sub tile that a wall blocks walking on
+	TileConflictFloorUnderWall
+)
+
+func (k TileConflictKind) String() string {
+	switch k {
+	case TileConflictStackedWalls:
+		return "stacked walls"
+	case TileConflictFloorUnderWall:
+		return "floor under wall"
+	}
+	return "unknown"
+}
+
+// TileConflict is a tile whose records conflict with each other, usually rendering oddly
+type TileConflict struct {
+	TileX    int              // The X position of the tile
+	TileY    int              // The Y position of the tile
+	SubTileX int              // The X position (0-4) within the tile of the first sub tile with the conflict
+	SubTileY int              // The Y position (0-4) within the tile of the first sub tile with the conflict
+	Kind     TileConflictKind // The kind of conflict
+}
+
+// Scans the tiles for conflicting records, for validating map data. Each kind of conflict is reported once per tile.
+// Hidden and empty records are ignored, as are records with no tile data. The map is not modified.
+func (m *MapEngine) FindTileConflicts() []TileConflict {
+	var result []TileConflict
+	for idx := range m.tiles {
+		for _, conflict := range m.tileConflicts(&m.tiles[idx]) {
+			conflict.TileX = idx % m.size.Width
+			conflict.TileY = idx / m.size.Width
+			result = append(result, conflict)
+		}
+	}
+	return result
+}
+
+// Returns the conflicts between the records of a tile, with positions within the tile
+func (m *MapEngine) tileConflicts(tile *d2ds1.TileRecord) []TileConflict {
+	var floors, walls []*d2dt1.Tile
+	for _, floor := range tile.Floors {
+		if floor.Hidden || floor.Prop1 == 0 {
+			continue
+		}
+		if tileData := m.GetTileData(int32(floor.Style), int32(floor.Sequence), d2enum.Floor); tileData != nil {
+			floors = append(floors, tileData)
+		}
+	}
+	for _, wall := range tile.Walls {
+		if wall.Hidden || wall.Prop1 == 0 {
+			continue
+		}
+		if tileData := m.GetTileData(int32(wall.Style), int32(wall.Sequence), wall.Type); tileData != nil {
+			walls = append(walls, tileData)
+		}
+	}
+
+	var result []TileConflict
+	found := make(map[TileConflictKind]bool)
+	report := func(kind TileConflictKind, subTileX, subTileY int) {
+		if !found[kind] {
+			found[kind] = true
+			result = append(result, TileConflict{SubTileX: subTileX, SubTileY: subTileY, Kind: kind})
+		}
+	}
+
+	for subTileY := 0; subTileY < 5; subTileY++ {
+		for subTileX := 0; subTileX < 5; subTileX++ {
+			blockingWalls := 0
+			for _, wall := range walls {
+				if wall.GetSubTileFlags(subTileX, subTileY).BlockWalk {
+					blockingWalls++
+				}
+			}
+			if blockingWalls == 0 {
+				continue
+			}
+			if blockingWalls > 1 {
+				report(TileConflictStackedWalls, subTileX, subTileY)
+			}
+			for _, floor := range floors {
+				if !floor.GetSubTileFlags(subTileX, subTileY).BlockWalk {
+					report(TileConflictFloorUnderWall, subTileX, subTileY)
+				}
+			}
+		}
+	}
+	return result
+}
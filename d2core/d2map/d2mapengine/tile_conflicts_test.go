@@ -0,0 +1,72 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dt1"
+)
+
+// createTestConflictMap creates a 3x3 map of walkable floors (style 1), with tile data for a walkable floor (style 1),
+// a floor that blocks every sub tile (style 2), and two walls (styles 3 and 4) that block the sub tile at 2,4
+func createTestConflictMap() *MapEngine {
+	engine := createTestMapEngine(3, 3)
+	solidFloor := d2dt1.Tile{Style: 2}
+	for i := range solidFloor.SubTileFlags {
+		solidFloor.SubTileFlags[i].BlockWalk = true
+	}
+	leftWall := d2dt1.Tile{Style: 3, Type: int32(d2enum.LeftWall)}
+	leftWall.GetSubTileFlags(2, 4).BlockWalk = true
+	rightWall := d2dt1.Tile{Style: 4, Type: int32(d2enum.RightWall)}
+	rightWall.GetSubTileFlags(2, 4).BlockWalk = true
+	engine.dt1TileData = []d2dt1.Tile{{Style: 1}, solidFloor, leftWall, rightWall}
+
+	for i := range engine.tiles {
+		engine.tiles[i].Floors = []d2ds1.FloorShadowRecord{{Style: 1, Prop1: 1}}
+	}
+	return engine
+}
+
+func TestFindTileConflictsOnCleanTiles(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestConflictMap()
+
+	// A wall over a floor that is solid too, and a second wall that is hidden, do not conflict
+	tile := engine.TileAt(1, 1)
+	tile.Floors[0].Style = 2
+	tile.Walls = []d2ds1.WallRecord{
+		{Type: d2enum.LeftWall, Style: 3, Prop1: 1},
+		{Type: d2enum.RightWall, Style: 4, Prop1: 1, Hidden: true},
+	}
+
+	assert.Empty(engine.FindTileConflicts())
+}
+
+func TestFindTileConflictsFlagsStackedWalls(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestConflictMap()
+	engine.TileAt(2, 1).Walls = []d2ds1.WallRecord{
+		{Type: d2enum.LeftWall, Style: 3, Prop1: 1},
+		{Type: d2enum.RightWall, Style: 4, Prop1: 1},
+	}
+
+	assert.Equal([]TileConflict{
+		{TileX: 2, TileY: 1, SubTileX: 2, SubTileY: 4, Kind: TileConflictStackedWalls},
+		{TileX: 2, TileY: 1, SubTileX: 2, SubTileY: 4, Kind: TileConflictFloorUnderWall},
+	}, engine.FindTileConflicts())
+}
+
+func TestFindTileConflictsFlagsFloorUnderWall(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestConflictMap()
+	engine.TileAt(0, 2).Walls = []d2ds1.WallRecord{{Type: d2enum.LeftWall, Style: 3, Prop1: 1}}
+
+	conflicts := engine.FindTileConflicts()
+	assert.Equal([]TileConflict{
+		{TileX: 0, TileY: 2, SubTileX: 2, SubTileY: 4, Kind: TileConflictFloorUnderWall},
+	}, conflicts)
+	assert.Equal("floor under wall", conflicts[0].Kind.String())
+}
@@ -0,0 +1,85 @@
+package d2mapengine
+
+import (
+	"image"
+	"math"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/beefsack/go-astar"
+)
+
+// Returns the tiles crossed by an entity following a path from its first node to its last, in the order they are
+// first entered. Each tile appears once, even if the path crosses it again later on.
+func (m *MapEngine) TilesAlongPath(path []astar.Pather) []image.Point {
+	var result []image.Point
+	seen := make(map[image.Point]bool)
+	visit := func(tile image.Point) {
+		if !seen[tile] {
+			seen[tile] = true
+			result = append(result, tile)
+		}
+	}
+
+	var previous *d2common.PathTile
+	for _, node := range path {
+		pathTile, ok := node.(*d2common.PathTile)
+		if !ok {
+			continue
+		}
+		if previous == nil {
+			visit(image.Point{X: int(math.Floor(pathTile.X)), Y: int(math.Floor(pathTile.Y))})
+		} else {
+			traverseTiles(previous.X, previous.Y, pathTile.X, pathTile.Y, visit)
+		}
+		previous = pathTile
+	}
+	return result
+}
+
+// Calls visit with each tile crossed by the straight line between two points, from the start tile to the end tile, using
+// a grid traversal (Amanatides & Woo). A line passing exactly through a tile corner moves diagonally, rather than
+// through one of the two tiles touching that corner.
+func traverseTiles(startX, startY, endX, endY float64, visit func(tile image.Point)) {
+	tile := image.Point{X: int(math.Floor(startX)), Y: int(math.Floor(startY))}
+	endTile := image.Point{X: int(math.Floor(endX)), Y: int(math.Floor(endY))}
+	visit(tile)
+
+	stepX, deltaX, nextX := traversalAxis(startX, endX)
+	stepY, deltaY, nextY := traversalAxis(startY, endY)
+	for tile != endTile {
+		if tile.X == endTile.X {
+			nextX = math.Inf(1)
+		}
+		if tile.Y == endTile.Y {
+			nextY = math.Inf(1)
+		}
+
+		crossesX := nextX <= nextY+traversalEpsilon
+		crossesY := nextY <= nextX+traversalEpsilon
+		if crossesX {
+			tile.X += stepX
+			nextX += deltaX
+		}
+		if crossesY {
+			tile.Y += stepY
+			nextY += deltaY
+		}
+		visit(tile)
+	}
+}
+
+// How close two tile boundary crossings must be to count as crossing a corner
+const traversalEpsilon = 1e-9
+
+// Returns the direction to step along one axis of a line, how far along the line (0 to 1) it is between tile boundaries
+// on that axis, and how far along the line the first boundary is
+func traversalAxis(start, end float64) (step int, delta, next float64) {
+	distance := end - start
+	switch {
+	case distance > 0:
+		return 1, 1 / distance, (math.Floor(start) + 1 - start) / distance
+	case distance < 0:
+		return -1, -1 / distance, (start - math.Floor(start)) / -distance
+	}
+	return 0, math.Inf(1), math.Inf(1)
+}
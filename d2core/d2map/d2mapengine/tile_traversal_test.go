@@ -0,0 +1,57 @@
+package d2mapengine
+
+import (
+	"image"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/beefsack/go-astar"
+)
+
+// createTestPath creates a path through the specified points, in world tiles
+func createTestPath(points ...[2]float64) []astar.Pather {
+	path := make([]astar.Pather, 0, len(points))
+	for _, point := range points {
+		path = append(path, &d2common.PathTile{Walkable: true, X: point[0], Y: point[1]})
+	}
+	return path
+}
+
+func TestTilesAlongDiagonalPath(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 4)
+
+	tiles := engine.TilesAlongPath(createTestPath([2]float64{0.5, 0.5}, [2]float64{2.5, 1.5}))
+	assert.Equal([]image.Point{{0, 0}, {1, 0}, {1, 1}, {2, 1}}, tiles)
+}
+
+func TestTilesAlongPathCrossesCornersDiagonally(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 4)
+
+	tiles := engine.TilesAlongPath(createTestPath([2]float64{3.5, 3.5}, [2]float64{0.5, 0.5}))
+	assert.Equal([]image.Point{{3, 3}, {2, 2}, {1, 1}, {0, 0}}, tiles)
+}
+
+func TestTilesAlongPathListsEachTileOnce(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 4)
+
+	tiles := engine.TilesAlongPath(createTestPath(
+		[2]float64{0.5, 0.5},
+		[2]float64{2.5, 0.5},
+		[2]float64{2.5, 1.5},
+		[2]float64{0.5, 1.5},
+		[2]float64{0.5, 0.2},
+	))
+	assert.Equal([]image.Point{{0, 0}, {1, 0}, {2, 0}, {2, 1}, {1, 1}, {0, 1}}, tiles)
+}
+
+func TestTilesAlongEmptyPath(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 4)
+
+	assert.Empty(engine.TilesAlongPath(nil))
+}
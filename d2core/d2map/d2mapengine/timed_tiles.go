@@ -0,0 +1,101 @@
+package d2mapengine
+
+import "github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+
+// TimedTile is a tile that switches between two states on a fixed interval as the map advances (eg: spike traps, timed
+// platforms). Each state sets both the art and the collision of the tile.
+type TimedTile struct {
+	TileX    int                 // The X position of the tile
+	TileY    int                 // The Y position of the tile
+	States   [2]d2ds1.TileRecord // The tile in each state
+	Interval float64             // The time spent in each state, in seconds
+	state    int                 // The index of the current state
+	elapsed  float64             // The time spent in the current state
+}
+
+// Returns the index (0 or 1) of the state the tile is currently in
+func (t *TimedTile) State() int {
+	return t.state
+}
+
+// Replaces a tile, updating the walk mesh under it and notifying the tile change listeners
+func (m *MapEngine) SetTile(tileX, tileY int, tile d2ds1.TileRecord) {
+	if tileX < 0 || tileX >= m.size.Width || tileY < 0 || tileY >= m.size.Height {
+		return
+	}
+
+	m.tiles[tileX+(tileY*m.size.Width)] = tile
+	m.regenerateWalkTile(tileX, tileY)
+	for _, listener := range m.tileChanged {
+		(*listener)(tileX, tileY)
+	}
+}
+
+// OnTileChanged adds a function called with the position of a tile whenever it is replaced after the map was built
+// (used by each renderer of the map to cache the new art). Returns a function that removes the listener.
+func (m *MapEngine) OnTileChanged(listener func(tileX, tileY int)) func() {
+	entry := &listener
+	m.tileChanged = append(m.tileChanged, entry)
+
+	return func() {
+		for i, existing := range m.tileChanged {
+			if existing == entry {
+				// The slice is copied, so listeners being notified while one is removed are all still called
+				m.tileChanged = append(m.tileChanged[:i:i], m.tileChanged[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Makes a tile switch between its current state and the alternate state every interval seconds, starting with the
+// current state. Returns nil if the tile is outside the map.
+func (m *MapEngine) AddTimedTile(tileX, tileY int, alternate d2ds1.TileRecord, interval float64) *TimedTile {
+	tile := m.TileAt(tileX, tileY)
+	if tile == nil {
+		return nil
+	}
+
+	timedTile := &TimedTile{
+		TileX:    tileX,
+		TileY:    tileY,
+		States:   [2]d2ds1.TileRecord{*tile, alternate},
+		Interval: interval,
+	}
+	m.timedTiles = append(m.timedTiles, timedTile)
+	return timedTile
+}
+
+// Stops a timed tile from switching, leaving it in its current state
+func (m *MapEngine) RemoveTimedTile(timedTile *TimedTile) {
+	timedTiles := make([]*TimedTile, 0, len(m.timedTiles))
+	for _, existing := range m.timedTiles {
+		if existing != timedTile {
+			timedTiles = append(timedTiles, existing)
+		}
+	}
+	m.timedTiles = timedTiles
+}
+
+// Returns the timed tiles on the map
+func (m *MapEngine) TimedTiles() []*TimedTile {
+	return m.timedTiles
+}
+
+func (m *MapEngine) advanceTimedTiles(tickTime float64) {
+	for _, timedTile := range m.timedTiles {
+		if timedTile.Interval <= 0 {
+			continue
+		}
+
+		state := timedTile.state
+		timedTile.elapsed += tickTime
+		for timedTile.elapsed >= timedTile.Interval {
+			timedTile.elapsed -= timedTile.Interval
+			timedTile.state = 1 - timedTile.state
+		}
+		if timedTile.state != state {
+			m.SetTile(timedTile.TileX, timedTile.TileY, timedTile.States[timedTile.state])
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dt1"
+)
+
+// createTestSpikeTrap creates a 3x3 map with a timed tile in the middle that switches between a walkable floor (style 1)
+// and a floor that blocks every sub tile (style 2)
+func createTestSpikeTrap(interval float64) (*MapEngine, *TimedTile) {
+	engine := createTestMapEngine(3, 3)
+	spikes := d2dt1.Tile{Style: 2}
+	for i := range spikes.SubTileFlags {
+		spikes.SubTileFlags[i].BlockWalk = true
+	}
+	engine.dt1TileData = []d2dt1.Tile{{Style: 1}, spikes}
+
+	for i := range engine.tiles {
+		engine.tiles[i].Floors = []d2ds1.FloorShadowRecord{{Style: 1, Prop1: 1}}
+	}
+	engine.RegenerateWalkPaths()
+
+	raised := d2ds1.TileRecord{Floors: []d2ds1.FloorShadowRecord{{Style: 2, Prop1: 1}}}
+	return engine, engine.AddTimedTile(1, 1, raised, interval)
+}
+
+// isTileWalkable returns true if every sub tile of a tile is walkable
+func isTileWalkable(engine *MapEngine, tileX, tileY int) bool {
+	for subTileY := tileY * 5; subTileY < (tileY+1)*5; subTileY++ {
+		for subTileX := tileX * 5; subTileX < (tileX+1)*5; subTileX++ {
+			if !engine.walkMesh[subTileX+subTileY*engine.size.Width*5].Walkable {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestTimedTileTogglesAtInterval(t *testing.T) {
+	assert := testify.New(t)
+	engine, timedTile := createTestSpikeTrap(2)
+	var changed [][2]int
+	engine.OnTileChanged(func(tileX, tileY int) {
+		changed = append(changed, [2]int{tileX, tileY})
+	})
+
+	engine.Advance(1.5)
+	assert.Equal(0, timedTile.State())
+	assert.Equal(byte(1), engine.TileAt(1, 1).Floors[0].Style)
+	assert.True(isTileWalkable(engine, 1, 1))
+	assert.Empty(changed)
+
+	engine.Advance(0.5)
+	assert.Equal(1, timedTile.State())
+	assert.Equal(byte(2), engine.TileAt(1, 1).Floors[0].Style)
+	assert.False(isTileWalkable(engine, 1, 1))
+	assert.Equal([][2]int{{1, 1}}, changed)
+
+	engine.Advance(2)
+	assert.Equal(0, timedTile.State())
+	assert.Equal(byte(1), engine.TileAt(1, 1).Floors[0].Style)
+	assert.True(isTileWalkable(engine, 1, 1))
+	assert.Len(changed, 2)
+}
+
+func TestTimedTileRelinksWalkMesh(t *testing.T) {
+	assert := testify.New(t)
+	engine, _ := createTestSpikeTrap(2)
+	width := engine.size.Width * 5
+	outside := &engine.walkMesh[4+5*width]
+	inside := &engine.walkMesh[5+5*width]
+	assert.Equal(inside, outside.Right)
+
+	engine.Advance(2)
+	assert.Nil(outside.Right)
+	assert.Nil(outside.DownRight)
+	assert.Nil(inside.Left)
+
+	engine.Advance(2)
+	assert.Equal(inside, outside.Right)
+	assert.Equal(outside, inside.Left)
+}
+
+func TestRemovedTimedTileStopsToggling(t *testing.T) {
+	assert := testify.New(t)
+	engine, timedTile := createTestSpikeTrap(2)
+
+	engine.Advance(2)
+	engine.RemoveTimedTile(timedTile)
+	engine.Advance(2)
+	assert.Equal(1, timedTile.State())
+	assert.Empty(engine.TimedTiles())
+	assert.False(isTileWalkable(engine, 1, 1))
+}
+
+func TestTileChangeListenersAreRemovedIndependently(t *testing.T) {
+	assert := testify.New(t)
+	engine, _ := createTestSpikeTrap(2)
+
+	var first, second int
+	stopFirst := engine.OnTileChanged(func(tileX, tileY int) { first++ })
+	engine.OnTileChanged(func(tileX, tileY int) { second++ })
+
+	engine.Advance(2)
+	assert.Equal(1, first)
+	assert.Equal(1, second)
+
+	stopFirst()
+	stopFirst()
+	engine.Advance(2)
+	assert.Equal(1, first)
+	assert.Equal(2, second)
+}
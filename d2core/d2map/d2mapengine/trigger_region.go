@@ -0,0 +1,61 @@
+package d2mapengine
+
+import "github.com/OpenDiablo2/OpenDiablo2/d2common"
+
+// TriggerRegion is a rectangular area of the map, in tiles, that runs callbacks when the focus (the camera or a focus
+// entity) enters or leaves it (eg: cutscene triggers, ambush spawns)
+type TriggerRegion struct {
+	Rect    d2common.Rectangle // The area of the region, in tiles
+	OnEnter func()             // Called when the focus moves into the region (may be nil)
+	OnLeave func()             // Called when the focus moves out of the region (may be nil)
+	inside  bool               // Whether the focus was inside the region at the last update
+}
+
+// Returns true if the world position lies within the region
+func (r *TriggerRegion) Contains(x, y float64) bool {
+	return x >= float64(r.Rect.Left) && x < float64(r.Rect.Right()) &&
+		y >= float64(r.Rect.Top) && y < float64(r.Rect.Bottom())
+}
+
+// Adds a trigger region to the map. The callbacks fire on the next focus update that crosses the region's boundary.
+func (m *MapEngine) AddTriggerRegion(rect d2common.Rectangle, onEnter, onLeave func()) *TriggerRegion {
+	region := &TriggerRegion{Rect: rect, OnEnter: onEnter, OnLeave: onLeave}
+	m.triggers = append(m.triggers, region)
+	return region
+}
+
+// Removes a trigger region from the map, without firing its leave callback
+func (m *MapEngine) RemoveTriggerRegion(region *TriggerRegion) {
+	regions := make([]*TriggerRegion, 0, len(m.triggers))
+	for _, existing := range m.triggers {
+		if existing != region {
+			regions = append(regions, existing)
+		}
+	}
+	m.triggers = regions
+}
+
+// Returns the trigger regions on the map
+func (m *MapEngine) TriggerRegions() []*TriggerRegion {
+	return m.triggers
+}
+
+// Sets the world position the trigger regions track, firing the enter and leave callbacks of the regions whose
+// boundary was crossed since the last update
+func (m *MapEngine) SetFocusPosition(x, y float64) {
+	// Callbacks may add or remove regions, so iterate over the regions as they were before the update
+	regions := append([]*TriggerRegion(nil), m.triggers...)
+	for _, region := range regions {
+		inside := region.Contains(x, y)
+		if inside == region.inside {
+			continue
+		}
+
+		region.inside = inside
+		if inside && region.OnEnter != nil {
+			region.OnEnter()
+		} else if !inside && region.OnLeave != nil {
+			region.OnLeave()
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+)
+
+func TestTriggerRegionFiresEnterAndLeaveOnce(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(10, 10)
+	var events []string
+	engine.AddTriggerRegion(d2common.Rectangle{Left: 4, Top: 4, Width: 2, Height: 2},
+		func() { events = append(events, "enter") },
+		func() { events = append(events, "leave") },
+	)
+
+	for x := 0.0; x <= 8; x += 0.5 {
+		engine.SetFocusPosition(x, 5)
+	}
+
+	assert.Equal([]string{"enter", "leave"}, events)
+}
+
+func TestTriggerRegionBoundaries(t *testing.T) {
+	assert := testify.New(t)
+	region := &TriggerRegion{Rect: d2common.Rectangle{Left: 4, Top: 4, Width: 2, Height: 2}}
+
+	assert.True(region.Contains(4, 4))
+	assert.True(region.Contains(5.99, 5.99))
+	assert.False(region.Contains(6, 5))
+	assert.False(region.Contains(5, 3.99))
+}
+
+func TestRemovedTriggerRegionDoesNotFire(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(10, 10)
+	entered := 0
+	region := engine.AddTriggerRegion(d2common.Rectangle{Width: 2, Height: 2}, func() { entered++ }, nil)
+
+	engine.SetFocusPosition(1, 1)
+	engine.SetFocusPosition(3, 3)
+	engine.RemoveTriggerRegion(region)
+	engine.SetFocusPosition(1, 1)
+
+	assert.Equal(1, entered)
+	assert.Empty(engine.TriggerRegions())
+}
@@ -10,35 +10,8 @@ import (
 
 func (m *MapEngine) RegenerateWalkPaths() {
 	for subTileY := 0; subTileY < m.size.Height*5; subTileY++ {
-		tileY := int(float64(subTileY) / 5.0)
 		for subTileX := 0; subTileX < m.size.Width*5; subTileX++ {
-			tileX := int(float64(subTileX) / 5.0)
-			tile := m.TileAt(tileX, tileY)
-			isBlocked := false
-			for _, floor := range tile.Floors {
-				tileData := m.GetTileData(int32(floor.Style), int32(floor.Sequence), d2enum.Floor)
-				if tileData == nil {
-					continue
-				}
-				tileSubAttributes := tileData.GetSubTileFlags(subTileX%5, subTileY%5)
-				isBlocked = isBlocked || tileSubAttributes.BlockWalk
-				if isBlocked {
-					break
-				}
-			}
-			if !isBlocked {
-				for _, wall := range tile.Walls {
-					tileData := m.GetTileData(int32(wall.Style), int32(wall.Sequence), wall.Type)
-					if tileData == nil {
-						continue
-					}
-					tileSubAttributes := tileData.GetSubTileFlags(subTileX%5, subTileY%5)
-					isBlocked = isBlocked || tileSubAttributes.BlockWalk
-					if isBlocked {
-						break
-					}
-				}
-			}
+			isBlocked := m.isSubTileBlocked(subTileX, subTileY)
 
 			index := subTileX + (subTileY * m.size.Width * 5)
 			m.walkMesh[index] = d2common.PathTile{
@@ -68,6 +41,72 @@ func (m *MapEngine) RegenerateWalkPaths() {
 	}
 }
 
+// Returns true if the floors or walls of a tile block walking on the specified sub tile
+func (m *MapEngine) isSubTileBlocked(subTileX, subTileY int) bool {
+	tile := m.TileAt(subTileX/5, subTileY/5)
+	for _, floor := range tile.Floors {
+		tileData := m.GetTileData(int32(floor.Style), int32(floor.Sequence), d2enum.Floor)
+		if tileData == nil {
+			continue
+		}
+		if !tileData.IsSubTileWalkable(subTileX%5, subTileY%5) {
+			return true
+		}
+	}
+	for _, wall := range tile.Walls {
+		tileData := m.GetTileData(int32(wall.Style), int32(wall.Sequence), wall.Type)
+		if tileData == nil {
+			continue
+		}
+		if !tileData.IsSubTileWalkable(subTileX%5, subTileY%5) {
+			return true
+		}
+	}
+	return false
+}
+
+// Regenerates the walk mesh of a single tile after it has changed, relinking its sub tiles with their neighbours
+func (m *MapEngine) regenerateWalkTile(tileX, tileY int) {
+	meshWidth := m.size.Width * 5
+	meshHeight := m.size.Height * 5
+	for subTileY := tileY * 5; subTileY < (tileY+1)*5; subTileY++ {
+		for subTileX := tileX * 5; subTileX < (tileX+1)*5; subTileX++ {
+			m.walkMesh[subTileX+subTileY*meshWidth].Walkable = !m.isSubTileBlocked(subTileX, subTileY)
+		}
+	}
+
+	// The sub tiles around the edge of the tile link into it, so they are relinked as well
+	for subTileY := tileY*5 - 1; subTileY <= (tileY+1)*5; subTileY++ {
+		for subTileX := tileX*5 - 1; subTileX <= (tileX+1)*5; subTileX++ {
+			if subTileX < 0 || subTileY < 0 || subTileX >= meshWidth || subTileY >= meshHeight {
+				continue
+			}
+			node := &m.walkMesh[subTileX+subTileY*meshWidth]
+			node.Up = m.walkLink(node, subTileX, subTileY-1)
+			node.Down = m.walkLink(node, subTileX, subTileY+1)
+			node.Left = m.walkLink(node, subTileX-1, subTileY)
+			node.Right = m.walkLink(node, subTileX+1, subTileY)
+			node.UpLeft = m.walkLink(node, subTileX-1, subTileY-1)
+			node.UpRight = m.walkLink(node, subTileX+1, subTileY-1)
+			node.DownLeft = m.walkLink(node, subTileX-1, subTileY+1)
+			node.DownRight = m.walkLink(node, subTileX+1, subTileY+1)
+		}
+	}
+}
+
+// Returns the neighbouring sub tile a walk mesh node links to, or nil if either of them is blocked
+func (m *MapEngine) walkLink(node *d2common.PathTile, subTileX, subTileY int) *d2common.PathTile {
+	meshWidth := m.size.Width * 5
+	if !node.Walkable || subTileX < 0 || subTileY < 0 || subTileX >= meshWidth || subTileY >= m.size.Height*5 {
+		return nil
+	}
+	neighbour := &m.walkMesh[subTileX+subTileY*meshWidth]
+	if !neighbour.Walkable {
+		return nil
+	}
+	return neighbour
+}
+
 // Finds a walkable path between two points
 func (m *MapEngine) PathFind(startX, startY, endX, endY float64) (path []astar.Pather, distance float64, found bool) {
 	startTileX := int(math.Floor(startX))
@@ -0,0 +1,67 @@
+package d2mapengine
+
+import "sort"
+
+// WalkableSegment is a connected area of walkable sub tiles in the walk mesh
+type WalkableSegment struct {
+	SubTileX int // The X position of the first sub tile in the segment
+	SubTileY int // The Y position of the first sub tile in the segment
+	Walkable int // The number of walkable sub tiles in the segment
+}
+
+// Returns the number of walkable sub tiles in the walk mesh, and the total number of sub tiles
+func (m *MapEngine) WalkabilityStats() (walkable, total int) {
+	for i := range m.walkMesh {
+		if m.walkMesh[i].Walkable {
+			walkable++
+		}
+	}
+	return walkable, len(m.walkMesh)
+}
+
+// Returns the connected walkable areas of the walk mesh, largest first. Sub tiles are connected to their eight
+// neighbours, matching the links made by RegenerateWalkPaths. More than one segment means part of the map cannot be
+// reached from the rest of it.
+func (m *MapEngine) WalkableSegments() []WalkableSegment {
+	meshWidth := m.size.Width * 5
+	meshHeight := m.size.Height * 5
+	visited := make([]bool, len(m.walkMesh))
+	var result []WalkableSegment
+
+	for index := range m.walkMesh {
+		if visited[index] || !m.walkMesh[index].Walkable {
+			continue
+		}
+
+		segment := WalkableSegment{SubTileX: index % meshWidth, SubTileY: index / meshWidth}
+		visited[index] = true
+		pending := []int{index}
+		for len(pending) > 0 {
+			current := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			segment.Walkable++
+
+			x, y := current%meshWidth, current/meshWidth
+			for offsetY := -1; offsetY <= 1; offsetY++ {
+				for offsetX := -1; offsetX <= 1; offsetX++ {
+					nx, ny := x+offsetX, y+offsetY
+					if nx < 0 || ny < 0 || nx >= meshWidth || ny >= meshHeight {
+						continue
+					}
+					neighbour := nx + ny*meshWidth
+					if visited[neighbour] || !m.walkMesh[neighbour].Walkable {
+						continue
+					}
+					visited[neighbour] = true
+					pending = append(pending, neighbour)
+				}
+			}
+		}
+		result = append(result, segment)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Walkable > result[j].Walkable
+	})
+	return result
+}
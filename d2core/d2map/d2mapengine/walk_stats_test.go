@@ -0,0 +1,67 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+// setTestWalkMesh marks the walk mesh sub tiles from rows of '.' (walkable) and '#' (blocked)
+func setTestWalkMesh(engine *MapEngine, rows []string) {
+	width := engine.size.Width * 5
+	for y, row := range rows {
+		for x, cell := range row {
+			engine.walkMesh[x+y*width].Walkable = cell == '.'
+		}
+	}
+}
+
+func TestWalkabilityStats(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(2, 1)
+	setTestWalkMesh(engine, []string{
+		"....#.....",
+		"....#.....",
+		"#####.....",
+		"..........",
+		"..........",
+	})
+
+	walkable, total := engine.WalkabilityStats()
+	assert.Equal(50, total)
+	assert.Equal(43, walkable)
+}
+
+func TestWalkableSegments(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(2, 1)
+	setTestWalkMesh(engine, []string{
+		"..#######.",
+		"..#.....#.",
+		"###.....##",
+		"#.#######.",
+		"###.......",
+	})
+
+	segments := engine.WalkableSegments()
+	assert.Equal([]WalkableSegment{
+		{SubTileX: 3, SubTileY: 1, Walkable: 10},
+		{SubTileX: 9, SubTileY: 3, Walkable: 8},
+		{SubTileX: 0, SubTileY: 0, Walkable: 4},
+		{SubTileX: 9, SubTileY: 0, Walkable: 2},
+		{SubTileX: 1, SubTileY: 3, Walkable: 1},
+	}, segments)
+
+	walkable, _ := engine.WalkabilityStats()
+	assert.Equal(25, walkable)
+}
+
+func TestWalkableSegmentsEmptyMesh(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(1, 1)
+
+	walkable, total := engine.WalkabilityStats()
+	assert.Equal(0, walkable)
+	assert.Equal(25, total)
+	assert.Empty(engine.WalkableSegments())
+}
@@ -0,0 +1,32 @@
+package d2mapengine
+
+// Warp is a tile of the map that takes the player to another level (eg: a cave entrance or the stairs down). The map
+// engine does not find the warps in the level data yet, so they are added by whatever places the level.
+type Warp struct {
+	TileX            int
+	TileY            int
+	DestinationLevel int // The id of the level the warp leads to (the Vis links of Levels.txt)
+}
+
+// Adds a warp on the tile, leading to the level with the id
+func (m *MapEngine) AddWarp(tileX, tileY, destinationLevel int) *Warp {
+	warp := &Warp{TileX: tileX, TileY: tileY, DestinationLevel: destinationLevel}
+	m.warps = append(m.warps, warp)
+	return warp
+}
+
+// Removes a warp from the map
+func (m *MapEngine) RemoveWarp(warp *Warp) {
+	warps := make([]*Warp, 0, len(m.warps))
+	for _, existing := range m.warps {
+		if existing != warp {
+			warps = append(warps, existing)
+		}
+	}
+	m.warps = warps
+}
+
+// Returns the warps on the map, in the order they were added
+func (m *MapEngine) Warps() []*Warp {
+	return m.warps
+}
@@ -0,0 +1,20 @@
+package d2mapengine
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestAddAndRemoveWarps(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMapEngine(4, 4)
+	first := engine.AddWarp(1, 0, 2)
+	second := engine.AddWarp(3, 2, 8)
+
+	assert.Equal([]*Warp{first, second}, engine.Warps())
+	assert.Equal(Warp{TileX: 3, TileY: 2, DestinationLevel: 8}, *second)
+
+	engine.RemoveWarp(first)
+	assert.Equal([]*Warp{second}, engine.Warps())
+}
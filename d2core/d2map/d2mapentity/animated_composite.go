@@ -33,6 +33,19 @@ func CreateAnimatedComposite(x, y int, object *d2datadict.ObjectLookupRecord, pa
 	return entity, nil
 }
 
+// GetObjectName returns the human readable name of the object this entity was created from (eg: "Zombie")
+func (ac *AnimatedComposite) GetObjectName() string {
+	if ac.objectLookup == nil {
+		return ""
+	}
+	return ac.objectLookup.Name()
+}
+
+// GetObjectLookup returns the lookup record of the object this entity was created from
+func (ac *AnimatedComposite) GetObjectLookup() *d2datadict.ObjectLookupRecord {
+	return ac.objectLookup
+}
+
 func (ac *AnimatedComposite) SetPlayer(player *Player) {
 	ac.player = player
 }
@@ -58,14 +71,22 @@ func (ac *AnimatedComposite) SetMode(animationMode, weaponClass string, directio
 
 // Render draws this animated entity onto the target
 func (ac *AnimatedComposite) Render(target d2render.Surface) {
-	target.PushTranslation(
-		ac.offsetX+int((ac.subcellX-ac.subcellY)*16),
-		ac.offsetY+int(((ac.subcellX+ac.subcellY)*8)-5),
-	)
+	target.PushTranslation(ac.GetScreenAnchor())
 	defer target.Pop()
 	ac.composite.Render(target)
 }
 
+// SetPaletteCycle rotates the colors of a range of palette indices when the entity is drawn, without affecting other
+// entities of the same type
+func (ac *AnimatedComposite) SetPaletteCycle(cycle d2asset.PaletteCycle) error {
+	return ac.composite.SetPaletteCycle(cycle)
+}
+
+// ClearPaletteCycle returns the entity to the colors of its animations
+func (ac *AnimatedComposite) ClearPaletteCycle() {
+	ac.composite.ClearPaletteCycle()
+}
+
 // rotate sets direction and changes animation
 func (ac *AnimatedComposite) rotate(angle float64) {
 	// TODO: Check if is in town and if is player.
@@ -1,6 +1,8 @@
 package d2mapentity
 
 import (
+	"image"
+
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2asset"
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
 )
@@ -27,14 +29,32 @@ func CreateAnimatedEntity(x, y int, animation *d2asset.Animation) *AnimatedEntit
 
 // Render draws this animated entity onto the target
 func (ae *AnimatedEntity) Render(target d2render.Surface) {
-	target.PushTranslation(
-		ae.offsetX+int((ae.subcellX-ae.subcellY)*16),
-		ae.offsetY+int(((ae.subcellX+ae.subcellY)*8)-5),
-	)
+	target.PushTranslation(ae.GetScreenAnchor())
 	defer target.Pop()
 	ae.animation.Render(target)
 }
 
+// GetFrameRect returns the rectangle of the frame the entity is drawing, relative to its feet
+func (ae *AnimatedEntity) GetFrameRect() image.Rectangle {
+	return ae.animation.GetCurrentFrameRect()
+}
+
+// BatchKey identifies the animation frame this entity is drawing, which is shared by clones of the same animation
+func (ae *AnimatedEntity) BatchKey() interface{} {
+	return ae.animation.FrameKey()
+}
+
+// SetPaletteCycle rotates the colors of a range of palette indices when the entity is drawn (eg: a shimmering item),
+// without affecting other entities drawn with the same animation
+func (ae *AnimatedEntity) SetPaletteCycle(cycle d2asset.PaletteCycle) error {
+	return ae.animation.SetPaletteCycle(cycle)
+}
+
+// ClearPaletteCycle returns the entity to the colors of its animation
+func (ae *AnimatedEntity) ClearPaletteCycle() {
+	ae.animation.ClearPaletteCycle()
+}
+
 func (ae AnimatedEntity) GetDirection() int {
 	return ae.direction
 }
@@ -0,0 +1,91 @@
+package d2mapentity
+
+import (
+	"image/color"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// DeathAnimation is the animation a corpse plays once when an entity dies (implemented by *d2asset.Animation)
+type DeathAnimation interface {
+	Render(target d2render.Surface) error
+	Advance(elapsed float64) error
+	SetPlayLoop(loop bool)
+	PlayForward()
+	IsOnLastFrame() bool
+}
+
+// Corpse is the remains of a dead entity. It plays the death animation once, holds the final frame and then
+// optionally fades out and despawns. Corpses are drawn beneath the living entities.
+type Corpse struct {
+	mapEntity
+	animation    DeathAnimation
+	fadeDelay    float64 // The time the final frame is held before fading, in seconds
+	fadeDuration float64 // The time taken to fade out, in seconds (0=never fade)
+	heldTime     float64 // The time since the death animation finished, in seconds
+}
+
+// CreateCorpse creates an instance of Corpse
+func CreateCorpse(x, y int, animation DeathAnimation) *Corpse {
+	animation.SetPlayLoop(false)
+	animation.PlayForward()
+
+	corpse := &Corpse{
+		mapEntity: createMapEntity(x, y),
+		animation: animation,
+	}
+	corpse.renderLayer = d2enum.EntityRenderLayerCorpse
+	return corpse
+}
+
+// SetFade makes the corpse fade out over the duration once its final frame has been held for the delay, both in
+// seconds. A duration of 0 keeps the corpse forever.
+func (c *Corpse) SetFade(delay, duration float64) {
+	c.fadeDelay = delay
+	c.fadeDuration = duration
+}
+
+// IsDeathAnimationFinished returns true when the death animation has reached its final frame
+func (c *Corpse) IsDeathAnimationFinished() bool {
+	return c.animation.IsOnLastFrame()
+}
+
+// IsDespawned returns true when the corpse has completely faded out and can be removed from the map
+func (c *Corpse) IsDespawned() bool {
+	return c.fadeDuration > 0 && c.heldTime >= c.fadeDelay+c.fadeDuration
+}
+
+// Returns the opacity of the corpse, from 1 (opaque) to 0 (faded out)
+func (c *Corpse) opacity() float64 {
+	if c.fadeDuration <= 0 || c.heldTime <= c.fadeDelay {
+		return 1
+	}
+	if c.IsDespawned() {
+		return 0
+	}
+	return 1 - (c.heldTime-c.fadeDelay)/c.fadeDuration
+}
+
+// Advance plays the death animation until the final frame, then counts towards the fade
+func (c *Corpse) Advance(elapsed float64) {
+	if !c.IsDeathAnimationFinished() {
+		c.animation.Advance(elapsed)
+		return
+	}
+	c.heldTime += elapsed
+}
+
+// Render draws the corpse onto the target, faded by its opacity
+func (c *Corpse) Render(target d2render.Surface) {
+	target.PushTranslation(c.GetScreenAnchor())
+	defer target.Pop()
+
+	if opacity := c.opacity(); opacity < 1 {
+		value := uint8(255 * opacity)
+		target.PushColor(color.RGBA{R: value, G: value, B: value, A: value})
+		defer target.Pop()
+	}
+
+	c.animation.Render(target)
+}
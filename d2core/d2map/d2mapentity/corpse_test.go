@@ -0,0 +1,91 @@
+package d2mapentity
+
+import (
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// testDeathAnimation is a death animation that advances one frame per second
+type testDeathAnimation struct {
+	frame      int
+	frameCount int
+	loop       bool
+	advanced   float64
+	rendered   []int
+}
+
+func (a *testDeathAnimation) Render(target d2render.Surface) error {
+	a.rendered = append(a.rendered, a.frame)
+	return nil
+}
+
+func (a *testDeathAnimation) Advance(elapsed float64) error {
+	a.advanced += elapsed
+	a.frame = int(a.advanced)
+	if a.loop {
+		a.frame %= a.frameCount
+	} else if a.frame >= a.frameCount {
+		a.frame = a.frameCount - 1
+	}
+	return nil
+}
+
+func (a *testDeathAnimation) SetPlayLoop(loop bool) { a.loop = loop }
+func (a *testDeathAnimation) PlayForward()          {}
+func (a *testDeathAnimation) IsOnLastFrame() bool   { return a.frame == a.frameCount-1 }
+
+func TestCorpseHoldsFinalDeathFrame(t *testing.T) {
+	assert := testify.New(t)
+	animation := &testDeathAnimation{frameCount: 4, loop: true}
+	corpse := CreateCorpse(10, 15, animation)
+
+	assert.False(animation.loop)
+	assert.Equal(d2enum.EntityRenderLayerCorpse, corpse.GetRenderLayer())
+	assert.False(corpse.IsDeathAnimationFinished())
+
+	corpse.Advance(2)
+	assert.Equal(2, animation.frame)
+	assert.False(corpse.IsDeathAnimationFinished())
+
+	corpse.Advance(1)
+	assert.True(corpse.IsDeathAnimationFinished())
+
+	corpse.Advance(10)
+	corpse.Advance(10)
+	assert.Equal(3, animation.frame)
+	assert.Equal(3.0, animation.advanced)
+	assert.False(corpse.IsDespawned())
+
+	target := &testSurface{}
+	corpse.Render(target)
+	assert.Equal([]int{3}, animation.rendered)
+	assert.Empty(target.colors)
+	assert.Empty(target.stack)
+}
+
+func TestCorpseFadesAndDespawns(t *testing.T) {
+	assert := testify.New(t)
+	animation := &testDeathAnimation{frameCount: 1}
+	corpse := CreateCorpse(0, 0, animation)
+	corpse.SetFade(1, 2)
+
+	corpse.Advance(1)
+	assert.Equal(1.0, corpse.opacity())
+
+	corpse.Advance(1)
+	assert.Equal(0.5, corpse.opacity())
+	assert.False(corpse.IsDespawned())
+
+	target := &testSurface{}
+	corpse.Render(target)
+	assert.Equal([]color.Color{color.RGBA{R: 127, G: 127, B: 127, A: 127}}, target.colors)
+	assert.Empty(target.stack)
+
+	corpse.Advance(1)
+	assert.True(corpse.IsDespawned())
+}
@@ -0,0 +1,45 @@
+package d2mapentity
+
+import "image/color"
+
+// Light is a point light source on the map (eg: a brazier, or a torch carried by an entity)
+type Light struct {
+	X      float64    // The center of the light, in world tiles
+	Y      float64    // The center of the light, in world tiles
+	Radius float64    // The radius of the light, in world tiles
+	Color  color.RGBA // The color of the light
+}
+
+// LightEmitter is implemented by entities that carry a light source which moves with them
+type LightEmitter interface {
+	GetLight() *Light
+	UpdateLight()
+}
+
+// AttachLight attaches a light source to the entity, replacing any light already attached. The light follows the
+// entity while it is on the map.
+func (m *mapEntity) AttachLight(radius float64, color color.RGBA) *Light {
+	m.light = &Light{Radius: radius, Color: color}
+	m.UpdateLight()
+	return m.light
+}
+
+// DetachLight removes the light source attached to the entity
+func (m *mapEntity) DetachLight() {
+	m.light = nil
+}
+
+// GetLight returns the light source attached to the entity, or nil if it has none
+func (m *mapEntity) GetLight() *Light {
+	return m.light
+}
+
+// UpdateLight moves the attached light source to the entity's current location
+func (m *mapEntity) UpdateLight() {
+	if m.light == nil {
+		return
+	}
+
+	m.light.X = m.locationX / 5
+	m.light.Y = m.locationY / 5
+}
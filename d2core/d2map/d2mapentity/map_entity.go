@@ -1,9 +1,12 @@
 package d2mapentity
 
 import (
+	"image"
 	"math"
 
 	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2data/d2datadict"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
 	"github.com/beefsack/go-astar"
 )
@@ -12,39 +15,80 @@ type MapEntity interface {
 	Render(target d2render.Surface)
 	Advance(tickTime float64)
 	GetPosition() (float64, float64)
+	GetRenderLayer() d2enum.EntityRenderLayer
+	GetRenderScale() float64
+	IsHighlighted() bool
+}
+
+// Batchable is implemented by entities that report the sprite frame they draw, so that the renderer can draw the
+// entities sharing a frame one after another. The key must be comparable, and equal for entities drawing the same frame.
+type Batchable interface {
+	BatchKey() interface{}
+}
+
+// Sortable is implemented by entities whose feet are offset from their location (eg: tall sprites), so that the
+// renderer sorts them by where they stand rather than by their location
+type Sortable interface {
+	GetSortOrigin() (float64, float64)
+}
+
+// Placed is implemented by entities created from an object placed on the map (eg: by a DS1 file), and reports the
+// lookup record of the object, which identifies it by its act, type and id
+type Placed interface {
+	GetObjectLookup() *d2datadict.ObjectLookupRecord
+}
+
+// Anchored is implemented by entities that are drawn from their feet rather than from the top corner of their tile, so
+// that the renderer scales them around the point they stand on and picks them where they are drawn
+type Anchored interface {
+	GetScreenAnchor() (int, int)
+}
+
+// Framed is implemented by entities that report the rectangle of the frame they draw, relative to their feet
+type Framed interface {
+	GetFrameRect() image.Rectangle
 }
 
 // mapEntity represents an entity on the map that can be animated
 type mapEntity struct {
-	LocationX          float64
-	LocationY          float64
-	TileX, TileY       int     // Coordinates of the tile the unit is within
+	locationX          float64
+	locationY          float64
+	tileX, tileY       int     // Coordinates of the tile the unit is within
 	subcellX, subcellY float64 // Subcell coordinates within the current tile
+	sortX, sortY       float64 // The offset from the location to the feet of the entity, in sub tiles
 	weaponClass        string
 	offsetX, offsetY   int
 	TargetX            float64
 	TargetY            float64
 	Speed              float64
+	Highlighted        bool // Whether the entity is drawn with a selection highlight (eg: when hovered or targeted)
 	path               []astar.Pather
+	waypoint           *d2common.PathTile // The path tile the entity is walking to (nil when not following a path)
+	renderLayer        d2enum.EntityRenderLayer
+	renderScale        float64
+	light              *Light // The light source that moves with the entity (may be nil)
 
 	done        func()
+	walkable    func(x, y float64) bool // Reports whether a world position is walkable, for IsPathBlocked (may be nil)
 	directioner func(angle float64)
+	tileChanged func(oldTileX, oldTileY int) // Called with the previous tile when the entity moves onto another tile
 }
 
 // createMapEntity creates an instance of mapEntity
 func createMapEntity(x, y int) mapEntity {
 	locX, locY := float64(x), float64(y)
 	return mapEntity{
-		LocationX: locX,
-		LocationY: locY,
-		TargetX:   locX,
-		TargetY:   locY,
-		TileX:     x / 5,
-		TileY:     y / 5,
-		subcellX:  1 + math.Mod(locX, 5),
-		subcellY:  1 + math.Mod(locY, 5),
-		Speed:     6,
-		path:      []astar.Pather{},
+		locationX:   locX,
+		locationY:   locY,
+		TargetX:     locX,
+		TargetY:     locY,
+		tileX:       x / 5,
+		tileY:       y / 5,
+		subcellX:    1 + math.Mod(locX, 5),
+		subcellY:    1 + math.Mod(locY, 5),
+		Speed:       6,
+		path:        []astar.Pather{},
+		renderScale: 1,
 	}
 }
 
@@ -57,8 +101,8 @@ func (m *mapEntity) getStepLength(tickTime float64) (float64, float64) {
 	length := tickTime * m.Speed
 
 	angle := 359 - d2common.GetAngleBetween(
-		m.LocationX,
-		m.LocationY,
+		m.locationX,
+		m.locationY,
 		m.TargetX,
 		m.TargetY,
 	)
@@ -69,7 +113,7 @@ func (m *mapEntity) getStepLength(tickTime float64) (float64, float64) {
 }
 
 func (m *mapEntity) IsAtTarget() bool {
-	return math.Abs(m.LocationX-m.TargetX) < 0.0001 && math.Abs(m.LocationY-m.TargetY) < 0.0001 && !m.HasPathFinding()
+	return math.Abs(m.locationX-m.TargetX) < 0.0001 && math.Abs(m.locationY-m.TargetY) < 0.0001 && !m.HasPathFinding()
 }
 
 func (m *mapEntity) Step(tickTime float64) {
@@ -83,23 +127,21 @@ func (m *mapEntity) Step(tickTime float64) {
 
 	stepX, stepY := m.getStepLength(tickTime)
 	for {
-		if d2common.AlmostEqual(m.LocationX-m.TargetX, 0, 0.0001) {
+		if d2common.AlmostEqual(m.locationX-m.TargetX, 0, 0.0001) {
 			stepX = 0
 		}
-		if d2common.AlmostEqual(m.LocationY-m.TargetY, 0, 0.0001) {
+		if d2common.AlmostEqual(m.locationY-m.TargetY, 0, 0.0001) {
 			stepY = 0
 		}
-		m.LocationX, stepX = d2common.AdjustWithRemainder(m.LocationX, stepX, m.TargetX)
-		m.LocationY, stepY = d2common.AdjustWithRemainder(m.LocationY, stepY, m.TargetY)
+		var locationX, locationY float64
+		locationX, stepX = d2common.AdjustWithRemainder(m.locationX, stepX, m.TargetX)
+		locationY, stepY = d2common.AdjustWithRemainder(m.locationY, stepY, m.TargetY)
+		m.setLocation(locationX, locationY)
 
-		m.subcellX = 1 + math.Mod(m.LocationX, 5)
-		m.subcellY = 1 + math.Mod(m.LocationY, 5)
-		m.TileX = int(m.LocationX / 5)
-		m.TileY = int(m.LocationY / 5)
-
-		if d2common.AlmostEqual(m.LocationX, m.TargetX, 0.01) && d2common.AlmostEqual(m.LocationY, m.TargetY, 0.01) {
+		if d2common.AlmostEqual(m.locationX, m.TargetX, 0.01) && d2common.AlmostEqual(m.locationY, m.TargetY, 0.01) {
 			if len(m.path) > 0 {
-				m.SetTarget(m.path[0].(*d2common.PathTile).X*5, m.path[0].(*d2common.PathTile).Y*5, m.done)
+				m.waypoint = m.path[0].(*d2common.PathTile)
+				m.SetTarget(m.waypoint.X*5, m.waypoint.Y*5, m.done)
 
 				if len(m.path) > 1 {
 					m.path = m.path[1:]
@@ -107,12 +149,8 @@ func (m *mapEntity) Step(tickTime float64) {
 					m.path = []astar.Pather{}
 				}
 			} else {
-				m.LocationX = m.TargetX
-				m.LocationY = m.TargetY
-				m.subcellX = 1 + math.Mod(m.LocationX, 5)
-				m.subcellY = 1 + math.Mod(m.LocationY, 5)
-				m.TileX = int(m.LocationX / 5)
-				m.TileY = int(m.LocationY / 5)
+				m.waypoint = nil
+				m.setLocation(m.TargetX, m.TargetY)
 			}
 		}
 
@@ -123,6 +161,38 @@ func (m *mapEntity) Step(tickTime float64) {
 	}
 }
 
+// Moves the entity to the sub tile location, notifying the tile listener when the entity moves onto another tile
+func (m *mapEntity) setLocation(x, y float64) {
+	oldTileX, oldTileY := m.tileX, m.tileY
+
+	m.locationX = x
+	m.locationY = y
+	m.subcellX = 1 + math.Mod(x, 5)
+	m.subcellY = 1 + math.Mod(y, 5)
+	m.tileX = int(x / 5)
+	m.tileY = int(y / 5)
+
+	if m.tileChanged != nil && (m.tileX != oldTileX || m.tileY != oldTileY) {
+		m.tileChanged(oldTileX, oldTileY)
+	}
+}
+
+// SetPosition moves the entity straight to the sub tile location (in the same units as GetLocation), cancelling any
+// movement in progress. The entity's position can only be changed through its methods, so that the map engine's
+// index of the entities on each tile stays up to date.
+func (m *mapEntity) SetPosition(x, y float64) {
+	m.path = []astar.Pather{}
+	m.waypoint = nil
+	m.TargetX, m.TargetY = x, y
+	m.setLocation(x, y)
+}
+
+// OnTileChanged sets the function called with the previous tile whenever the entity moves onto another tile (used by
+// the map engine to index the entities on each tile)
+func (m *mapEntity) OnTileChanged(listener func(oldTileX, oldTileY int)) {
+	m.tileChanged = listener
+}
+
 func (m *mapEntity) HasPathFinding() bool {
 	return len(m.path) > 0
 }
@@ -134,8 +204,8 @@ func (m *mapEntity) SetTarget(tx, ty float64, done func()) {
 
 	if m.directioner != nil {
 		angle := 359 - d2common.GetAngleBetween(
-			m.LocationX,
-			m.LocationY,
+			m.locationX,
+			m.locationY,
 			tx,
 			ty,
 		)
@@ -158,6 +228,52 @@ func angleToDirection(angle float64) int {
 	return newDirection
 }
 
+// GetScreenAnchor returns the screen offset from the top corner of the entity's tile to its feet, which it is drawn from
+func (m *mapEntity) GetScreenAnchor() (int, int) {
+	return m.offsetX + int((m.subcellX-m.subcellY)*16), m.offsetY + int(((m.subcellX+m.subcellY)*8)-5)
+}
+
 func (m *mapEntity) GetPosition() (float64, float64) {
-	return float64(m.TileX), float64(m.TileY)
+	return float64(m.tileX), float64(m.tileY)
+}
+
+// GetLocation returns the sub tile location of the entity, in the same units as its target
+func (m *mapEntity) GetLocation() (float64, float64) {
+	return m.locationX, m.locationY
+}
+
+// GetRenderLayer returns the render pass this entity is drawn in
+func (m *mapEntity) GetRenderLayer() d2enum.EntityRenderLayer {
+	return m.renderLayer
+}
+
+// SetRenderLayer sets the render pass this entity is drawn in
+func (m *mapEntity) SetRenderLayer(layer d2enum.EntityRenderLayer) {
+	m.renderLayer = layer
+}
+
+// GetRenderScale returns the scale this entity is drawn at
+func (m *mapEntity) GetRenderScale() float64 {
+	return m.renderScale
+}
+
+// IsHighlighted returns true if the entity is drawn with a selection highlight
+func (m *mapEntity) IsHighlighted() bool {
+	return m.Highlighted
+}
+
+// SetSortOrigin sets the offset from the entity's location to its feet, in sub tiles, which the renderer uses to sort
+// the entity against the entities around it
+func (m *mapEntity) SetSortOrigin(x, y float64) {
+	m.sortX, m.sortY = x, y
+}
+
+// GetSortOrigin returns the offset from the entity's location to its feet, in sub tiles
+func (m *mapEntity) GetSortOrigin() (float64, float64) {
+	return m.sortX, m.sortY
+}
+
+// SetRenderScale sets the scale this entity is drawn at (eg: for champion monsters), preserving its anchor
+func (m *mapEntity) SetRenderScale(scale float64) {
+	m.renderScale = scale
 }
@@ -44,8 +44,8 @@ func CreateMissile(x, y int, record *d2datadict.MissileRecord) (*Missile, error)
 func (m *Missile) SetRadians(angle float64, done func()) {
 	r := float64(m.record.Range)
 
-	x := m.LocationX + (r * math.Cos(angle))
-	y := m.LocationY + (r * math.Sin(angle))
+	x := m.locationX + (r * math.Cos(angle))
+	y := m.locationY + (r * math.Sin(angle))
 
 	m.SetTarget(x, y, done)
 }
@@ -0,0 +1,57 @@
+package d2mapentity
+
+import (
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// ObjectPartSprite is the animation drawn for a single part of a multi-part object (implemented by *d2asset.Animation)
+type ObjectPartSprite interface {
+	Render(target d2render.Surface) error
+	Advance(elapsed float64) error
+}
+
+// ObjectPart is one sub-sprite of a multi-part object, drawn at an offset relative to the object
+type ObjectPart struct {
+	OffsetX int
+	OffsetY int
+	Sprite  ObjectPartSprite
+}
+
+// MultiPartObject is a map object composed of several independently animated sprites (eg: a fountain with a separate
+// water animation). The parts are composited in order, so later parts are drawn over earlier ones.
+type MultiPartObject struct {
+	mapEntity
+	parts []ObjectPart
+}
+
+// CreateMultiPartObject creates an instance of MultiPartObject
+func CreateMultiPartObject(x, y int, parts []ObjectPart) *MultiPartObject {
+	return &MultiPartObject{
+		mapEntity: createMapEntity(x, y),
+		parts:     parts,
+	}
+}
+
+// Parts returns the parts of this object, in the order they are drawn
+func (mo *MultiPartObject) Parts() []ObjectPart {
+	return mo.parts
+}
+
+// Render draws each part of this object onto the target at its offset
+func (mo *MultiPartObject) Render(target d2render.Surface) {
+	target.PushTranslation(mo.GetScreenAnchor())
+	defer target.Pop()
+
+	for _, part := range mo.parts {
+		target.PushTranslation(part.OffsetX, part.OffsetY)
+		part.Sprite.Render(target)
+		target.Pop()
+	}
+}
+
+// Advance advances the animation of each part independently
+func (mo *MultiPartObject) Advance(elapsed float64) {
+	for _, part := range mo.parts {
+		part.Sprite.Advance(elapsed)
+	}
+}
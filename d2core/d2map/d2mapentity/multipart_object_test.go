@@ -0,0 +1,98 @@
+package d2mapentity
+
+import (
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// testSurface is a d2render.Surface that tracks translations and records where sprites were drawn
+type testSurface struct {
+	d2render.Surface
+	x, y   int
+	stack  [][2]int
+	drawn  []testSpriteDraw
+	colors []color.Color
+}
+
+type testSpriteDraw struct {
+	name  string
+	frame int
+	x, y  int
+}
+
+func (s *testSurface) PushTranslation(x, y int) {
+	s.stack = append(s.stack, [2]int{s.x, s.y})
+	s.x += x
+	s.y += y
+}
+
+func (s *testSurface) PushColor(c color.Color) {
+	s.stack = append(s.stack, [2]int{s.x, s.y})
+	s.colors = append(s.colors, c)
+}
+
+func (s *testSurface) Pop() {
+	last := s.stack[len(s.stack)-1]
+	s.x, s.y = last[0], last[1]
+	s.stack = s.stack[:len(s.stack)-1]
+}
+
+// testSprite is an object part sprite with a fixed frame length
+type testSprite struct {
+	name        string
+	frameLength float64
+	elapsed     float64
+}
+
+func (s *testSprite) frame() int {
+	return int(s.elapsed / s.frameLength)
+}
+
+func (s *testSprite) Render(target d2render.Surface) error {
+	surface := target.(*testSurface)
+	surface.drawn = append(surface.drawn, testSpriteDraw{name: s.name, frame: s.frame(), x: surface.x, y: surface.y})
+	return nil
+}
+
+func (s *testSprite) Advance(elapsed float64) error {
+	s.elapsed += elapsed
+	return nil
+}
+
+func TestMultiPartObjectRendersPartsAtOffsets(t *testing.T) {
+	assert := testify.New(t)
+	base := &testSprite{name: "base", frameLength: 1}
+	water := &testSprite{name: "water", frameLength: 0.25}
+	object := CreateMultiPartObject(0, 0, []ObjectPart{
+		{Sprite: base},
+		{OffsetX: 10, OffsetY: -20, Sprite: water},
+	})
+
+	target := &testSurface{}
+	object.Render(target)
+
+	assert.Len(target.drawn, 2)
+	assert.Equal("base", target.drawn[0].name)
+	assert.Equal("water", target.drawn[1].name)
+	assert.Equal(target.drawn[0].x+10, target.drawn[1].x)
+	assert.Equal(target.drawn[0].y-20, target.drawn[1].y)
+	assert.Empty(target.stack)
+}
+
+func TestMultiPartObjectAdvancesPartsIndependently(t *testing.T) {
+	assert := testify.New(t)
+	base := &testSprite{name: "base", frameLength: 1}
+	water := &testSprite{name: "water", frameLength: 0.25}
+	object := CreateMultiPartObject(0, 0, []ObjectPart{{Sprite: base}, {Sprite: water}})
+
+	object.Advance(0.5)
+
+	target := &testSurface{}
+	object.Render(target)
+	assert.Equal(0, target.drawn[0].frame)
+	assert.Equal(2, target.drawn[1].frame)
+}
@@ -0,0 +1,66 @@
+package d2mapentity
+
+import (
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/beefsack/go-astar"
+)
+
+// PathFollower is implemented by entities that can walk along a path of waypoints (eg: one found by the map engine's
+// FindPath)
+type PathFollower interface {
+	FollowPath(path []d2common.PathTile)
+	RemainingPath() []d2common.PathTile
+	IsPathBlocked() bool
+	SetWalkableCheck(check func(x, y float64) bool)
+}
+
+// FollowPath makes the entity walk through the waypoints in order at its movement speed, moving on to the next
+// waypoint as it reaches each one. The waypoints are in world tile units, as returned by FindPath. A nil or empty path
+// stops the entity where it is.
+func (m *mapEntity) FollowPath(path []d2common.PathTile) {
+	m.done = nil
+	m.waypoint = nil
+	m.path = make([]astar.Pather, len(path))
+	for i := range path {
+		waypoint := path[i]
+		m.path[i] = &waypoint
+	}
+
+	if len(path) == 0 {
+		m.TargetX, m.TargetY = m.locationX, m.locationY
+	}
+}
+
+// RemainingPath returns the waypoints the entity has still to reach, starting with the one it is walking to
+func (m *mapEntity) RemainingPath() []d2common.PathTile {
+	remaining := make([]d2common.PathTile, 0, len(m.path)+1)
+	if m.waypoint != nil {
+		remaining = append(remaining, *m.waypoint)
+	}
+	for _, pather := range m.path {
+		remaining = append(remaining, *pather.(*d2common.PathTile))
+	}
+	return remaining
+}
+
+// SetWalkableCheck sets the function IsPathBlocked uses to look up whether a world position is currently walkable (the
+// map engine sets this when the entity is added to it)
+func (m *mapEntity) SetWalkableCheck(check func(x, y float64) bool) {
+	m.walkable = check
+}
+
+// IsPathBlocked returns true if any of the remaining waypoints can no longer be walked on (eg: a door was closed after
+// the path was found), so that the path can be found again. Without a walkable check, the waypoints' own walkable
+// flags are used.
+func (m *mapEntity) IsPathBlocked() bool {
+	for _, waypoint := range m.RemainingPath() {
+		walkable := waypoint.Walkable
+		if m.walkable != nil {
+			walkable = m.walkable(waypoint.X, waypoint.Y)
+		}
+		if !walkable {
+			return true
+		}
+	}
+	return false
+}
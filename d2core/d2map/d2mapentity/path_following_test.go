@@ -0,0 +1,104 @@
+package d2mapentity
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+)
+
+// Returns a walkable waypoint at the sub tile position
+func createTestWaypoint(subTileX, subTileY int) d2common.PathTile {
+	return d2common.PathTile{Walkable: true, X: float64(subTileX) / 5, Y: float64(subTileY) / 5}
+}
+
+func TestFollowPathWalksThroughWaypoints(t *testing.T) {
+	assert := testify.New(t)
+	entity := createMapEntity(0, 0)
+	entity.FollowPath([]d2common.PathTile{createTestWaypoint(3, 0), createTestWaypoint(3, 3)})
+
+	for i := 0; i < 100 && (entity.HasPathFinding() || !entity.IsAtTarget()); i++ {
+		entity.Step(0.1)
+	}
+
+	x, y := entity.GetLocation()
+	assert.InDelta(3, x, 0.01)
+	assert.InDelta(3, y, 0.01)
+	assert.Empty(entity.RemainingPath())
+}
+
+func TestFollowPathMovesAtEntitySpeed(t *testing.T) {
+	assert := testify.New(t)
+	entity := createMapEntity(0, 0)
+	entity.FollowPath([]d2common.PathTile{createTestWaypoint(10, 0)})
+
+	// The first step picks up the waypoint, and the entity then walks 6 sub tiles per second
+	entity.Step(0.1)
+	entity.Step(0.5)
+
+	x, _ := entity.GetLocation()
+	assert.InDelta(3, x, 0.01)
+	assert.Len(entity.RemainingPath(), 1)
+}
+
+func TestFollowPathReportsRemainingWaypoints(t *testing.T) {
+	assert := testify.New(t)
+	entity := createMapEntity(0, 0)
+	path := []d2common.PathTile{createTestWaypoint(5, 0), createTestWaypoint(10, 0), createTestWaypoint(15, 0)}
+	entity.FollowPath(path)
+	assert.Equal(path, entity.RemainingPath())
+
+	// Halfway to the second waypoint, the first has been passed
+	entity.Step(0.1)
+	entity.Step(1.25)
+	assert.Equal(path[1:], entity.RemainingPath())
+}
+
+func TestFollowPathNilStopsEntity(t *testing.T) {
+	assert := testify.New(t)
+	entity := createMapEntity(0, 0)
+	entity.FollowPath([]d2common.PathTile{createTestWaypoint(10, 0)})
+	entity.Step(0.1)
+	entity.Step(0.5)
+
+	entity.FollowPath(nil)
+	entity.Step(0.5)
+
+	x, y := entity.GetLocation()
+	assert.InDelta(3, x, 0.01)
+	assert.InDelta(0, y, 0.01)
+	assert.True(entity.IsAtTarget())
+	assert.Empty(entity.RemainingPath())
+}
+
+func TestIsPathBlockedUsesWaypointFlags(t *testing.T) {
+	assert := testify.New(t)
+	entity := createMapEntity(0, 0)
+	blocked := createTestWaypoint(10, 0)
+	blocked.Walkable = false
+
+	entity.FollowPath([]d2common.PathTile{createTestWaypoint(5, 0)})
+	assert.False(entity.IsPathBlocked())
+
+	entity.FollowPath([]d2common.PathTile{createTestWaypoint(5, 0), blocked})
+	assert.True(entity.IsPathBlocked())
+}
+
+func TestIsPathBlockedUsesWalkableCheck(t *testing.T) {
+	assert := testify.New(t)
+	entity := createMapEntity(0, 0)
+	entity.FollowPath([]d2common.PathTile{createTestWaypoint(5, 0), createTestWaypoint(10, 0)})
+
+	closed := false
+	entity.SetWalkableCheck(func(x, y float64) bool {
+		return !closed || x < 2
+	})
+	assert.False(entity.IsPathBlocked())
+
+	closed = true
+	assert.True(entity.IsPathBlocked())
+
+	entity.FollowPath(nil)
+	assert.False(entity.IsPathBlocked())
+}
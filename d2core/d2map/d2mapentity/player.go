@@ -86,8 +86,7 @@ func (v *Player) Advance(tickTime float64) {
 
 func (v *Player) Render(target d2render.Surface) {
 	v.AnimatedComposite.Render(target)
-	offX := v.AnimatedComposite.offsetX + int((v.AnimatedComposite.subcellX-v.AnimatedComposite.subcellY)*16)
-	offY := v.AnimatedComposite.offsetY + int(((v.AnimatedComposite.subcellX+v.AnimatedComposite.subcellY)*8)-5)
+	offX, offY := v.GetScreenAnchor()
 	v.nameLabel.X = offX
 	v.nameLabel.Y = offY - 100
 	v.nameLabel.Render(target)
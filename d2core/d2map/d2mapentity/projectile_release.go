@@ -0,0 +1,24 @@
+package d2mapentity
+
+// FrameEventSource is an animation that can run a callback when playback reaches a frame (eg: *d2asset.Animation)
+type FrameEventSource interface {
+	OnFrame(frameIndex int, callback func())
+}
+
+// Locatable is an entity with a sub tile location that projectiles can be fired from
+type Locatable interface {
+	GetLocation() (float64, float64)
+}
+
+// ProjectileSpawnFunc creates a projectile at x, y travelling towards the target
+type ProjectileSpawnFunc func(x, y, targetX, targetY float64)
+
+// BindProjectileRelease ties the release frame of an attack animation to a projectile spawn. Each time the animation
+// reaches the release frame, spawn is called with the attacker's location at that moment and the target location.
+func BindProjectileRelease(animation FrameEventSource, releaseFrame int, attacker Locatable,
+	targetX, targetY float64, spawn ProjectileSpawnFunc) {
+	animation.OnFrame(releaseFrame, func() {
+		x, y := attacker.GetLocation()
+		spawn(x, y, targetX, targetY)
+	})
+}
@@ -0,0 +1,53 @@
+package d2mapentity
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+// testAttackAnimation is a frame event source that steps through its frames one at a time
+type testAttackAnimation struct {
+	frame  int
+	events map[int][]func()
+}
+
+func (a *testAttackAnimation) OnFrame(frameIndex int, callback func()) {
+	if a.events == nil {
+		a.events = make(map[int][]func())
+	}
+	a.events[frameIndex] = append(a.events[frameIndex], callback)
+}
+
+func (a *testAttackAnimation) step() {
+	a.frame++
+	for _, callback := range a.events[a.frame] {
+		callback()
+	}
+}
+
+type testProjectile struct {
+	x, y, targetX, targetY float64
+}
+
+func TestBindProjectileReleaseSpawnsOnReleaseFrame(t *testing.T) {
+	assert := testify.New(t)
+	animation := &testAttackAnimation{}
+	attacker := createMapEntity(2, 3)
+	var spawned []testProjectile
+
+	BindProjectileRelease(animation, 3, &attacker, 50, 60, func(x, y, targetX, targetY float64) {
+		spawned = append(spawned, testProjectile{x, y, targetX, targetY})
+	})
+
+	animation.step()
+	animation.step()
+	assert.Empty(spawned)
+
+	attacker.SetPosition(12, 17)
+	animation.step()
+	assert.Equal([]testProjectile{{12, 17, 50, 60}}, spawned)
+
+	animation.step()
+	assert.Len(spawned, 1)
+}
@@ -0,0 +1,79 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestAdvanceSubFrame(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	assert.Equal(0, mr.Advance(0.04))
+	assert.InDelta(0.04, mr.FrameRemainder(), 0.000001)
+	assert.Equal(0, mr.Advance(0.05))
+	assert.InDelta(0.09, mr.FrameRemainder(), 0.000001)
+	assert.Equal(0, mr.CurrentFrame())
+}
+
+func TestAdvanceSingleFrame(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	assert.Equal(1, mr.Advance(0.125))
+	assert.InDelta(0.025, mr.FrameRemainder(), 0.000001)
+	assert.Equal(1, mr.CurrentFrame())
+
+	// The remainder carries over into the next call
+	assert.Equal(1, mr.Advance(0.08))
+	assert.InDelta(0.005, mr.FrameRemainder(), 0.000001)
+	assert.Equal(2, mr.CurrentFrame())
+}
+
+func TestAdvanceMultipleFrames(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	assert.Equal(3, mr.Advance(0.35))
+	assert.InDelta(0.05, mr.FrameRemainder(), 0.000001)
+	assert.Equal(3, mr.CurrentFrame())
+}
+
+// steppingEntity is a sprite entity that moves one sub tile along the x axis each time it is advanced
+type steppingEntity struct {
+	*spriteEntity
+}
+
+func (e *steppingEntity) Advance(tickTime float64) {
+	e.spriteEntity.Advance(tickTime)
+	e.locationX++
+}
+
+// Returns the horizontal screen position the entity is drawn at
+func renderedEntityX(mr *MapRenderer) int {
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	return target.callsOf("text")[0].x
+}
+
+func TestEntityDrawnBetweenTicks(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	entity := &steppingEntity{createSpriteEntity("walker", 0, 0, createTestSurface(10, 10))}
+	mr.mapEngine.AddEntity(entity)
+	located := renderedEntityX(mr)
+
+	mr.mapEngine.SetEntityTickRate(4)
+	mr.mapEngine.Advance(0.25)
+	mr.mapEngine.Advance(0.25)
+
+	// The entity is drawn moving smoothly from its location after the previous tick to its location after the last
+	mr.mapEngine.Advance(0.0625)
+	assert.Equal(located-12, renderedEntityX(mr))
+	mr.mapEngine.Advance(0.125)
+	assert.Equal(located-4, renderedEntityX(mr))
+
+	mr.mapEngine.SetEntityTickRate(0)
+	assert.Equal(located, renderedEntityX(mr))
+}
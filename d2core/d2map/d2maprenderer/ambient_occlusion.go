@@ -0,0 +1,89 @@
+package d2maprenderer
+
+import (
+	"image/color"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+const (
+	ambientOcclusionAlpha      = 96 // The alpha of the darkening drawn over a floor tile that contains a wall
+	ambientOcclusionStripCount = 8  // The number of horizontal strips used to draw the darkened tile diamond
+)
+
+// Enables or disables the ambient occlusion pass, which darkens floor tiles at the base of walls
+func (mr *MapRenderer) SetAmbientOcclusion(enabled bool) {
+	mr.occlusion = enabled
+}
+
+// Returns true if the ambient occlusion pass is enabled
+func (mr *MapRenderer) IsAmbientOcclusionEnabled() bool {
+	return mr.occlusion
+}
+
+// Returns how strongly the floor of a tile is occluded by the surrounding walls, from 0 (open floor) to 1. A tile
+// containing a wall is fully occluded, and a tile next to one is half occluded, giving a gradient away from the wall.
+func (mr *MapRenderer) ambientOcclusionAt(tileX, tileY int) float64 {
+	if tileHasOccludingWall(mr.mapEngine.TileAt(tileX, tileY)) {
+		return 1
+	}
+
+	for offsetY := -1; offsetY <= 1; offsetY++ {
+		for offsetX := -1; offsetX <= 1; offsetX++ {
+			if !mr.mapEngine.TileExists(tileX+offsetX, tileY+offsetY) {
+				continue
+			}
+			if tileHasOccludingWall(mr.mapEngine.TileAt(tileX+offsetX, tileY+offsetY)) {
+				return 0.5
+			}
+		}
+	}
+
+	return 0
+}
+
+// Returns true if the tile has a visible wall that casts ambient occlusion onto the floor
+func tileHasOccludingWall(tile *d2ds1.TileRecord) bool {
+	for _, wall := range tile.Walls {
+		if wall.Hidden || wall.Prop1 == 0 {
+			continue
+		}
+		if (wall.Type.UpperWall() || wall.Type.LowerWall()) && wall.Type != d2enum.Tree {
+			return true
+		}
+	}
+	return false
+}
+
+// Darkens the floor of the tile at the current viewport translation by its ambient occlusion
+func (mr *MapRenderer) renderAmbientOcclusion(tileX, tileY int, tile *d2ds1.TileRecord, target d2render.Surface) {
+	if !mr.occlusion || len(tile.Floors) == 0 {
+		return
+	}
+
+	occlusion := mr.ambientOcclusionAt(tileX, tileY)
+	if occlusion <= 0 {
+		return
+	}
+
+	alpha := uint8(ambientOcclusionAlpha * occlusion)
+	shade := color.RGBA{A: alpha}
+	screenX, screenY := mr.viewport.GetTranslationScreen()
+	diamondHalfWidth, diamondHalfHeight := mr.viewport.tileDiamondHalfSize()
+	stripHeight := diamondHalfHeight * 2 / ambientOcclusionStripCount
+	for strip := 0; strip < ambientOcclusionStripCount; strip++ {
+		// The width of the diamond at the middle of the strip
+		middleY := strip*stripHeight + stripHeight/2
+		distance := middleY - diamondHalfHeight
+		if distance < 0 {
+			distance = -distance
+		}
+		halfWidth := diamondHalfWidth * (diamondHalfHeight - distance) / diamondHalfHeight
+
+		target.PushTranslation(screenX-halfWidth, screenY+strip*stripHeight)
+		target.DrawRect(halfWidth*2, stripHeight, shade)
+		target.Pop()
+	}
+}
@@ -0,0 +1,90 @@
+package d2maprenderer
+
+import (
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+// createTestOcclusionMap creates a row of floor tiles with a wall on the first tile
+func createTestOcclusionMap() *MapRenderer {
+	mr := createTestMapRenderer(4, 1)
+	mr.setImageCacheRecord(1, 1, 0, 0, createTestSurface(160, 80))
+	for x := 0; x < 4; x++ {
+		mr.mapEngine.TileAt(x, 0).Floors = []d2ds1.FloorShadowRecord{{Style: 1, Sequence: 1, Prop1: 1}}
+	}
+	mr.mapEngine.TileAt(0, 0).Walls = []d2ds1.WallRecord{
+		{Type: d2enum.LowerWallsEquivalentToLeftWall, Style: 2, Sequence: 1, Prop1: 1, Hidden: true},
+		{Type: d2enum.RightWall, Style: 2, Sequence: 1, Prop1: 1},
+	}
+	return mr
+}
+
+// Returns the alpha of each ambient occlusion strip drawn onto the target, in draw order
+func occlusionAlphas(target *testSurface) []uint8 {
+	var result []uint8
+	for _, call := range target.callsOf("rect") {
+		result = append(result, call.color.(color.RGBA).A)
+	}
+	return result
+}
+
+func TestAmbientOcclusionDarkensFloorsNextToWalls(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr := createTestOcclusionMap()
+
+	assert.Equal(1.0, mr.ambientOcclusionAt(0, 0))
+	assert.Equal(0.5, mr.ambientOcclusionAt(1, 0))
+	assert.Equal(0.0, mr.ambientOcclusionAt(2, 0))
+	assert.Equal(0.0, mr.ambientOcclusionAt(3, 0))
+
+	mr.SetAmbientOcclusion(true)
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	alphas := occlusionAlphas(target)
+	assert.Len(alphas, 2*ambientOcclusionStripCount)
+	for i, alpha := range alphas {
+		if i < ambientOcclusionStripCount {
+			assert.Equal(uint8(ambientOcclusionAlpha), alpha)
+		} else {
+			assert.Equal(uint8(ambientOcclusionAlpha/2), alpha)
+		}
+	}
+	assert.Equal(0, target.GetDepth())
+}
+
+func TestAmbientOcclusionStripsFillTileDiamond(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr := createTestOcclusionMap()
+	mr.SetAmbientOcclusion(true)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	tileX, tileY := mr.viewport.WorldToScreen(0, 0)
+	for _, call := range target.callsOf("rect")[:ambientOcclusionStripCount] {
+		middleX := call.x + call.width/2
+		middleY := call.y + call.height/2
+		assert.Equal(tileX, middleX)
+		assert.True(mr.viewport.PointInTileDiamond(call.x, middleY, tileX, tileY))
+		assert.True(mr.viewport.PointInTileDiamond(call.x+call.width, middleY, tileX, tileY))
+	}
+}
+
+func TestAmbientOcclusionDisabledByDefault(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr := createTestOcclusionMap()
+	assert.False(mr.IsAmbientOcclusionEnabled())
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Empty(target.callsOf("rect"))
+}
@@ -0,0 +1,89 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+func TestAnimatedFloorStaysWithinFrameRange(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+
+	mr := createTestMapRenderer(1, 1)
+	frames := []*testSurface{createTestSurface(160, 80), createTestSurface(160, 80), createTestSurface(160, 80)}
+	for i, frame := range frames {
+		mr.setImageCacheRecord(1, 1, 0, byte(i), frame)
+	}
+	mr.mapEngine.TileAt(0, 0).Floors = []d2ds1.FloorShadowRecord{
+		{Style: 1, Sequence: 1, Prop1: 1, Animated: true, FrameCount: byte(len(frames))},
+	}
+
+	// Cover a full cycle of the renderer's frame counter
+	for i := 0; i < 12; i++ {
+		before := GetImageCacheStats().Misses
+		target := createTestSurface(800, 600)
+		mr.Render(target)
+
+		assert.Equal(before, GetImageCacheStats().Misses, "frame %d", mr.CurrentFrame())
+		assert.Equal(mr.CurrentFrame()%len(frames), indexOfFrame(target, frames))
+
+		mr.Advance(tileFrameLength)
+	}
+}
+
+// Returns the index of the frame rendered onto the target, or -1 if none of the frames were rendered
+func indexOfFrame(target *testSurface, frames []*testSurface) int {
+	for i, frame := range frames {
+		if indexOfRender(target, frame) != -1 {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestAnimatedFloorsUseTheirOwnFrameCount(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+
+	mr := createTestMapRenderer(2, 1)
+	shortFrames := make([]*testSurface, 3)
+	longFrames := make([]*testSurface, 12)
+	for i := range shortFrames {
+		shortFrames[i] = createTestSurface(160, 80)
+		mr.setImageCacheRecord(1, 1, 0, byte(i), shortFrames[i])
+	}
+	for i := range longFrames {
+		longFrames[i] = createTestSurface(160, 80)
+		mr.setImageCacheRecord(2, 1, 0, byte(i), longFrames[i])
+	}
+	mr.mapEngine.TileAt(0, 0).Floors = []d2ds1.FloorShadowRecord{
+		{Style: 1, Sequence: 1, Prop1: 1, Animated: true, FrameCount: byte(len(shortFrames))},
+	}
+	mr.mapEngine.TileAt(1, 0).Floors = []d2ds1.FloorShadowRecord{
+		{Style: 2, Sequence: 1, Prop1: 1, Animated: true, FrameCount: byte(len(longFrames))},
+	}
+
+	// Neither animation restarts early when ten frames have passed, and the longer one reaches all of its frames
+	for frame := 0; frame < 24; frame++ {
+		target := createTestSurface(800, 600)
+		mr.Render(target)
+
+		assert.Equal(frame%len(shortFrames), indexOfFrame(target, shortFrames), "frame %d", frame)
+		assert.Equal(frame%len(longFrames), indexOfFrame(target, longFrames), "frame %d", frame)
+
+		mr.Advance(tileFrameLength)
+	}
+}
+
+func TestAnimatedFloorWithUnknownFrameCount(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	mr.Advance(tileFrameLength * 13)
+	assert.Equal(13, mr.CurrentFrame())
+	assert.Equal(byte(3), mr.tileAnimationFrame(0))
+	assert.Equal(byte(1), mr.tileAnimationFrame(4))
+}
@@ -0,0 +1,37 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+func TestAnimatedWallAdvancesFrames(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+
+	mr := createTestMapRenderer(2, 1)
+	frames := []*testSurface{createTestSurface(160, 80), createTestSurface(160, 80), createTestSurface(160, 80)}
+	for i, frame := range frames {
+		mr.setImageCacheRecord(1, 1, d2enum.LeftWall, byte(i), frame)
+	}
+	staticImage := createTestSurface(160, 80)
+	mr.setImageCacheRecord(2, 1, d2enum.LeftWall, 4, staticImage)
+
+	mr.mapEngine.TileAt(0, 0).Walls = []d2ds1.WallRecord{{Type: d2enum.LeftWall, Style: 1, Sequence: 1, Animated: true}}
+	mr.mapEngine.TileAt(1, 0).Walls = []d2ds1.WallRecord{{Type: d2enum.LeftWall, Style: 2, Sequence: 1, RandomIndex: 4}}
+
+	for i := range frames {
+		target := createTestSurface(800, 600)
+		mr.Render(target)
+
+		assert.NotEqual(-1, indexOfRender(target, frames[i]))
+		assert.NotEqual(-1, indexOfRender(target, staticImage))
+		assert.Len(target.callsOf("render"), 2)
+
+		mr.Advance(tileFrameLength)
+	}
+}
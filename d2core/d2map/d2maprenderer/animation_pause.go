@@ -0,0 +1,26 @@
+package d2maprenderer
+
+// Freezes or resumes the tile animations (eg: while the inventory is open), independently of the entities. While
+// paused, Advance leaves the tile animations on their current frame.
+func (mr *MapRenderer) SetTileAnimationsPaused(paused bool) {
+	mr.tilesPaused = paused
+}
+
+// Returns true if the tile animations are frozen
+func (mr *MapRenderer) TileAnimationsPaused() bool {
+	return mr.tilesPaused
+}
+
+// Freezes or resumes the entities of the map engine being rendered, independently of the tile animations. The setting
+// is carried over when the map engine is replaced.
+func (mr *MapRenderer) SetEntityAnimationsPaused(paused bool) {
+	mr.entityPaused = paused
+	if mr.mapEngine != nil {
+		mr.mapEngine.SetEntitiesPaused(paused)
+	}
+}
+
+// Returns true if the entities are frozen
+func (mr *MapRenderer) EntityAnimationsPaused() bool {
+	return mr.entityPaused
+}
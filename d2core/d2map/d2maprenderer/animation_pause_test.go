@@ -0,0 +1,71 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+// Advances the map engine and then the renderer, as a game screen does each frame
+func advanceRenderedMap(mr *MapRenderer, elapsed float64) {
+	mr.mapEngine.Advance(elapsed)
+	mr.Advance(elapsed)
+}
+
+func TestPausedTileAnimationsKeepEntitiesRunning(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	entity := createTestEntity("zombie", 0, 0)
+	mr.mapEngine.AddEntity(entity)
+	advanceRenderedMap(mr, 0.25)
+
+	mr.SetTileAnimationsPaused(true)
+	assert.True(mr.TileAnimationsPaused())
+	advanceRenderedMap(mr, 0.5)
+	assert.Equal(2, mr.CurrentFrame())
+	assert.InDelta(0.05, mr.FrameRemainder(), 0.000001)
+	assert.InDelta(0.75, entity.advanced, 0.000001)
+
+	// Resuming carries on from the frozen frame rather than catching up
+	mr.SetTileAnimationsPaused(false)
+	assert.Equal(1, mr.Advance(0.06))
+	assert.Equal(3, mr.CurrentFrame())
+}
+
+func TestPausedEntityAnimationsKeepTilesRunning(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	entity := createTestEntity("zombie", 0, 0)
+	mr.mapEngine.AddEntity(entity)
+
+	mr.SetEntityAnimationsPaused(true)
+	assert.True(mr.EntityAnimationsPaused())
+	advanceRenderedMap(mr, 0.5)
+	assert.Equal(5, mr.CurrentFrame())
+	assert.Zero(entity.advanced)
+
+	mr.SetEntityAnimationsPaused(false)
+	advanceRenderedMap(mr, 0.1)
+	assert.InDelta(0.1, entity.advanced, 0.000001)
+}
+
+func TestEntityAnimationPauseCarriesToNewMapEngine(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	mr.SetEntityAnimationsPaused(true)
+
+	next := createTestMapRenderer(1, 1).mapEngine
+	mr.SetMapEngine(next)
+	assert.True(next.EntitiesPaused())
+}
+
+func TestEntityAnimationResumeCarriesToPausedMapEngine(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	// A map engine paused by another renderer runs its entities once it is drawn by a renderer that does not pause them
+	next := createTestMapRenderer(1, 1).mapEngine
+	next.SetEntitiesPaused(true)
+	mr.SetMapEngine(next)
+	assert.False(next.EntitiesPaused())
+}
@@ -0,0 +1,42 @@
+package d2maprenderer
+
+import (
+	"image/color"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+var (
+	axisGizmoXColor = color.RGBA{R: 255, G: 64, B: 64, A: 255} // The color of the +X world axis
+	axisGizmoYColor = color.RGBA{R: 64, G: 255, B: 64, A: 255} // The color of the +Y world axis
+)
+
+// Sets whether a gizmo is drawn at the world origin, with a line along each world axis to the point one tile away
+// (+X in red, +Y in green), to check the coordinate conversions after changes to the viewport math
+func (mr *MapRenderer) SetAxisGizmo(enabled bool) {
+	mr.axisGizmo = enabled
+}
+
+func (mr *MapRenderer) renderAxisGizmo(target d2render.Surface) {
+	originX, originY := mr.viewport.WorldToScreen(0, 0)
+	axes := []struct {
+		worldX, worldY float64
+		label          string
+		color          color.RGBA
+	}{
+		{1, 0, "+X", axisGizmoXColor},
+		{0, 1, "+Y", axisGizmoYColor},
+	}
+
+	target.PushTranslation(originX, originY)
+	defer target.Pop()
+
+	for _, axis := range axes {
+		endX, endY := mr.viewport.WorldToScreen(axis.worldX, axis.worldY)
+		target.DrawLine(endX-originX, endY-originY, axis.color)
+
+		target.PushTranslation(endX-originX, endY-originY)
+		target.DrawText("%s", axis.label)
+		target.Pop()
+	}
+}
@@ -0,0 +1,50 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestAxisGizmoMatchesWorldToScreen(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(4, 4)
+	mr.SetTileSize(128, 48)
+	mr.MoveCameraTo(mr.WorldToOrtho(1.5, 0.5))
+	mr.SetAxisGizmo(true)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	lines := target.callsOf("line")
+	if !assert.Len(lines, 2) {
+		return
+	}
+
+	originX, originY := mr.viewport.WorldToScreen(0, 0)
+	for i, axis := range [][2]float64{{1, 0}, {0, 1}} {
+		endX, endY := mr.viewport.WorldToScreen(axis[0], axis[1])
+		assert.Equal(originX, lines[i].x)
+		assert.Equal(originY, lines[i].y)
+		assert.Equal(endX, lines[i].x+lines[i].width)
+		assert.Equal(endY, lines[i].y+lines[i].height)
+	}
+	assert.Equal(axisGizmoXColor, lines[0].color)
+	assert.Equal(axisGizmoYColor, lines[1].color)
+
+	xLabel := target.calls[indexOfText(target, "+X")]
+	endX, endY := mr.viewport.WorldToScreen(1, 0)
+	assert.Equal(endX, xLabel.x)
+	assert.Equal(endY, xLabel.y)
+	assert.Equal(0, target.GetDepth())
+}
+
+func TestAxisGizmoDisabledByDefault(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(4, 4)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Equal(-1, indexOfText(target, "+X"))
+	assert.Empty(target.callsOf("line"))
+}
@@ -0,0 +1,53 @@
+package d2maprenderer
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+var (
+	tileBoundsColor   = color.RGBA{R: 255, G: 200, B: 0, A: 96}  // The outline of the rectangle a tile is culled with
+	entityBoundsColor = color.RGBA{R: 255, G: 0, B: 255, A: 255} // The outline of the rectangle an entity is picked with
+)
+
+// Sets whether the screen rectangles the renderer culls tiles with and picks entities with are outlined, to show
+// which regions the visibility and picking checks consider
+func (mr *MapRenderer) SetBoundsDebug(enabled bool) {
+	mr.boundsDebug = enabled
+}
+
+func (mr *MapRenderer) renderBoundsDebug(target d2render.Surface) {
+	viewport := mr.viewport
+	minX, minY, maxX, maxY := mr.visibleTileBounds(viewport)
+	for tileY := minY; tileY <= maxY; tileY++ {
+		for tileX := minX; tileX <= maxX; tileX++ {
+			if viewport.IsTileVisible(float64(tileX), float64(tileY)) {
+				outlineScreenRect(viewport.tileScreenBounds(float64(tileX), float64(tileY)), tileBoundsColor, target)
+			}
+		}
+	}
+
+	screen := image.Rect(0, 0, viewport.defaultScreenRect.Width, viewport.defaultScreenRect.Height)
+	for _, entity := range *mr.mapEngine.Entities() {
+		if bounds := mr.entityScreenBounds(entity); bounds.Overlaps(screen) {
+			outlineScreenRect(bounds, entityBoundsColor, target)
+		}
+	}
+}
+
+// Draws the edges of a screen rectangle, from its top left and bottom right corners
+func outlineScreenRect(rect image.Rectangle, c color.Color, target d2render.Surface) {
+	width, height := rect.Dx(), rect.Dy()
+
+	target.PushTranslation(rect.Min.X, rect.Min.Y)
+	target.DrawLine(width, 0, c)
+	target.DrawLine(0, height, c)
+	target.Pop()
+
+	target.PushTranslation(rect.Max.X, rect.Max.Y)
+	target.DrawLine(-width, 0, c)
+	target.DrawLine(0, -height, c)
+	target.Pop()
+}
@@ -0,0 +1,78 @@
+package d2maprenderer
+
+import (
+	"image"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+// Returns the rectangles outlined on the target in a color, from the line pairs drawn at their top left corners
+func outlinedRects(target *testSurface, c interface{}) []image.Rectangle {
+	var lines []testDrawCall
+	for _, line := range target.callsOf("line") {
+		if line.color == c {
+			lines = append(lines, line)
+		}
+	}
+
+	var result []image.Rectangle
+	for i := 0; i+3 < len(lines); i += 4 {
+		result = append(result, image.Rect(lines[i].x, lines[i].y, lines[i].x+lines[i].width, lines[i].y+lines[i+1].height))
+	}
+	return result
+}
+
+func TestBoundsDebugOutlinesCullingAndPickingRects(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(3, 3)
+	mr.MoveCameraTo(mr.WorldToOrtho(1.5, 1.5))
+	mr.SetBoundsDebug(true)
+
+	entity := createTestEntity("monster", 1, 2)
+	entity.scale = 2
+	mr.mapEngine.AddEntity(entity)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	var expected []image.Rectangle
+	for tileY := 0; tileY < 3; tileY++ {
+		for tileX := 0; tileX < 3; tileX++ {
+			if mr.viewport.IsTileVisible(float64(tileX), float64(tileY)) {
+				expected = append(expected, mr.viewport.tileScreenBounds(float64(tileX), float64(tileY)))
+			}
+		}
+	}
+	assert.Len(expected, 9)
+	assert.Equal(expected, outlinedRects(target, tileBoundsColor))
+
+	// The picking rectangle is the one marquee selection tests
+	entityRects := outlinedRects(target, entityBoundsColor)
+	assert.Equal([]image.Rectangle{mr.entityScreenBounds(entity)}, entityRects)
+	assert.Equal(80, entityRects[0].Dx())
+	assert.Equal(160, entityRects[0].Dy())
+	assert.Len(mr.EntitiesInScreenRect(image.Rect(entityRects[0].Min.X, entityRects[0].Min.Y,
+		entityRects[0].Min.X+1, entityRects[0].Min.Y+1)), 1)
+	assert.Equal(0, target.GetDepth())
+}
+
+func TestBoundsDebugSkipsOffscreenEntities(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(40, 40)
+	mr.SetBoundsDebug(true)
+	mr.mapEngine.AddEntity(createTestEntity("far away", 39, 39))
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Empty(outlinedRects(target, entityBoundsColor))
+}
+
+func TestBoundsDebugDisabledByDefault(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(3, 3)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Empty(target.callsOf("line"))
+}
@@ -1,20 +1,105 @@
 package d2maprenderer
 
+import "math"
+
+const (
+	minZoom = 0.25 // The furthest the map can be zoomed out
+	maxZoom = 4    // The furthest the map can be zoomed in
+)
+
 type Camera struct {
-	x float64
-	y float64
+	x      float64
+	y      float64
+	zoom   float64                 // The scale the map is drawn at (0 is treated as 1, so a new camera is not zoomed)
+	tween  *cameraTween            // The smooth move in progress (nil=not moving)
+	easing func(t float64) float64 // The easing of smooth moves (nil=ease in and out)
 }
 
+// cameraTween is a smooth camera move, in ortho coordinates
+type cameraTween struct {
+	startX, startY   float64
+	targetX, targetY float64
+	duration         float64 // The length of the move, in seconds
+	elapsed          float64 // The time since the move started, in seconds
+}
+
+// Moves the camera to the position immediately, cancelling any smooth move in progress
 func (c *Camera) MoveTo(x, y float64) {
 	c.x = x
 	c.y = y
+	c.tween = nil
 }
 
+// Moves the camera by the offset. A smooth move in progress is shifted by the same offset, so it still ends in the same
+// place relative to the camera.
 func (c *Camera) MoveBy(x, y float64) {
 	c.x += x
 	c.y += y
+	if c.tween != nil {
+		c.tween.startX += x
+		c.tween.startY += y
+		c.tween.targetX += x
+		c.tween.targetY += y
+	}
+}
+
+// Moves the camera to the position over the duration, in seconds, as the camera advances. Calling this again before a
+// move has finished starts the new move from wherever the camera currently is, so the camera never jumps.
+func (c *Camera) MoveToSmooth(x, y, duration float64) {
+	if duration <= 0 {
+		c.MoveTo(x, y)
+		return
+	}
+	c.tween = &cameraTween{startX: c.x, startY: c.y, targetX: x, targetY: y, duration: duration}
+}
+
+// Returns true while a smooth move is in progress
+func (c *Camera) IsMoving() bool {
+	return c.tween != nil
+}
+
+// Sets the easing of smooth moves, which maps the fraction of the duration elapsed (0-1) to the fraction of the
+// distance covered (0-1), or nil to ease in and out. See EaseLinear, EaseInOut and EaseSpring for the built-in easings.
+func (c *Camera) SetEasing(easing func(t float64) float64) {
+	c.easing = easing
+}
+
+// Advances the smooth move in progress
+func (c *Camera) Advance(elapsed float64) {
+	tween := c.tween
+	if tween == nil {
+		return
+	}
+
+	tween.elapsed += elapsed
+	if tween.elapsed >= tween.duration {
+		c.MoveTo(tween.targetX, tween.targetY)
+		return
+	}
+
+	easing := c.easing
+	if easing == nil {
+		easing = EaseInOut
+	}
+	progress := easing(tween.elapsed / tween.duration)
+	c.x = tween.startX + (tween.targetX-tween.startX)*progress
+	c.y = tween.startY + (tween.targetY-tween.startY)*progress
 }
 
 func (c *Camera) GetPosition() (float64, float64) {
 	return c.x, c.y
 }
+
+// Sets the scale the map is drawn at around the camera (eg: 0.5 shows twice as much of the map), clamped to the
+// supported zoom range
+func (c *Camera) SetZoom(factor float64) {
+	c.zoom = math.Max(minZoom, math.Min(maxZoom, factor))
+}
+
+// Returns the scale the map is drawn at (1=native size)
+func (c *Camera) GetZoom() float64 {
+	if c.zoom == 0 {
+		return 1
+	}
+	return c.zoom
+}
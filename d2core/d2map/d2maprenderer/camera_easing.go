@@ -0,0 +1,30 @@
+package d2maprenderer
+
+import "math"
+
+// How quickly the spring easing settles; higher values move more of the distance early on
+const springStiffness = 8
+
+// Moves the camera at a constant speed, starting and stopping abruptly (eg: snappy combat moves)
+func EaseLinear(t float64) float64 {
+	return t
+}
+
+// Accelerates over the first half of a move and decelerates over the second (quadratic). This is the default easing.
+func EaseInOut(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return 1 - 2*(1-t)*(1-t)
+}
+
+// Moves the camera like a critically damped spring pulling it toward the target: it sets off quickly and settles
+// gently, without overshooting (eg: gentle cutscene moves). The curve is scaled so the move still ends on the target.
+func EaseSpring(t float64) float64 {
+	return springResponse(t) / springResponse(1)
+}
+
+// Returns how far a critically damped spring has moved toward its target after t, starting at rest
+func springResponse(t float64) float64 {
+	return 1 - (1+springStiffness*t)*math.Exp(-springStiffness*t)
+}
@@ -0,0 +1,70 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+// Moves a camera 100 units along X over a second with an easing, returning its X position after each quarter second
+func easedCameraPositions(easing func(t float64) float64) []float64 {
+	camera := &Camera{}
+	camera.SetEasing(easing)
+	camera.MoveToSmooth(100, 0, 1)
+
+	var positions []float64
+	for i := 0; i < 4; i++ {
+		camera.Advance(0.25)
+		x, _ := camera.GetPosition()
+		positions = append(positions, x)
+	}
+	return positions
+}
+
+func TestCameraEasingIntermediatePositions(t *testing.T) {
+	assert := testify.New(t)
+
+	tests := []struct {
+		name     string
+		easing   func(t float64) float64
+		expected []float64
+	}{
+		{"linear", EaseLinear, []float64{25, 50, 75, 100}},
+		{"ease in out", EaseInOut, []float64{12.5, 50, 87.5, 100}},
+		{"spring", EaseSpring, []float64{59.5793, 91.1173, 98.5624, 100}},
+	}
+
+	for _, test := range tests {
+		positions := easedCameraPositions(test.easing)
+		for i, expected := range test.expected {
+			assert.InDelta(expected, positions[i], 0.0001, "%s at step %d", test.name, i)
+		}
+	}
+}
+
+func TestCameraEasingDefaultsToEaseInOut(t *testing.T) {
+	assert := testify.New(t)
+
+	assert.Equal(easedCameraPositions(EaseInOut), easedCameraPositions(nil))
+}
+
+func TestCameraEasingsStartAndEndOnTarget(t *testing.T) {
+	assert := testify.New(t)
+
+	for _, easing := range []func(t float64) float64{EaseLinear, EaseInOut, EaseSpring} {
+		assert.InDelta(0, easing(0), 0.0001)
+		assert.InDelta(1, easing(1), 0.0001)
+	}
+}
+
+func TestSpringEasingDoesNotOvershoot(t *testing.T) {
+	assert := testify.New(t)
+
+	previous := 0.0
+	for step := 1; step <= 100; step++ {
+		progress := EaseSpring(float64(step) / 100)
+		assert.True(progress >= previous)
+		assert.True(progress <= 1.0000001)
+		previous = progress
+	}
+}
@@ -0,0 +1,66 @@
+package d2maprenderer
+
+import (
+	"math"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+// Locks the camera onto an entity (eg: the player), centering the camera on it and following it as the renderer
+// advances, or releases the camera when the entity is nil. Following takes over from any smooth camera move in progress.
+func (mr *MapRenderer) SetCameraTarget(entity d2mapentity.MapEntity) {
+	mr.cameraTarget = entity
+	if entity != nil {
+		mr.MoveCameraTo(mr.viewport.WorldToOrtho(entityWorldPosition(entity)))
+	}
+}
+
+// Returns the entity the camera follows, or nil
+func (mr *MapRenderer) GetCameraTarget() d2mapentity.MapEntity {
+	return mr.cameraTarget
+}
+
+// Sets how far, in screen pixels, the followed entity can move from the center of the screen before the camera
+// follows it, so that small movements do not shake the view. The radius is measured on screen, so it covers less of
+// the map as the camera zooms in.
+func (mr *MapRenderer) SetCameraDeadZone(radius float64) {
+	mr.deadZone = math.Max(radius, 0)
+}
+
+// Returns the camera dead zone radius, in screen pixels
+func (mr *MapRenderer) GetCameraDeadZone() float64 {
+	return mr.deadZone
+}
+
+// Returns the world position of an entity, using its sub tile location when it has one so the camera moves smoothly
+func entityWorldPosition(entity d2mapentity.MapEntity) (float64, float64) {
+	if locatable, ok := entity.(d2mapentity.Locatable); ok {
+		x, y := locatable.GetLocation()
+		return x / 5, y / 5
+	}
+	return entity.GetPosition()
+}
+
+// Moves the camera just far enough to bring the followed entity back inside the dead zone
+func (mr *MapRenderer) followCameraTarget() {
+	if mr.cameraTarget == nil {
+		return
+	}
+
+	cameraX, cameraY := mr.camera.GetPosition()
+	if mr.camera.IsMoving() {
+		mr.camera.MoveTo(cameraX, cameraY)
+	}
+
+	targetX, targetY := mr.viewport.WorldToOrtho(entityWorldPosition(mr.cameraTarget))
+	offsetX, offsetY := targetX-cameraX, targetY-cameraY
+	distance := math.Hypot(offsetX, offsetY)
+	deadZone := mr.deadZone / mr.viewport.GetZoom()
+	if distance <= deadZone {
+		return
+	}
+
+	scale := (distance - deadZone) / distance
+	mr.camera.MoveTo(cameraX+offsetX*scale, cameraY+offsetY*scale)
+	mr.updateCameraFocus()
+}
@@ -0,0 +1,90 @@
+package d2maprenderer
+
+import (
+	"math"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+// Returns the distance, in ortho pixels, between the camera and the world position
+func cameraDistanceTo(mr *MapRenderer, worldX, worldY float64) float64 {
+	targetX, targetY := mr.WorldToOrtho(worldX, worldY)
+	cameraX, cameraY := mr.GetCamera().GetPosition()
+	return math.Hypot(targetX-cameraX, targetY-cameraY)
+}
+
+func TestCameraFollowsTarget(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(8, 8)
+	hero := d2mapentity.CreateMultiPartObject(10, 10, nil)
+
+	mr.SetCameraTarget(hero)
+	assert.InDelta(0, cameraDistanceTo(mr, 2, 2), 0.0001)
+
+	hero.SetPosition(17, 12)
+	mr.Advance(0.01)
+	assert.InDelta(0, cameraDistanceTo(mr, 3.4, 2.4), 0.0001)
+
+	mr.SetCameraTarget(nil)
+	hero.SetPosition(30, 30)
+	mr.Advance(0.01)
+	assert.InDelta(0, cameraDistanceTo(mr, 3.4, 2.4), 0.0001)
+}
+
+func TestCameraDeadZoneIgnoresSmallMovements(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(8, 8)
+	hero := d2mapentity.CreateMultiPartObject(10, 10, nil)
+	mr.SetCameraDeadZone(100)
+	mr.SetCameraTarget(hero)
+
+	// Half a tile along the world X axis is about 45 pixels away, inside the dead zone
+	hero.SetPosition(12.5, 10)
+	mr.Advance(0.01)
+	assert.InDelta(0, cameraDistanceTo(mr, 2, 2), 0.0001)
+
+	// Moving further pulls the camera along, leaving the entity at the edge of the dead zone
+	hero.SetPosition(35, 10)
+	mr.Advance(0.01)
+	assert.InDelta(100, cameraDistanceTo(mr, 7, 2), 0.0001)
+}
+
+func TestCameraDeadZoneIsMeasuredOnScreen(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(8, 8)
+	hero := d2mapentity.CreateMultiPartObject(10, 10, nil)
+	mr.SetCameraDeadZone(100)
+	mr.SetZoom(2)
+	mr.SetCameraTarget(hero)
+
+	// At twice the size, the dead zone covers 50 pixels of the unzoomed map
+	hero.SetPosition(15, 10)
+	mr.Advance(0.01)
+	assert.InDelta(50, cameraDistanceTo(mr, 3, 2), 0.0001)
+}
+
+func TestZoomAtKeepsFollowedTargetCentered(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(8, 8)
+	hero := d2mapentity.CreateMultiPartObject(10, 10, nil)
+	mr.SetCameraTarget(hero)
+
+	mr.ZoomAt(0, 0, 1)
+	mr.Advance(0.01)
+	assert.Equal(2.0, mr.GetZoom())
+	assert.InDelta(0, cameraDistanceTo(mr, 2, 2), 0.0001)
+}
+
+func TestCameraFollowTakesOverSmoothMove(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(8, 8)
+	mr.MoveCameraToSmooth(500, 500, 1)
+
+	mr.SetCameraTarget(d2mapentity.CreateMultiPartObject(10, 10, nil))
+	assert.False(mr.GetCamera().IsMoving())
+	mr.Advance(0.5)
+	assert.InDelta(0, cameraDistanceTo(mr, 2, 2), 0.0001)
+}
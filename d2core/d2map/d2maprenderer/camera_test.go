@@ -0,0 +1,114 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+func TestSmoothCameraMoveEasesToTarget(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	mr.MoveCameraToSmooth(100, -40, 1)
+	assert.True(mr.GetCamera().IsMoving())
+
+	mr.Advance(0.25)
+	x, y := mr.GetCamera().GetPosition()
+	assert.InDelta(12.5, x, 0.0001)
+	assert.InDelta(-5, y, 0.0001)
+
+	mr.Advance(0.25)
+	x, _ = mr.GetCamera().GetPosition()
+	assert.InDelta(50, x, 0.0001)
+
+	mr.Advance(0.6)
+	x, y = mr.GetCamera().GetPosition()
+	assert.Equal(100.0, x)
+	assert.Equal(-40.0, y)
+	assert.False(mr.GetCamera().IsMoving())
+}
+
+func TestSmoothCameraRetargetsFromCurrentPosition(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	mr.MoveCameraToSmooth(100, 0, 1)
+	mr.Advance(0.5)
+
+	mr.MoveCameraToSmooth(0, 100, 1)
+	x, y := mr.GetCamera().GetPosition()
+	assert.InDelta(50, x, 0.0001)
+	assert.InDelta(0, y, 0.0001)
+
+	// The new move starts where the old one was interrupted, rather than jumping to either end
+	mr.Advance(0.01)
+	x, y = mr.GetCamera().GetPosition()
+	assert.InDelta(50, x, 0.1)
+	assert.InDelta(0, y, 0.1)
+
+	mr.Advance(1)
+	x, y = mr.GetCamera().GetPosition()
+	assert.Equal(0.0, x)
+	assert.Equal(100.0, y)
+}
+
+func TestMoveCameraToCancelsSmoothMove(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	mr.MoveCameraToSmooth(100, 0, 1)
+
+	mr.MoveCameraTo(-20, 30)
+	assert.False(mr.GetCamera().IsMoving())
+	mr.Advance(1)
+	x, y := mr.GetCamera().GetPosition()
+	assert.Equal(-20.0, x)
+	assert.Equal(30.0, y)
+}
+
+func TestSmoothCameraUsesEasing(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	mr.GetCamera().SetEasing(func(t float64) float64 { return t })
+
+	mr.MoveCameraToSmooth(100, 0, 2)
+	mr.Advance(0.5)
+	x, _ := mr.GetCamera().GetPosition()
+	assert.InDelta(25, x, 0.0001)
+}
+
+func TestMoveCameraToSmoothWithoutDurationSnaps(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	mr.MoveCameraToSmooth(100, 0, 0)
+	assert.False(mr.GetCamera().IsMoving())
+	x, _ := mr.GetCamera().GetPosition()
+	assert.Equal(100.0, x)
+}
+
+func TestSetMapEngineCentersCameraOnStart(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	next := createTestMapRenderer(4, 4).mapEngine
+	next.TileAt(2, 3).Walls = []d2ds1.WallRecord{{Type: d2enum.SpecialTile1, Style: 30, Sequence: 0}}
+
+	mr.SetMapEngine(next)
+	expectedX, expectedY := mr.WorldToOrtho(2.5, 3.5)
+	x, y := mr.camera.GetPosition()
+	assert.Equal(expectedX, x)
+	assert.Equal(expectedY, y)
+}
+
+func TestSetMapEngineCentersCameraWithoutStart(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	mr.SetMapEngine(createTestMapRenderer(4, 2).mapEngine)
+
+	expectedX, expectedY := mr.WorldToOrtho(2, 1)
+	x, y := mr.camera.GetPosition()
+	assert.Equal(expectedX, x)
+	assert.Equal(expectedY, y)
+}
@@ -0,0 +1,78 @@
+package d2maprenderer
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// DebugStyle holds the colors used by the map debug visualization
+type DebugStyle struct {
+	TileColor          color.RGBA // The tile edges
+	SubTileColor       color.RGBA // The sub-tile grid lines
+	TileCollisionColor color.RGBA // The markers on sub-tiles that block walking
+	WalkableColor      color.RGBA // The fill of walkable sub-tiles, at the walkability level
+	BlockedColor       color.RGBA // The fill of sub-tiles that block walking, at the walkability level
+}
+
+// DefaultDebugStyle returns the default debug visualization colors
+func DefaultDebugStyle() DebugStyle {
+	return DebugStyle{
+		TileColor:          color.RGBA{R: 255, G: 255, B: 255, A: 100},
+		SubTileColor:       color.RGBA{R: 80, G: 80, B: 255, A: 50},
+		TileCollisionColor: color.RGBA{R: 128, G: 0, B: 0, A: 100},
+		WalkableColor:      color.RGBA{R: 0, G: 160, B: 0, A: 60},
+		BlockedColor:       color.RGBA{R: 160, G: 0, B: 0, A: 60},
+	}
+}
+
+// Sets the colors used by the debug visualization
+func (mr *MapRenderer) SetDebugStyle(style DebugStyle) {
+	mr.debugStyle = style
+}
+
+// Returns the colors used by the debug visualization
+func (mr *MapRenderer) GetDebugStyle() DebugStyle {
+	return mr.debugStyle
+}
+
+// Sets a single debug visualization color by name (tile, subtile, collision, walkable or blocked)
+func (mr *MapRenderer) SetDebugColor(name string, c color.RGBA) error {
+	switch strings.ToLower(name) {
+	case "tile":
+		mr.debugStyle.TileColor = c
+	case "subtile":
+		mr.debugStyle.SubTileColor = c
+	case "collision":
+		mr.debugStyle.TileCollisionColor = c
+	case "walkable":
+		mr.debugStyle.WalkableColor = c
+	case "blocked":
+		mr.debugStyle.BlockedColor = c
+	default:
+		return fmt.Errorf("unknown debug color: %s", name)
+	}
+
+	return nil
+}
+
+// Parses an RRGGBB or RRGGBBAA hex color
+func parseHexColor(value string) (color.RGBA, error) {
+	value = strings.TrimPrefix(value, "#")
+	if len(value) == 6 {
+		value += "ff"
+	}
+
+	if len(value) != 8 {
+		return color.RGBA{}, errors.New("expected an RRGGBB or RRGGBBAA color")
+	}
+
+	rgba, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+
+	return color.RGBA{R: uint8(rgba >> 24), G: uint8(rgba >> 16), B: uint8(rgba >> 8), A: uint8(rgba)}, nil
+}
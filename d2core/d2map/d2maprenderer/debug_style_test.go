@@ -0,0 +1,49 @@
+package d2maprenderer
+
+import (
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestDebugColorOverrideChangesDrawCalls(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	mr.debugVisLevel = 2
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	lines := target.callsOf("line")
+	assert.Equal(DefaultDebugStyle().TileColor, lines[0].color)
+	assert.Equal(DefaultDebugStyle().SubTileColor, lines[2].color)
+
+	assert.Nil(mr.SetDebugColor("tile", color.RGBA{G: 255, A: 255}))
+	assert.Nil(mr.SetDebugColor("SubTile", color.RGBA{R: 255, A: 255}))
+	assert.NotNil(mr.SetDebugColor("walls", color.RGBA{}))
+
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+	lines = target.callsOf("line")
+	assert.Equal(color.RGBA{G: 255, A: 255}, lines[0].color)
+	assert.Equal(color.RGBA{G: 255, A: 255}, lines[1].color)
+	assert.Equal(color.RGBA{R: 255, A: 255}, lines[2].color)
+	assert.Equal(DefaultDebugStyle().TileCollisionColor, mr.GetDebugStyle().TileCollisionColor)
+}
+
+func TestParseHexColor(t *testing.T) {
+	assert := testify.New(t)
+
+	c, err := parseHexColor("#ff8000")
+	assert.Nil(err)
+	assert.Equal(color.RGBA{R: 255, G: 128, A: 255}, c)
+
+	c, err = parseHexColor("10203040")
+	assert.Nil(err)
+	assert.Equal(color.RGBA{R: 16, G: 32, B: 48, A: 64}, c)
+
+	_, err = parseHexColor("fff")
+	assert.NotNil(err)
+	_, err = parseHexColor("gggggg")
+	assert.NotNil(err)
+}
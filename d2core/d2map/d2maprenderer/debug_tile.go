@@ -0,0 +1,91 @@
+package d2maprenderer
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// Selects a single tile to inspect. While a tile is selected, only that tile draws the detailed debug overlay (walls,
+// sub-tiles and collision), and the other tiles draw at most the tile grid.
+func (mr *MapRenderer) SelectDebugTile(tileX, tileY int) {
+	mr.debugTile = &image.Point{X: tileX, Y: tileY}
+}
+
+// Selects the tile under the screen position (eg: a mouse click) to inspect
+func (mr *MapRenderer) SelectDebugTileAt(screenX, screenY int) {
+	mr.SelectDebugTile(mr.tileAtScreen(screenX, screenY))
+}
+
+// Clears the selected debug tile, so the debug overlay is drawn for every tile again
+func (mr *MapRenderer) ClearDebugTile() {
+	mr.debugTile = nil
+}
+
+// Returns the selected debug tile, and false if no tile is selected
+func (mr *MapRenderer) GetDebugTile() (int, int, bool) {
+	if mr.debugTile == nil {
+		return 0, 0, false
+	}
+	return mr.debugTile.X, mr.debugTile.Y, true
+}
+
+// Sets whether the sub-tile debug visualization labels each sub-tile with its (x, y) index within the tile, to check
+// the walk mesh indexing
+func (mr *MapRenderer) SetDebugSubTileLabels(enabled bool) {
+	mr.subTileLabels = enabled
+}
+
+// Returns the offset of a sub-tile's top corner from the top corner of its tile, in pixels
+func (v *Viewport) subTileIsoOffset(subTileX, subTileY int) (int, int) {
+	return v.subTileScreenOffset(float64(subTileX), float64(subTileY))
+}
+
+// Returns the size of a sub-tile's diamond, in pixels
+func (v *Viewport) subTileDiamondSize() (width, height int) {
+	return int(math.Round(v.tileHalfWidth * 2 / subTilesPerTile)), int(math.Round(v.tileHalfHeight * 2 / subTilesPerTile))
+}
+
+// Returns the debug visualization level a tile is drawn with
+func (mr *MapRenderer) tileDebugLevel(tileX, tileY, debugVisLevel int) int {
+	if mr.debugTile == nil {
+		return debugVisLevel
+	}
+
+	if tileX == mr.debugTile.X && tileY == mr.debugTile.Y {
+		return d2common.MaxInt(debugVisLevel, 2)
+	}
+
+	return d2common.MinInt(debugVisLevel, 1)
+}
+
+// Returns the index of a sub-tile in the walk mesh of a map of the specified width, in tiles
+func walkMeshIndex(mapWidth, tileX, tileY, subTileX, subTileY int) int {
+	return ((subTileY + tileY*5) * mapWidth * 5) + subTileX + tileX*5
+}
+
+// Returns true if the walk mesh allows walking on a sub-tile
+func (mr *MapRenderer) isSubTileWalkable(tileX, tileY, subTileX, subTileY int) bool {
+	walkMesh := *mr.mapEngine.WalkMesh()
+	index := walkMeshIndex(mr.mapEngine.Size().Width, tileX, tileY, subTileX, subTileY)
+	if index < 0 || index >= len(walkMesh) {
+		return false
+	}
+	return walkMesh[index].Walkable
+}
+
+// Fills the diamond of a sub-tile of the specified size, whose top corner is at the offset, one row of pixels at a time
+func fillSubTileDiamond(isoX, isoY, width, height int, c color.Color, target d2render.Surface) {
+	for row := 0; row < height; row++ {
+		halfWidth := (row + 1) * width / height
+		if row >= height/2 {
+			halfWidth = (height - row) * width / height
+		}
+		target.PushTranslation(isoX-halfWidth, isoY+row)
+		target.DrawRect(halfWidth*2, 1, c)
+		target.Pop()
+	}
+}
@@ -0,0 +1,139 @@
+package d2maprenderer
+
+import (
+	"fmt"
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestSelectedDebugTileOnlyDrawsDetailedOverlay(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(3, 3)
+	mr.MoveCameraTo(mr.viewport.WorldToOrtho(1.5, 1.5))
+	mr.debugVisLevel = 2
+
+	// Without a selection every tile draws the collision of all 25 of its (unwalkable) sub-tiles
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Len(target.callsOf("rect"), 9*25)
+
+	mr.SelectDebugTile(1, 2)
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+
+	// Only the selected tile draws its sub-tile grid and collision, every tile still draws its outline
+	assert.Len(target.callsOf("rect"), 25)
+	assert.Len(target.callsOf("line"), 9*2+8)
+	screenX, screenY := mr.viewport.WorldToScreen(1, 2)
+	for _, rect := range target.callsOf("rect") {
+		assert.True(rect.x >= screenX-80 && rect.x <= screenX+80)
+		assert.True(rect.y >= screenY && rect.y <= screenY+80)
+	}
+}
+
+func TestSelectedDebugTileShownAtTileGridLevel(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(2, 2)
+	mr.debugVisLevel = 1
+	mr.SelectDebugTile(0, 0)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Len(target.callsOf("rect"), 25)
+}
+
+func TestSelectDebugTileAtScreenPosition(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+	mr.MoveCameraTo(mr.viewport.WorldToOrtho(5, 5))
+
+	_, _, selected := mr.GetDebugTile()
+	assert.False(selected)
+
+	screenX, screenY := mr.viewport.WorldToScreen(3.5, 7.5)
+	mr.SelectDebugTileAt(screenX, screenY)
+	tileX, tileY, selected := mr.GetDebugTile()
+	assert.True(selected)
+	assert.Equal(3, tileX)
+	assert.Equal(7, tileY)
+
+	mr.ClearDebugTile()
+	_, _, selected = mr.GetDebugTile()
+	assert.False(selected)
+}
+
+func TestDebugSubTileLabelsDrawnAtSubTilePositions(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	mr.debugVisLevel = 2
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Equal(-1, indexOfText(target, "0,0"))
+
+	mr.SetDebugSubTileLabels(true)
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+
+	// The tile's top corner is at the center of the screen, and each step along a sub-tile axis is 16x8 pixels
+	assert.Len(target.callsOf("text"), 1+25)
+	for subTileY := 0; subTileY < 5; subTileY++ {
+		for subTileX := 0; subTileX < 5; subTileX++ {
+			index := indexOfText(target, fmt.Sprintf("%d,%d", subTileX, subTileY))
+			if !assert.NotEqual(-1, index) {
+				continue
+			}
+			label := target.calls[index]
+			assert.Equal(400+(subTileX-subTileY)*16-6, label.x)
+			assert.Equal(300+(subTileX+subTileY)*8+2, label.y)
+		}
+	}
+}
+
+func TestWalkabilityDebugLevelFillsSubTiles(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	mr.mapEngine.RegenerateWalkPaths()
+	(*mr.mapEngine.WalkMesh())[walkMeshIndex(1, 0, 0, 2, 3)].Walkable = false
+	mr.debugVisLevel = 3
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	// Every sub-tile is filled, rather than only the blocked ones getting a marker
+	var walkableRows, blockedRows []testDrawCall
+	for _, rect := range target.callsOf("rect") {
+		switch rect.color {
+		case color.Color(mr.debugStyle.WalkableColor):
+			walkableRows = append(walkableRows, rect)
+		case color.Color(mr.debugStyle.BlockedColor):
+			blockedRows = append(blockedRows, rect)
+		}
+	}
+	_, subTileHeight := mr.viewport.subTileDiamondSize()
+	assert.Len(walkableRows, 24*subTileHeight)
+	assert.Len(blockedRows, subTileHeight)
+	assert.Len(target.callsOf("rect"), 25*subTileHeight)
+
+	// The blocked rows form the diamond of sub-tile 2,3, widest across its middle
+	tileX, tileY := mr.viewport.WorldToScreen(0, 0)
+	isoX, isoY := mr.viewport.subTileIsoOffset(2, 3)
+	assert.Equal(tileY+isoY, blockedRows[0].y)
+	assert.Equal(tileX+isoX-2, blockedRows[0].x)
+	assert.Equal(4, blockedRows[0].width)
+	assert.Equal(32, blockedRows[7].width)
+	assert.Equal(32, blockedRows[8].width)
+	assert.Equal(tileY+isoY+subTileHeight-1, blockedRows[15].y)
+	assert.Equal(4, blockedRows[15].width)
+}
+
+func TestWalkMeshIndex(t *testing.T) {
+	assert := testify.New(t)
+
+	assert.Equal(0, walkMeshIndex(3, 0, 0, 0, 0))
+	assert.Equal(4, walkMeshIndex(3, 0, 0, 4, 0))
+	assert.Equal(15, walkMeshIndex(3, 0, 0, 0, 1))
+	assert.Equal((7*15)+5+3, walkMeshIndex(3, 1, 1, 3, 2))
+}
@@ -0,0 +1,67 @@
+package d2maprenderer
+
+import (
+	"image"
+	"sort"
+)
+
+// Returns the screen space rectangle the renderer uses to cull a tile. It is generous enough to include the walls
+// drawn above the tile's floor diamond.
+func (v *Viewport) tileScreenBounds(x, y float64) image.Rectangle {
+	screenX1, screenY1 := v.OrthoToScreen(v.WorldToOrtho(x-tileVisibilityPadding, y))
+	screenX2, screenY2 := v.OrthoToScreen(v.WorldToOrtho(x+tileVisibilityPadding, y))
+	return image.Rect(screenX1, screenY1, screenX2, screenY2)
+}
+
+// Returns the tiles the renderer would draw within the screen region, as (tileX, tileY, pass) in the order they are
+// drawn. Each pass (1=floors and lower walls, 2=upper walls, 3=roofs) draws every visible tile on the ground before the
+// next begins. The raised tiles follow the ground's second pass one level at a time from the lowest up, each level
+// drawing its first and then its second pass, and every tile's roof is drawn in the third pass.
+func (mr *MapRenderer) TileDrawOrder(rect image.Rectangle) [][3]int {
+	rect = rect.Canon()
+	minX, minY, maxX, maxY := mr.visibleTileBounds(mr.viewport)
+
+	var tiles, ground [][2]int
+	var elevated []elevatedTile
+	for tileY := minY; tileY <= maxY; tileY++ {
+		for tileX := minX; tileX <= maxX; tileX++ {
+			if !mr.viewport.IsTileVisible(float64(tileX), float64(tileY)) {
+				continue
+			}
+			if !mr.viewport.tileScreenBounds(float64(tileX), float64(tileY)).Overlaps(rect) {
+				continue
+			}
+			tiles = append(tiles, [2]int{tileX, tileY})
+			if elevation := mr.mapEngine.TileElevation(tileX, tileY); elevation != 0 {
+				elevated = append(elevated, elevatedTile{tileX: tileX, tileY: tileY, elevation: elevation})
+			} else {
+				ground = append(ground, [2]int{tileX, tileY})
+			}
+		}
+	}
+	sort.SliceStable(elevated, func(i, j int) bool { return elevated[i].elevation < elevated[j].elevation })
+
+	result := make([][3]int, 0, len(tiles)*3)
+	for pass := 1; pass <= 2; pass++ {
+		for _, tile := range ground {
+			result = append(result, [3]int{tile[0], tile[1], pass})
+		}
+	}
+	for start := 0; start < len(elevated); {
+		end := start + 1
+		for end < len(elevated) && elevated[end].elevation == elevated[start].elevation {
+			end++
+		}
+		for pass := 1; pass <= 2; pass++ {
+			for _, raised := range elevated[start:end] {
+				result = append(result, [3]int{raised.tileX, raised.tileY, pass})
+			}
+		}
+		start = end
+	}
+	for _, tile := range tiles {
+		result = append(result, [3]int{tile[0], tile[1], 3})
+	}
+
+	return result
+}
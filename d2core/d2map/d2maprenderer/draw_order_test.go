@@ -0,0 +1,89 @@
+package d2maprenderer
+
+import (
+	"image"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// Renders a 3x3 map, returning the order TileDrawOrder reports and the order the renderer drew the tiles in
+func renderTestDrawOrder(setup func(mr *MapRenderer)) (expected, actual [][3]int) {
+	defer InvalidateImageCache()
+
+	mr := createTestMapRenderer(3, 3)
+	mr.camera.MoveTo(mr.viewport.WorldToOrtho(1.5, 1.5))
+	setup(mr)
+
+	// Every tile draws a distinct image in each pass, so the render calls identify the tile and pass
+	drawn := make(map[d2render.Surface][3]int)
+	passTypes := []d2enum.TileType{d2enum.Floor, d2enum.LeftWall, d2enum.Roof}
+	for tileY := 0; tileY < 3; tileY++ {
+		for tileX := 0; tileX < 3; tileX++ {
+			style := byte(1 + tileX + tileY*3)
+			for pass, tileType := range passTypes {
+				tileImage := createTestSurface(160, 80)
+				mr.setImageCacheRecord(style, 1, tileType, 0, tileImage)
+				drawn[tileImage] = [3]int{tileX, tileY, pass + 1}
+			}
+
+			tile := mr.mapEngine.TileAt(tileX, tileY)
+			tile.Floors = []d2ds1.FloorShadowRecord{{Style: style, Sequence: 1, Prop1: 1}}
+			tile.Walls = []d2ds1.WallRecord{
+				{Type: d2enum.LeftWall, Style: style, Sequence: 1, Prop1: 1},
+				{Type: d2enum.Roof, Style: style, Sequence: 1, Prop1: 1},
+			}
+		}
+	}
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	for _, call := range target.callsOf("render") {
+		actual = append(actual, drawn[call.source])
+	}
+
+	return mr.TileDrawOrder(image.Rect(0, 0, 800, 600)), actual
+}
+
+func TestTileDrawOrderMatchesRenderPasses(t *testing.T) {
+	assert := testify.New(t)
+
+	expected, actual := renderTestDrawOrder(func(mr *MapRenderer) {})
+	assert.Len(expected, 27)
+	assert.Equal(expected, actual)
+}
+
+func TestTileDrawOrderMatchesElevatedTiles(t *testing.T) {
+	assert := testify.New(t)
+
+	expected, actual := renderTestDrawOrder(func(mr *MapRenderer) {
+		mr.mapEngine.SetTileElevation(2, 0, 80)
+		mr.mapEngine.SetTileElevation(1, 1, 40)
+	})
+	assert.Len(expected, 27)
+	assert.Equal(expected, actual)
+
+	// The raised tiles are drawn after the ground's upper walls, the lowest level first, and roofs come last
+	assert.Equal([][3]int{{1, 1, 1}, {1, 1, 2}, {2, 0, 1}, {2, 0, 2}}, expected[14:18])
+	assert.Equal([3]int{0, 0, 3}, expected[18])
+}
+
+func TestTileDrawOrderLimitedToScreenRegion(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(9, 1)
+
+	order := mr.TileDrawOrder(image.Rect(0, 0, 800, 600))
+	assert.Len(order, 8*3)
+
+	order = mr.TileDrawOrder(image.Rect(400, 600, 0, 0))
+	assert.Equal([][3]int{
+		{0, 0, 1}, {1, 0, 1}, {2, 0, 1},
+		{0, 0, 2}, {1, 0, 2}, {2, 0, 2},
+		{0, 0, 3}, {1, 0, 3}, {2, 0, 3},
+	}, order)
+}
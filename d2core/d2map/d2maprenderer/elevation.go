@@ -0,0 +1,50 @@
+package d2maprenderer
+
+import (
+	"sort"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// elevatedTile is a visible tile raised above the ground plane
+type elevatedTile struct {
+	tileX, tileY int
+	elevation    int
+	tile         *d2ds1.TileRecord
+}
+
+// Renders the floors, walls and entities of the raised tiles once the ground level has been drawn, so that a bridge
+// covers the ground and anything walking beneath it. The tiles are drawn one level at a time from the lowest up, and
+// within a level back to front, like the ground. Roofs are drawn with the ground's in the third pass.
+func (mr *MapRenderer) renderElevatedTiles(viewport *Viewport, target d2render.Surface) {
+	minX, minY, maxX, maxY := mr.visibleTileBounds(viewport)
+	tiles := mr.mapEngine.TilesInRect(minX, minY, maxX, maxY)
+
+	var elevated []elevatedTile
+	for tileY := minY; tileY <= maxY; tileY++ {
+		for tileX := minX; tileX <= maxX; tileX++ {
+			elevation := mr.mapEngine.TileElevation(tileX, tileY)
+			if elevation != 0 && viewport.IsTileVisible(float64(tileX), float64(tileY)) {
+				elevated = append(elevated, elevatedTile{tileX, tileY, elevation, tiles[tileY-minY][tileX-minX]})
+			}
+		}
+	}
+	sort.SliceStable(elevated, func(i, j int) bool { return elevated[i].elevation < elevated[j].elevation })
+
+	for start := 0; start < len(elevated); {
+		end := start + 1
+		for end < len(elevated) && elevated[end].elevation == elevated[start].elevation {
+			end++
+		}
+
+		level := elevated[start:end]
+		for _, raised := range level {
+			mr.renderTileInPass1(raised.tileX, raised.tileY, raised.tile, viewport, target)
+		}
+		for _, raised := range level {
+			mr.renderTileInPass2(raised.tileX, raised.tileY, raised.tile, viewport, target)
+		}
+		start = end
+	}
+}
@@ -0,0 +1,98 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+// createTestBridgeRenderer creates a renderer for a 3x3 map of floors, with a bridge floor on the center tile and an
+// upper wall on the tile in front of it. An entity stands on the bridge, and another on the ground beside it.
+func createTestBridgeRenderer() (mr *MapRenderer, bridge, wall *testSurface) {
+	mr = createTestMapRenderer(3, 3)
+	ground := createTestSurface(160, 80)
+	bridge = createTestSurface(160, 80)
+	wall = createTestSurface(160, 80)
+	mr.setImageCacheRecord(1, 1, d2enum.Floor, 0, ground)
+	mr.setImageCacheRecord(2, 1, d2enum.Floor, 0, bridge)
+	mr.setImageCacheRecord(3, 1, d2enum.LeftWall, 0, wall)
+
+	for i := range *mr.mapEngine.Tiles() {
+		(*mr.mapEngine.Tiles())[i].Floors = []d2ds1.FloorShadowRecord{{Style: 1, Sequence: 1, Prop1: 1}}
+	}
+	mr.mapEngine.TileAt(1, 1).Floors = []d2ds1.FloorShadowRecord{{Style: 2, Sequence: 1, Prop1: 1}}
+	mr.mapEngine.TileAt(2, 2).Walls = []d2ds1.WallRecord{{Type: d2enum.LeftWall, Style: 3, Sequence: 1}}
+
+	mr.mapEngine.AddEntity(createTestEntity("walker", 1, 1))
+	mr.mapEngine.AddEntity(createTestEntity("beside", 2, 1))
+	mr.MoveCameraTo(mr.WorldToOrtho(1.5, 1.5))
+	return mr, bridge, wall
+}
+
+func TestElevatedTileRendersHigher(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+
+	mr, bridge, _ := createTestBridgeRenderer()
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	groundFloor := target.calls[indexOfRender(target, bridge)]
+	groundWalker := target.calls[indexOfText(target, "entity:walker")]
+
+	mr.mapEngine.SetTileElevation(1, 1, 40)
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+	raisedFloor := target.calls[indexOfRender(target, bridge)]
+	raisedWalker := target.calls[indexOfText(target, "entity:walker")]
+
+	assert.Equal(groundFloor.x, raisedFloor.x)
+	assert.Equal(groundFloor.y-40, raisedFloor.y)
+	assert.Equal(groundWalker.x, raisedWalker.x)
+	assert.Equal(groundWalker.y-40, raisedWalker.y)
+
+	// The entity beside the bridge stays on the ground
+	beside := target.calls[indexOfText(target, "entity:beside")]
+	assert.Equal(groundWalker.y+40, beside.y)
+}
+
+func TestElevatedTileSortsAboveGroundLevel(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+
+	// On the ground, the walker is drawn back to front with the tiles in front of it
+	mr, bridge, wall := createTestBridgeRenderer()
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.True(indexOfText(target, "entity:walker") < indexOfText(target, "entity:beside"))
+	assert.True(indexOfText(target, "entity:walker") < indexOfRender(target, wall))
+
+	// Raised, the bridge and the walker are drawn over all of the ground level, including the entities and walls
+	// in front of it
+	mr.mapEngine.SetTileElevation(1, 1, 40)
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+	bridgeIndex := indexOfRender(target, bridge)
+	walkerIndex := indexOfText(target, "entity:walker")
+	assert.True(indexOfText(target, "entity:beside") < bridgeIndex)
+	assert.True(indexOfRender(target, wall) < bridgeIndex)
+	assert.True(bridgeIndex < walkerIndex)
+}
+
+func TestElevatedTilesDrawLowestLevelFirst(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(3, 1)
+	for x := 0; x < 3; x++ {
+		mr.mapEngine.AddEntity(createTestEntity(string(rune('a'+x)), float64(x), 0))
+	}
+	mr.mapEngine.SetTileElevation(0, 0, 80)
+	mr.mapEngine.SetTileElevation(1, 0, 20)
+	mr.mapEngine.SetTileElevation(2, 0, 20)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.True(indexOfText(target, "entity:b") < indexOfText(target, "entity:c"))
+	assert.True(indexOfText(target, "entity:c") < indexOfText(target, "entity:a"))
+}
@@ -0,0 +1,110 @@
+package d2maprenderer
+
+import (
+	"math"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+// entityDraw is an entity queued to be drawn on a tile, with the keys the draws are ordered by
+type entityDraw struct {
+	entity d2mapentity.MapEntity
+	key    interface{} // The sprite frame the entity draws (nil=unknown)
+	depth  int         // The sub-tile row the entity's feet are on, drawn back to front
+	feetX  float64     // The position of the entity's feet, in sub-tiles
+	feetY  float64     // The position of the entity's feet, in sub-tiles
+	group  int         // The index of the first entity at the same depth that draws the same sprite frame
+}
+
+// Returns the position of an entity's feet, in sub-tiles: its location offset by its sort origin. Entities without a
+// sub-tile location use their world position.
+func entityFeet(entity d2mapentity.MapEntity) (x, y float64) {
+	if locatable, ok := entity.(d2mapentity.Locatable); ok {
+		x, y = locatable.GetLocation()
+	} else {
+		worldX, worldY := entity.GetPosition()
+		x, y = worldX*5, worldY*5
+	}
+
+	if sortable, ok := entity.(d2mapentity.Sortable); ok {
+		originX, originY := sortable.GetSortOrigin()
+		x, y = x+originX, y+originY
+	}
+	return x, y
+}
+
+// Returns the sub-tile row an entity's feet are on. Entities on higher rows are nearer to the camera.
+func entityDepth(entity d2mapentity.MapEntity) int {
+	x, y := entityFeet(entity)
+	return int(math.Floor(x)) + int(math.Floor(y))
+}
+
+// Returns the entities on a tile that are drawn in a render layer, in the order they are drawn. The entities are
+// drawn back to front by sub-tile row, then by the Y and X of their feet. The entities on a row that draw the same
+// sprite frame are drawn one after another, where the first of them would be drawn, so that their draws can be batched.
+func (mr *MapRenderer) orderEntityDraws(tileX, tileY int, layer d2enum.EntityRenderLayer) []entityDraw {
+	draws := mr.entityDraws[:0]
+	for _, entity := range mr.mapEngine.EntitiesAt(tileX, tileY) {
+		if entity.GetRenderLayer() != layer {
+			continue
+		}
+		if mr.entityBudget != nil && !mr.entityBudget[entity] {
+			continue
+		}
+
+		draw := entityDraw{entity: entity}
+		draw.feetX, draw.feetY = entityFeet(entity)
+		draw.depth = int(math.Floor(draw.feetX)) + int(math.Floor(draw.feetY))
+		if batchable, ok := entity.(d2mapentity.Batchable); ok {
+			draw.key = batchable.BatchKey()
+		}
+		draws = append(draws, draw)
+	}
+
+	// Stable insertion sorts, as a tile only holds a few entities: first by position, then by row and batch
+	for i := 1; i < len(draws); i++ {
+		for j := i; j > 0 && feetBefore(draws[j], draws[j-1]); j-- {
+			draws[j], draws[j-1] = draws[j-1], draws[j]
+		}
+	}
+
+	for i := range draws {
+		draws[i].group = i
+		if draws[i].key == nil {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if draws[j].depth == draws[i].depth && draws[j].key == draws[i].key {
+				draws[i].group = draws[j].group
+				break
+			}
+		}
+	}
+
+	for i := 1; i < len(draws); i++ {
+		for j := i; j > 0 && drawsBefore(draws[j], draws[j-1]); j-- {
+			draws[j], draws[j-1] = draws[j-1], draws[j]
+		}
+	}
+
+	mr.entityDraws = draws
+	return draws
+}
+
+func feetBefore(a, b entityDraw) bool {
+	if a.depth != b.depth {
+		return a.depth < b.depth
+	}
+	if a.feetY != b.feetY {
+		return a.feetY < b.feetY
+	}
+	return a.feetX < b.feetX
+}
+
+func drawsBefore(a, b entityDraw) bool {
+	if a.depth != b.depth {
+		return a.depth < b.depth
+	}
+	return a.group < b.group
+}
@@ -0,0 +1,219 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// spriteEntity is a map entity at a sub-tile location that draws a single sprite frame
+type spriteEntity struct {
+	*testEntity
+	locationX, locationY float64
+	sprite               *testSurface
+}
+
+func createSpriteEntity(name string, locationX, locationY float64, sprite *testSurface) *spriteEntity {
+	return &spriteEntity{
+		testEntity: createTestEntity(name, float64(int(locationX/5)), float64(int(locationY/5))),
+		locationX:  locationX,
+		locationY:  locationY,
+		sprite:     sprite,
+	}
+}
+
+func (e *spriteEntity) Render(target d2render.Surface) {
+	_ = target.Render(e.sprite)
+	target.DrawText("%s", e.name)
+}
+
+func (e *spriteEntity) GetLocation() (float64, float64) {
+	return e.locationX, e.locationY
+}
+
+func (e *spriteEntity) BatchKey() interface{} {
+	return e.sprite
+}
+
+// tallEntity is a sprite entity whose feet are offset from its location
+type tallEntity struct {
+	*spriteEntity
+	originX, originY float64
+}
+
+func (e *tallEntity) GetSortOrigin() (float64, float64) {
+	return e.originX, e.originY
+}
+
+// Returns the number of times consecutive render calls switch to a different source image
+func countSourceSwitches(target *testSurface) int {
+	switches := 0
+	var previous d2render.Surface
+	for _, call := range target.callsOf("render") {
+		if call.source != previous {
+			switches++
+			previous = call.source
+		}
+	}
+	return switches
+}
+
+// Creates a map with a swarm of monsters drawn with a few alternating sprites, several of them on each sub-tile row
+func createTestSwarm(tiles, perTile int) (*MapRenderer, []*testSurface) {
+	mr := createTestMapRenderer(tiles, tiles)
+	mr.MoveCameraTo(mr.viewport.WorldToOrtho(float64(tiles)/2, float64(tiles)/2))
+
+	sprites := []*testSurface{createTestSurface(1, 1), createTestSurface(1, 1), createTestSurface(1, 1)}
+	for tileY := 0; tileY < tiles; tileY++ {
+		for tileX := 0; tileX < tiles; tileX++ {
+			for i := 0; i < perTile; i++ {
+				locationX := float64(tileX*5 + i%5)
+				locationY := float64(tileY*5 + (i/5)%5)
+				mr.mapEngine.AddEntity(createSpriteEntity("monster", locationX, locationY, sprites[i%len(sprites)]))
+			}
+		}
+	}
+
+	return mr, sprites
+}
+
+func TestEntityDrawsAreBatchedWithinDepth(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	goblin := createTestSurface(1, 1)
+	skeleton := createTestSurface(1, 1)
+
+	add := func(name string, x, y float64, sprite *testSurface) {
+		mr.mapEngine.AddEntity(createSpriteEntity(name, x, y, sprite))
+	}
+	add("goblin1", 1, 1, goblin)
+	add("skeleton1", 2, 0, skeleton)
+	add("goblin2", 0, 2, goblin)
+	add("goblin3", 3, 3, goblin)
+	add("skeleton2", 0.5, 0.5, skeleton)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	// The entities are drawn back to front by sub-tile row, and the goblins on row 2 are drawn together, after the
+	// skeleton behind them
+	var order []string
+	for _, call := range target.callsOf("text") {
+		order = append(order, call.text)
+	}
+	assert.Equal([]string{"skeleton2", "skeleton1", "goblin1", "goblin2", "goblin3"}, order)
+}
+
+func TestSwarmBatchingReducesSourceSwitches(t *testing.T) {
+	assert := testify.New(t)
+	mr, _ := createTestSwarm(2, 12)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	draws := target.callsOf("render")
+	assert.Len(draws, 4*12)
+	assert.True(countSourceSwitches(target) < len(draws))
+
+	// Every draw still happens back to front within its tile
+	for tileY := 0; tileY < 2; tileY++ {
+		for tileX := 0; tileX < 2; tileX++ {
+			previous := -1
+			for _, draw := range mr.orderEntityDraws(tileX, tileY, d2enum.EntityRenderLayerNormal) {
+				assert.True(draw.depth >= previous)
+				previous = draw.depth
+			}
+		}
+	}
+}
+
+func BenchmarkRenderSwarm(b *testing.B) {
+	mr, _ := createTestSwarm(8, 12)
+	target := createTestSurface(1, 1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		target.calls = target.calls[:0]
+		mr.Render(target)
+	}
+	b.StopTimer()
+
+	b.Logf("%d source switches for %d entity draws", countSourceSwitches(target), len(target.callsOf("render")))
+}
+
+func TestEntitiesSortByFeetPosition(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	sprite := createTestSurface(1, 1)
+
+	// The tall entity's location is its sprite top, two rows behind the short entity, but its feet are two rows in front
+	tall := &tallEntity{spriteEntity: createSpriteEntity("tall", 1, 1, sprite), originX: 2, originY: 2}
+	short := createSpriteEntity("short", 2, 2, sprite)
+	mr.mapEngine.AddEntity(tall)
+	mr.mapEngine.AddEntity(short)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.True(indexOfText(target, "short") < indexOfText(target, "tall"))
+
+	tall.originX, tall.originY = 0, 0
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+	assert.True(indexOfText(target, "tall") < indexOfText(target, "short"))
+}
+
+func TestMapEntitySortOriginOffsetsDepth(t *testing.T) {
+	assert := testify.New(t)
+	object := d2mapentity.CreateMultiPartObject(3, 4, nil)
+	assert.Equal(7, entityDepth(object))
+
+	object.SetSortOrigin(1.5, 2)
+	assert.Equal(10, entityDepth(object))
+}
+
+func TestEntitiesOnARowSortByFeetYThenX(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	// Each entity draws its own sprite, so none are batched. All of them are on sub-tile row 4.
+	for _, entity := range []*spriteEntity{
+		createSpriteEntity("front", 1.5, 3.2, createTestSurface(1, 1)),
+		createSpriteEntity("back", 4, 0.1, createTestSurface(1, 1)),
+		createSpriteEntity("middle-right", 2.5, 2, createTestSurface(1, 1)),
+		createSpriteEntity("middle-left", 2, 2, createTestSurface(1, 1)),
+	} {
+		mr.mapEngine.AddEntity(entity)
+	}
+
+	var order []string
+	for _, draw := range mr.orderEntityDraws(0, 0, d2enum.EntityRenderLayerNormal) {
+		order = append(order, draw.entity.(*spriteEntity).name)
+	}
+	assert.Equal([]string{"back", "middle-left", "middle-right", "front"}, order)
+}
+
+func TestEntitiesWithoutLocationSortByWorldPosition(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	mr.mapEngine.AddEntity(createTestEntity("front", 0.5, 0.5))
+	mr.mapEngine.AddEntity(createTestEntity("back", 0.1, 0.1))
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.True(indexOfText(target, "entity:back") < indexOfText(target, "entity:front"))
+}
+
+func TestOrderingEntityDrawsDoesNotAllocate(t *testing.T) {
+	assert := testify.New(t)
+	mr, _ := createTestSwarm(1, 12)
+	mr.orderEntityDraws(0, 0, d2enum.EntityRenderLayerNormal)
+
+	allocations := testing.AllocsPerRun(10, func() {
+		mr.orderEntityDraws(0, 0, d2enum.EntityRenderLayerNormal)
+	})
+	assert.Equal(0.0, allocations)
+}
@@ -0,0 +1,59 @@
+package d2maprenderer
+
+import (
+	"sort"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+// A set of map entities
+type entitySet map[d2mapentity.MapEntity]bool
+
+// Sets the maximum number of entities rendered per frame, or 0 for no limit. When more entities are visible, only
+// those nearest the center of the camera are rendered; the rest are skipped for the frame, not removed.
+func (mr *MapRenderer) SetMaxRenderedEntities(n int) {
+	if n < 0 {
+		n = 0
+	}
+	mr.maxEntities = n
+}
+
+// Returns the maximum number of entities rendered per frame (0=no limit)
+func (mr *MapRenderer) GetMaxRenderedEntities() int {
+	return mr.maxEntities
+}
+
+// Selects the entities that will be rendered this frame. Returns nil when every visible entity can be rendered.
+func (mr *MapRenderer) selectRenderedEntities() entitySet {
+	if mr.maxEntities <= 0 {
+		return nil
+	}
+
+	var visible []d2mapentity.MapEntity
+	for _, entity := range *mr.mapEngine.Entities() {
+		x, y := entity.GetPosition()
+		if mr.viewport.IsTileVisible(float64(int(x)), float64(int(y))) {
+			visible = append(visible, entity)
+		}
+	}
+
+	if len(visible) <= mr.maxEntities {
+		return nil
+	}
+
+	centerX, centerY := mr.viewport.OrthoToWorld(mr.camera.GetPosition())
+	distance := func(entity d2mapentity.MapEntity) float64 {
+		x, y := entity.GetPosition()
+		dx, dy := x+0.5-centerX, y+0.5-centerY
+		return dx*dx + dy*dy
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		return distance(visible[i]) < distance(visible[j])
+	})
+
+	result := make(entitySet, mr.maxEntities)
+	for _, entity := range visible[:mr.maxEntities] {
+		result[entity] = true
+	}
+	return result
+}
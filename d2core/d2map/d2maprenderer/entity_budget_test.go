@@ -0,0 +1,60 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestMaxRenderedEntitiesRendersNearestToCamera(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(7, 7)
+	mr.camera.MoveTo(mr.viewport.WorldToOrtho(3.5, 3.5))
+	for _, entity := range []*testEntity{
+		createTestEntity("far1", 0, 0),
+		createTestEntity("far2", 6, 6),
+		createTestEntity("near1", 4, 3),
+		createTestEntity("center", 3, 3),
+		createTestEntity("far3", 0, 6),
+		createTestEntity("near2", 3, 2),
+	} {
+		mr.mapEngine.AddEntity(entity)
+	}
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Len(target.callsOf("text"), 6)
+
+	mr.SetMaxRenderedEntities(3)
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+
+	assert.Len(target.callsOf("text"), 3)
+	for _, name := range []string{"center", "near1", "near2"} {
+		assert.NotEqual(-1, indexOfText(target, "entity:"+name), name)
+	}
+
+	mr.SetMaxRenderedEntities(-1)
+	assert.Equal(0, mr.GetMaxRenderedEntities())
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Len(target.callsOf("text"), 6)
+}
+
+func TestMaxRenderedEntitiesIgnoresOffscreenEntities(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(20, 1)
+	mr.mapEngine.AddEntity(createTestEntity("offscreen", 19, 0))
+	mr.mapEngine.AddEntity(createTestEntity("onscreen1", 5, 0))
+	mr.mapEngine.AddEntity(createTestEntity("onscreen2", 6, 0))
+	mr.SetMaxRenderedEntities(2)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	// Only the on screen entities count towards the limit, so nothing needs to be skipped
+	assert.Nil(mr.entityBudget)
+	assert.NotEqual(-1, indexOfText(target, "entity:onscreen1"))
+	assert.NotEqual(-1, indexOfText(target, "entity:onscreen2"))
+	assert.Len(*mr.mapEngine.Entities(), 3)
+}
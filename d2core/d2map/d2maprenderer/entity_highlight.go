@@ -0,0 +1,30 @@
+package d2maprenderer
+
+import (
+	"image/color"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+var defaultHighlightColor = color.RGBA{R: 255, G: 215, B: 100, A: 255}
+
+// The offsets the entity silhouette is drawn at to form the highlight outline
+var highlightOffsets = [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+
+// Sets the color of the outline drawn around highlighted entities
+func (mr *MapRenderer) SetHighlightColor(c color.RGBA) {
+	mr.highlight = c
+}
+
+// Draws a solid silhouette of the entity, offset in each direction, behind the entity to form an outline
+func (mr *MapRenderer) renderEntityHighlight(mapEntity d2mapentity.MapEntity, target d2render.Surface) {
+	target.PushSilhouette(mr.highlight)
+	defer target.Pop()
+
+	for _, offset := range highlightOffsets {
+		target.PushTranslation(offset[0], offset[1])
+		mapEntity.Render(target)
+		target.Pop()
+	}
+}
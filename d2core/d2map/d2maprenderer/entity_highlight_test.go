@@ -0,0 +1,68 @@
+package d2maprenderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+)
+
+func TestHighlightedEntityRendersOutline(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	entity := createTestEntity("zombie", 0, 0)
+	entity.highlighted = true
+	mr.mapEngine.AddEntity(entity)
+	mr.SetHighlightColor(color.RGBA{R: 255, A: 255})
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	calls := target.callsOf("text")
+	assert.Len(calls, len(highlightOffsets)+1)
+	for _, call := range calls[:len(highlightOffsets)] {
+		assert.Equal(color.RGBA{R: 255, A: 255}, call.silhouette)
+	}
+	assert.Nil(calls[len(calls)-1].silhouette)
+	assert.Equal(0, target.GetDepth())
+}
+
+func TestHighlightOutlinesAnimatedEntity(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	entity := createTestAnimatedEntity(0, 0, 6, 10, d2dat.DATColor{B: 200})
+	entity.Highlighted = true
+	mr.mapEngine.AddEntity(entity)
+	mr.SetHighlightColor(color.RGBA{R: 255, A: 255})
+
+	target := createTestSoftwareSurface(800, 600)
+	mr.Render(target)
+	pixels := target.Screenshot()
+
+	// The animation keeps its own color, and the outline is drawn in the highlight color a pixel around it
+	sprite := findTestColorBounds(pixels, color.RGBA{B: 200, A: 255})
+	assert.Equal(image.Pt(6, 10), sprite.Size())
+	outline := color.RGBA{R: 255, A: 255}
+	assert.Equal(outline, pixels.RGBAAt(sprite.Min.X-1, sprite.Min.Y))
+	assert.Equal(outline, pixels.RGBAAt(sprite.Max.X, sprite.Max.Y-1))
+	assert.Equal(outline, pixels.RGBAAt(sprite.Min.X, sprite.Min.Y-1))
+	assert.Equal(outline, pixels.RGBAAt(sprite.Max.X-1, sprite.Max.Y))
+	assert.NotEqual(outline, pixels.RGBAAt(sprite.Min.X-1, sprite.Min.Y-1))
+	assert.Equal(0, target.GetDepth())
+}
+
+func TestNonHighlightedEntityHasNoOutline(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	mr.mapEngine.AddEntity(createTestEntity("zombie", 0, 0))
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	calls := target.callsOf("text")
+	assert.Len(calls, 1)
+	assert.Empty(calls[0].colors)
+}
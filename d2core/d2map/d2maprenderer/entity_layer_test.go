@@ -0,0 +1,79 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+// Returns the index of the first recorded call matching the predicate, or -1
+func indexOfCall(target *testSurface, match func(call testDrawCall) bool) int {
+	for i, call := range target.calls {
+		if match(call) {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexOfText(target *testSurface, text string) int {
+	return indexOfCall(target, func(call testDrawCall) bool { return call.op == "text" && call.text == text })
+}
+
+func indexOfRender(target *testSurface, source *testSurface) int {
+	return indexOfCall(target, func(call testDrawCall) bool { return call.op == "render" && call.source == source })
+}
+
+func TestEntityRenderLayers(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+
+	mr := createTestMapRenderer(1, 1)
+	roofImage := createTestSurface(160, 80)
+	mr.setImageCacheRecord(1, 1, d2enum.Roof, 0, roofImage)
+	mr.mapEngine.TileAt(0, 0).Walls = []d2ds1.WallRecord{{Type: d2enum.Roof, Style: 1, Sequence: 1}}
+
+	below := createTestEntity("below", 0, 0)
+	below.layer = d2enum.EntityRenderLayerBelow
+	normal := createTestEntity("normal", 0, 0)
+	above := createTestEntity("above", 0, 0)
+	above.layer = d2enum.EntityRenderLayerAboveRoof
+	mr.mapEngine.AddEntity(above)
+	mr.mapEngine.AddEntity(normal)
+	mr.mapEngine.AddEntity(below)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	belowIndex := indexOfText(target, "entity:below")
+	normalIndex := indexOfText(target, "entity:normal")
+	roofIndex := indexOfRender(target, roofImage)
+	aboveIndex := indexOfText(target, "entity:above")
+
+	assert.NotEqual(-1, belowIndex)
+	assert.NotEqual(-1, roofIndex)
+	assert.True(belowIndex < normalIndex)
+	assert.True(normalIndex < roofIndex)
+	assert.True(roofIndex < aboveIndex)
+	assert.Len(target.callsOf("text"), 3)
+}
+
+func TestCorpsesRenderBelowLivingEntities(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	living := createTestEntity("living", 0, 0)
+	corpse := createTestEntity("corpse", 0, 0)
+	corpse.layer = d2enum.EntityRenderLayerCorpse
+	mr.mapEngine.AddEntity(living)
+	mr.mapEngine.AddEntity(corpse)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	corpseIndex := indexOfText(target, "entity:corpse")
+	assert.NotEqual(-1, corpseIndex)
+	assert.True(corpseIndex < indexOfText(target, "entity:living"))
+}
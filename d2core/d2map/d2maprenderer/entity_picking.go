@@ -0,0 +1,69 @@
+package d2maprenderer
+
+import (
+	"image"
+	"math"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+const (
+	entityPickWidth  = 40 // The width of the screen footprint of an entity that does not report its frame, in pixels
+	entityPickHeight = 80 // The height of the screen footprint of an entity that does not report its frame, in pixels
+)
+
+// Returns the screen offset from the top corner of an entity's tile to its feet: as reported by the entity, or from its
+// sub tile location, or the center of its tile if it has neither
+func (mr *MapRenderer) entityScreenAnchor(entity d2mapentity.MapEntity) (int, int) {
+	if anchored, ok := entity.(d2mapentity.Anchored); ok {
+		return anchored.GetScreenAnchor()
+	}
+
+	tileX, tileY := entity.GetPosition()
+	if locatable, ok := entity.(d2mapentity.Locatable); ok {
+		x, y := locatable.GetLocation()
+		return mr.viewport.subTileScreenOffset(x-tileX*5, y-tileY*5)
+	}
+	return mr.viewport.subTileScreenOffset(2.5, 2.5)
+}
+
+// Returns the screen space rectangle an entity occupies: the frame it draws, or a footprint standing on its feet if it
+// does not report one, scaled around its feet by the entity's render scale. The offsets from the tile are in the
+// unzoomed space the map is drawn in, so they are scaled by the zoom as the drawn entity is.
+func (mr *MapRenderer) entityScreenBounds(entity d2mapentity.MapEntity) image.Rectangle {
+	zoom := mr.viewport.GetZoom()
+	x, y := entity.GetPosition()
+	orthoX, orthoY := mr.viewport.WorldToOrtho(x, y)
+	screenX, screenY := mr.viewport.OrthoToScreen(orthoX, orthoY-float64(mr.mapEngine.TileElevation(int(x), int(y))))
+	anchorX, anchorY := mr.entityScreenAnchor(entity)
+	offsetX, offsetY := mr.entityInterpolationOffset(entity)
+	feetX := float64(screenX) + float64(anchorX+offsetX)*zoom
+	feetY := float64(screenY) + float64(anchorY+offsetY)*zoom
+
+	frame := image.Rect(-entityPickWidth/2, -entityPickHeight, entityPickWidth/2, 0)
+	if framed, ok := entity.(d2mapentity.Framed); ok {
+		frame = framed.GetFrameRect()
+	}
+
+	scale := entity.GetRenderScale() * zoom
+	return image.Rect(
+		int(math.Floor(feetX+float64(frame.Min.X)*scale)),
+		int(math.Floor(feetY+float64(frame.Min.Y)*scale)),
+		int(math.Ceil(feetX+float64(frame.Max.X)*scale)),
+		int(math.Ceil(feetY+float64(frame.Max.Y)*scale)),
+	)
+}
+
+// Returns the entities whose screen footprint intersects the specified screen rectangle (eg: for marquee selection)
+func (mr *MapRenderer) EntitiesInScreenRect(rect image.Rectangle) []d2mapentity.MapEntity {
+	rect = rect.Canon()
+
+	var result []d2mapentity.MapEntity
+	for _, entity := range *mr.mapEngine.Entities() {
+		if mr.entityScreenBounds(entity).Overlaps(rect) {
+			result = append(result, entity)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,60 @@
+package d2maprenderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+func TestEntitiesInScreenRect(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(20, 20)
+
+	cluster := []*testEntity{
+		createTestEntity("a", 0, 0),
+		createTestEntity("b", 1, 0),
+		createTestEntity("c", 0, 1),
+	}
+	for _, entity := range cluster {
+		mr.mapEngine.AddEntity(entity)
+	}
+	mr.mapEngine.AddEntity(createTestEntity("outside", 2, 0))
+	mr.mapEngine.AddEntity(createTestEntity("far", 10, 10))
+
+	selected := mr.EntitiesInScreenRect(image.Rect(300, 250, 500, 400))
+	assert.Equal([]d2mapentity.MapEntity{cluster[0], cluster[1], cluster[2]}, selected)
+
+	// Dragging from the bottom right to the top left selects the same entities
+	selected = mr.EntitiesInScreenRect(image.Rectangle{Min: image.Pt(500, 400), Max: image.Pt(300, 250)})
+	assert.Len(selected, 3)
+
+	assert.Empty(mr.EntitiesInScreenRect(image.Rect(0, 0, 10, 10)))
+}
+
+func TestZoomedEntityPicking(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	mr.SetZoom(2)
+
+	entity := createTestAnimatedEntity(3, 1, 4, 8, d2dat.DATColor{R: 255, G: 255, B: 255})
+	footprint := createTestEntity("footprint", 0, 0)
+	mr.mapEngine.AddEntity(entity)
+	mr.mapEngine.AddEntity(footprint)
+
+	target := createTestSoftwareSurface(800, 600)
+	mr.Render(target)
+	drawn := findTestColorBounds(target.Screenshot(), color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	// The entity is picked by the frame it draws, which is scaled by the zoom along with its offset from the tile
+	assert.Equal(image.Pt(8, 16), drawn.Size())
+	assert.Equal(drawn, mr.entityScreenBounds(entity))
+	assert.Equal([]d2mapentity.MapEntity{entity}, mr.EntitiesInScreenRect(drawn))
+
+	// The footprint of an entity on this tile spans screen x 380-420 and y 260-340 unzoomed
+	assert.Equal(image.Rect(360, 220, 440, 380), mr.entityScreenBounds(footprint))
+}
@@ -0,0 +1,77 @@
+package d2maprenderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+)
+
+// Renders an animated entity standing on a sub tile, returning the bounds of the pixels it draws and its pick bounds
+func renderScaledAnimatedEntity(scale float64) (drawn, picked image.Rectangle) {
+	mr := createTestMapRenderer(1, 1)
+	entity := createTestAnimatedEntity(3, 1, 4, 8, d2dat.DATColor{R: 255, G: 255, B: 255})
+	entity.SetRenderScale(scale)
+	mr.mapEngine.AddEntity(entity)
+
+	target := createTestSoftwareSurface(800, 600)
+	mr.Render(target)
+	return findTestColorBounds(target.Screenshot(), color.RGBA{R: 255, G: 255, B: 255, A: 255}),
+		mr.entityScreenBounds(entity)
+}
+
+func TestScaledEntityRender(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	normal := createTestEntity("normal", 0, 0)
+	champion := createTestEntity("champion", 0, 0)
+	champion.scale = 2
+	mr.mapEngine.AddEntity(normal)
+	mr.mapEngine.AddEntity(champion)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	normalCall := target.calls[indexOfText(target, "entity:normal")]
+	championCall := target.calls[indexOfText(target, "entity:champion")]
+	assert.Equal(1.0, normalCall.scale)
+	assert.Equal(2.0, championCall.scale)
+	assert.Equal(0, target.GetDepth())
+}
+
+func TestScaledEntityStandsOnItsFeet(t *testing.T) {
+	assert := testify.New(t)
+	normal, normalPicked := renderScaledAnimatedEntity(1)
+	champion, championPicked := renderScaledAnimatedEntity(2)
+
+	// The scaled sprite grows up and out from the point it stands on, at its sub tile rather than the tile corner
+	assert.Equal(image.Pt(4, 8), normal.Size())
+	assert.Equal(image.Pt(8, 16), champion.Size())
+	assert.Equal(normal.Max.Y, champion.Max.Y)
+	assert.Equal(normal.Min.X+normal.Dx()/2, champion.Min.X+champion.Dx()/2)
+
+	// Entities are picked by the frame they draw
+	assert.Equal(normal, normalPicked)
+	assert.Equal(champion, championPicked)
+}
+
+func TestScaledEntityPicking(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	champion := createTestEntity("champion", 0, 0)
+	champion.scale = 2
+	mr.mapEngine.AddEntity(champion)
+
+	// The footprint of an unscaled entity on this tile spans screen y 260-340, the scaled one spans 180-340
+	assert.Equal(image.Rect(360, 180, 440, 340), mr.entityScreenBounds(champion))
+	assert.Len(mr.EntitiesInScreenRect(image.Rect(395, 200, 405, 210)), 1)
+	assert.Len(mr.EntitiesInScreenRect(image.Rect(430, 300, 435, 310)), 1)
+
+	champion.scale = 1
+	assert.Empty(mr.EntitiesInScreenRect(image.Rect(395, 200, 405, 210)))
+}
@@ -0,0 +1,74 @@
+package d2maprenderer
+
+import "github.com/OpenDiablo2/OpenDiablo2/d2common"
+
+// Returns the current time, in seconds (replaced in tests to simulate slow frames)
+var renderClock = d2common.Now
+
+// The time spent in each part of a render, in seconds
+type RenderTimings struct {
+	Pass1           float64 // Floors, shadows and lower walls
+	Pass2           float64 // Walls and entities
+	Pass3           float64 // Roofs and the entities above them
+	SceneTint       float64 // The full screen tint
+	Overlays        float64 // The optional overlays (the debug visualization, measurement and world text)
+	OverlaysSkipped bool    // Whether any of the optional overlays were skipped to stay within the frame budget
+}
+
+// Returns the total time spent rendering, in seconds
+func (t RenderTimings) Total() float64 {
+	return t.Pass1 + t.Pass2 + t.Pass3 + t.SceneTint + t.Overlays
+}
+
+// Measures the time between the steps of a render
+type frameTimer struct {
+	start float64
+	last  float64
+}
+
+func startFrameTimer() frameTimer {
+	now := renderClock()
+	return frameTimer{start: now, last: now}
+}
+
+// Returns the time since the previous lap, in seconds
+func (t *frameTimer) lap() float64 {
+	now := renderClock()
+	elapsed := now - t.last
+	t.last = now
+	return elapsed
+}
+
+// Returns the time from the start of the frame to the last lap, in seconds
+func (t *frameTimer) elapsed() float64 {
+	return t.last - t.start
+}
+
+// Sets the time a frame may take, in seconds, before the optional overlays (the debug visualization, measurement and
+// world text) are skipped for the rest of the frame. The core passes are always rendered. A budget of 0 never skips the
+// overlays.
+func (mr *MapRenderer) SetFrameBudget(seconds float64) {
+	if seconds < 0 {
+		seconds = 0
+	}
+	mr.frameBudget = seconds
+}
+
+// Returns the frame time budget, in seconds (0=unlimited)
+func (mr *MapRenderer) GetFrameBudget() float64 {
+	return mr.frameBudget
+}
+
+// Returns the time spent in each part of the last render
+func (mr *MapRenderer) GetRenderTimings() RenderTimings {
+	return mr.timings
+}
+
+// Returns true if an optional overlay can still be drawn this frame, recording the overlay as skipped otherwise
+func (mr *MapRenderer) allowOverlay(timer *frameTimer) bool {
+	if mr.frameBudget > 0 && timer.elapsed() > mr.frameBudget {
+		mr.timings.OverlaysSkipped = true
+		return false
+	}
+	return true
+}
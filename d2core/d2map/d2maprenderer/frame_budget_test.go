@@ -0,0 +1,110 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// slowEntity is an entity that takes a fixed amount of simulated time to render
+type slowEntity struct {
+	*testEntity
+	now      *float64
+	duration float64
+}
+
+func (e *slowEntity) Render(target d2render.Surface) {
+	*e.now += e.duration
+	e.testEntity.Render(target)
+}
+
+func useTestRenderClock(clock func() float64) func() {
+	previous := renderClock
+	renderClock = clock
+	return func() { renderClock = previous }
+}
+
+func TestFrameBudgetSkipsOverlaysOnSlowFrame(t *testing.T) {
+	assert := testify.New(t)
+	now := 0.0
+	defer useTestRenderClock(func() float64 { return now })()
+
+	mr := createTestMapRenderer(1, 1)
+	mr.SetFrameBudget(0.02)
+	mr.mapEngine.AddEntity(&slowEntity{testEntity: createTestEntity("slow", 0, 0), now: &now, duration: 0.05})
+	mr.DrawWorldText(0, 0, "label")
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	// The entity is still drawn, but the world text is not
+	assert.NotEqual(-1, indexOfText(target, "entity:slow"))
+	assert.Equal(-1, indexOfText(target, "label"))
+
+	timings := mr.GetRenderTimings()
+	assert.True(timings.OverlaysSkipped)
+	assert.InDelta(0.05, timings.Pass2, 0.0001)
+	assert.InDelta(0.05, timings.Total(), 0.0001)
+
+	// Skipped labels are not carried over to the next frame
+	target = createTestSurface(800, 600)
+	mr.SetFrameBudget(0)
+	mr.Render(target)
+	assert.Equal(-1, indexOfText(target, "label"))
+	assert.False(mr.GetRenderTimings().OverlaysSkipped)
+}
+
+func TestFrameBudgetKeepsOverlaysOnFastFrame(t *testing.T) {
+	assert := testify.New(t)
+	now := 0.0
+	defer useTestRenderClock(func() float64 { return now })()
+
+	mr := createTestMapRenderer(1, 1)
+	mr.SetFrameBudget(0.1)
+	mr.mapEngine.AddEntity(&slowEntity{testEntity: createTestEntity("slow", 0, 0), now: &now, duration: 0.05})
+	mr.DrawWorldText(0, 0, "label")
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	assert.NotEqual(-1, indexOfText(target, "entity:slow"))
+	assert.NotEqual(-1, indexOfText(target, "label"))
+	assert.False(mr.GetRenderTimings().OverlaysSkipped)
+}
+
+func TestFrameBudgetSkipsDebugOverlayAfterSlowFirstPass(t *testing.T) {
+	assert := testify.New(t)
+	now := 0.0
+	defer useTestRenderClock(func() float64 {
+		now += 0.01
+		return now
+	})()
+
+	mr := createTestMapRenderer(1, 1)
+	mr.debugVisLevel = 1
+	mr.mapEngine.AddEntity(createTestEntity("fast", 0, 0))
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.NotEmpty(target.callsOf("line"))
+
+	mr.SetFrameBudget(0.005)
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Empty(target.callsOf("line"))
+	assert.NotEqual(-1, indexOfText(target, "entity:fast"))
+	assert.True(mr.GetRenderTimings().OverlaysSkipped)
+}
+
+func TestFrameBudgetIsNotNegative(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	mr.SetFrameBudget(-1)
+	assert.Equal(0.0, mr.GetFrameBudget())
+
+	mr.SetFrameBudget(1.0 / 60)
+	assert.Equal(1.0/60, mr.GetFrameBudget())
+}
@@ -2,7 +2,8 @@ package d2maprenderer
 
 import "github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dt1"
 
-func (mr *MapRenderer) decodeTileGfxData(blocks []d2dt1.Block, pixels *[]byte, tileYOffset int32, tileWidth int32) {
+func (mr *MapRenderer) decodeTileGfxData(blocks []d2dt1.Block, pixels *[]byte, tileXOffset, tileYOffset int32,
+	tileWidth int32) {
 	for _, block := range blocks {
 		if block.Format == d2dt1.BlockFormatIsometric {
 			// 3D isometric decoding
@@ -20,9 +21,10 @@ func (mr *MapRenderer) decodeTileGfxData(blocks []d2dt1.Block, pixels *[]byte, t
 				length -= n
 				for n > 0 {
 					colorIndex := block.EncodedData[idx]
-					if colorIndex != 0 {
+					if !mr.palette.IsTransparent(colorIndex) {
+						mr.decodedColors.add(colorIndex)
 						pixelColor := mr.palette.Colors[colorIndex]
-						offset := 4 * (((blockY + y + tileYOffset) * tileWidth) + (blockX + x))
+						offset := 4 * (((blockY + y + tileYOffset) * tileWidth) + (blockX + x + tileXOffset))
 						(*pixels)[offset] = pixelColor.R
 						(*pixels)[offset+1] = pixelColor.G
 						(*pixels)[offset+2] = pixelColor.B
@@ -57,10 +59,11 @@ func (mr *MapRenderer) decodeTileGfxData(blocks []d2dt1.Block, pixels *[]byte, t
 			length -= int32(b2)
 			for b2 > 0 {
 				colorIndex := block.EncodedData[idx]
-				if colorIndex != 0 {
+				if !mr.palette.IsTransparent(colorIndex) {
+					mr.decodedColors.add(colorIndex)
 					pixelColor := mr.palette.Colors[colorIndex]
 
-					offset := 4 * (((blockY + y + tileYOffset) * tileWidth) + (blockX + x))
+					offset := 4 * (((blockY + y + tileYOffset) * tileWidth) + (blockX + x + tileXOffset))
 					(*pixels)[offset] = pixelColor.R
 					(*pixels)[offset+1] = pixelColor.G
 					(*pixels)[offset+2] = pixelColor.B
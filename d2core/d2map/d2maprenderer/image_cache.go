@@ -1,26 +1,208 @@
 package d2maprenderer
 
 import (
+	"container/list"
+	"image"
+
 	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
 )
 
-var imageCacheRecords map[uint32]d2render.Surface
+// imageCacheRecord is a tile image in the region image cache
+type imageCacheRecord struct {
+	lookupIndex uint32
+	surface     d2render.Surface
+	source      *image.Point    // The tile the image was generated for, so it can be regenerated if evicted (nil=unknown)
+	colors      paletteIndexSet // The palette indices the image was decoded from
+	stale       bool            // Whether the palette colors the image was decoded with have changed since
+}
+
+var imageCacheRecords map[uint32]*list.Element // The cached images, as elements of imageCacheOrder
+var imageCacheOrder *list.List                 // The cached images, most recently used first
+var imageCacheEvicted map[uint32]image.Point   // The tile each evicted image was generated for
+var imageCacheBudget int                       // The approximate memory the cached images may use (0=unlimited)
+var imageCacheDropped []d2render.Surface       // The dropped images, disposed of before the next frame is rendered
+var imageCacheStats ImageCacheStats
+
+// The most stale images decoded again with the current palette colors each frame. The others are drawn with their
+// previous colors until a later frame, so that a palette cycle stepping does not decode every visible tile at once.
+const staleImageRefreshBudget = 8
+
+// ImageCacheStats contains diagnostic information about the region image cache
+type ImageCacheStats struct {
+	Records   int // The number of cached tile images
+	Bytes     int // The approximate memory used by the cached tile images
+	Hits      int // The number of lookups that found a cached image since the cache was last invalidated
+	Misses    int // The number of lookups that did not find a cached image since the cache was last invalidated
+	Evictions int // The number of images dropped to stay within the budget since the cache was last invalidated
+}
 
 // Invalidates the global region image cache. Call this when you are changing regions
 func InvalidateImageCache() {
 	imageCacheRecords = nil
+	imageCacheOrder = nil
+	imageCacheEvicted = nil
+	imageCacheStats = ImageCacheStats{}
+}
+
+// Returns diagnostic information about the global region image cache
+func GetImageCacheStats() ImageCacheStats {
+	return imageCacheStats
+}
+
+// Sets the approximate memory, in bytes, the global region image cache may use (0=unlimited). Once the budget is
+// exceeded the least recently used images are dropped, and generated again if they are needed later on.
+func SetImageCacheBudget(bytes int) {
+	imageCacheBudget = bytes
+	evictImageCacheRecords()
+}
+
+// Returns the approximate memory, in bytes, the global region image cache may use (0=unlimited)
+func GetImageCacheBudget() int {
+	return imageCacheBudget
 }
 
+// Returns diagnostic information about the tile images cached for rendering, which are shared by all map renderers
+func (mr *MapRenderer) TileCacheStats() ImageCacheStats {
+	return GetImageCacheStats()
+}
+
+// Returns the index of a tile image in the cache
+func imageCacheLookupIndex(style, sequence byte, tileType d2enum.TileType, randomIndex byte) uint32 {
+	return uint32(style)<<24 | uint32(sequence)<<16 | uint32(tileType)<<8 | uint32(randomIndex)
+}
+
+// Returns a cached tile image, or nil if it is not cached. An image evicted to stay within the budget is generated
+// again from the tile it was made for, unless the tile cache is being generated already. A stale image is generated
+// again with the current palette colors while the frame's refresh budget lasts, and returned as it is otherwise.
 func (mr *MapRenderer) getImageCacheRecord(style, sequence byte, tileType d2enum.TileType, randomIndex byte) d2render.Surface {
-	lookupIndex := uint32(style)<<24 | uint32(sequence)<<16 | uint32(tileType)<<8 | uint32(randomIndex)
-	return imageCacheRecords[lookupIndex]
+	lookupIndex := imageCacheLookupIndex(style, sequence, tileType, randomIndex)
+	if element, found := imageCacheRecords[lookupIndex]; found {
+		imageCacheStats.Hits++
+		imageCacheOrder.MoveToFront(element)
+		record := element.Value.(*imageCacheRecord)
+		if !record.stale || mr.cachingTile != nil || mr.staleRefreshes >= staleImageRefreshBudget ||
+			mr.mapEngine.TileAt(record.source.X, record.source.Y) == nil {
+			return record.surface
+		}
+
+		mr.staleRefreshes++
+		removeImageCacheRecord(element)
+		return mr.regenerateImageCacheRecord(lookupIndex, *record.source)
+	}
+
+	imageCacheStats.Misses++
+	source, evicted := imageCacheEvicted[lookupIndex]
+	if !evicted || mr.cachingTile != nil || mr.mapEngine.TileAt(source.X, source.Y) == nil {
+		return nil
+	}
+
+	delete(imageCacheEvicted, lookupIndex)
+	return mr.regenerateImageCacheRecord(lookupIndex, source)
+}
+
+// Generates the images of the tile an image was made for, returning the image if it was generated again
+func (mr *MapRenderer) regenerateImageCacheRecord(lookupIndex uint32, source image.Point) d2render.Surface {
+	mr.generateTileCacheAt(source.X, source.Y)
+	if element, found := imageCacheRecords[lookupIndex]; found {
+		return element.Value.(*imageCacheRecord).surface
+	}
+	return nil
 }
 
 func (mr *MapRenderer) setImageCacheRecord(style, sequence byte, tileType d2enum.TileType, randomIndex byte, image d2render.Surface) {
-	lookupIndex := uint32(style)<<24 | uint32(sequence)<<16 | uint32(tileType)<<8 | uint32(randomIndex)
+	lookupIndex := imageCacheLookupIndex(style, sequence, tileType, randomIndex)
 	if imageCacheRecords == nil {
-		imageCacheRecords = make(map[uint32]d2render.Surface)
+		imageCacheRecords = make(map[uint32]*list.Element)
+		imageCacheOrder = list.New()
+	}
+	if existing, found := imageCacheRecords[lookupIndex]; found {
+		// An image cached again must not be disposed of
+		unlinkImageCacheRecord(existing, existing.Value.(*imageCacheRecord).surface != image)
+	}
+	delete(imageCacheEvicted, lookupIndex)
+
+	record := &imageCacheRecord{lookupIndex: lookupIndex, surface: image, colors: mr.decodedColors}
+	mr.decodedColors = paletteIndexSet{}
+	if mr.cachingTile != nil {
+		source := *mr.cachingTile
+		record.source = &source
+	}
+	imageCacheRecords[lookupIndex] = imageCacheOrder.PushFront(record)
+	imageCacheStats.Records++
+	imageCacheStats.Bytes += imageByteSize(image)
+	evictImageCacheRecords()
+}
+
+// Drops the least recently used images until the cache is within its budget. The most recently used image is always
+// kept, even if it is larger than the budget on its own.
+func evictImageCacheRecords() {
+	if imageCacheBudget <= 0 || imageCacheOrder == nil {
+		return
+	}
+
+	for imageCacheStats.Bytes > imageCacheBudget && imageCacheOrder.Len() > 1 {
+		element := imageCacheOrder.Back()
+		record := element.Value.(*imageCacheRecord)
+		removeImageCacheRecord(element)
+		imageCacheStats.Evictions++
+		if record.source != nil {
+			if imageCacheEvicted == nil {
+				imageCacheEvicted = make(map[uint32]image.Point)
+			}
+			imageCacheEvicted[record.lookupIndex] = *record.source
+		}
+	}
+}
+
+// Marks the cached images decoded from any of the palette indices as stale, so they are generated again with the
+// current palette when they are next drawn. Images whose tile is unknown cannot be generated again, so they are kept as
+// they are. Returns the number of images marked.
+func invalidateImageCacheColors(colors paletteIndexSet) int {
+	marked := 0
+	for _, element := range imageCacheRecords {
+		record := element.Value.(*imageCacheRecord)
+		if record.source == nil || record.stale || !record.colors.intersects(colors) {
+			continue
+		}
+
+		record.stale = true
+		marked++
+	}
+	return marked
+}
+
+// Drops a cached image. The image is disposed of before the next frame is rendered rather than straight away, as the
+// caller of a lookup that evicted it may still be using it.
+func removeImageCacheRecord(element *list.Element) {
+	unlinkImageCacheRecord(element, true)
+}
+
+// Drops a cached image from the cache and its statistics, queueing the image to be disposed of if requested
+func unlinkImageCacheRecord(element *list.Element, dispose bool) {
+	record := element.Value.(*imageCacheRecord)
+	imageCacheOrder.Remove(element)
+	delete(imageCacheRecords, record.lookupIndex)
+	imageCacheStats.Records--
+	imageCacheStats.Bytes -= imageByteSize(record.surface)
+	if dispose && record.surface != nil {
+		imageCacheDropped = append(imageCacheDropped, record.surface)
+	}
+}
+
+// Disposes of the images dropped from the cache since the last frame
+func disposeDroppedImages() {
+	for _, surface := range imageCacheDropped {
+		_ = surface.Dispose()
+	}
+	imageCacheDropped = nil
+}
+
+// Returns the approximate memory used by an RGBA image
+func imageByteSize(image d2render.Surface) int {
+	if image == nil {
+		return 0
 	}
-	imageCacheRecords[lookupIndex] = image
+	width, height := image.GetSize()
+	return width * height * 4
 }
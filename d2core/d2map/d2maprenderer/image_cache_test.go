@@ -0,0 +1,154 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+func TestImageCacheStats(t *testing.T) {
+	assert := testify.New(t)
+	InvalidateImageCache()
+	defer InvalidateImageCache()
+
+	mr := createTestMapRenderer(1, 1)
+	mr.setImageCacheRecord(1, 1, d2enum.Floor, 0, createTestSurface(160, 80))
+	mr.setImageCacheRecord(1, 2, d2enum.Floor, 0, createTestSurface(160, 80))
+	mr.setImageCacheRecord(1, 1, d2enum.LeftWall, 0, createTestSurface(160, 200))
+
+	stats := GetImageCacheStats()
+	assert.Equal(3, stats.Records)
+	assert.Equal(2*160*80*4+160*200*4, stats.Bytes)
+	assert.Equal(0, stats.Hits)
+	assert.Equal(0, stats.Misses)
+
+	assert.NotNil(mr.getImageCacheRecord(1, 1, d2enum.Floor, 0))
+	assert.Nil(mr.getImageCacheRecord(9, 9, d2enum.Floor, 0))
+	assert.Nil(mr.getImageCacheRecord(1, 1, d2enum.Floor, 1))
+
+	stats = GetImageCacheStats()
+	assert.Equal(1, stats.Hits)
+	assert.Equal(2, stats.Misses)
+
+	// Replacing a record does not count it twice
+	mr.setImageCacheRecord(1, 1, d2enum.Floor, 0, createTestSurface(10, 10))
+	stats = GetImageCacheStats()
+	assert.Equal(3, stats.Records)
+	assert.Equal(160*80*4+10*10*4+160*200*4, stats.Bytes)
+
+	InvalidateImageCache()
+	assert.Equal(ImageCacheStats{}, GetImageCacheStats())
+}
+
+func TestImageCacheRecachingSameImage(t *testing.T) {
+	assert := testify.New(t)
+	InvalidateImageCache()
+	defer InvalidateImageCache()
+
+	mr := createTestMapRenderer(1, 1)
+	image := createTestSurface(160, 80)
+	mr.setImageCacheRecord(1, 1, d2enum.Floor, 0, image)
+	mr.setImageCacheRecord(1, 1, d2enum.Floor, 0, image)
+
+	// The image is counted once, and is not disposed of as it is still cached
+	stats := GetImageCacheStats()
+	assert.Equal(1, stats.Records)
+	assert.Equal(160*80*4, stats.Bytes)
+	disposeDroppedImages()
+	assert.False(image.disposed)
+}
+
+// Makes the tile cache draw to test surfaces, returning a function that restores the renderer surfaces and the cache
+func useTestTileSurfaces() func() {
+	previous := newTileSurface
+	newTileSurface = func(width, height int, filter d2render.Filter) (d2render.Surface, error) {
+		return createTestSurface(width, height), nil
+	}
+	InvalidateImageCache()
+	return func() {
+		newTileSurface = previous
+		InvalidateImageCache()
+		SetImageCacheBudget(0)
+	}
+}
+
+// createTestCachedMapRenderer creates a renderer for a row of tiles, each with a floor of a different style. The styles
+// have no tile data, so each is cached as a 10x10 placeholder image (400 bytes).
+func createTestCachedMapRenderer(width int) *MapRenderer {
+	mr := createTestMapRenderer(width, 1)
+	for x := 0; x < width; x++ {
+		mr.mapEngine.TileAt(x, 0).Floors = []d2ds1.FloorShadowRecord{{Style: byte(x + 1), Prop1: 1}}
+	}
+	return mr
+}
+
+func TestImageCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestTileSurfaces()()
+	SetImageCacheBudget(1000)
+
+	mr := createTestCachedMapRenderer(3)
+	mr.generateTileCacheAt(0, 0)
+	mr.generateTileCacheAt(1, 0)
+	assert.NotNil(mr.getImageCacheRecord(1, 0, d2enum.Floor, 0))
+
+	// The floor of tile 1 was used least recently, so it makes room for the floor of tile 2
+	mr.generateTileCacheAt(2, 0)
+	stats := mr.TileCacheStats()
+	assert.Equal(2, stats.Records)
+	assert.Equal(800, stats.Bytes)
+	assert.Equal(1, stats.Evictions)
+	assert.Contains(imageCacheEvicted, imageCacheLookupIndex(2, 0, d2enum.Floor, 0))
+	assert.NotContains(imageCacheRecords, imageCacheLookupIndex(2, 0, d2enum.Floor, 0))
+}
+
+func TestImageCacheRegeneratesEvictedRecord(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestTileSurfaces()()
+	SetImageCacheBudget(400)
+
+	mr := createTestCachedMapRenderer(2)
+	mr.generateTileCacheAt(0, 0)
+	mr.generateTileCacheAt(1, 0)
+	assert.Equal(1, mr.TileCacheStats().Records)
+
+	image := mr.getImageCacheRecord(1, 0, d2enum.Floor, 0)
+	assert.NotNil(image)
+	width, height := image.GetSize()
+	assert.Equal(10, width)
+	assert.Equal(10, height)
+
+	// Bringing the floor of tile 0 back evicted the floor of tile 1 in turn
+	stats := mr.TileCacheStats()
+	assert.Equal(1, stats.Records)
+	assert.Equal(2, stats.Evictions)
+	assert.Contains(imageCacheEvicted, imageCacheLookupIndex(2, 0, d2enum.Floor, 0))
+}
+
+func TestImageCacheBudgetShrinksCache(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestTileSurfaces()()
+
+	mr := createTestCachedMapRenderer(4)
+	for x := 0; x < 4; x++ {
+		mr.generateTileCacheAt(x, 0)
+	}
+	assert.Equal(1600, mr.TileCacheStats().Bytes)
+	assert.Equal(0, mr.TileCacheStats().Evictions)
+
+	SetImageCacheBudget(800)
+	assert.Equal(800, GetImageCacheBudget())
+	assert.Equal(2, mr.TileCacheStats().Records)
+	assert.Equal(800, mr.TileCacheStats().Bytes)
+
+	// Records made outside of the tile cache cannot be regenerated, so they are not remembered once evicted
+	mr.setImageCacheRecord(9, 9, d2enum.Floor, 0, createTestSurface(10, 10))
+	assert.NotNil(mr.getImageCacheRecord(4, 0, d2enum.Floor, 0))
+	SetImageCacheBudget(400)
+	assert.NotContains(imageCacheEvicted, imageCacheLookupIndex(9, 9, d2enum.Floor, 0))
+	assert.Nil(mr.getImageCacheRecord(9, 9, d2enum.Floor, 0))
+}
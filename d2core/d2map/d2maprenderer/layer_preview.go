@@ -0,0 +1,78 @@
+package d2maprenderer
+
+import (
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// LayerType is one of the kinds of tile art the map is composited from
+type LayerType int
+
+const (
+	LayerFloors     LayerType = iota // Floor tiles
+	LayerShadows                     // Shadows cast onto the floor
+	LayerLowerWalls                  // Walls drawn beneath the floor (eg: the far side of a cliff)
+	LayerUpperWalls                  // Walls and doors drawn above the floor
+	LayerRoofs                       // Roofs, drawn above everything else
+)
+
+// Renders a single layer of tile art in isolation (eg: for artists to review the shadows of a map), without the
+// entities, overlays or scene tint. Every visible tile is drawn in one pass, rather than the composite of Render.
+// Unknown layers render nothing.
+func (mr *MapRenderer) RenderLayerOnly(layer LayerType, target d2render.Surface) {
+	mr.LoadTileCache()
+	viewport := mr.viewport
+	if zoom := viewport.GetZoom(); zoom != 1 {
+		target.PushScale(zoom)
+		defer target.Pop()
+		defer viewport.enterRenderSpace()()
+	}
+
+	minX, minY, maxX, maxY := mr.visibleTileBounds(viewport)
+	for tileY := minY; tileY <= maxY; tileY++ {
+		for tileX := minX; tileX <= maxX; tileX++ {
+			if viewport.IsTileVisible(float64(tileX), float64(tileY)) {
+				viewport.PushTranslationWorld(float64(tileX), float64(tileY))
+				mr.renderTileLayer(mr.mapEngine.TileAt(tileX, tileY), layer, target)
+				viewport.PopTranslation()
+			}
+		}
+	}
+}
+
+// Renders the tile art of a single layer of a tile
+func (mr *MapRenderer) renderTileLayer(tile *d2ds1.TileRecord, layer LayerType, target d2render.Surface) {
+	switch layer {
+	case LayerFloors:
+		for _, floor := range tile.Floors {
+			if !floor.Hidden && floor.Prop1 != 0 {
+				mr.renderFloor(floor, target)
+			}
+		}
+	case LayerShadows:
+		for _, shadow := range tile.Shadows {
+			if !shadow.Hidden && shadow.Prop1 != 0 {
+				mr.renderShadow(shadow, target)
+			}
+		}
+	case LayerLowerWalls:
+		for _, wall := range tile.Walls {
+			if !wall.Hidden && wall.Prop1 != 0 && wall.Type.LowerWall() {
+				mr.renderWall(wall, mr.viewport, target)
+			}
+		}
+	case LayerUpperWalls:
+		for _, wall := range tile.Walls {
+			if !wall.Hidden && wall.Type.UpperWall() {
+				mr.renderWall(wall, mr.viewport, target)
+			}
+		}
+	case LayerRoofs:
+		for _, wall := range tile.Walls {
+			if wall.Type == d2enum.Roof {
+				mr.renderWall(wall, mr.viewport, target)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// createTestLayeredMap creates a renderer for a two tile map with art in every layer and an entity, returning the
+// image used for each layer
+func createTestLayeredMap() (*MapRenderer, map[LayerType]d2render.Surface) {
+	mr := createTestMapRenderer(2, 1)
+	images := map[LayerType]d2render.Surface{
+		LayerFloors:     createTestSurface(160, 80),
+		LayerShadows:    createTestSurface(160, 80),
+		LayerLowerWalls: createTestSurface(160, 80),
+		LayerUpperWalls: createTestSurface(160, 200),
+		LayerRoofs:      createTestSurface(160, 80),
+	}
+	mr.setImageCacheRecord(1, 1, 0, 0, images[LayerFloors])
+	mr.setImageCacheRecord(1, 1, 13, 0, images[LayerShadows])
+	mr.setImageCacheRecord(1, 1, d2enum.LowerWallsEquivalentToLeftWall, 0, images[LayerLowerWalls])
+	mr.setImageCacheRecord(1, 1, d2enum.LeftWall, 0, images[LayerUpperWalls])
+	mr.setImageCacheRecord(1, 1, d2enum.Roof, 0, images[LayerRoofs])
+
+	for tileX := 0; tileX < 2; tileX++ {
+		tile := mr.mapEngine.TileAt(tileX, 0)
+		tile.Floors = []d2ds1.FloorShadowRecord{{Style: 1, Sequence: 1, Prop1: 1}}
+		tile.Shadows = []d2ds1.FloorShadowRecord{{Style: 1, Sequence: 1, Prop1: 1}}
+		tile.Walls = []d2ds1.WallRecord{
+			{Type: d2enum.LowerWallsEquivalentToLeftWall, Style: 1, Sequence: 1, Prop1: 1},
+			{Type: d2enum.LeftWall, Style: 1, Sequence: 1, Prop1: 1},
+			{Type: d2enum.Roof, Style: 1, Sequence: 1, Prop1: 1},
+		}
+	}
+	mr.mapEngine.AddEntity(createTestEntity("hero", 0, 0))
+
+	return mr, images
+}
+
+func TestRenderLayerOnlyRendersFloorsOnly(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr, images := createTestLayeredMap()
+
+	target := createTestSurface(800, 600)
+	mr.RenderLayerOnly(LayerFloors, target)
+
+	renders := target.callsOf("render")
+	assert.Len(renders, 2)
+	for _, render := range renders {
+		assert.Equal(images[LayerFloors], render.source)
+	}
+	assert.Equal(-1, indexOfText(target, "entity:hero"))
+}
+
+func TestRenderLayerOnlyRendersEachLayer(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr, images := createTestLayeredMap()
+
+	for layer, image := range images {
+		target := createTestSurface(800, 600)
+		mr.RenderLayerOnly(layer, target)
+
+		renders := target.callsOf("render")
+		assert.Len(renders, 2, "layer %d", layer)
+		for _, render := range renders {
+			assert.Equal(image, render.source, "layer %d", layer)
+		}
+	}
+}
+
+func TestRenderCompositesAllLayers(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr, _ := createTestLayeredMap()
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Len(target.callsOf("render"), 10)
+	assert.NotEqual(-1, indexOfText(target, "entity:hero"))
+}
@@ -0,0 +1,148 @@
+package d2maprenderer
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"math"
+	"os"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// The largest width or height of an exported map image, in pixels
+const maxExportImageSize = 16384
+
+// Creates the surface the whole map is rendered onto when it is exported
+var newExportSurface = func(width, height int) (d2render.Surface, error) {
+	return d2render.NewSurface(width, height, d2render.FilterNearest)
+}
+
+// Renders the entire map (the tiles and entities of every pass) at its native scale, independent of the viewport and
+// camera, and returns the result (eg: for debugging map generation). The image is sized to fit every tile, including
+// the art of tall walls and roofs rising above the top of the map. Overlays and the scene tint are not drawn.
+func (mr *MapRenderer) RenderMapToImage() (image.Image, error) {
+	bounds := mr.mapOrthoBounds()
+	if bounds.Empty() {
+		return nil, errors.New("the map has no tiles to export")
+	}
+	if bounds.Dx() > maxExportImageSize || bounds.Dy() > maxExportImageSize {
+		return nil, errors.New("the map is too large to export")
+	}
+
+	target, err := newExportSurface(bounds.Dx(), bounds.Dy())
+	if err != nil {
+		return nil, err
+	}
+
+	// The camera is placed so the top left corner of the screen is the top left corner of the bounds
+	camera := &Camera{}
+	camera.MoveTo(float64(bounds.Min.X+bounds.Dx()/2), float64(bounds.Min.Y+bounds.Dy()/2))
+	viewport := NewViewport(0, 0, bounds.Dx(), bounds.Dy())
+	viewport.SetCamera(camera)
+	viewport.SetTileSize(mr.viewport.GetTileSize())
+
+	previousViewport, previousBudget := mr.viewport, mr.entityBudget
+	mr.viewport, mr.entityBudget = viewport, nil
+	defer func() { mr.viewport, mr.entityBudget = previousViewport, previousBudget }()
+
+	mr.LoadTileCache()
+	mr.prepareTileLights(viewport)
+	mr.renderPass1(viewport, target)
+	mr.renderPass2(viewport, target)
+	mr.renderPass3(viewport, target)
+	return target.Screenshot(), nil
+}
+
+// Renders the entire map and writes it to a PNG file
+func (mr *MapRenderer) ExportMapPNG(path string) error {
+	result, err := mr.RenderMapToImage()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, result); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Returns the ortho rectangle covered by the map at native scale: the diamond of every tile, and the cached art of
+// every floor, shadow and wall drawn on it
+func (mr *MapRenderer) mapOrthoBounds() image.Rectangle {
+	viewport := NewViewport(0, 0, 0, 0)
+	viewport.SetTileSize(mr.viewport.GetTileSize())
+	var bounds image.Rectangle
+	include := func(x, y, width, height float64) {
+		rect := image.Rect(int(math.Floor(x)), int(math.Floor(y)), int(math.Ceil(x+width)), int(math.Ceil(y+height)))
+		bounds = bounds.Union(rect)
+	}
+
+	mapSize := mr.mapEngine.Size()
+	for tileY := 0; tileY < mapSize.Height; tileY++ {
+		for tileX := 0; tileX < mapSize.Width; tileX++ {
+			orthoX, orthoY := viewport.WorldToOrtho(float64(tileX), float64(tileY))
+			include(orthoX-viewport.tileHalfWidth, orthoY, viewport.tileHalfWidth*2, viewport.tileHalfHeight*2)
+
+			tile := mr.mapEngine.TileAt(tileX, tileY)
+			for _, record := range mr.tileArt(tile) {
+				width, height := record.image.GetSize()
+				include(orthoX+record.offsetX, orthoY+record.offsetY, float64(width), float64(height))
+			}
+		}
+	}
+	return bounds
+}
+
+// tileArt is a cached image drawn for a tile, and its ortho offset from the top corner of the tile
+type tileArt struct {
+	image            d2render.Surface
+	offsetX, offsetY float64
+}
+
+// Returns the cached images drawn for a tile, with the offsets they are drawn at
+func (mr *MapRenderer) tileArt(tile *d2ds1.TileRecord) []tileArt {
+	tileWidth, _ := mr.viewport.GetTileSize()
+	var result []tileArt
+	add := func(image d2render.Surface, offsetX, offsetY float64) {
+		if image != nil {
+			result = append(result, tileArt{image: image, offsetX: offsetX, offsetY: offsetY})
+		}
+	}
+
+	for _, floor := range tile.Floors {
+		if !floor.Hidden && floor.Prop1 != 0 {
+			index := floor.RandomIndex
+			if floor.Animated {
+				index = mr.tileAnimationFrame(floor.FrameCount)
+			}
+			add(mr.getImageCacheRecord(floor.Style, floor.Sequence, d2enum.Floor, index),
+				-tileWidth/2, float64(floor.YAdjust))
+		}
+	}
+	for _, shadow := range tile.Shadows {
+		if !shadow.Hidden && shadow.Prop1 != 0 {
+			add(mr.getImageCacheRecord(shadow.Style, shadow.Sequence, d2enum.Shadow, shadow.RandomIndex),
+				-tileWidth/2, float64(shadow.YAdjust))
+		}
+	}
+	for _, wall := range tile.Walls {
+		if wall.Hidden {
+			continue
+		}
+		index := wall.RandomIndex
+		if wall.Animated {
+			index = mr.tileAnimationFrame(wall.FrameCount)
+		}
+		add(mr.getImageCacheRecord(wall.Style, wall.Sequence, wall.Type, index),
+			float64(wall.XAdjust)+wallOrthoOffsetX, float64(wall.YAdjust)+wallOrthoOffsetY)
+	}
+	return result
+}
@@ -0,0 +1,118 @@
+package d2maprenderer
+
+import (
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// Makes map exports render onto test surfaces, returning a function that restores the renderer surfaces. The surfaces
+// are also sent to the channel, so tests can inspect the draw calls.
+func useTestExportSurfaces(surfaces chan<- *testSurface) func() {
+	previous := newExportSurface
+	newExportSurface = func(width, height int) (d2render.Surface, error) {
+		surface := createTestSurface(width, height)
+		surfaces <- surface
+		return surface, nil
+	}
+	return func() { newExportSurface = previous }
+}
+
+// createTestExportRenderer creates a renderer for a 3x2 map of floors, with a wall on the top tile rising 200 pixels
+// above the floor
+func createTestExportRenderer() (mr *MapRenderer, floor, wall *testSurface) {
+	mr = createTestMapRenderer(3, 2)
+	floor = createTestSurface(160, 80)
+	wall = createTestSurface(160, 280)
+	mr.setImageCacheRecord(1, 1, d2enum.Floor, 0, floor)
+	mr.setImageCacheRecord(2, 1, d2enum.LeftWall, 0, wall)
+
+	for i := range *mr.mapEngine.Tiles() {
+		(*mr.mapEngine.Tiles())[i].Floors = []d2ds1.FloorShadowRecord{{Style: 1, Sequence: 1, Prop1: 1}}
+	}
+	mr.mapEngine.TileAt(0, 0).Walls = []d2ds1.WallRecord{
+		{Type: d2enum.LeftWall, Style: 2, Sequence: 1, Prop1: 1, YAdjust: -192},
+	}
+	return mr, floor, wall
+}
+
+func TestRenderMapToImageFitsWholeMap(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	surfaces := make(chan *testSurface, 1)
+	defer useTestExportSurfaces(surfaces)()
+
+	mr, floor, wall := createTestExportRenderer()
+	// The export does not depend on where the camera is
+	mr.MoveCameraTo(5000, -3000)
+	result, err := mr.RenderMapToImage()
+	if !assert.NoError(err) {
+		return
+	}
+	target := <-surfaces
+
+	// The map is 5 half tiles wide and 5 high along the diagonals, plus the 200 pixels of wall above the top tile
+	assert.Equal(image.Rect(0, 0, 5*80, 5*40+200), result.Bounds())
+
+	renders := target.callsOf("render")
+	assert.Len(renders, 7)
+	for _, call := range renders {
+		width, height := call.source.GetSize()
+		assert.True(call.x >= 0 && call.y >= 0, "drawn at %d,%d", call.x, call.y)
+		assert.True(call.x+width <= 400 && call.y+height <= 400, "drawn at %d,%d", call.x, call.y)
+	}
+
+	// The top of the wall is the top of the image, and the top tile is below it
+	wallCall := target.calls[indexOfRender(target, wall)]
+	assert.Equal(0, wallCall.y)
+	floorCall := target.calls[indexOfRender(target, floor)]
+	assert.Equal(200, floorCall.y)
+
+	// The renderer's own viewport is restored afterwards
+	x, y := mr.viewport.ScreenToOrtho(400, 300)
+	assert.Equal(5000.0, x)
+	assert.Equal(-3000.0, y)
+}
+
+func TestExportMapPNG(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	surfaces := make(chan *testSurface, 1)
+	defer useTestExportSurfaces(surfaces)()
+
+	directory, err := ioutil.TempDir("", "mapexport")
+	if !assert.NoError(err) {
+		return
+	}
+	defer os.RemoveAll(directory)
+
+	mr, _, _ := createTestExportRenderer()
+	path := filepath.Join(directory, "map.png")
+	assert.NoError(mr.ExportMapPNG(path))
+
+	file, err := os.Open(path)
+	if !assert.NoError(err) {
+		return
+	}
+	defer file.Close()
+	config, _, err := image.DecodeConfig(file)
+	assert.NoError(err)
+	assert.Equal(400, config.Width)
+	assert.Equal(400, config.Height)
+}
+
+func TestRenderMapToImageWithoutTiles(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(0, 0)
+
+	_, err := mr.RenderMapToImage()
+	assert.Error(err)
+}
@@ -0,0 +1,52 @@
+package d2maprenderer
+
+import (
+	"image/color"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapengine"
+)
+
+var mapTransitionColor = color.RGBA{A: 255}
+
+// A fade to black, map swap and fade back in, driven through the scene tint
+type mapTransition struct {
+	duration float64                // The length of each of the fade out and fade in, or 0 to swap instantly
+	elapsed  float64                // The time spent fading out so far
+	pending  *d2mapengine.MapEngine // The map engine swapped in once the fade out completes
+}
+
+// Sets the length (in seconds) of the fade out and of the fade in used when SetMapEngine swaps the map. A duration of 0
+// swaps instantly.
+func (mr *MapRenderer) SetMapTransitionDuration(duration float64) {
+	mr.transition.duration = duration
+}
+
+// Returns true while the renderer is fading out towards a map swap
+func (mr *MapRenderer) IsMapTransitionPending() bool {
+	return mr.transition.pending != nil
+}
+
+func (mr *MapRenderer) startMapTransition(mapEngine *d2mapengine.MapEngine) {
+	mr.transition.pending = mapEngine
+	mr.transition.elapsed = 0
+	mr.SetSceneTint(mapTransitionColor, 0)
+}
+
+func (mr *MapRenderer) advanceMapTransition(elapsed float64) {
+	transition := &mr.transition
+	if transition.pending == nil {
+		return
+	}
+
+	transition.elapsed += elapsed
+	if transition.elapsed < transition.duration {
+		mr.SetSceneTint(mapTransitionColor, transition.elapsed/transition.duration)
+		return
+	}
+
+	mr.swapMapEngine(transition.pending)
+	transition.pending = nil
+
+	mr.SetSceneTint(mapTransitionColor, 1)
+	mr.SetSceneTintDuration(transition.duration)
+}
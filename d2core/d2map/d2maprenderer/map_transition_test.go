@@ -0,0 +1,55 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapengine"
+)
+
+func TestMapTransitionSwapsAtPeakDarkness(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	original := mr.mapEngine
+	next := d2mapengine.CreateMapEngine()
+	next.ResetMapTiles(2, 2)
+
+	mr.SetMapTransitionDuration(1)
+	mr.SetMapEngine(next)
+	assert.True(mr.IsMapTransitionPending())
+	assert.Equal(original, mr.mapEngine)
+
+	mr.Advance(0.5)
+	_, strength := mr.GetSceneTint()
+	assert.InDelta(0.5, strength, 0.0001)
+	assert.Equal(original, mr.mapEngine)
+
+	mr.Advance(0.5)
+	_, strength = mr.GetSceneTint()
+	assert.Equal(1.0, strength)
+	assert.Equal(next, mr.mapEngine)
+	assert.False(mr.IsMapTransitionPending())
+
+	mr.Advance(0.5)
+	_, strength = mr.GetSceneTint()
+	assert.InDelta(0.5, strength, 0.0001)
+
+	mr.Advance(0.5)
+	_, strength = mr.GetSceneTint()
+	assert.Equal(0.0, strength)
+}
+
+func TestMapTransitionDisabledSwapsInstantly(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	next := d2mapengine.CreateMapEngine()
+	next.ResetMapTiles(2, 2)
+
+	mr.SetMapEngine(next)
+	assert.Equal(next, mr.mapEngine)
+	assert.False(mr.IsMapTransitionPending())
+
+	_, strength := mr.GetSceneTint()
+	assert.Equal(0.0, strength)
+}
@@ -0,0 +1,85 @@
+package d2maprenderer
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+var (
+	measurementColor      = color.RGBA{R: 255, G: 255, A: 255} // The color of the measured line
+	measurementRulerColor = color.RGBA{R: 255, G: 160, A: 192} // The color of the rulers along the world axes
+	measurementBackground = color.RGBA{A: 192}                 // The color drawn behind the distance labels
+)
+
+// WorldPoint is a position on the map, in world tiles
+type WorldPoint struct {
+	X float64
+	Y float64
+}
+
+// Returns the distance to another point, in world tiles
+func (p WorldPoint) DistanceTo(other WorldPoint) float64 {
+	return math.Hypot(other.X-p.X, other.Y-p.Y)
+}
+
+// The two world points a measurement is drawn between
+type measurement struct {
+	from WorldPoint
+	to   WorldPoint
+}
+
+// Draws a line between two world points (eg: picked with ScreenToWorld) labelled with the distance between them, in
+// tiles and sub-tiles. The measurement is drawn on every render until it is cleared.
+func (mr *MapRenderer) SetMeasurement(p1, p2 WorldPoint) {
+	mr.measurement = &measurement{from: p1, to: p2}
+}
+
+// Removes the measurement
+func (mr *MapRenderer) ClearMeasurement() {
+	mr.measurement = nil
+}
+
+// Sets whether the measurement also draws rulers along the world axes, labelled with the distance along each axis
+func (mr *MapRenderer) SetMeasurementRulers(enabled bool) {
+	mr.measureRulers = enabled
+}
+
+// Returns the label of a distance, in tiles and sub-tiles
+func measurementLabel(prefix string, tiles float64) string {
+	return fmt.Sprintf("%s%.2f tiles (%.1f sub-tiles)", prefix, tiles, tiles*5)
+}
+
+func (mr *MapRenderer) renderMeasurement(target d2render.Surface) {
+	if mr.measurement == nil {
+		return
+	}
+
+	from, to := mr.measurement.from, mr.measurement.to
+	if mr.measureRulers {
+		corner := WorldPoint{X: to.X, Y: from.Y}
+		mr.renderMeasurementLine(from, corner, measurementRulerColor, measurementLabel("x: ", math.Abs(to.X-from.X)), target)
+		mr.renderMeasurementLine(corner, to, measurementRulerColor, measurementLabel("y: ", math.Abs(to.Y-from.Y)), target)
+	}
+
+	mr.renderMeasurementLine(from, to, measurementColor, measurementLabel("", from.DistanceTo(to)), target)
+}
+
+// Draws a line between two world points, with a label centered on it
+func (mr *MapRenderer) renderMeasurementLine(from, to WorldPoint, c color.Color, label string, target d2render.Surface) {
+	fromX, fromY := mr.viewport.WorldToScreen(from.X, from.Y)
+	toX, toY := mr.viewport.WorldToScreen(to.X, to.Y)
+
+	target.PushTranslation(fromX, fromY)
+	target.DrawLine(toX-fromX, toY-fromY, c)
+	target.Pop()
+
+	mr.renderWorldTextLabel(worldTextLabel{
+		worldX:  (from.X + to.X) / 2,
+		worldY:  (from.Y + to.Y) / 2,
+		text:    label,
+		options: WorldTextOptions{Anchor: WorldTextAnchorCenter, Background: measurementBackground},
+	}, target)
+}
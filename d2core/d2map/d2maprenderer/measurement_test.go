@@ -0,0 +1,52 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestMeasurementLabelShowsTileDistance(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+	mr.MoveCameraTo(mr.viewport.WorldToOrtho(5, 5))
+	mr.SetMeasurement(WorldPoint{X: 1, Y: 2}, WorldPoint{X: 4, Y: 6})
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	index := indexOfText(target, "5.00 tiles (25.0 sub-tiles)")
+	assert.NotEqual(-1, index)
+
+	// The line runs between the two points
+	fromX, fromY := mr.viewport.WorldToScreen(1, 2)
+	toX, toY := mr.viewport.WorldToScreen(4, 6)
+	lines := target.callsOf("line")
+	assert.Len(lines, 1)
+	assert.Equal(fromX, lines[0].x)
+	assert.Equal(fromY, lines[0].y)
+	assert.Equal(toX-fromX, lines[0].width)
+	assert.Equal(toY-fromY, lines[0].height)
+
+	mr.ClearMeasurement()
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Equal(-1, indexOfText(target, "5.00 tiles (25.0 sub-tiles)"))
+	assert.Empty(target.callsOf("line"))
+}
+
+func TestMeasurementRulersShowAxisDistances(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+	mr.MoveCameraTo(mr.viewport.WorldToOrtho(5, 5))
+	mr.SetMeasurementRulers(true)
+	mr.SetMeasurement(WorldPoint{X: 6, Y: 6}, WorldPoint{X: 4.5, Y: 8})
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	assert.NotEqual(-1, indexOfText(target, "x: 1.50 tiles (7.5 sub-tiles)"))
+	assert.NotEqual(-1, indexOfText(target, "y: 2.00 tiles (10.0 sub-tiles)"))
+	assert.NotEqual(-1, indexOfText(target, "2.50 tiles (12.5 sub-tiles)"))
+	assert.Len(target.callsOf("line"), 3)
+}
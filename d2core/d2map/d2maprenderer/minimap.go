@@ -0,0 +1,158 @@
+package d2maprenderer
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapengine"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// The width and height of the dot drawn for each entity on the minimap, in pixels
+const miniMapDotSize = 3
+
+// MiniMapStyle holds the colors used by the minimap
+type MiniMapStyle struct {
+	WalkableColor color.RGBA // The sub-tiles that can be walked on
+	WallColor     color.RGBA // The sub-tiles blocked by walls
+	PlayerColor   color.RGBA // The dots of players
+	NPCColor      color.RGBA // The dots of NPCs
+	MissileColor  color.RGBA // The dots of missiles
+	CorpseColor   color.RGBA // The dots of corpses
+	EntityColor   color.RGBA // The dots of every other entity (eg: monsters and objects)
+}
+
+// DefaultMiniMapStyle returns the default minimap colors
+func DefaultMiniMapStyle() MiniMapStyle {
+	return MiniMapStyle{
+		WalkableColor: color.RGBA{R: 60, G: 60, B: 60, A: 160},
+		WallColor:     color.RGBA{R: 200, G: 200, B: 200, A: 220},
+		PlayerColor:   color.RGBA{R: 0, G: 255, B: 0, A: 255},
+		NPCColor:      color.RGBA{R: 255, G: 255, B: 0, A: 255},
+		MissileColor:  color.RGBA{R: 255, G: 128, B: 0, A: 255},
+		CorpseColor:   color.RGBA{R: 120, G: 0, B: 0, A: 255},
+		EntityColor:   color.RGBA{R: 255, G: 0, B: 0, A: 255},
+	}
+}
+
+// MiniMapRenderer draws a small top-down overview of the map around a position (eg: for a corner of the HUD), from
+// the walk mesh and the walls of the tiles. The map is drawn along the world axes rather than isometrically.
+type MiniMapRenderer struct {
+	mapEngine *d2mapengine.MapEngine
+	style     MiniMapStyle
+	scale     int // The width and height of each sub-tile, in pixels
+}
+
+// Creates a minimap renderer for the map, drawing each sub-tile as a single pixel
+func CreateMiniMapRenderer(mapEngine *d2mapengine.MapEngine) *MiniMapRenderer {
+	return &MiniMapRenderer{mapEngine: mapEngine, style: DefaultMiniMapStyle(), scale: 1}
+}
+
+// Sets the map engine the minimap is drawn from
+func (mm *MiniMapRenderer) SetMapEngine(mapEngine *d2mapengine.MapEngine) {
+	mm.mapEngine = mapEngine
+}
+
+// Sets the colors used by the minimap
+func (mm *MiniMapRenderer) SetStyle(style MiniMapStyle) {
+	mm.style = style
+}
+
+// Returns the colors used by the minimap
+func (mm *MiniMapRenderer) GetStyle() MiniMapStyle {
+	return mm.style
+}
+
+// Sets the width and height each sub-tile is drawn at, in pixels (at least 1)
+func (mm *MiniMapRenderer) SetScale(pixelsPerSubTile int) {
+	mm.scale = d2common.MaxInt(pixelsPerSubTile, 1)
+}
+
+// Returns the width and height each sub-tile is drawn at, in pixels
+func (mm *MiniMapRenderer) GetScale() int {
+	return mm.scale
+}
+
+// Renders the map within a radius, in pixels, of the center world position (in tiles). The minimap is a square
+// about twice the radius wide, rounded to whole sub-tiles, whose top left corner is the target's current
+// translation, with the center sub-tile in the middle.
+func (mm *MiniMapRenderer) Render(target d2render.Surface, centerX, centerY float64, pixelRadius int) {
+	cellRadius := pixelRadius / mm.scale
+	originX := int(math.Floor(centerX*5)) - cellRadius
+	originY := int(math.Floor(centerY*5)) - cellRadius
+	cells := cellRadius*2 + 1
+
+	for row := 0; row < cells; row++ {
+		mm.renderRow(originX, originY+row, cells, row*mm.scale, target)
+	}
+
+	for _, entity := range *mm.mapEngine.Entities() {
+		x, y := entityWorldPosition(entity)
+		column := int(math.Floor(x*5)) - originX
+		row := int(math.Floor(y*5)) - originY
+		if column < 0 || row < 0 || column >= cells || row >= cells {
+			continue
+		}
+
+		target.PushTranslation(column*mm.scale+(mm.scale-miniMapDotSize)/2, row*mm.scale+(mm.scale-miniMapDotSize)/2)
+		target.DrawRect(miniMapDotSize, miniMapDotSize, mm.entityColor(entity))
+		target.Pop()
+	}
+}
+
+// Renders a row of sub-tiles, drawing each run of sub-tiles of the same color as a single rectangle
+func (mm *MiniMapRenderer) renderRow(subTileX, subTileY, cells, pixelY int, target d2render.Surface) {
+	runStart := 0
+	runColor, runVisible := mm.subTileColor(subTileX, subTileY)
+	for column := 1; column <= cells; column++ {
+		c, visible := color.RGBA{}, false
+		if column < cells {
+			c, visible = mm.subTileColor(subTileX+column, subTileY)
+			if visible == runVisible && c == runColor {
+				continue
+			}
+		}
+
+		if runVisible {
+			target.PushTranslation(runStart*mm.scale, pixelY)
+			target.DrawRect((column-runStart)*mm.scale, mm.scale, runColor)
+			target.Pop()
+		}
+		runStart, runColor, runVisible = column, c, visible
+	}
+}
+
+// Returns the color of a sub-tile on the minimap, and false if it is not drawn (it is off the map, or blocked without
+// a wall, eg: the void between the rooms of a dungeon)
+func (mm *MiniMapRenderer) subTileColor(subTileX, subTileY int) (color.RGBA, bool) {
+	size := mm.mapEngine.Size()
+	if subTileX < 0 || subTileY < 0 || subTileX >= size.Width*5 || subTileY >= size.Height*5 {
+		return color.RGBA{}, false
+	}
+
+	if (*mm.mapEngine.WalkMesh())[subTileX+subTileY*size.Width*5].Walkable {
+		return mm.style.WalkableColor, true
+	}
+	if len(mm.mapEngine.TileAt(subTileX/5, subTileY/5).Walls) > 0 {
+		return mm.style.WallColor, true
+	}
+	return color.RGBA{}, false
+}
+
+// Returns the color of an entity's dot on the minimap
+func (mm *MiniMapRenderer) entityColor(entity d2mapentity.MapEntity) color.RGBA {
+	switch entity.(type) {
+	case *d2mapentity.Player:
+		return mm.style.PlayerColor
+	case *d2mapentity.NPC:
+		return mm.style.NPCColor
+	case *d2mapentity.Missile:
+		return mm.style.MissileColor
+	case *d2mapentity.Corpse:
+		return mm.style.CorpseColor
+	default:
+		return mm.style.EntityColor
+	}
+}
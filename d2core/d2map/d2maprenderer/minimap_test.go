@@ -0,0 +1,108 @@
+package d2maprenderer
+
+import (
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapengine"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// testCorpseAnimation is a death animation with a single frame
+type testCorpseAnimation struct{}
+
+func (testCorpseAnimation) Render(target d2render.Surface) error { return nil }
+func (testCorpseAnimation) Advance(elapsed float64) error        { return nil }
+func (testCorpseAnimation) SetPlayLoop(loop bool)                {}
+func (testCorpseAnimation) PlayForward()                         {}
+func (testCorpseAnimation) IsOnLastFrame() bool                  { return true }
+
+// createTestMiniMapEngine creates a 2x2 tile map whose top left tile can be walked on and whose top right tile has a
+// wall. The bottom tiles are blocked without walls.
+func createTestMiniMapEngine() *d2mapengine.MapEngine {
+	engine := d2mapengine.CreateMapEngine()
+	engine.ResetMapTiles(2, 2)
+	walkMesh := *engine.WalkMesh()
+	for subTileY := 0; subTileY < 5; subTileY++ {
+		for subTileX := 0; subTileX < 5; subTileX++ {
+			walkMesh[subTileX+subTileY*10].Walkable = true
+		}
+	}
+	engine.TileAt(1, 0).Walls = []d2ds1.WallRecord{{Type: d2enum.LeftWall}}
+	return engine
+}
+
+func TestMiniMapDrawsWalkableAreasAndWalls(t *testing.T) {
+	assert := testify.New(t)
+	mm := CreateMiniMapRenderer(createTestMiniMapEngine())
+	style := mm.GetStyle()
+	target := createTestSurface(100, 100)
+
+	// The minimap is 11 sub-tiles wide, starting 3 sub-tiles above and left of the map
+	mm.Render(target, 0.5, 0.5, 5)
+
+	rects := target.callsOf("rect")
+	assert.Len(rects, 10)
+	for row := 0; row < 5; row++ {
+		walkable, wall := rects[row*2], rects[row*2+1]
+		assert.Equal(testDrawCall{op: "rect", x: 3, y: 3 + row, width: 5, height: 1, color: style.WalkableColor, scale: 1},
+			walkable)
+		assert.Equal(testDrawCall{op: "rect", x: 8, y: 3 + row, width: 3, height: 1, color: style.WallColor, scale: 1},
+			wall)
+	}
+	assert.Equal(0, target.GetDepth())
+}
+
+func TestMiniMapOnlyDrawsAroundTheCenter(t *testing.T) {
+	assert := testify.New(t)
+	mm := CreateMiniMapRenderer(createTestMiniMapEngine())
+	mm.SetScale(2)
+	target := createTestSurface(100, 100)
+
+	// A radius of 2 sub-tiles around the center of the top left tile
+	mm.Render(target, 0.5, 0.5, 4)
+
+	rects := target.callsOf("rect")
+	assert.Len(rects, 5)
+	for row, rect := range rects {
+		assert.Equal(0, rect.x)
+		assert.Equal(row*2, rect.y)
+		assert.Equal(10, rect.width)
+		assert.Equal(2, rect.height)
+	}
+}
+
+func TestMiniMapColorsEntityDotsByType(t *testing.T) {
+	assert := testify.New(t)
+	engine := createTestMiniMapEngine()
+	engine.AddEntity(createTestEntity("monster", 1, 0))
+	engine.AddEntity(d2mapentity.CreateCorpse(2, 2, testCorpseAnimation{}))
+	engine.AddEntity(createTestEntity("far away", 5, 5))
+	mm := CreateMiniMapRenderer(engine)
+	mm.SetScale(3)
+	style := mm.GetStyle()
+	target := createTestSurface(100, 100)
+
+	mm.Render(target, 0.5, 0.5, 15)
+
+	var dots []testDrawCall
+	for _, rect := range target.callsOf("rect") {
+		if rect.width == miniMapDotSize && rect.height == miniMapDotSize {
+			dots = append(dots, rect)
+		}
+	}
+	if assert.Len(dots, 2) {
+		// The minimap starts 3 sub-tiles left of the map, at 3 pixels a sub-tile
+		assert.Equal(color.Color(style.EntityColor), dots[0].color)
+		assert.Equal(24, dots[0].x)
+		assert.Equal(9, dots[0].y)
+		assert.Equal(color.Color(style.CorpseColor), dots[1].color)
+		assert.Equal(15, dots[1].x)
+		assert.Equal(15, dots[1].y)
+	}
+}
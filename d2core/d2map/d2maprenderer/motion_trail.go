@@ -0,0 +1,100 @@
+package d2maprenderer
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// motionTrail is the fading copies drawn behind a fast moving entity (eg: a projectile or a dash)
+type motionTrail struct {
+	length int     // The number of copies drawn behind the entity
+	fade   float64 // The opacity of each copy relative to the copy in front of it (0-1)
+}
+
+// The motion trails drawn behind entities
+type motionTrails map[d2mapentity.MapEntity]*motionTrail
+
+// Draws fading copies of the entity at its recent locations behind it: its locations after each of its recent entity
+// ticks, as kept by the map engine. Each copy is drawn with the opacity of the copy in front of it multiplied by the
+// fade (0-1). A length of 0 removes the trail.
+func (mr *MapRenderer) SetMotionTrail(entity d2mapentity.MapEntity, length int, fade float64) {
+	if length <= 0 {
+		mr.ClearMotionTrail(entity)
+		return
+	}
+	if mr.trails == nil {
+		mr.trails = make(motionTrails)
+	}
+
+	fade = math.Max(0, math.Min(1, fade))
+	if trail, found := mr.trails[entity]; found {
+		trail.length, trail.fade = length, fade
+	} else {
+		mr.trails[entity] = &motionTrail{length: length, fade: fade}
+	}
+	mr.updateEntitySampleLimit()
+}
+
+// Stops drawing the motion trail behind the entity (eg: once it has been removed from the map)
+func (mr *MapRenderer) ClearMotionTrail(entity d2mapentity.MapEntity) {
+	delete(mr.trails, entity)
+	mr.updateEntitySampleLimit()
+}
+
+// Has the map engine keep enough recent locations of each entity for the longest motion trail: one for the entity and
+// one per copy
+func (mr *MapRenderer) updateEntitySampleLimit() {
+	longest := 0
+	for _, trail := range mr.trails {
+		longest = d2common.MaxInt(longest, trail.length)
+	}
+	mr.mapEngine.SetEntitySampleLimit(longest + 1)
+}
+
+// Returns the sub tile location of an entity, or the location of its tile if it does not report one
+func entityLocation(entity d2mapentity.MapEntity) (float64, float64) {
+	if locatable, ok := entity.(d2mapentity.Locatable); ok {
+		return locatable.GetLocation()
+	}
+	x, y := entity.GetPosition()
+	return x * 5, y * 5
+}
+
+// Returns the screen offset of a sub tile offset, at the viewport's tile size
+func (v *Viewport) subTileScreenOffset(offsetX, offsetY float64) (int, int) {
+	return int(math.Round((offsetX - offsetY) * v.tileHalfWidth / subTilesPerTile)),
+		int(math.Round((offsetX + offsetY) * v.tileHalfHeight / subTilesPerTile))
+}
+
+// Draws the copies of the entity's motion trail relative to where the entity is drawn at the current translation,
+// oldest first so that the copies nearest the entity are drawn over the older ones
+func (mr *MapRenderer) renderMotionTrail(mapEntity d2mapentity.MapEntity, target d2render.Surface) {
+	trail, found := mr.trails[mapEntity]
+	if !found {
+		return
+	}
+
+	samples := mr.mapEngine.EntitySamples(mapEntity)
+	if len(samples) > trail.length+1 {
+		samples = samples[:trail.length+1]
+	}
+
+	x, y := mr.mapEngine.InterpolatedLocation(mapEntity)
+	for i := len(samples) - 1; i >= 1; i-- {
+		alpha := uint8(255 * math.Pow(trail.fade, float64(i)))
+		if alpha == 0 {
+			continue
+		}
+
+		offsetX, offsetY := samples[i][0]-x, samples[i][1]-y
+		target.PushTranslation(mr.viewport.subTileScreenOffset(offsetX, offsetY))
+		// color.RGBA is alpha premultiplied, so white at the copy's opacity leaves the colors of the entity unchanged
+		target.PushColor(color.RGBA{R: alpha, G: alpha, B: alpha, A: alpha})
+		mapEntity.Render(target)
+		target.PopN(2)
+	}
+}
@@ -0,0 +1,142 @@
+package d2maprenderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+)
+
+// Advances the map engine once with the entity at each of the sub tile locations along the x axis
+func moveTrailedEntity(mr *MapRenderer, entity *spriteEntity, locations ...float64) {
+	for _, x := range locations {
+		entity.locationX = x
+		mr.mapEngine.Advance(0.01)
+	}
+}
+
+func TestMotionTrailDrawsFadingCopies(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	entity := createSpriteEntity("bolt", 0, 0, createTestSurface(10, 10))
+	mr.mapEngine.AddEntity(entity)
+	mr.SetMotionTrail(entity, 3, 0.5)
+	moveTrailedEntity(mr, entity, 0, 1, 2, 3, 4)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	calls := target.callsOf("text")
+	assert.Len(calls, 4)
+	current := calls[3]
+	assert.Empty(current.colors)
+
+	// The copies are drawn oldest and faintest first, at the recent locations one sub tile apart
+	for i, copyCall := range calls[:3] {
+		behind := 3 - i
+		alpha := []uint8{127, 63, 31}[behind-1]
+		assert.Equal([]color.Color{color.RGBA{R: alpha, G: alpha, B: alpha, A: alpha}}, copyCall.colors)
+		assert.Equal(current.x-behind*16, copyCall.x)
+		assert.Equal(current.y-behind*8, copyCall.y)
+	}
+	assert.Equal(0, target.GetDepth())
+}
+
+func TestMotionTrailGrowsWithSamples(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	entity := createSpriteEntity("bolt", 0, 0, createTestSurface(10, 10))
+	mr.mapEngine.AddEntity(entity)
+	mr.SetMotionTrail(entity, 3, 0.5)
+	moveTrailedEntity(mr, entity, 0, 1)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Len(target.callsOf("text"), 2)
+}
+
+func TestMotionTrailLengthChange(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	entity := createSpriteEntity("bolt", 0, 0, createTestSurface(10, 10))
+	mr.mapEngine.AddEntity(entity)
+	mr.SetMotionTrail(entity, 4, 0.5)
+	moveTrailedEntity(mr, entity, 0, 1, 2, 3, 4)
+
+	mr.SetMotionTrail(entity, 2, 0.5)
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Len(target.callsOf("text"), 3)
+
+	mr.SetMotionTrail(entity, 0, 0.5)
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Len(target.callsOf("text"), 1)
+}
+
+func TestClearMotionTrail(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	entity := createSpriteEntity("bolt", 0, 0, createTestSurface(10, 10))
+	mr.mapEngine.AddEntity(entity)
+	mr.SetMotionTrail(entity, 3, 0.5)
+	moveTrailedEntity(mr, entity, 0, 1, 2, 3)
+	mr.ClearMotionTrail(entity)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Len(target.callsOf("text"), 1)
+}
+
+func TestMotionTrailFadesAnimatedEntity(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	entity := createTestAnimatedEntity(0, 0, 4, 4, d2dat.DATColor{R: 255, G: 255, B: 255})
+	mr.mapEngine.AddEntity(entity)
+	mr.SetMotionTrail(entity, 2, 0.5)
+	for x := 0.0; x <= 2; x++ {
+		entity.SetPosition(x, 0)
+		mr.mapEngine.Advance(0.01)
+	}
+
+	target := createTestSoftwareSurface(800, 600)
+	_ = target.Clear(color.Black)
+	mr.Render(target)
+	pixels := target.Screenshot()
+
+	// The copies keep the shape of the animation, faded over the black background one sub tile apart
+	sprite := findTestColorBounds(pixels, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	assert.Equal(image.Pt(4, 4), sprite.Size())
+	assert.Equal(sprite.Sub(image.Pt(16, 8)), findTestColorBounds(pixels, color.RGBA{R: 127, G: 127, B: 127, A: 255}))
+	assert.Equal(sprite.Sub(image.Pt(32, 16)), findTestColorBounds(pixels, color.RGBA{R: 63, G: 63, B: 63, A: 255}))
+	assert.Equal(0, target.GetDepth())
+}
+
+func TestMotionTrailFollowsEntityTicks(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	entity := createSpriteEntity("bolt", 0, 0, createTestSurface(10, 10))
+	mr.mapEngine.AddEntity(entity)
+	mr.mapEngine.SetEntityTickRate(4)
+	mr.SetMotionTrail(entity, 2, 0.5)
+
+	// The renderer advancing between entity ticks does not add copies
+	entity.locationX = 1
+	mr.mapEngine.Advance(0.25)
+	for i := 0; i < 5; i++ {
+		mr.Advance(0.01)
+	}
+	entity.locationX = 2
+	mr.mapEngine.Advance(0.25)
+
+	// Half way to the next tick, the entity is drawn half way between its last two locations, ahead of the copy
+	mr.mapEngine.Advance(0.125)
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	calls := target.callsOf("text")
+	assert.Len(calls, 2)
+	assert.Equal(calls[1].x-8, calls[0].x)
+}
@@ -0,0 +1,49 @@
+package d2maprenderer
+
+import (
+	"sync"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2asset"
+)
+
+// Loads a palette file (replaced in tests, which run without the game archives)
+var loadPaletteFile = d2asset.LoadPalette
+
+// The palettes already loaded for each region type. The tile cache may be regenerated off the main thread, so the
+// cache is guarded by a mutex.
+var (
+	paletteCacheMutex   sync.Mutex
+	paletteCacheRecords map[d2enum.RegionIdType]*d2dat.DATPalette
+)
+
+// Invalidates the global palette cache, so palettes are loaded again on next use (eg: after the assets are reloaded)
+func InvalidatePaletteCache() {
+	paletteCacheMutex.Lock()
+	defer paletteCacheMutex.Unlock()
+
+	paletteCacheRecords = nil
+}
+
+// Returns the palette for a region type, loading it on first use. Palettes that fail to load are not cached, so the
+// load is retried next time.
+func loadPaletteForAct(levelType d2enum.RegionIdType) (*d2dat.DATPalette, error) {
+	paletteCacheMutex.Lock()
+	defer paletteCacheMutex.Unlock()
+
+	if palette, found := paletteCacheRecords[levelType]; found {
+		return palette, nil
+	}
+
+	palette, err := readPaletteForAct(levelType)
+	if err != nil {
+		return nil, err
+	}
+
+	if paletteCacheRecords == nil {
+		paletteCacheRecords = make(map[d2enum.RegionIdType]*d2dat.DATPalette)
+	}
+	paletteCacheRecords[levelType] = palette
+	return palette, nil
+}
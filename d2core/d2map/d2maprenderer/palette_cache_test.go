@@ -0,0 +1,112 @@
+package d2maprenderer
+
+import (
+	"sync"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2resource"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2asset"
+)
+
+// Replaces the palette loader with one that counts the loads of each path, returning a function that restores it
+func useTestPaletteLoader(loads map[string]int, missing map[string]bool) func() {
+	var mutex sync.Mutex
+	previous := loadPaletteFile
+	loadPaletteFile = func(path string) (*d2dat.DATPalette, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		loads[path]++
+		if missing[path] {
+			return nil, &d2asset.ErrNotFound{Path: path}
+		}
+		return &d2dat.DATPalette{}, nil
+	}
+	InvalidatePaletteCache()
+	return func() {
+		loadPaletteFile = previous
+		InvalidatePaletteCache()
+	}
+}
+
+func TestPaletteCacheLoadsEachRegionOnce(t *testing.T) {
+	assert := testify.New(t)
+	loads := make(map[string]int)
+	defer useTestPaletteLoader(loads, nil)()
+
+	first, err := loadPaletteForAct(d2enum.RegionAct2Town)
+	assert.NoError(err)
+	second, err := loadPaletteForAct(d2enum.RegionAct2Town)
+	assert.NoError(err)
+
+	assert.Same(first, second)
+	assert.Equal(1, loads[d2resource.PaletteAct2])
+}
+
+func TestPaletteCacheInvalidateReloads(t *testing.T) {
+	assert := testify.New(t)
+	loads := make(map[string]int)
+	defer useTestPaletteLoader(loads, nil)()
+
+	first, _ := loadPaletteForAct(d2enum.RegionAct1Town)
+	InvalidatePaletteCache()
+	second, _ := loadPaletteForAct(d2enum.RegionAct1Town)
+
+	assert.True(first != second)
+	assert.Equal(2, loads[d2resource.PaletteAct1])
+}
+
+func TestPaletteCacheDoesNotCacheFailures(t *testing.T) {
+	assert := testify.New(t)
+	loads := make(map[string]int)
+	missing := map[string]bool{d2resource.PaletteAct5: true, d2resource.PaletteAct1: true}
+	defer useTestPaletteLoader(loads, missing)()
+
+	_, err := loadPaletteForAct(d2enum.RegonAct5Town)
+	assert.Error(err)
+
+	delete(missing, d2resource.PaletteAct1)
+	palette, err := loadPaletteForAct(d2enum.RegonAct5Town)
+	assert.NoError(err)
+	assert.NotNil(palette)
+	assert.Equal(2, loads[d2resource.PaletteAct5])
+}
+
+func TestPaletteCacheUnknownRegion(t *testing.T) {
+	assert := testify.New(t)
+	loads := make(map[string]int)
+	defer useTestPaletteLoader(loads, nil)()
+
+	_, err := loadPaletteForAct(d2enum.RegionIdType(-1))
+	assert.Error(err)
+	assert.Empty(loads)
+}
+
+func TestPaletteCacheConcurrentLoads(t *testing.T) {
+	assert := testify.New(t)
+	loads := make(map[string]int)
+	defer useTestPaletteLoader(loads, nil)()
+
+	const workers = 16
+	palettes := make([]*d2dat.DATPalette, workers)
+	errs := make([]error, workers)
+	var wait sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			palettes[i], errs[i] = loadPaletteForAct(d2enum.RegionAct3Jungle)
+		}(i)
+	}
+	wait.Wait()
+
+	for i := 0; i < workers; i++ {
+		assert.NoError(errs[i])
+		assert.Same(palettes[0], palettes[i])
+	}
+	assert.Equal(1, loads[d2resource.PaletteAct3])
+}
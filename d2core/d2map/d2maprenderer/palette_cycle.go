@@ -0,0 +1,201 @@
+package d2maprenderer
+
+import (
+	"errors"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2asset"
+)
+
+// The three palette ranges rotated by default to animate liquids on the act palettes: a fast range for the glow of
+// lava, a medium one for the ripple of water and a slow one for the shimmer of deep water. The colors they rotate are
+// those of the act's PL2 base palette.
+var DefaultTilePaletteCycles = []d2asset.PaletteCycle{
+	{First: 1, Last: 8, StepsPerSecond: 10},
+	{First: 9, Last: 16, StepsPerSecond: 6},
+	{First: 17, Last: 24, StepsPerSecond: 3},
+}
+
+// Loads a palette transform file (replaced in tests, which run without the game archives)
+var loadPaletteTransformFile = d2asset.LoadPaletteTransform
+
+// paletteIndexSet is a set of palette indices (eg: the colors used by a cached tile image)
+type paletteIndexSet [4]uint64
+
+func (s *paletteIndexSet) add(index byte) {
+	s[index>>6] |= 1 << (index & 63)
+}
+
+func (s *paletteIndexSet) addRange(first, last byte) {
+	for index := int(first); index <= int(last); index++ {
+		s.add(byte(index))
+	}
+}
+
+func (s paletteIndexSet) intersects(other paletteIndexSet) bool {
+	for i := range s {
+		if s[i]&other[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// tilePaletteCycles are the palette ranges rotated to animate the tiles of the map
+type tilePaletteCycles struct {
+	enabled bool                   // Whether the ranges are rotated as the renderer advances
+	ranges  []d2asset.PaletteCycle // The rotated ranges
+	steps   []int                  // How far along each range the colors have moved
+	elapsed float64                // The time the ranges have been rotated for, in seconds
+	base    *d2dat.DATPalette      // The palette of the map before the ranges are rotated
+}
+
+// Returns the tile palette cycles of a new renderer, with the default ranges registered
+func newTilePaletteCycles() tilePaletteCycles {
+	return tilePaletteCycles{
+		ranges: append([]d2asset.PaletteCycle(nil), DefaultTilePaletteCycles...),
+		steps:  make([]int, len(DefaultTilePaletteCycles)),
+	}
+}
+
+// Enables or disables rotating the palette ranges of the tiles as the renderer advances. Only the cached tile images
+// drawn with the rotated colors are decoded again when a range steps, a few each frame as they are drawn. Disabling
+// the cycling restores the original colors.
+func (mr *MapRenderer) SetPaletteCycling(enabled bool) {
+	if mr.paletteCycles.enabled == enabled {
+		return
+	}
+
+	mr.paletteCycles.enabled = enabled
+	if !enabled {
+		mr.resetPaletteCycles()
+	}
+}
+
+// Returns whether the palette ranges of the tiles are rotated as the renderer advances
+func (mr *MapRenderer) IsPaletteCycling() bool {
+	return mr.paletteCycles.enabled
+}
+
+// Registers a palette range to rotate along with the default ranges (eg: for the liquids of a custom tile set)
+func (mr *MapRenderer) RegisterPaletteCycle(cycle d2asset.PaletteCycle) error {
+	if cycle.Last <= cycle.First {
+		return errors.New("a palette cycle needs at least two palette indices")
+	}
+	if cycle.StepsPerSecond <= 0 {
+		return errors.New("a palette cycle needs a positive speed")
+	}
+	for _, other := range mr.paletteCycles.ranges {
+		if cycle.First <= other.Last && other.First <= cycle.Last {
+			return errors.New("the palette cycle overlaps a registered cycle")
+		}
+	}
+
+	mr.paletteCycles.ranges = append(mr.paletteCycles.ranges, cycle)
+	mr.paletteCycles.steps = append(mr.paletteCycles.steps, 0)
+	return nil
+}
+
+// Unregisters every palette range, including the default ranges, restoring the original colors
+func (mr *MapRenderer) ClearPaletteCycles() {
+	mr.resetPaletteCycles()
+	mr.paletteCycles.ranges = nil
+	mr.paletteCycles.steps = nil
+}
+
+// Returns the registered palette ranges
+func (mr *MapRenderer) PaletteCycles() []d2asset.PaletteCycle {
+	return append([]d2asset.PaletteCycle(nil), mr.paletteCycles.ranges...)
+}
+
+// Returns the palette the tiles of a region are decoded with and their palette ranges are rotated from: the base
+// palette of the act's PL2 file, with the transparent index of the act palette, or the act palette itself if the PL2
+// file cannot be loaded
+func loadTilePaletteForAct(levelType d2enum.RegionIdType) (*d2dat.DATPalette, error) {
+	palette, err := loadPaletteForAct(levelType)
+	if err != nil {
+		return nil, err
+	}
+
+	_, transformPath, _ := actPalettePaths(levelType)
+	transform, err := loadPaletteTransformFile(transformPath)
+	if err != nil {
+		return palette, nil
+	}
+
+	result := &d2dat.DATPalette{TransparentIndex: palette.TransparentIndex}
+	for i, c := range transform.BasePalette.Colors {
+		result.Colors[i] = d2dat.DATColor{R: c.R, G: c.G, B: c.B}
+	}
+	return result, nil
+}
+
+// Rotates the palette ranges by the elapsed time (in seconds)
+func (mr *MapRenderer) advancePaletteCycles(elapsed float64) {
+	cycles := &mr.paletteCycles
+	if !cycles.enabled || len(cycles.ranges) == 0 {
+		return
+	}
+
+	cycles.elapsed += elapsed
+	var changed paletteIndexSet
+	stepped := false
+	for i, cycle := range cycles.ranges {
+		if step := cycle.Step(cycles.elapsed); step != cycles.steps[i] {
+			cycles.steps[i] = step
+			changed.addRange(cycle.First, cycle.Last)
+			stepped = true
+		}
+	}
+
+	if stepped {
+		mr.applyPaletteCycles(changed)
+	}
+}
+
+// Moves every palette range back to its original colors
+func (mr *MapRenderer) resetPaletteCycles() {
+	cycles := &mr.paletteCycles
+	cycles.elapsed = 0
+
+	var changed paletteIndexSet
+	stepped := false
+	for i, cycle := range cycles.ranges {
+		if cycles.steps[i] != 0 {
+			cycles.steps[i] = 0
+			changed.addRange(cycle.First, cycle.Last)
+			stepped = true
+		}
+	}
+
+	if stepped {
+		mr.applyPaletteCycles(changed)
+	}
+}
+
+// Recolors the palette of the map with the current steps of the ranges, and drops the cached tile images that use
+// the changed palette indices, so they are decoded with the new colors when they are next drawn
+func (mr *MapRenderer) applyPaletteCycles(changed paletteIndexSet) {
+	if mr.paletteCycles.base == nil {
+		return
+	}
+
+	mr.palette = mr.cyclePalette(mr.paletteCycles.base)
+	invalidateImageCacheColors(changed)
+}
+
+// Returns the palette with each range rotated by its current step, or the palette itself if no range has moved
+func (mr *MapRenderer) cyclePalette(palette *d2dat.DATPalette) *d2dat.DATPalette {
+	if palette == nil {
+		return nil
+	}
+
+	result := palette
+	for i, cycle := range mr.paletteCycles.ranges {
+		if mr.paletteCycles.steps[i] != 0 {
+			result = cycle.Shift(result, mr.paletteCycles.steps[i])
+		}
+	}
+	return result
+}
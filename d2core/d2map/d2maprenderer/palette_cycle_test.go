@@ -0,0 +1,244 @@
+package d2maprenderer
+
+import (
+	"image"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dt1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2pl2"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2asset"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// Returns a palette whose colors are each their own index in red
+func createTestGradientPalette() *d2dat.DATPalette {
+	palette := &d2dat.DATPalette{}
+	for i := range palette.Colors {
+		palette.Colors[i] = d2dat.DATColor{R: byte(i)}
+	}
+	return palette
+}
+
+// Caches a placeholder image for the floor of a tile, as if it was decoded from the palette indices
+func cacheTestFloor(mr *MapRenderer, tileX int, style byte, colors ...byte) {
+	mr.mapEngine.TileAt(tileX, 0).Floors = []d2ds1.FloorShadowRecord{{Style: style, Prop1: 1}}
+	mr.cachingTile = &image.Point{X: tileX}
+	for _, index := range colors {
+		mr.decodedColors.add(index)
+	}
+	mr.setImageCacheRecord(style, 0, d2enum.Floor, 0, createTestSurface(10, 10))
+	mr.cachingTile = nil
+}
+
+// createTestCyclingRenderer creates a renderer over two floors, the first drawn with the palette indices of a cycle
+// from 1 to 4 stepping once a second and the second drawn with other indices
+func createTestCyclingRenderer() *MapRenderer {
+	mr := createTestMapRenderer(2, 1)
+	mr.paletteCycles.base = createTestGradientPalette()
+	mr.palette = mr.paletteCycles.base
+	mr.ClearPaletteCycles()
+	mr.RegisterPaletteCycle(d2asset.PaletteCycle{First: 1, Last: 4, StepsPerSecond: 1})
+
+	cacheTestFloor(mr, 0, 1, 2, 3, 50)
+	cacheTestFloor(mr, 1, 2, 50, 60)
+	return mr
+}
+
+func TestDecodedTileRecordsItsPaletteIndices(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+
+	mr := createTestMapRenderer(1, 1)
+	mr.palette = createTestGradientPalette()
+	block := d2dt1.Block{Length: 5, EncodedData: []byte{0, 3, 3, 0, 40}}
+	pixels := make([]byte, 4*3)
+	mr.decodeTileGfxData([]d2dt1.Block{block}, &pixels, 0, 0, 3)
+	mr.setImageCacheRecord(1, 0, d2enum.Floor, 0, createTestSurface(3, 1))
+
+	// The transparent index is not drawn, so it is not recorded
+	var expected paletteIndexSet
+	expected.add(3)
+	expected.add(40)
+	record := imageCacheRecords[imageCacheLookupIndex(1, 0, d2enum.Floor, 0)].Value.(*imageCacheRecord)
+	assert.Equal(expected, record.colors)
+	assert.Equal(paletteIndexSet{}, mr.decodedColors)
+}
+
+func TestPaletteCyclingInvalidatesOnlyAffectedTiles(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestTileSurfaces()()
+
+	mr := createTestCyclingRenderer()
+	mr.SetPaletteCycling(true)
+	assert.True(mr.IsPaletteCycling())
+
+	// The cycle has not stepped yet
+	mr.Advance(0.5)
+	assert.Equal(2, mr.TileCacheStats().Records)
+	assert.Same(mr.paletteCycles.base, mr.palette)
+
+	mr.Advance(0.5)
+	assert.Equal(byte(2), mr.palette.Colors[1].R)
+	assert.Equal(byte(1), mr.palette.Colors[4].R)
+	assert.Equal(byte(5), mr.palette.Colors[5].R)
+
+	// Only the image drawn with the cycled colors is stale, and it is kept until it is next drawn
+	cycled := imageCacheRecords[imageCacheLookupIndex(1, 0, d2enum.Floor, 0)].Value.(*imageCacheRecord)
+	other := imageCacheRecords[imageCacheLookupIndex(2, 0, d2enum.Floor, 0)].Value.(*imageCacheRecord)
+	assert.True(cycled.stale)
+	assert.False(other.stale)
+	assert.Equal(2, mr.TileCacheStats().Records)
+	assert.Equal(0, mr.TileCacheStats().Evictions)
+
+	// The stale image is generated again when it is next drawn, and the old one disposed of before the next frame
+	refreshed := mr.getImageCacheRecord(1, 0, d2enum.Floor, 0)
+	assert.NotNil(refreshed)
+	assert.False(cycled.surface == refreshed)
+	assert.Same(other.surface, mr.getImageCacheRecord(2, 0, d2enum.Floor, 0))
+	assert.Equal(2, mr.TileCacheStats().Records)
+	assert.False(cycled.surface.(*testSurface).disposed)
+	mr.Render(createTestSurface(800, 600))
+	assert.True(cycled.surface.(*testSurface).disposed)
+	assert.False(refreshed.(*testSurface).disposed)
+}
+
+func TestStaleImagesRefreshedWithinFrameBudget(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestTileSurfaces()()
+
+	mr := createTestMapRenderer(staleImageRefreshBudget+1, 1)
+	mr.paletteCycles.base = createTestGradientPalette()
+	mr.palette = mr.paletteCycles.base
+	for x := 0; x <= staleImageRefreshBudget; x++ {
+		cacheTestFloor(mr, x, byte(x+1), 1)
+	}
+	var colors paletteIndexSet
+	colors.add(1)
+	assert.Equal(staleImageRefreshBudget+1, invalidateImageCacheColors(colors))
+
+	// The images past the budget are drawn as they are until the next frame
+	stale := make([]d2render.Surface, staleImageRefreshBudget+1)
+	for x := range stale {
+		stale[x] = imageCacheRecords[imageCacheLookupIndex(byte(x+1), 0, d2enum.Floor, 0)].Value.(*imageCacheRecord).surface
+		surface := mr.getImageCacheRecord(byte(x+1), 0, d2enum.Floor, 0)
+		if x < staleImageRefreshBudget {
+			assert.False(stale[x] == surface)
+		} else {
+			assert.Same(stale[x], surface)
+		}
+	}
+
+	mr.staleRefreshes = 0
+	refreshed := mr.getImageCacheRecord(staleImageRefreshBudget+1, 0, d2enum.Floor, 0)
+	assert.False(stale[staleImageRefreshBudget] == refreshed)
+}
+
+func TestDisablingPaletteCyclingRestoresColors(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestTileSurfaces()()
+
+	mr := createTestCyclingRenderer()
+	mr.SetPaletteCycling(true)
+	mr.Advance(2)
+	assert.Equal(byte(3), mr.palette.Colors[1].R)
+
+	cacheTestFloor(mr, 0, 1, 2)
+	mr.SetPaletteCycling(false)
+	assert.False(mr.IsPaletteCycling())
+	assert.Equal(*mr.paletteCycles.base, *mr.palette)
+	assert.True(imageCacheRecords[imageCacheLookupIndex(1, 0, d2enum.Floor, 0)].Value.(*imageCacheRecord).stale)
+
+	// The cycle starts from the original colors when enabled again
+	mr.SetPaletteCycling(true)
+	mr.Advance(0.5)
+	assert.Equal(byte(1), mr.palette.Colors[1].R)
+}
+
+func TestPaletteCyclingFollowsTilePause(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestTileSurfaces()()
+
+	mr := createTestCyclingRenderer()
+	mr.SetPaletteCycling(true)
+	mr.tilesPaused = true
+	mr.Advance(3)
+	assert.Equal(byte(1), mr.palette.Colors[1].R)
+	assert.Equal(2, mr.TileCacheStats().Records)
+
+	mr.tilesPaused = false
+	mr.Advance(1)
+	assert.Equal(byte(2), mr.palette.Colors[1].R)
+}
+
+func TestPaletteCyclingDisabledByDefault(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestTileSurfaces()()
+
+	mr := createTestCyclingRenderer()
+	mr.Advance(1)
+	assert.False(mr.IsPaletteCycling())
+	assert.Same(mr.paletteCycles.base, mr.palette)
+	assert.Equal(2, mr.TileCacheStats().Records)
+}
+
+func TestRegisterPaletteCycle(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	assert.Equal(DefaultTilePaletteCycles, mr.PaletteCycles())
+	assert.Len(DefaultTilePaletteCycles, 3)
+
+	assert.Error(mr.RegisterPaletteCycle(d2asset.PaletteCycle{First: 30, Last: 30, StepsPerSecond: 1}))
+	assert.Error(mr.RegisterPaletteCycle(d2asset.PaletteCycle{First: 30, Last: 40}))
+	assert.Error(mr.RegisterPaletteCycle(d2asset.PaletteCycle{First: 20, Last: 40, StepsPerSecond: 1}))
+
+	custom := d2asset.PaletteCycle{First: 200, Last: 210, StepsPerSecond: 4}
+	assert.NoError(mr.RegisterPaletteCycle(custom))
+	assert.Equal(append(append([]d2asset.PaletteCycle(nil), DefaultTilePaletteCycles...), custom), mr.PaletteCycles())
+
+	mr.ClearPaletteCycles()
+	assert.Empty(mr.PaletteCycles())
+}
+
+// Replaces the palette transform loader with one that returns the PL2 file, or fails if it is nil, returning a
+// function that restores it
+func useTestPaletteTransformLoader(pl2 *d2pl2.PL2File) func() {
+	previous := loadPaletteTransformFile
+	loadPaletteTransformFile = func(path string) (*d2pl2.PL2File, error) {
+		if pl2 == nil {
+			return nil, &d2asset.ErrNotFound{Path: path}
+		}
+		return pl2, nil
+	}
+	return func() { loadPaletteTransformFile = previous }
+}
+
+func TestTilePaletteFromActPL2(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestPaletteLoader(make(map[string]int), nil)()
+	pl2 := &d2pl2.PL2File{}
+	pl2.BasePalette.Colors[5] = d2pl2.PL2Color{R: 10, G: 20, B: 30}
+	defer useTestPaletteTransformLoader(pl2)()
+
+	// The colors of the PL2 base palette are rotated, and the transparent index of the act palette is kept
+	palette, err := loadTilePaletteForAct(d2enum.RegionAct1Town)
+	assert.NoError(err)
+	assert.Equal(d2dat.DATColor{R: 10, G: 20, B: 30}, palette.Colors[5])
+	assert.Equal(byte(0), palette.TransparentIndex)
+}
+
+func TestTilePaletteWithoutActPL2(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestPaletteLoader(make(map[string]int), nil)()
+	defer useTestPaletteTransformLoader(nil)()
+
+	act, err := loadPaletteForAct(d2enum.RegionAct2Town)
+	assert.NoError(err)
+	palette, err := loadTilePaletteForAct(d2enum.RegionAct2Town)
+	assert.NoError(err)
+	assert.Same(act, palette)
+}
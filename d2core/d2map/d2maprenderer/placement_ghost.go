@@ -0,0 +1,56 @@
+package d2maprenderer
+
+import (
+	"image/color"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// The color the placement ghost is drawn with, which makes it semi-transparent
+var placementGhostColor = color.RGBA{R: 255, G: 255, B: 255, A: 128}
+
+// placementGhost is a preview of an object being placed on the map (eg: while dragging it in an editor)
+type placementGhost struct {
+	sprite       d2render.Surface
+	tileX, tileY int
+}
+
+// Draws a semi-transparent preview of the sprite centered on the tile, until it is cleared. A nil sprite clears the
+// preview.
+func (mr *MapRenderer) SetPlacementGhost(sprite d2render.Surface, tileX, tileY int) {
+	if sprite == nil {
+		mr.ClearPlacementGhost()
+		return
+	}
+	mr.ghost = &placementGhost{sprite: sprite, tileX: tileX, tileY: tileY}
+}
+
+// Draws a semi-transparent preview of the sprite on the tile under the screen position (eg: the mouse cursor), snapped
+// to the center of the tile. Returns the tile the preview was placed on.
+func (mr *MapRenderer) SetPlacementGhostAtScreen(sprite d2render.Surface, screenX, screenY int) (tileX, tileY int) {
+	tileX, tileY = mr.tileAtScreen(screenX, screenY)
+	mr.SetPlacementGhost(sprite, tileX, tileY)
+	return tileX, tileY
+}
+
+// Stops drawing the placement preview (eg: once the object has been placed)
+func (mr *MapRenderer) ClearPlacementGhost() {
+	mr.ghost = nil
+}
+
+// Returns the tile the placement preview is drawn on, and whether there is a preview
+func (mr *MapRenderer) GetPlacementGhostTile() (tileX, tileY int, found bool) {
+	if mr.ghost == nil {
+		return 0, 0, false
+	}
+	return mr.ghost.tileX, mr.ghost.tileY, true
+}
+
+func (mr *MapRenderer) renderPlacementGhost(target d2render.Surface) {
+	screenX, screenY := mr.viewport.WorldToScreen(float64(mr.ghost.tileX)+0.5, float64(mr.ghost.tileY)+0.5)
+	target.PushTranslation(screenX, screenY)
+	target.PushColor(placementGhostColor)
+	defer target.PopN(2)
+
+	_ = target.Render(mr.ghost.sprite)
+}
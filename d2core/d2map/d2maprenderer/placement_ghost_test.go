@@ -0,0 +1,66 @@
+package d2maprenderer
+
+import (
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestPlacementGhostRendersAtTileWithAlpha(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(4, 4)
+	sprite := createTestSurface(32, 32)
+
+	mr.SetPlacementGhost(sprite, 2, 1)
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	index := indexOfRender(target, sprite)
+	if !assert.NotEqual(-1, index) {
+		return
+	}
+	call := target.calls[index]
+	x, y := mr.viewport.WorldToScreen(2.5, 1.5)
+	assert.Equal(x, call.x)
+	assert.Equal(y, call.y)
+	assert.Contains(call.colors, color.Color(placementGhostColor))
+	assert.True(placementGhostColor.A < 255)
+}
+
+func TestPlacementGhostSnapsScreenPositionToTile(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(4, 4)
+	sprite := createTestSurface(32, 32)
+
+	// Anywhere within the tile snaps the ghost to the tile center
+	screenX, screenY := mr.viewport.WorldToScreen(1.2, 2.9)
+	tileX, tileY := mr.SetPlacementGhostAtScreen(sprite, screenX, screenY)
+	assert.Equal(1, tileX)
+	assert.Equal(2, tileY)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	call := target.calls[indexOfRender(target, sprite)]
+	x, y := mr.viewport.WorldToScreen(1.5, 2.5)
+	assert.Equal(x, call.x)
+	assert.Equal(y, call.y)
+}
+
+func TestClearPlacementGhost(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(4, 4)
+	sprite := createTestSurface(32, 32)
+
+	mr.SetPlacementGhost(sprite, 0, 0)
+	_, _, found := mr.GetPlacementGhostTile()
+	assert.True(found)
+
+	mr.ClearPlacementGhost()
+	_, _, found = mr.GetPlacementGhostTile()
+	assert.False(found)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Equal(-1, indexOfRender(target, sprite))
+}
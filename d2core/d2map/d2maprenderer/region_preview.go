@@ -0,0 +1,48 @@
+package d2maprenderer
+
+import (
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapengine"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// Creates a map renderer for previewing a map (eg: in the region list of an editor), without the terminal commands.
+// The tile images are not cached until LoadTileCache is called or the map is first rendered in full, so the map's
+// summary and RenderPreview are available without decoding any tile art.
+func CreateMapPreviewRenderer(mapEngine *d2mapengine.MapEngine) *MapRenderer {
+	result := newMapRenderer(mapEngine, NewViewport(0, 0, 800, 600))
+	result.tilesDeferred = true
+	result.watchTileChanges()
+	result.moveCameraToStart()
+	return result
+}
+
+// Caches the tile images of a map whose caching was deferred by CreateMapPreviewRenderer (eg: when the region is
+// opened for editing). Does nothing once the images are cached.
+func (mr *MapRenderer) LoadTileCache() {
+	if !mr.tilesDeferred {
+		return
+	}
+
+	mr.tilesDeferred = false
+	mr.generateTileCache()
+}
+
+// Returns true if the tile images have not been cached yet
+func (mr *MapRenderer) IsTileCacheDeferred() bool {
+	return mr.tilesDeferred
+}
+
+// Renders a low detail, top-down preview of the whole map from its walk mesh and walls (see MiniMapRenderer), within a
+// square of the size, in pixels, at the target's current translation. No tile images are needed.
+func (mr *MapRenderer) RenderPreview(target d2render.Surface, size int) {
+	mapSize := mr.mapEngine.Size()
+	subTiles := d2common.MaxInt(mapSize.Width, mapSize.Height) * 5
+	if subTiles == 0 {
+		return
+	}
+
+	miniMap := CreateMiniMapRenderer(mr.mapEngine)
+	miniMap.SetScale(size / subTiles)
+	miniMap.Render(target, float64(mapSize.Width)/2, float64(mapSize.Height)/2, size/2)
+}
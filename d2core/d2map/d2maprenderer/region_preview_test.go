@@ -0,0 +1,114 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapengine"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// Makes the tile cache draw to test surfaces, counting the surfaces created. Returns a function that restores the
+// renderer surfaces and the cache.
+func useCountedTileSurfaces(created *int) func() {
+	restore := useTestTileSurfaces()
+	previous := newTileSurface
+	newTileSurface = func(width, height int, filter d2render.Filter) (d2render.Surface, error) {
+		*created++
+		return previous(width, height, filter)
+	}
+	return func() {
+		newTileSurface = previous
+		restore()
+	}
+}
+
+// createTestPreviewEngine creates a 3x2 map with a floor on each tile, and a wall and an entity on the first tile. The
+// floors have no tile data, so each is cached as a placeholder image.
+func createTestPreviewEngine() *d2mapengine.MapEngine {
+	engine := d2mapengine.CreateMapEngine()
+	engine.ResetMapTiles(3, 2)
+	for i := range *engine.Tiles() {
+		(*engine.Tiles())[i].Floors = []d2ds1.FloorShadowRecord{{Style: byte(i + 1), Prop1: 1}}
+	}
+	engine.TileAt(0, 0).Walls = []d2ds1.WallRecord{{Type: d2enum.LeftWall}}
+	engine.AddEntity(createTestEntity("chest", 0, 0))
+	return engine
+}
+
+func TestPreviewRendererDoesNotDecodeTiles(t *testing.T) {
+	assert := testify.New(t)
+	created := 0
+	defer useCountedTileSurfaces(&created)()
+	defer useTestPaletteLoader(map[string]int{}, nil)()
+
+	mr := CreateMapPreviewRenderer(createTestPreviewEngine())
+	summary := mr.mapEngine.Summary()
+	assert.Equal(3, summary.Width)
+	assert.Equal(2, summary.Height)
+	assert.Equal(1, summary.WallLayers)
+	assert.Equal(1, summary.Entities)
+	assert.True(mr.IsTileCacheDeferred())
+
+	// Changing a tile of the previewed map does not cache it either
+	mr.mapEngine.SetTile(1, 1, d2ds1.TileRecord{Floors: []d2ds1.FloorShadowRecord{{Style: 20, Prop1: 1}}})
+
+	target := createTestSurface(60, 60)
+	mr.RenderPreview(target, 60)
+	assert.NotEmpty(target.callsOf("rect"))
+	assert.Equal(0, created)
+	assert.Equal(0, mr.TileCacheStats().Records)
+}
+
+func TestPreviewRendererCachesTilesWhenOpened(t *testing.T) {
+	assert := testify.New(t)
+	created := 0
+	defer useCountedTileSurfaces(&created)()
+	defer useTestPaletteLoader(map[string]int{}, nil)()
+
+	mr := CreateMapPreviewRenderer(createTestPreviewEngine())
+	mr.LoadTileCache()
+	assert.False(mr.IsTileCacheDeferred())
+	assert.Equal(6, created)
+
+	// The images are only cached once
+	mr.LoadTileCache()
+	assert.Equal(6, created)
+}
+
+func TestPreviewRendererCachesTilesOnFirstRender(t *testing.T) {
+	assert := testify.New(t)
+	created := 0
+	defer useCountedTileSurfaces(&created)()
+	defer useTestPaletteLoader(map[string]int{}, nil)()
+
+	mr := CreateMapPreviewRenderer(createTestPreviewEngine())
+	mr.Render(createTestSurface(800, 600))
+	assert.False(mr.IsTileCacheDeferred())
+	assert.NotNil(mr.getImageCacheRecord(1, 0, d2enum.Floor, 0))
+}
+
+func TestPreviewRendererKeepsMainRendererWatchingTiles(t *testing.T) {
+	assert := testify.New(t)
+	created := 0
+	defer useCountedTileSurfaces(&created)()
+	defer useTestPaletteLoader(map[string]int{}, nil)()
+
+	engine := createTestPreviewEngine()
+	mr := newMapRenderer(engine, NewViewport(0, 0, 800, 600))
+	mr.watchTileChanges()
+	mr.generateTileCache()
+
+	// Previewing the live map does not stop the main renderer caching the tiles that change
+	preview := CreateMapPreviewRenderer(engine)
+	engine.SetTile(1, 1, d2ds1.TileRecord{Floors: []d2ds1.FloorShadowRecord{{Style: 20, Prop1: 1}}})
+	assert.NotNil(mr.getImageCacheRecord(20, 0, d2enum.Floor, 0))
+
+	preview.Close()
+	mr.Close()
+	engine.SetTile(1, 1, d2ds1.TileRecord{Floors: []d2ds1.FloorShadowRecord{{Style: 21, Prop1: 1}}})
+	assert.Nil(mr.getImageCacheRecord(21, 0, d2enum.Floor, 0))
+}
@@ -0,0 +1,65 @@
+package d2maprenderer
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapengine"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// Creates the surface a region thumbnail is rendered onto
+var newThumbnailSurface = func(width, height int) (d2render.Surface, error) {
+	return d2render.NewSurface(width, height, d2render.FilterLinear)
+}
+
+// Loads the map engine for a region (eg: by generating it with d2mapgen)
+type RegionLoader func(regionType d2enum.RegionIdType) (*d2mapengine.MapEngine, error)
+
+// The options used to render region thumbnails
+type ThumbnailOptions struct {
+	Width     int                   // The width of each thumbnail, in pixels
+	Height    int                   // The height of each thumbnail, in pixels
+	Scale     float64               // The scale the region is drawn at (eg: 0.25 shows 4 times the thumbnail's area)
+	Configure func(mr *MapRenderer) // Configures the renderer before each region is drawn (optional)
+}
+
+// Renders a downscaled preview of each region, centered on the region, for a level select or warp screen. The
+// regions are loaded and drawn offscreen, and the thumbnails are returned in the same order as the regions.
+func RenderRegionThumbnails(regionTypes []d2enum.RegionIdType, load RegionLoader,
+	options ThumbnailOptions) ([]*image.RGBA, error) {
+	scale := options.Scale
+	if scale <= 0 {
+		scale = 1
+	}
+
+	result := make([]*image.RGBA, 0, len(regionTypes))
+	for _, regionType := range regionTypes {
+		mapEngine, err := load(regionType)
+		if err != nil {
+			return nil, fmt.Errorf("loading region %d: %v", regionType, err)
+		}
+
+		target, err := newThumbnailSurface(options.Width, options.Height)
+		if err != nil {
+			return nil, err
+		}
+
+		viewport := NewViewport(0, 0, int(float64(options.Width)/scale), int(float64(options.Height)/scale))
+		mr := newMapRenderer(mapEngine, viewport)
+		if mapEngine.LevelType().Id != 0 {
+			mr.generateTileCache()
+		}
+		mr.camera.MoveTo(viewport.WorldToOrtho(mapEngine.GetCenterPosition()))
+		if options.Configure != nil {
+			options.Configure(mr)
+		}
+
+		target.PushScale(scale)
+		result = append(result, mr.RenderToImage(target))
+		target.Pop()
+	}
+
+	return result, nil
+}
@@ -0,0 +1,83 @@
+package d2maprenderer
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapengine"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// useTestThumbnailSurfaces makes region thumbnails render onto test surfaces. Returns a function that restores the
+// original surface factory.
+func useTestThumbnailSurfaces() func() {
+	original := newThumbnailSurface
+	newThumbnailSurface = func(width, height int) (d2render.Surface, error) {
+		return createTestSurface(width, height), nil
+	}
+	return func() { newThumbnailSurface = original }
+}
+
+// Returns true if none of the pixels of the image have been drawn
+func isBlankImage(img *image.RGBA) bool {
+	for _, value := range img.Pix {
+		if value != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRenderRegionThumbnails(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestThumbnailSurfaces()()
+
+	sizes := map[d2enum.RegionIdType]int{
+		d2enum.RegionAct1Town:       4,
+		d2enum.RegionAct1Wilderness: 8,
+		d2enum.RegionAct2Town:       2,
+	}
+	var loaded []d2enum.RegionIdType
+	load := func(regionType d2enum.RegionIdType) (*d2mapengine.MapEngine, error) {
+		loaded = append(loaded, regionType)
+		engine := d2mapengine.CreateMapEngine()
+		engine.ResetMapTiles(sizes[regionType], sizes[regionType])
+		return engine, nil
+	}
+
+	regions := []d2enum.RegionIdType{d2enum.RegionAct1Town, d2enum.RegionAct1Wilderness, d2enum.RegionAct2Town}
+	thumbnails, err := RenderRegionThumbnails(regions, load, ThumbnailOptions{
+		Width:     80,
+		Height:    60,
+		Scale:     0.1,
+		Configure: func(mr *MapRenderer) { mr.debugVisLevel = 1 },
+	})
+
+	assert.Nil(err)
+	assert.Equal(regions, loaded)
+	assert.Len(thumbnails, 3)
+	for i, thumbnail := range thumbnails {
+		assert.Equal(image.Rect(0, 0, 80, 60), thumbnail.Bounds())
+		assert.False(isBlankImage(thumbnail), "region %d", regions[i])
+	}
+}
+
+func TestRenderRegionThumbnailsLoadError(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestThumbnailSurfaces()()
+
+	load := func(regionType d2enum.RegionIdType) (*d2mapengine.MapEngine, error) {
+		return nil, errors.New("missing preset")
+	}
+
+	thumbnails, err := RenderRegionThumbnails([]d2enum.RegionIdType{d2enum.RegionAct1Town}, load, ThumbnailOptions{
+		Width:  80,
+		Height: 60,
+	})
+	assert.Nil(thumbnails)
+	assert.NotNil(err)
+}
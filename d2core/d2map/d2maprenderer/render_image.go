@@ -0,0 +1,96 @@
+package d2maprenderer
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// The result of comparing two rendered images
+type ImageDiff struct {
+	DifferentPixels int             // The number of pixels where a channel differs by more than the tolerance
+	MaxDelta        int             // The largest difference found in any channel
+	Bounds          image.Rectangle // The smallest rectangle containing all of the differing pixels
+}
+
+func (d ImageDiff) String() string {
+	if d.DifferentPixels == 0 {
+		return "images match"
+	}
+
+	return fmt.Sprintf("%d pixels differ (max channel delta %d) within %v", d.DifferentPixels, d.MaxDelta, d.Bounds)
+}
+
+// Renders the map onto the target surface and returns a screenshot of the result (eg: for visual regression tests)
+func (mr *MapRenderer) RenderToImage(target d2render.Surface) *image.RGBA {
+	mr.Render(target)
+	return target.Screenshot()
+}
+
+// Creates the surface a frame is rendered onto by RenderToImageAt
+var newFrameSurface = func(width, height int) (d2render.Surface, error) {
+	return d2render.NewSurface(width, height, d2render.FilterNearest)
+}
+
+// Renders the map from the current camera at an explicit resolution, independent of the size of the viewport, and
+// returns the result (eg: to capture cutscene frames at 1920x1080 whatever the window size). The map is culled to the
+// area visible at that resolution, and keeps the viewport's zoom, tile size and cull margin.
+func (mr *MapRenderer) RenderToImageAt(width, height int) (*image.RGBA, error) {
+	if width <= 0 || height <= 0 {
+		return nil, errors.New("the frame size must be positive")
+	}
+	if width > maxExportImageSize || height > maxExportImageSize {
+		return nil, errors.New("the frame size is too large")
+	}
+
+	target, err := newFrameSurface(width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	previousViewport := mr.viewport
+	mr.viewport = mr.viewport.resized(width, height)
+	defer func() { mr.viewport = previousViewport }()
+
+	return mr.RenderToImage(target), nil
+}
+
+// Compares two images pixel by pixel. Channels that differ by no more than the tolerance are considered equal.
+func CompareImages(expected, actual *image.RGBA, tolerance int) (ImageDiff, error) {
+	var diff ImageDiff
+	if expected.Bounds().Size() != actual.Bounds().Size() {
+		return diff, errors.New("image sizes do not match")
+	}
+
+	size := expected.Bounds().Size()
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			expectedOffset := expected.PixOffset(expected.Rect.Min.X+x, expected.Rect.Min.Y+y)
+			actualOffset := actual.PixOffset(actual.Rect.Min.X+x, actual.Rect.Min.Y+y)
+
+			pixelDelta := 0
+			for i := 0; i < 4; i++ {
+				delta := int(expected.Pix[expectedOffset+i]) - int(actual.Pix[actualOffset+i])
+				if delta < 0 {
+					delta = -delta
+				}
+				if delta > pixelDelta {
+					pixelDelta = delta
+				}
+			}
+
+			if pixelDelta > diff.MaxDelta {
+				diff.MaxDelta = pixelDelta
+			}
+
+			if pixelDelta > tolerance {
+				diff.DifferentPixels++
+				diff.Bounds = diff.Bounds.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+
+	return diff, nil
+}
@@ -0,0 +1,210 @@
+package d2maprenderer
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden images in testdata")
+
+// Compares an image against a golden image in testdata, rewriting the golden image when -update is passed
+func assertGoldenImage(t *testing.T, name string, actual *image.RGBA) {
+	assert := testify.New(t)
+	path := filepath.Join("testdata", name)
+
+	if *updateGolden {
+		file, err := os.Create(path)
+		assert.Nil(err)
+		defer file.Close()
+		assert.Nil(png.Encode(file, actual))
+		return
+	}
+
+	file, err := os.Open(path)
+	if !assert.Nil(err) {
+		return
+	}
+	defer file.Close()
+
+	decoded, err := png.Decode(file)
+	if !assert.Nil(err) {
+		return
+	}
+
+	expected := image.NewRGBA(decoded.Bounds())
+	for y := decoded.Bounds().Min.Y; y < decoded.Bounds().Max.Y; y++ {
+		for x := decoded.Bounds().Min.X; x < decoded.Bounds().Max.X; x++ {
+			expected.Set(x, y, decoded.At(x, y))
+		}
+	}
+
+	diff, err := CompareImages(expected, actual, 2)
+	assert.Nil(err)
+	assert.Equal(0, diff.DifferentPixels, "%s: %v", name, diff)
+}
+
+func TestCompareImages(t *testing.T) {
+	assert := testify.New(t)
+	expected := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	actual := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	actual.Set(1, 1, color.RGBA{R: 2})
+	actual.Set(2, 3, color.RGBA{G: 50})
+
+	diff, err := CompareImages(expected, actual, 2)
+	assert.Nil(err)
+	assert.Equal(1, diff.DifferentPixels)
+	assert.Equal(50, diff.MaxDelta)
+	assert.Equal(image.Rect(2, 3, 3, 4), diff.Bounds)
+
+	_, err = CompareImages(expected, image.NewRGBA(image.Rect(0, 0, 2, 2)), 0)
+	assert.NotNil(err)
+}
+
+// Creates a software surface of a tile image, with its isometric diamond filled with one color
+func createTestDiamondImage(width, height int, c color.RGBA) d2render.Surface {
+	surface := createTestSoftwareSurface(width, height)
+	pixels := make([]byte, 4*width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dx, dy := 2*x-width+1, 2*y-height+1
+			if dx < 0 {
+				dx = -dx
+			}
+			if dy < 0 {
+				dy = -dy
+			}
+			if dx*height+dy*width <= width*height {
+				copy(pixels[4*(y*width+x):], []byte{c.R, c.G, c.B, c.A})
+			}
+		}
+	}
+	_ = surface.ReplacePixels(pixels)
+	return surface
+}
+
+func TestRenderFixtureMatchesGolden(t *testing.T) {
+	defer InvalidateImageCache()
+
+	// A checkerboard of floors, a shadow, an entity standing in the middle of the map, the tile grid and a scene tint
+	mr := createTestMapRenderer(3, 3)
+	mr.setImageCacheRecord(1, 1, d2enum.Floor, 0, createTestDiamondImage(160, 80, color.RGBA{R: 60, G: 120, B: 60, A: 255}))
+	mr.setImageCacheRecord(2, 1, d2enum.Floor, 0, createTestDiamondImage(160, 80, color.RGBA{R: 120, G: 100, B: 60, A: 255}))
+	mr.setImageCacheRecord(3, 1, d2enum.Shadow, 0, createTestDiamondImage(80, 40, color.RGBA{A: 255}))
+	for i := range *mr.mapEngine.Tiles() {
+		tile := &(*mr.mapEngine.Tiles())[i]
+		tile.Floors = []d2ds1.FloorShadowRecord{{Style: byte(1 + i%2), Sequence: 1, Prop1: 1}}
+	}
+	mr.mapEngine.TileAt(1, 1).Shadows = []d2ds1.FloorShadowRecord{{Style: 3, Sequence: 1, Prop1: 1, YAdjust: 20}}
+	mr.mapEngine.AddEntity(createTestAnimatedEntity(7, 7, 12, 30, d2dat.DATColor{R: 200, G: 40, B: 40}))
+
+	mr.viewport = NewViewport(0, 0, 320, 240)
+	mr.viewport.SetCamera(&mr.camera)
+	mr.debugVisLevel = 1
+	mr.MoveCameraTo(mr.WorldToOrtho(1.5, 1.5))
+	mr.SetSceneTint(color.RGBA{R: 255, A: 255}, 0.25)
+
+	target := createTestSoftwareSurface(320, 240)
+	_ = target.Clear(color.Black)
+
+	assertGoldenImage(t, "render_fixture.png", mr.RenderToImage(target))
+}
+
+// Makes RenderToImageAt render onto test surfaces, returning a function that restores the renderer surfaces. The
+// surfaces are also sent to the channel, so tests can inspect the draw calls.
+func useTestFrameSurfaces(surfaces chan<- *testSurface) func() {
+	previous := newFrameSurface
+	newFrameSurface = func(width, height int) (d2render.Surface, error) {
+		surface := createTestSurface(width, height)
+		surfaces <- surface
+		return surface, nil
+	}
+	return func() { newFrameSurface = previous }
+}
+
+// Renders the map through RenderToImageAt, returning the frame and the draw calls of the floor image
+func renderTestFloorFrame(t *testing.T, mr *MapRenderer, floor *testSurface, width, height int) (*image.RGBA,
+	[]testDrawCall) {
+	surfaces := make(chan *testSurface, 1)
+	defer useTestFrameSurfaces(surfaces)()
+	result, err := mr.RenderToImageAt(width, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var floors []testDrawCall
+	for _, call := range (<-surfaces).callsOf("render") {
+		if call.source == floor {
+			floors = append(floors, call)
+		}
+	}
+	return result, floors
+}
+
+func TestRenderToImageAtExplicitSize(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+
+	mr := createTestMapRenderer(40, 40)
+	floor := createTestSurface(160, 80)
+	mr.setImageCacheRecord(1, 1, d2enum.Floor, 0, floor)
+	for i := range *mr.mapEngine.Tiles() {
+		(*mr.mapEngine.Tiles())[i].Floors = []d2ds1.FloorShadowRecord{{Style: 1, Sequence: 1, Prop1: 1}}
+	}
+	mr.MoveCameraTo(mr.WorldToOrtho(20, 20))
+
+	result, small := renderTestFloorFrame(t, mr, floor, 320, 240)
+	assert.Equal(image.Rect(0, 0, 320, 240), result.Bounds())
+
+	result, large := renderTestFloorFrame(t, mr, floor, 1920, 1080)
+	assert.Equal(image.Rect(0, 0, 1920, 1080), result.Bounds())
+
+	// The larger frame shows more of the map, but tiles beyond its edges are still culled
+	assert.True(len(large) > len(small), "%d floors drawn at 1920x1080, %d at 320x240", len(large), len(small))
+	assert.True(len(large) < 40*40, "%d floors drawn", len(large))
+
+	// The same tiles are drawn, at the same positions, as by a renderer whose viewport is 1920x1080
+	native := createTestMapRenderer(40, 40)
+	native.mapEngine = mr.mapEngine
+	native.viewport = NewViewport(0, 0, 1920, 1080)
+	native.viewport.SetCamera(&native.camera)
+	native.MoveCameraTo(mr.WorldToOrtho(20, 20))
+	nativeTarget := createTestSurface(1920, 1080)
+	native.Render(nativeTarget)
+	assert.Equal(nativeTarget.callsOf("render"), large)
+
+	// The frame is covered to its corners, and the tile under the camera is drawn at the center of the frame
+	var covered image.Rectangle
+	centered := false
+	for _, call := range large {
+		covered = covered.Union(image.Rect(call.x, call.y, call.x+160, call.y+80))
+		centered = centered || (call.x == 960-80 && call.y == 540)
+	}
+	assert.True(result.Bounds().In(covered), "floors cover %v", covered)
+	assert.True(centered)
+
+	// The renderer's own viewport is unchanged
+	assert.Equal(800, mr.viewport.screenRect.Width)
+	assert.Equal(600, mr.viewport.screenRect.Height)
+}
+
+func TestRenderToImageAtRejectsInvalidSizes(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(3, 3)
+
+	_, err := mr.RenderToImageAt(0, 100)
+	assert.NotNil(err)
+	_, err = mr.RenderToImageAt(100, maxExportImageSize+1)
+	assert.NotNil(err)
+}
@@ -2,76 +2,361 @@ package d2maprenderer
 
 import (
 	"errors"
+	"image"
 	"image/color"
 	"log"
+	"math"
 
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapengine"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
 
 	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
 	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2resource"
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2asset"
 
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
 	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
 	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2term"
 )
 
+const (
+	tileFrameLength = 0.1 // The length of a tile animation frame, in seconds
+	tileFrameCount  = 10  // The number of frames assumed for animated tiles with an unknown frame count
+)
+
 // The map renderer, used to render the map
 type MapRenderer struct {
-	mapEngine     *d2mapengine.MapEngine // The map engine that is being rendered
-	palette       *d2dat.DATPalette      // The palette used for this map
-	viewport      *Viewport              // The viewport for the map renderer (used for rendering offsets)
-	camera        Camera                 // The camera for this map renderer (used to determine where on the map we are rendering)
-	debugVisLevel int                    // Debug visibility index (0=none, 1=tiles, 2=sub-tiles)
-	lastFrameTime float64                // The last time the map was rendered
-	currentFrame  int                    // The current render frame (for animations)
+	mapEngine      *d2mapengine.MapEngine // The map engine that is being rendered
+	palette        *d2dat.DATPalette      // The palette used for this map
+	viewport       *Viewport              // The viewport for the map renderer (used for rendering offsets)
+	camera         Camera                 // The camera for this map renderer (used to determine where on the map we are rendering)
+	debugVisLevel  int                    // Debug visibility index (0=none, 1=tiles, 2=sub-tiles, 3=walkability)
+	debugStyle     DebugStyle             // The colors used by the debug visualization
+	lastFrameTime  float64                // The last time the map was rendered
+	currentFrame   int                    // The number of tile animation frames advanced (each tile wraps it by its frame count)
+	sceneTint      sceneTint              // The full screen tint drawn after all passes
+	highlight      color.RGBA             // The color of the outline drawn around highlighted entities
+	transition     mapTransition          // The fade used when swapping map engines
+	revealMask     revealMask             // The spotlight outside of which the map is darkened
+	worldText      []worldTextLabel       // The text labels queued to be drawn on the next render
+	occlusion      bool                   // Whether floors at the base of walls are darkened
+	maxEntities    int                    // The maximum number of entities rendered per frame (0=no limit)
+	entityBudget   entitySet              // The entities selected for rendering this frame (nil=all)
+	debugTile      *image.Point           // The only tile that draws the detailed debug overlay (nil=all tiles)
+	frameBudget    float64                // The time a frame may take before the optional overlays are skipped (0=unlimited)
+	timings        RenderTimings          // The time spent in each part of the last render
+	measurement    *measurement           // The distance measurement drawn over the map (nil=none)
+	measureRulers  bool                   // Whether the measurement is drawn with rulers along the world axes
+	entityDraws    []entityDraw           // The entity draws of the tile being rendered (reused between tiles)
+	cameraTarget   d2mapentity.MapEntity  // The entity the camera follows (nil=none)
+	deadZone       float64                // How far the followed entity can move from the screen center, in pixels
+	subTileLabels  bool                   // Whether the sub-tile debug overlay labels each sub-tile with its index
+	cachingTile    *image.Point           // The tile whose images are being cached (nil=not generating the tile cache)
+	ghost          *placementGhost        // The preview of an object being placed (nil=none)
+	shadowOrder    ShadowOrder            // Whether shadows are drawn over or under floors
+	regionShadows  regionShadowOrders     // The shadow order of region types that differ from shadowOrder
+	trails         motionTrails           // The fading copies drawn behind fast moving entities
+	tilesPaused    bool                   // Whether the tile animations are frozen on their current frame
+	entityPaused   bool                   // Whether the map engine's entities are frozen
+	warpDebug      bool                   // Whether the warps are drawn with lines from the start and their destinations
+	globalLight    float64                // The brightness floors and walls are drawn at (0=black, 1=full brightness)
+	axisGizmo      bool                   // Whether the world axes are drawn at the origin
+	lighting       tileLighting           // The point lights that light the tiles this frame
+	tilesDeferred  bool                   // Whether the tile images are not cached until the map is first rendered
+	boundsDebug    bool                   // Whether the culling and picking rectangles are outlined
+	paletteCycles  tilePaletteCycles      // The palette ranges rotated to animate liquid tiles
+	decodedColors  paletteIndexSet        // The palette indices used by the tile image being decoded
+	staleRefreshes int                    // The stale tile images decoded again during the current frame
+	stopTileWatch  func()                 // Stops caching the tiles the map engine replaces (nil=not watching)
 }
 
 // Creates an instance of the map renderer
 func CreateMapRenderer(mapEngine *d2mapengine.MapEngine) *MapRenderer {
-	result := &MapRenderer{
-		mapEngine: mapEngine,
-		viewport:  NewViewport(0, 0, 800, 600),
-	}
-
-	result.viewport.SetCamera(&result.camera)
+	result := newMapRenderer(mapEngine, NewViewport(0, 0, 800, 600))
 
-	d2term.BindAction("mapdebugvis", "set map debug visualization level", func(level int) {
+	d2term.BindAction("mapdebugvis", "set map debug visualization level (0=none, 1=tiles, 2=sub-tiles, 3=walkability)", func(level int) {
 		result.debugVisLevel = level
 	})
 
+	d2term.BindAction("mapdebugcolor", "set a map debug visualization color (tile, subtile, collision, walkable, blocked) to RRGGBB[AA]", func(name, value string) {
+		c, err := parseHexColor(value)
+		if err == nil {
+			err = result.SetDebugColor(name, c)
+		}
+		if err != nil {
+			d2term.OutputError("%s", err)
+		}
+	})
+
+	d2term.BindAction("mapdebugtile", "show the detailed map debug visualization for a single tile only (-1 -1 for all tiles)", func(x, y int) {
+		if x < 0 || y < 0 {
+			result.ClearDebugTile()
+			return
+		}
+		result.SelectDebugTile(x, y)
+	})
+
+	d2term.BindAction("mapdebugsubtiles", "label each sub-tile with its index in the sub-tile debug visualization", func(enabled bool) {
+		result.SetDebugSubTileLabels(enabled)
+	})
+
+	d2term.BindAction("mapdebugwarps", "draw a line from the map start to each warp, labelled with the level it leads to", func(enabled bool) {
+		result.SetWarpDebug(enabled)
+	})
+
+	d2term.BindAction("mapdebugaxes", "draw the world X (red) and Y (green) axes at the origin", func(enabled bool) {
+		result.SetAxisGizmo(enabled)
+	})
+
+	d2term.BindAction("mapdebugbounds", "outline the screen rectangles tiles are culled with (yellow) and entities are picked with (magenta)", func(enabled bool) {
+		result.SetBoundsDebug(enabled)
+	})
+
+	d2term.BindAction("mapframebudget", "set the frame time (in milliseconds) after which map overlays are skipped (0=unlimited)", func(milliseconds float64) {
+		result.SetFrameBudget(milliseconds / 1000)
+	})
+
+	d2term.BindAction("maplight", "set the brightness map tiles are drawn at (0=black, 1=full brightness)", func(level float64) {
+		result.SetGlobalLight(level)
+	})
+
+	d2term.BindAction("mappalettecycle", "enable or disable animating liquid tiles by rotating their palette colors", func(enabled bool) {
+		result.SetPaletteCycling(enabled)
+	})
+
+	d2term.BindAction("mapao", "enable or disable map ambient occlusion at wall bases", func(enabled bool) {
+		result.SetAmbientOcclusion(enabled)
+	})
+
+	d2term.BindAction("mapcachestat", "display map tile image cache statistics", func() {
+		stats := GetImageCacheStats()
+		d2term.OutputInfo("tile images: %d (%d KB)", stats.Records, stats.Bytes/1024)
+		d2term.OutputInfo("cache hits: %d, misses: %d, evictions: %d", stats.Hits, stats.Misses, stats.Evictions)
+	})
+
+	d2term.BindAction("mapexport", "render the whole map to a PNG file", func(path string) {
+		if err := result.ExportMapPNG(path); err != nil {
+			d2term.OutputError("%s", err)
+			return
+		}
+		d2term.OutputInfo("map exported to %s", path)
+	})
+
+	d2term.BindAction("mapcachebudget", "set the memory (in KB) map tile images may use before the least used are dropped (0=unlimited)", func(kilobytes int) {
+		SetImageCacheBudget(kilobytes * 1024)
+	})
+
+	result.watchTileChanges()
 	if mapEngine.LevelType().Id != 0 {
 		result.generateTileCache()
+		result.moveCameraToStart()
+	}
+
+	return result
+}
+
+// Creates a map renderer without binding the terminal commands (eg: for rendering offscreen)
+func newMapRenderer(mapEngine *d2mapengine.MapEngine, viewport *Viewport) *MapRenderer {
+	result := &MapRenderer{
+		mapEngine:     mapEngine,
+		viewport:      viewport,
+		highlight:     defaultHighlightColor,
+		debugStyle:    DefaultDebugStyle(),
+		globalLight:   1,
+		paletteCycles: newTilePaletteCycles(),
 	}
 
+	result.viewport.SetCamera(&result.camera)
 	return result
 }
 
+// Caches the art of the tiles the map engine replaces, no longer watching the engine rendered before
+func (mr *MapRenderer) watchTileChanges() {
+	if mr.stopTileWatch != nil {
+		mr.stopTileWatch()
+	}
+	mr.stopTileWatch = mr.mapEngine.OnTileChanged(mr.generateTileCacheAt)
+}
+
+// Stops caching the tiles the map engine replaces, so that a renderer that is no longer used (eg: the preview of a map
+// that stays loaded) is not notified by the engine
+func (mr *MapRenderer) Close() {
+	if mr.stopTileWatch != nil {
+		mr.stopTileWatch()
+		mr.stopTileWatch = nil
+	}
+}
+
 func (mr *MapRenderer) RegenerateTileCache() {
 	mr.generateTileCache()
 }
 
 func (mr *MapRenderer) SetMapEngine(mapEngine *d2mapengine.MapEngine) {
+	if mr.transition.duration > 0 && mr.mapEngine != nil {
+		mr.startMapTransition(mapEngine)
+		return
+	}
+
+	mr.swapMapEngine(mapEngine)
+}
+
+func (mr *MapRenderer) swapMapEngine(mapEngine *d2mapengine.MapEngine) {
 	mr.mapEngine = mapEngine
+	mr.watchTileChanges()
+	mapEngine.SetEntitiesPaused(mr.entityPaused)
+	mr.updateEntitySampleLimit()
 	mr.generateTileCache()
+	mr.moveCameraToStart()
+}
+
+// Centers the camera on the position the player starts at on the map, or the center of the map if it has no start
+func (mr *MapRenderer) moveCameraToStart() {
+	mr.MoveCameraTo(mr.WorldToOrtho(mr.mapEngine.GetStartPosition()))
 }
 
 func (mr *MapRenderer) Render(target d2render.Surface) {
-	mr.renderPass1(mr.viewport, target)
-	if mr.debugVisLevel > 0 {
+	disposeDroppedImages()
+	mr.staleRefreshes = 0
+	mr.LoadTileCache()
+	mr.entityBudget = mr.selectRenderedEntities()
+	if zoom := mr.viewport.GetZoom(); zoom != 1 {
+		target.PushScale(zoom)
+		defer target.Pop()
+		defer mr.viewport.enterRenderSpace()()
+	}
+
+	mr.timings = RenderTimings{}
+	timer := startFrameTimer()
+
+	mr.prepareTileLights(mr.viewport)
+
+	mask := mr.drawRevealMask(target)
+	mr.renderRevealed(target, mask, func() { mr.renderPass1(mr.viewport, target) })
+	mr.timings.Pass1 = timer.lap()
+	if mr.debugVisLevel > 0 && mr.allowOverlay(&timer) {
 		mr.renderDebug(mr.debugVisLevel, mr.viewport, target)
+		mr.timings.Overlays += timer.lap()
+	}
+	mr.renderRevealed(target, mask, func() {
+		mr.renderPass2(mr.viewport, target)
+		mr.timings.Pass2 = timer.lap()
+		mr.renderPass3(mr.viewport, target)
+	})
+	mr.timings.Pass3 = timer.lap()
+	mr.renderSceneTint(mr.viewport, target)
+	mr.timings.SceneTint = timer.lap()
+	if mr.ghost != nil {
+		mr.renderPlacementGhost(target)
+	}
+	if mr.measurement != nil && mr.allowOverlay(&timer) {
+		mr.renderMeasurement(target)
+		mr.timings.Overlays += timer.lap()
+	}
+	if mr.warpDebug && mr.allowOverlay(&timer) {
+		mr.renderWarpDebug(target)
+		mr.timings.Overlays += timer.lap()
+	}
+	if mr.axisGizmo && mr.allowOverlay(&timer) {
+		mr.renderAxisGizmo(target)
+		mr.timings.Overlays += timer.lap()
+	}
+	if mr.boundsDebug && mr.allowOverlay(&timer) {
+		mr.renderBoundsDebug(target)
+		mr.timings.Overlays += timer.lap()
+	}
+	if len(mr.worldText) > 0 {
+		if mr.allowOverlay(&timer) {
+			mr.renderWorldText(target)
+			mr.timings.Overlays += timer.lap()
+		} else {
+			mr.worldText = mr.worldText[:0]
+		}
 	}
-	mr.renderPass2(mr.viewport, target)
-	mr.renderPass3(mr.viewport, target)
 }
 
 func (mr *MapRenderer) MoveCameraTo(x, y float64) {
 	mr.camera.MoveTo(x, y)
+	mr.updateCameraFocus()
+}
+
+// Moves the camera to the ortho position over the duration, in seconds (eg: to follow the player, or for cutscenes).
+// The camera moves as the renderer advances.
+func (mr *MapRenderer) MoveCameraToSmooth(x, y, duration float64) {
+	mr.camera.MoveToSmooth(x, y, duration)
+	mr.updateCameraFocus()
+}
+
+// Returns the camera the map is rendered from (eg: to wait for a smooth move to finish)
+func (mr *MapRenderer) GetCamera() *Camera {
+	return &mr.camera
 }
 
 func (mr *MapRenderer) MoveCameraBy(x, y float64) {
 	mr.camera.MoveBy(x, y)
+	mr.updateCameraFocus()
+}
+
+// Pans the camera by a screen space drag, in pixels, so that the map follows the mouse (dragging right moves the
+// camera left). The delta is converted through the viewport, so the map stays under the cursor at any tile size.
+func (mr *MapRenderer) PanByScreen(dxPixels, dyPixels int) {
+	startX, startY := mr.viewport.ScreenToOrtho(0, 0)
+	endX, endY := mr.viewport.ScreenToOrtho(dxPixels, dyPixels)
+	mr.MoveCameraBy(startX-endX, startY-endY)
+}
+
+// Sets the scale the map is drawn at, zooming around the center of the screen
+func (mr *MapRenderer) SetZoom(zoom float64) {
+	mr.viewport.SetZoom(zoom)
+}
+
+// Returns the scale the map is drawn at
+func (mr *MapRenderer) GetZoom() float64 {
+	return mr.viewport.GetZoom()
+}
+
+// Zooms toward the screen position (eg: the mouse cursor), moving the camera so the point under it stays in place.
+// The delta is in powers of two, so 1 doubles the zoom and -1 halves it. While the camera follows an entity, the zoom
+// is centered on the screen instead, so the camera stays on the entity.
+func (mr *MapRenderer) ZoomAt(screenX, screenY int, delta float64) {
+	if mr.cameraTarget != nil {
+		mr.viewport.SetZoom(mr.viewport.GetZoom() * math.Pow(2, delta))
+		return
+	}
+
+	beforeX, beforeY := mr.viewport.ScreenToOrtho(screenX, screenY)
+	mr.viewport.SetZoom(mr.viewport.GetZoom() * math.Pow(2, delta))
+	afterX, afterY := mr.viewport.ScreenToOrtho(screenX, screenY)
+	mr.MoveCameraBy(beforeX-afterX, beforeY-afterY)
+}
+
+// Tells the map engine where the camera is centered, so its trigger regions can follow the camera
+func (mr *MapRenderer) updateCameraFocus() {
+	if mr.mapEngine == nil {
+		return
+	}
+	mr.mapEngine.SetFocusPosition(mr.viewport.OrthoToWorld(mr.camera.GetPosition()))
+}
+
+// Sets the number of tiles beyond the screen edges that are rendered, for both tiles and the entities standing on them
+func (mr *MapRenderer) SetCullMargin(tiles int) {
+	mr.viewport.SetCullMargin(tiles)
+}
+
+// Sets the projected size of an isometric tile, in pixels, used by all of the screen and world conversions
+func (mr *MapRenderer) SetTileSize(width, height float64) {
+	mr.viewport.SetTileSize(width, height)
+}
+
+// Sets the size of the screen the map is rendered to (eg: the size of the window's surface after it was resized), so
+// that the map fills the screen and tiles are culled against its real edges. Non-positive sizes are ignored.
+func (mr *MapRenderer) SetViewportSize(width, height int) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	mr.viewport.SetSize(width, height)
 }
 
 func (mr *MapRenderer) ScreenToWorld(x, y int) (float64, float64) {
@@ -86,137 +371,211 @@ func (mr *MapRenderer) WorldToOrtho(x, y float64) (float64, float64) {
 	return mr.viewport.WorldToOrtho(x, y)
 }
 
-func (mr *MapRenderer) renderPass1(viewport *Viewport, target d2render.Surface) {
+// Returns the range of tiles (inclusive) the render passes loop over: the tiles that may be visible, clamped to the map
+func (mr *MapRenderer) visibleTileBounds(viewport *Viewport) (minX, minY, maxX, maxY int) {
 	mapSize := mr.mapEngine.Size()
-	// TODO: Render based on visible area
-	for tileY := 0; tileY < mapSize.Height; tileY++ {
-		for tileX := 0; tileX < mapSize.Width; tileX++ {
-			tile := mr.mapEngine.TileAt(tileX, tileY)
-			if viewport.IsTileVisible(float64(tileX), float64(tileY)) {
-				viewport.PushTranslationWorld(float64(tileX), float64(tileY))
-				mr.renderTilePass1(tile, target)
-				viewport.PopTranslation()
+	minX, minY, maxX, maxY = viewport.GetVisibleTileBounds()
+	return d2common.MaxInt(minX, 0), d2common.MaxInt(minY, 0),
+		d2common.MinInt(maxX, mapSize.Width-1), d2common.MinInt(maxY, mapSize.Height-1)
+}
+
+func (mr *MapRenderer) renderPass1(viewport *Viewport, target d2render.Surface) {
+	minX, minY, maxX, maxY := mr.visibleTileBounds(viewport)
+	tiles := mr.mapEngine.TilesInRect(minX, minY, maxX, maxY)
+	for tileY := minY; tileY <= maxY; tileY++ {
+		for tileX := minX; tileX <= maxX; tileX++ {
+			if viewport.IsTileVisible(float64(tileX), float64(tileY)) && mr.mapEngine.TileElevation(tileX, tileY) == 0 {
+				mr.renderTileInPass1(tileX, tileY, tiles[tileY-minY][tileX-minX], viewport, target)
 			}
 		}
 	}
 }
 
 func (mr *MapRenderer) renderPass2(viewport *Viewport, target d2render.Surface) {
-	mapSize := mr.mapEngine.Size()
-
-	// TODO: Render based on visible area
-	for tileY := 0; tileY < mapSize.Height; tileY++ {
-		for tileX := 0; tileX < mapSize.Width; tileX++ {
-			tile := mr.mapEngine.TileAt(tileX, tileY)
-			if viewport.IsTileVisible(float64(tileX), float64(tileY)) {
-				viewport.PushTranslationWorld(float64(tileX), float64(tileY))
-				mr.renderTilePass2(tile, target)
-
-				// TODO: Do not loop over every entity every frame
-				for _, mapEntity := range *mr.mapEngine.Entities() {
-					entityX, entityY := mapEntity.GetPosition()
-					if (int(entityX) != tileX) || (int(entityY) != tileY) {
-						continue
-					}
-					target.PushTranslation(viewport.GetTranslationScreen())
-					mapEntity.Render(target)
-					target.Pop()
-				}
-				viewport.PopTranslation()
+	minX, minY, maxX, maxY := mr.visibleTileBounds(viewport)
+	tiles := mr.mapEngine.TilesInRect(minX, minY, maxX, maxY)
+	for tileY := minY; tileY <= maxY; tileY++ {
+		for tileX := minX; tileX <= maxX; tileX++ {
+			if viewport.IsTileVisible(float64(tileX), float64(tileY)) && mr.mapEngine.TileElevation(tileX, tileY) == 0 {
+				mr.renderTileInPass2(tileX, tileY, tiles[tileY-minY][tileX-minX], viewport, target)
 			}
 		}
 	}
+
+	if mr.mapEngine.HasElevatedTiles() {
+		mr.renderElevatedTiles(viewport, target)
+	}
 }
 
 func (mr *MapRenderer) renderPass3(viewport *Viewport, target d2render.Surface) {
-	mapSize := mr.mapEngine.Size()
-	// TODO: Render based on visible area
-	for tileY := 0; tileY < mapSize.Height; tileY++ {
-		for tileX := 0; tileX < mapSize.Width; tileX++ {
-			tile := mr.mapEngine.TileAt(tileX, tileY)
+	minX, minY, maxX, maxY := mr.visibleTileBounds(viewport)
+	tiles := mr.mapEngine.TilesInRect(minX, minY, maxX, maxY)
+	for tileY := minY; tileY <= maxY; tileY++ {
+		for tileX := minX; tileX <= maxX; tileX++ {
 			if viewport.IsTileVisible(float64(tileX), float64(tileY)) {
-				viewport.PushTranslationWorld(float64(tileX), float64(tileY))
-				mr.renderTilePass3(tile, target)
-				viewport.PopTranslation()
+				mr.renderTileInPass3(tileX, tileY, tiles[tileY-minY][tileX-minX], viewport, target)
 			}
 		}
 	}
+}
 
+// Renders the floors, shadows and lower walls of a tile, and the entities drawn below them
+func (mr *MapRenderer) renderTileInPass1(tileX, tileY int, tile *d2ds1.TileRecord, viewport *Viewport,
+	target d2render.Surface) {
+	defer mr.pushTileTranslation(tileX, tileY, viewport)()
+	defer mr.lightTile(tileX, tileY)()
+	mr.renderTilePass1(tile, target)
+	mr.renderAmbientOcclusion(tileX, tileY, tile, target)
+	mr.renderEntities(tileX, tileY, d2enum.EntityRenderLayerBelow, viewport, target)
 }
 
-func (mr *MapRenderer) renderTilePass1(tile *d2ds1.TileRecord, target d2render.Surface) {
-	for _, wall := range tile.Walls {
-		if !wall.Hidden && wall.Prop1 != 0 && wall.Type.LowerWall() {
-			mr.renderWall(wall, mr.viewport, target)
-		}
+// Renders the upper walls of a tile, and the corpses and entities standing on it
+func (mr *MapRenderer) renderTileInPass2(tileX, tileY int, tile *d2ds1.TileRecord, viewport *Viewport,
+	target d2render.Surface) {
+	defer mr.pushTileTranslation(tileX, tileY, viewport)()
+	defer mr.lightTile(tileX, tileY)()
+	mr.renderTilePass2(tile, target)
+	mr.renderEntities(tileX, tileY, d2enum.EntityRenderLayerCorpse, viewport, target)
+	mr.renderEntities(tileX, tileY, d2enum.EntityRenderLayerNormal, viewport, target)
+}
+
+// Renders the roofs of a tile, and the entities drawn above them
+func (mr *MapRenderer) renderTileInPass3(tileX, tileY int, tile *d2ds1.TileRecord, viewport *Viewport,
+	target d2render.Surface) {
+	defer mr.pushTileTranslation(tileX, tileY, viewport)()
+	defer mr.lightTile(tileX, tileY)()
+	mr.renderTilePass3(tile, target)
+	mr.renderEntities(tileX, tileY, d2enum.EntityRenderLayerAboveRoof, viewport, target)
+}
+
+// Translates the viewport to the top corner of a tile, raised by the tile's elevation. Returns a function that
+// restores the translation.
+func (mr *MapRenderer) pushTileTranslation(tileX, tileY int, viewport *Viewport) func() {
+	viewport.PushTranslationWorld(float64(tileX), float64(tileY))
+	elevation := mr.mapEngine.TileElevation(tileX, tileY)
+	if elevation == 0 {
+		return viewport.PopTranslation
 	}
 
-	for _, floor := range tile.Floors {
-		if !floor.Hidden && floor.Prop1 != 0 {
-			mr.renderFloor(floor, target)
-		}
+	viewport.PushTranslationOrtho(0, -float64(elevation))
+	return func() {
+		viewport.PopTranslation()
+		viewport.PopTranslation()
 	}
+}
 
-	for _, shadow := range tile.Shadows {
-		if !shadow.Hidden && shadow.Prop1 != 0 {
-			mr.renderShadow(shadow, target)
+// Renders the entities standing on the specified tile that belong to the specified render layer
+func (mr *MapRenderer) renderEntities(tileX, tileY int, layer d2enum.EntityRenderLayer, viewport *Viewport, target d2render.Surface) {
+	for _, draw := range mr.orderEntityDraws(tileX, tileY, layer) {
+		mapEntity := draw.entity
+		target.PushTranslation(viewport.GetTranslationScreen())
+		target.PushTranslation(mr.entityInterpolationOffset(mapEntity))
+		if scale := mapEntity.GetRenderScale(); scale != 1 {
+			// The entity is scaled around its feet, so that it stays standing where it would be drawn unscaled
+			anchorX, anchorY := mr.entityScreenAnchor(mapEntity)
+			target.PushTranslation(anchorX, anchorY)
+			target.PushScale(scale)
+			target.PushTranslation(-anchorX, -anchorY)
+			mr.renderEntity(mapEntity, target)
+			target.PopN(3)
+		} else {
+			mr.renderEntity(mapEntity, target)
 		}
+		target.PopN(2)
 	}
 }
 
-func (mr *MapRenderer) renderTilePass2(tile *d2ds1.TileRecord, target d2render.Surface) {
-	for _, wall := range tile.Walls {
-		if !wall.Hidden && wall.Type.UpperWall() {
-			mr.renderWall(wall, mr.viewport, target)
-		}
+// Returns the screen offset from the location of an entity to where it is drawn, between its locations after the last
+// two entity ticks
+func (mr *MapRenderer) entityInterpolationOffset(mapEntity d2mapentity.MapEntity) (int, int) {
+	x, y := entityLocation(mapEntity)
+	drawnX, drawnY := mr.mapEngine.InterpolatedLocation(mapEntity)
+	return mr.viewport.subTileScreenOffset(drawnX-x, drawnY-y)
+}
+
+// Renders a single entity at the current translation, with its motion trail and its selection highlight if it has them
+func (mr *MapRenderer) renderEntity(mapEntity d2mapentity.MapEntity, target d2render.Surface) {
+	if len(mr.trails) > 0 {
+		mr.renderMotionTrail(mapEntity, target)
+	}
+	if mapEntity.IsHighlighted() {
+		mr.renderEntityHighlight(mapEntity, target)
 	}
+	mapEntity.Render(target)
 }
 
-func (mr *MapRenderer) renderTilePass3(tile *d2ds1.TileRecord, target d2render.Surface) {
-	for _, wall := range tile.Walls {
-		if wall.Type == d2enum.Roof {
-			mr.renderWall(wall, mr.viewport, target)
-		}
+func (mr *MapRenderer) renderTilePass1(tile *d2ds1.TileRecord, target d2render.Surface) {
+	mr.renderTileLayer(tile, LayerLowerWalls, target)
+	if mr.tileShadowOrder(tile) == ShadowsBelowFloors {
+		mr.renderTileLayer(tile, LayerShadows, target)
+		mr.renderTileLayer(tile, LayerFloors, target)
+	} else {
+		mr.renderTileLayer(tile, LayerFloors, target)
+		mr.renderTileLayer(tile, LayerShadows, target)
 	}
 }
 
+func (mr *MapRenderer) renderTilePass2(tile *d2ds1.TileRecord, target d2render.Surface) {
+	mr.renderTileLayer(tile, LayerUpperWalls, target)
+}
+
+func (mr *MapRenderer) renderTilePass3(tile *d2ds1.TileRecord, target d2render.Surface) {
+	mr.renderTileLayer(tile, LayerRoofs, target)
+}
+
 func (mr *MapRenderer) renderFloor(tile d2ds1.FloorShadowRecord, target d2render.Surface) {
 	var img d2render.Surface
 	if !tile.Animated {
 		img = mr.getImageCacheRecord(tile.Style, tile.Sequence, 0, tile.RandomIndex)
 	} else {
-		img = mr.getImageCacheRecord(tile.Style, tile.Sequence, 0, byte(mr.currentFrame))
+		img = mr.getImageCacheRecord(tile.Style, tile.Sequence, 0, mr.tileAnimationFrame(tile.FrameCount))
 	}
 	if img == nil {
 		log.Printf("Render called on uncached floor {%v,%v}", tile.Style, tile.Sequence)
 		return
 	}
 
-	mr.viewport.PushTranslationOrtho(-80, float64(tile.YAdjust))
+	tileWidth, _ := mr.viewport.GetTileSize()
+	mr.viewport.PushTranslationOrtho(-tileWidth/2, float64(tile.YAdjust))
 	defer mr.viewport.PopTranslation()
 
 	target.PushTranslation(mr.viewport.GetTranslationScreen())
 	defer target.Pop()
+	if mr.pushTileLight(target) {
+		defer target.Pop()
+	}
 
 	target.Render(img)
 }
 
 func (mr *MapRenderer) renderWall(tile d2ds1.WallRecord, viewport *Viewport, target d2render.Surface) {
-	img := mr.getImageCacheRecord(tile.Style, tile.Sequence, tile.Type, tile.RandomIndex)
+	var img d2render.Surface
+	if !tile.Animated {
+		img = mr.getImageCacheRecord(tile.Style, tile.Sequence, tile.Type, tile.RandomIndex)
+	} else {
+		img = mr.getImageCacheRecord(tile.Style, tile.Sequence, tile.Type, mr.tileAnimationFrame(tile.FrameCount))
+	}
 	if img == nil {
 		log.Printf("Render called on uncached wall {%v,%v,%v}", tile.Style, tile.Sequence, tile.Type)
 		return
 	}
 
-	viewport.PushTranslationOrtho(-80, float64(tile.YAdjust)-8)
+	viewport.PushTranslationOrtho(float64(tile.XAdjust)+wallOrthoOffsetX, float64(tile.YAdjust)+wallOrthoOffsetY)
 	defer viewport.PopTranslation()
 
 	target.PushTranslation(viewport.GetTranslationScreen())
 	defer target.Pop()
+	if mr.pushTileLight(target) {
+		defer target.Pop()
+	}
 
 	target.Render(img)
 }
 
+// The color shadows are drawn with, to draw them partly transparent. color.RGBA is alpha premultiplied, so this leaves the
+// colors of the shadow unchanged.
+var shadowColor = color.RGBA{R: 160, G: 160, B: 160, A: 160}
+
 func (mr *MapRenderer) renderShadow(tile d2ds1.FloorShadowRecord, target d2render.Surface) {
 	img := mr.getImageCacheRecord(tile.Style, tile.Sequence, 13, tile.RandomIndex)
 	if img == nil {
@@ -224,23 +583,23 @@ func (mr *MapRenderer) renderShadow(tile d2ds1.FloorShadowRecord, target d2rende
 		return
 	}
 
-	defer mr.viewport.PushTranslationOrtho(-80, float64(tile.YAdjust)).PopTranslation()
+	tileWidth, _ := mr.viewport.GetTileSize()
+	defer mr.viewport.PushTranslationOrtho(-tileWidth/2, float64(tile.YAdjust)).PopTranslation()
 
 	target.PushTranslation(mr.viewport.GetTranslationScreen())
-	target.PushColor(color.RGBA{R: 255, G: 255, B: 255, A: 160})
+	target.PushColor(shadowColor)
 	defer target.PopN(2)
 
 	target.Render(img)
 }
 
 func (mr *MapRenderer) renderDebug(debugVisLevel int, viewport *Viewport, target d2render.Surface) {
-	mapSize := mr.mapEngine.Size()
-	// TODO: Render based on visible area
-	for tileY := 0; tileY < mapSize.Height; tileY++ {
-		for tileX := 0; tileX < mapSize.Width; tileX++ {
+	minX, minY, maxX, maxY := mr.visibleTileBounds(viewport)
+	for tileY := minY; tileY <= maxY; tileY++ {
+		for tileX := minX; tileX <= maxX; tileX++ {
 			if viewport.IsTileVisible(float64(tileX), float64(tileY)) {
 				viewport.PushTranslationWorld(float64(tileX), float64(tileY))
-				mr.renderTileDebug(tileX, tileY, debugVisLevel, target)
+				mr.renderTileDebug(tileX, tileY, mr.tileDebugLevel(tileX, tileY, debugVisLevel), target)
 				viewport.PopTranslation()
 			}
 		}
@@ -248,9 +607,9 @@ func (mr *MapRenderer) renderDebug(debugVisLevel int, viewport *Viewport, target
 }
 
 func (mr *MapRenderer) renderTileDebug(ax, ay int, debugVisLevel int, target d2render.Surface) {
-	subTileColor := color.RGBA{R: 80, G: 80, B: 255, A: 50}
-	tileColor := color.RGBA{R: 255, G: 255, B: 255, A: 100}
-	tileCollisionColor := color.RGBA{R: 128, G: 0, B: 0, A: 100}
+	subTileColor := mr.debugStyle.SubTileColor
+	tileColor := mr.debugStyle.TileColor
+	tileCollisionColor := mr.debugStyle.TileCollisionColor
 
 	screenX1, screenY1 := mr.viewport.WorldToScreen(float64(ax), float64(ay))
 	screenX2, screenY2 := mr.viewport.WorldToScreen(float64(ax+1), float64(ay))
@@ -266,16 +625,17 @@ func (mr *MapRenderer) renderTileDebug(ax, ay int, debugVisLevel int, target d2r
 	target.Pop()
 
 	if debugVisLevel > 1 {
-		for i := 1; i <= 4; i++ {
-			x2 := i * 16
-			y2 := i * 8
+		tileHalfWidth, tileHalfHeight := mr.viewport.tileDiamondHalfSize()
+		subTileWidth, subTileHeight := mr.viewport.subTileDiamondSize()
+		for i := 1; i < subTilesPerTile; i++ {
+			x2, y2 := mr.viewport.subTileIsoOffset(i, 0)
 
 			target.PushTranslation(-x2, y2)
-			target.DrawLine(80, 40, subTileColor)
+			target.DrawLine(tileHalfWidth, tileHalfHeight, subTileColor)
 			target.Pop()
 
 			target.PushTranslation(x2, y2)
-			target.DrawLine(-80, 40, subTileColor)
+			target.DrawLine(-tileHalfWidth, tileHalfHeight, subTileColor)
 			target.Pop()
 		}
 
@@ -297,60 +657,109 @@ func (mr *MapRenderer) renderTileDebug(ax, ay int, debugVisLevel int, target d2r
 
 		for yy := 0; yy < 5; yy++ {
 			for xx := 0; xx < 5; xx++ {
-				isoX := (xx - yy) * 16
-				isoY := (xx + yy) * 8
-				var walkableArea = (*mr.mapEngine.WalkMesh())[((yy+(ay*5))*mr.mapEngine.Size().Width*5)+xx+(ax*5)]
-				if !walkableArea.Walkable {
+				isoX, isoY := mr.viewport.subTileIsoOffset(xx, yy)
+				walkable := mr.isSubTileWalkable(ax, ay, xx, yy)
+				if debugVisLevel > 2 {
+					fillColor := mr.debugStyle.WalkableColor
+					if !walkable {
+						fillColor = mr.debugStyle.BlockedColor
+					}
+					fillSubTileDiamond(isoX, isoY, subTileWidth, subTileHeight, fillColor, target)
+				} else if !walkable {
 					target.PushTranslation(isoX-3, isoY+4)
 					target.DrawRect(5, 5, tileCollisionColor)
 					target.Pop()
 				}
+				if mr.subTileLabels {
+					target.PushTranslation(isoX-6, isoY+2)
+					target.DrawText("%v,%v", xx, yy)
+					target.Pop()
+				}
 			}
 		}
 	}
 }
 
-func (mr *MapRenderer) Advance(elapsed float64) {
-	frameLength := 0.1
+// Advances the tile animations by the elapsed time (in seconds), unless they are paused. Returns the number of
+// animation frames advanced.
+func (mr *MapRenderer) Advance(elapsed float64) int {
+	framesAdvanced := 0
+	if !mr.tilesPaused {
+		mr.lastFrameTime += elapsed
+		framesAdvanced = int(mr.lastFrameTime / tileFrameLength)
+		mr.lastFrameTime -= float64(framesAdvanced) * tileFrameLength
+
+		mr.currentFrame += framesAdvanced
+		mr.advancePaletteCycles(elapsed)
+	}
+
+	mr.advanceSceneTint(elapsed)
+	mr.advanceMapTransition(elapsed)
+	if mr.camera.IsMoving() {
+		mr.camera.Advance(elapsed)
+		mr.updateCameraFocus()
+	}
+	mr.followCameraTarget()
+
+	return framesAdvanced
+}
+
+// Returns the time (in seconds) accumulated towards the next animation frame
+func (mr *MapRenderer) FrameRemainder() float64 {
+	return mr.lastFrameTime
+}
+
+// Returns the number of tile animation frames advanced. Each animated tile shows this frame modulo its own frame count.
+func (mr *MapRenderer) CurrentFrame() int {
+	return mr.currentFrame
+}
+
+// Maps the current tile animation frame onto the frames available to an animated tile, as cached with the tile. A frame
+// count of 0 (unknown) assumes the tile has the usual 10 frames.
+func (mr *MapRenderer) tileAnimationFrame(frameCount byte) byte {
+	if frameCount == 0 {
+		return byte(mr.currentFrame % tileFrameCount)
+	}
+	return byte(mr.currentFrame % int(frameCount))
+}
 
-	mr.lastFrameTime += elapsed
-	framesAdvanced := int(mr.lastFrameTime / frameLength)
-	mr.lastFrameTime -= float64(framesAdvanced) * frameLength
+// Loads the palette for a region type from the archives
+func readPaletteForAct(levelType d2enum.RegionIdType) (*d2dat.DATPalette, error) {
+	palettePath, _, err := actPalettePaths(levelType)
+	if err != nil {
+		return nil, err
+	}
 
-	mr.currentFrame += framesAdvanced
-	if mr.currentFrame > 9 {
-		mr.currentFrame = 0
+	palette, err := loadPaletteFile(palettePath)
+	if _, notFound := err.(*d2asset.ErrNotFound); notFound && palettePath != d2resource.PaletteAct1 {
+		// The act palette is missing from the archives (eg: act 5 without the expansion), so fall back to act 1
+		return loadPaletteFile(d2resource.PaletteAct1)
 	}
+
+	return palette, err
 }
 
-func loadPaletteForAct(levelType d2enum.RegionIdType) (*d2dat.DATPalette, error) {
-	var palettePath string
+// Returns the paths of the DAT palette and the PL2 palette transform of the act a region type belongs to
+func actPalettePaths(levelType d2enum.RegionIdType) (palettePath, transformPath string, err error) {
 	switch levelType {
 	case d2enum.RegionAct1Town, d2enum.RegionAct1Wilderness, d2enum.RegionAct1Cave, d2enum.RegionAct1Crypt,
 		d2enum.RegionAct1Monestary, d2enum.RegionAct1Courtyard, d2enum.RegionAct1Barracks,
 		d2enum.RegionAct1Jail, d2enum.RegionAct1Cathedral, d2enum.RegionAct1Catacombs, d2enum.RegionAct1Tristram:
-		palettePath = d2resource.PaletteAct1
-		break
+		return d2resource.PaletteAct1, d2resource.PaletteTransformAct1, nil
 	case d2enum.RegionAct2Town, d2enum.RegionAct2Sewer, d2enum.RegionAct2Harem, d2enum.RegionAct2Basement,
 		d2enum.RegionAct2Desert, d2enum.RegionAct2Tomb, d2enum.RegionAct2Lair, d2enum.RegionAct2Arcane:
-		palettePath = d2resource.PaletteAct2
-		break
+		return d2resource.PaletteAct2, d2resource.PaletteTransformAct2, nil
 	case d2enum.RegionAct3Town, d2enum.RegionAct3Jungle, d2enum.RegionAct3Kurast, d2enum.RegionAct3Spider,
 		d2enum.RegionAct3Dungeon, d2enum.RegionAct3Sewer:
-		palettePath = d2resource.PaletteAct3
-		break
+		return d2resource.PaletteAct3, d2resource.PaletteTransformAct3, nil
 	case d2enum.RegionAct4Town, d2enum.RegionAct4Mesa, d2enum.RegionAct4Lava, d2enum.RegionAct5Lava:
-		palettePath = d2resource.PaletteAct4
-		break
+		return d2resource.PaletteAct4, d2resource.PaletteTransformAct4, nil
 	case d2enum.RegonAct5Town, d2enum.RegionAct5Siege, d2enum.RegionAct5Barricade, d2enum.RegionAct5Temple,
 		d2enum.RegionAct5IceCaves, d2enum.RegionAct5Baal:
-		palettePath = d2resource.PaletteAct5
-		break
+		return d2resource.PaletteAct5, d2resource.PaletteTransformAct5, nil
 	default:
-		return nil, errors.New("failed to find palette for region")
+		return "", "", errors.New("failed to find palette for region")
 	}
-
-	return d2asset.LoadPalette(palettePath)
 }
 
 func (mr *MapRenderer) ViewportToLeft() {
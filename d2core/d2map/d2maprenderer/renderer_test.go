@@ -0,0 +1,304 @@
+package d2maprenderer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sync"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dc6"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2asset"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapengine"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render/software"
+)
+
+// testDrawCall records a single draw operation made against a testSurface
+type testDrawCall struct {
+	op     string      // The operation (rect, line, text, render)
+	x, y   int         // The translation the operation was made at
+	width  int         // The width (or line x offset)
+	height int         // The height (or line y offset)
+	color  color.Color // The color passed to the operation
+	text   string      // The text drawn, for text operations
+	source d2render.Surface
+	colors []color.Color // The pushed colors active at the time of the operation
+	scale  float64       // The scale active at the time of the operation
+	// The silhouette color active at the time of the operation
+	silhouette color.Color
+}
+
+type testSurfaceState struct {
+	x, y       int
+	color      color.Color
+	silhouette color.Color
+	scale      float64
+}
+
+// testSurface is a d2render.Surface that records the operations performed on it. Rects and lines are also rasterized
+// so the result can be compared against golden images.
+type testSurface struct {
+	width, height int
+	calls         []testDrawCall
+	stack         []testSurfaceState
+	current       testSurfaceState
+	pixels        *image.RGBA
+	disposed      bool
+}
+
+func createTestSurface(width, height int) *testSurface {
+	return &testSurface{
+		width:   width,
+		height:  height,
+		current: testSurfaceState{scale: 1},
+		pixels:  image.NewRGBA(image.Rect(0, 0, width, height)),
+	}
+}
+
+func (s *testSurface) blend(rect image.Rectangle, c color.Color) {
+	draw.Draw(s.pixels, rect, image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+func (s *testSurface) record(call testDrawCall) {
+	call.x, call.y = s.current.x, s.current.y
+	call.scale = s.current.scale
+	call.silhouette = s.current.silhouette
+	for _, state := range s.stack {
+		if state.color != nil {
+			call.colors = append(call.colors, state.color)
+		}
+	}
+	if s.current.color != nil {
+		call.colors = append(call.colors, s.current.color)
+	}
+	s.calls = append(s.calls, call)
+}
+
+func (s *testSurface) callsOf(op string) []testDrawCall {
+	var result []testDrawCall
+	for _, call := range s.calls {
+		if call.op == op {
+			result = append(result, call)
+		}
+	}
+	return result
+}
+
+func (s *testSurface) push() {
+	s.stack = append(s.stack, s.current)
+	s.current.color = nil
+}
+
+func (s *testSurface) Clear(c color.Color) error {
+	draw.Draw(s.pixels, s.pixels.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return nil
+}
+
+func (s *testSurface) DrawRect(width, height int, c color.Color) {
+	s.record(testDrawCall{op: "rect", width: width, height: height, color: c})
+	x, y := s.current.x, s.current.y
+	s.blend(image.Rect(x, y, x+int(float64(width)*s.current.scale), y+int(float64(height)*s.current.scale)), c)
+}
+
+func (s *testSurface) DrawLine(x, y int, c color.Color) {
+	s.record(testDrawCall{op: "line", width: x, height: y, color: c})
+	dx, dy := float64(x)*s.current.scale, float64(y)*s.current.scale
+	steps := int(math.Max(math.Abs(dx), math.Abs(dy)))
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		px := s.current.x + int(math.Round(dx*t))
+		py := s.current.y + int(math.Round(dy*t))
+		s.blend(image.Rect(px, py, px+1, py+1), c)
+	}
+}
+
+func (s *testSurface) DrawText(format string, params ...interface{}) {
+	s.record(testDrawCall{op: "text", text: fmt.Sprintf(format, params...)})
+}
+
+func (s *testSurface) GetSize() (int, int) { return s.width, s.height }
+
+func (s *testSurface) GetDepth() int { return len(s.stack) }
+
+func (s *testSurface) Pop() {
+	count := len(s.stack)
+	if count == 0 {
+		panic("empty stack")
+	}
+	s.current = s.stack[count-1]
+	s.stack = s.stack[:count-1]
+}
+
+func (s *testSurface) PopN(n int) {
+	for i := 0; i < n; i++ {
+		s.Pop()
+	}
+}
+
+func (s *testSurface) PushColor(c color.Color) {
+	s.push()
+	s.current.color = c
+}
+
+func (s *testSurface) PushSilhouette(c color.Color) {
+	s.push()
+	s.current.silhouette = c
+}
+
+func (s *testSurface) PushCompositeMode(mode d2render.CompositeMode) { s.push() }
+
+func (s *testSurface) PushFilter(filter d2render.Filter) { s.push() }
+
+func (s *testSurface) PushMask(mask d2render.Surface) error {
+	s.record(testDrawCall{op: "mask", source: mask})
+	s.push()
+	return nil
+}
+
+func (s *testSurface) PushScale(scale float64) {
+	s.push()
+	s.current.scale *= scale
+}
+
+func (s *testSurface) PushTranslation(x, y int) {
+	s.push()
+	s.current.x += int(float64(x) * s.current.scale)
+	s.current.y += int(float64(y) * s.current.scale)
+}
+
+func (s *testSurface) Render(surface d2render.Surface) error {
+	s.record(testDrawCall{op: "render", source: surface})
+	return nil
+}
+
+func (s *testSurface) ReplacePixels(pixels []byte) error { return nil }
+
+func (s *testSurface) Dispose() error {
+	s.disposed = true
+	return nil
+}
+
+func (s *testSurface) Screenshot() *image.RGBA {
+	result := image.NewRGBA(s.pixels.Bounds())
+	copy(result.Pix, s.pixels.Pix)
+	return result
+}
+
+// testEntity is a map entity that records how it was rendered and advanced
+type testEntity struct {
+	name        string
+	x, y        float64
+	advanced    float64
+	layer       d2enum.EntityRenderLayer
+	scale       float64
+	highlighted bool
+}
+
+func createTestEntity(name string, x, y float64) *testEntity {
+	return &testEntity{name: name, x: x, y: y, scale: 1}
+}
+
+func (e *testEntity) Render(target d2render.Surface) {
+	target.DrawText("entity:%s", e.name)
+}
+
+func (e *testEntity) Advance(tickTime float64) {
+	e.advanced += tickTime
+}
+
+func (e *testEntity) GetPosition() (float64, float64) {
+	return e.x, e.y
+}
+
+func (e *testEntity) GetRenderLayer() d2enum.EntityRenderLayer {
+	return e.layer
+}
+
+func (e *testEntity) GetRenderScale() float64 {
+	return e.scale
+}
+
+func (e *testEntity) IsHighlighted() bool {
+	return e.highlighted
+}
+
+var (
+	testRenderer     *software.Renderer
+	testRendererOnce sync.Once
+)
+
+// Returns the software renderer, initializing it as the renderer the animations decode their frames with
+func getTestRenderer() *software.Renderer {
+	testRendererOnce.Do(func() {
+		testRenderer, _ = software.CreateRenderer()
+		_ = d2render.Initialize(testRenderer)
+	})
+	return testRenderer
+}
+
+// Creates a software surface to render into, so the pixels drawn by animations can be checked
+func createTestSoftwareSurface(width, height int) d2render.Surface {
+	surface, _ := getTestRenderer().NewSurface(width, height, d2render.FilterNearest)
+	return surface
+}
+
+// createTestAnimatedEntity creates an entity showing an animation of a single DC6 frame, filled with one color, whose
+// bottom center is the feet of the entity
+func createTestAnimatedEntity(x, y, width, height int, c d2dat.DATColor) *d2mapentity.AnimatedEntity {
+	getTestRenderer()
+
+	var frameData []byte
+	for row := 0; row < height; row++ {
+		frameData = append(frameData, byte(width))
+		for column := 0; column < width; column++ {
+			frameData = append(frameData, 1)
+		}
+		frameData = append(frameData, 0x80)
+	}
+
+	palette := &d2dat.DATPalette{}
+	palette.Colors[1] = c
+	frame := &d2dc6.DC6Frame{
+		Width:     uint32(width),
+		Height:    uint32(height),
+		OffsetX:   int32(-width / 2),
+		OffsetY:   int32(-height),
+		FrameData: frameData,
+	}
+	dc6 := &d2dc6.DC6File{Directions: 1, FramesPerDirection: 1, Frames: []*d2dc6.DC6Frame{frame}}
+	animation, err := d2asset.CreateAnimationFromDC6(dc6, palette)
+	if err != nil {
+		panic(err)
+	}
+
+	return d2mapentity.CreateAnimatedEntity(x, y, animation)
+}
+
+// Returns the bounds of the pixels of a color in an image
+func findTestColorBounds(img *image.RGBA, c color.RGBA) image.Rectangle {
+	var bounds image.Rectangle
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			if img.RGBAAt(x, y) == c {
+				bounds = bounds.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return bounds
+}
+
+// createTestMapRenderer creates a map renderer over an empty map without loading any assets
+func createTestMapRenderer(width, height int) *MapRenderer {
+	engine := d2mapengine.CreateMapEngine()
+	engine.ResetMapTiles(width, height)
+
+	return newMapRenderer(engine, NewViewport(0, 0, 800, 600))
+}
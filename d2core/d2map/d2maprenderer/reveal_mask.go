@@ -0,0 +1,173 @@
+package d2maprenderer
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+var defaultRevealMaskColor = color.RGBA{A: 215} // Leaves a sixth of the brightness of the map outside the spotlight
+
+// A circular spotlight; the map outside of it is drawn darkened
+type revealMask struct {
+	enabled bool
+	centerX float64    // The center of the spotlight, in world tiles
+	centerY float64    // The center of the spotlight, in world tiles
+	radius  float64    // The radius of the spotlight, in world tiles
+	color   color.RGBA // The color drawn over the map outside of the spotlight (opaque black hides it)
+
+	surface d2render.Surface // The mask the map is drawn through, the size of the target
+	hole    d2render.Surface // The spotlight as drawn on the mask, transparent inside of the circle
+	holeKey revealMaskHoleKey
+}
+
+// The parameters a reveal mask hole was drawn with
+type revealMaskHoleKey struct {
+	radius     float64
+	color      color.RGBA
+	tileWidth  float64
+	tileHeight float64
+}
+
+// Sets a circular spotlight centered on the specified world position. Everything outside of the radius (in tiles) is
+// drawn darkened.
+func (mr *MapRenderer) SetRevealMask(centerX, centerY, radius float64) {
+	mr.revealMask.enabled = true
+	mr.revealMask.centerX = centerX
+	mr.revealMask.centerY = centerY
+	mr.revealMask.radius = radius
+	if mr.revealMask.color == (color.RGBA{}) {
+		mr.revealMask.color = defaultRevealMaskColor
+	}
+}
+
+// Sets the color drawn over the map outside of the reveal mask. Its alpha sets how dark the map is drawn there, and
+// an opaque color hides it.
+func (mr *MapRenderer) SetRevealMaskColor(c color.RGBA) {
+	mr.revealMask.color = c
+}
+
+// Removes the reveal mask
+func (mr *MapRenderer) ClearRevealMask() {
+	mask := &mr.revealMask
+	mask.enabled = false
+	for _, surface := range []d2render.Surface{mask.surface, mask.hole} {
+		if surface != nil {
+			_ = surface.Dispose()
+		}
+	}
+	mask.surface, mask.hole = nil, nil
+}
+
+// Returns true if the center of the tile lies within the reveal mask, or there is no reveal mask
+func (mr *MapRenderer) IsTileRevealed(tileX, tileY int) bool {
+	mask := mr.revealMask
+	if !mask.enabled {
+		return true
+	}
+
+	dx := float64(tileX) + 0.5 - mask.centerX
+	dy := float64(tileY) + 0.5 - mask.centerY
+	return math.Sqrt(dx*dx+dy*dy) <= mask.radius
+}
+
+// Renders through the reveal mask, which darkens every pixel drawn outside of the spotlight. Renders directly when
+// there is no mask.
+func (mr *MapRenderer) renderRevealed(target, mask d2render.Surface, render func()) {
+	if mask != nil && target.PushMask(mask) == nil {
+		defer target.Pop()
+	}
+	render()
+}
+
+// Draws the reveal mask for the target at the current camera position: the mask color, with the spotlight cut out of
+// it. Returns nil if there is no reveal mask, or it can't be drawn.
+func (mr *MapRenderer) drawRevealMask(target d2render.Surface) d2render.Surface {
+	mask := &mr.revealMask
+	if !mask.enabled {
+		return nil
+	}
+
+	width, height := target.GetSize()
+	if mask.surface != nil {
+		if maskWidth, maskHeight := mask.surface.GetSize(); maskWidth != width || maskHeight != height {
+			_ = mask.surface.Dispose()
+			mask.surface = nil
+		}
+	}
+	if mask.surface == nil {
+		surface, err := newTileSurface(width, height, d2render.FilterNearest)
+		if err != nil {
+			return nil
+		}
+		mask.surface = surface
+	}
+
+	_ = mask.surface.Clear(mask.color)
+	if hole := mr.revealMaskHole(); hole != nil {
+		holeWidth, holeHeight := hole.GetSize()
+		centerX, centerY := mr.viewport.WorldToScreen(mask.centerX, mask.centerY)
+		mask.surface.PushScale(mr.viewport.GetZoom())
+		mask.surface.PushTranslation(centerX-holeWidth/2, centerY-holeHeight/2)
+		mask.surface.PushCompositeMode(d2render.CompositeModeCopy)
+		_ = mask.surface.Render(hole)
+		mask.surface.PopN(3)
+	}
+
+	return mask.surface
+}
+
+// Returns the spotlight as it is drawn on the reveal mask: the circle projected onto the screen, transparent inside
+// of it and the mask color around it. The hole is redrawn when the radius, color or tile size changes, and is nil if
+// the spotlight is empty.
+func (mr *MapRenderer) revealMaskHole() d2render.Surface {
+	mask := &mr.revealMask
+	tileWidth, tileHeight := mr.viewport.GetTileSize()
+	key := revealMaskHoleKey{radius: mask.radius, color: mask.color, tileWidth: tileWidth, tileHeight: tileHeight}
+	if mask.hole != nil && mask.holeKey == key {
+		return mask.hole
+	}
+	if mask.hole != nil {
+		_ = mask.hole.Dispose()
+		mask.hole = nil
+	}
+
+	// A circle of world tiles is projected to an ellipse, spanning the radius diagonally across the tiles
+	width := int(math.Ceil(mask.radius * tileWidth * math.Sqrt2))
+	height := int(math.Ceil(mask.radius * tileHeight * math.Sqrt2))
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+
+	pixels := make([]byte, 4*width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			orthoX := float64(x) + 0.5 - float64(width)/2
+			orthoY := float64(y) + 0.5 - float64(height)/2
+			worldX := orthoX/tileWidth + orthoY/tileHeight
+			worldY := orthoY/tileHeight - orthoX/tileWidth
+			if worldX*worldX+worldY*worldY <= mask.radius*mask.radius {
+				continue
+			}
+
+			offset := 4 * (y*width + x)
+			pixels[offset] = mask.color.R
+			pixels[offset+1] = mask.color.G
+			pixels[offset+2] = mask.color.B
+			pixels[offset+3] = mask.color.A
+		}
+	}
+
+	hole, err := newTileSurface(width, height, d2render.FilterNearest)
+	if err != nil {
+		return nil
+	}
+	if err := hole.ReplacePixels(pixels); err != nil {
+		_ = hole.Dispose()
+		return nil
+	}
+
+	mask.hole, mask.holeKey = hole, key
+	return hole
+}
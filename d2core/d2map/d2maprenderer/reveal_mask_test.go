@@ -0,0 +1,74 @@
+package d2maprenderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dat"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+func TestRevealMaskDarkensTilesOutsideRadius(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+
+	mr := createTestMapRenderer(3, 1)
+	floorImage := createTestSoftwareSurface(160, 80)
+	_ = floorImage.Clear(color.White)
+	mr.setImageCacheRecord(1, 1, 0, 0, floorImage)
+	for x := 0; x < 3; x++ {
+		mr.mapEngine.TileAt(x, 0).Floors = []d2ds1.FloorShadowRecord{{Style: 1, Sequence: 1, Prop1: 1}}
+	}
+
+	mr.SetRevealMask(0.5, 0.5, 1)
+	assert.True(mr.IsTileRevealed(0, 0))
+	assert.True(mr.IsTileRevealed(1, 0))
+	assert.False(mr.IsTileRevealed(2, 0))
+
+	target := createTestSoftwareSurface(800, 600)
+	mr.Render(target)
+	assert.Equal(0, target.GetDepth())
+
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	darkened := color.RGBA{R: 40, G: 40, B: 40, A: 255}
+	colorAt := func(pixels *image.RGBA, worldX, worldY float64) color.RGBA {
+		x, y := mr.viewport.WorldToScreen(worldX, worldY)
+		return pixels.RGBAAt(x, y)
+	}
+
+	// The mask is cut out per pixel, so the tile straddling the edge of the spotlight is only darkened outside of it
+	pixels := target.Screenshot()
+	assert.Equal(white, colorAt(pixels, 0.5, 0.5))
+	assert.Equal(white, colorAt(pixels, 1.25, 0.5))
+	assert.Equal(darkened, colorAt(pixels, 1.75, 0.5))
+	assert.Equal(darkened, colorAt(pixels, 2.5, 0.5))
+
+	mr.ClearRevealMask()
+	target = createTestSoftwareSurface(800, 600)
+	mr.Render(target)
+	pixels = target.Screenshot()
+	assert.Equal(white, colorAt(pixels, 1.75, 0.5))
+	assert.Equal(white, colorAt(pixels, 2.5, 0.5))
+}
+
+func TestRevealMaskDarkensEntitiesOutsideRadius(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(3, 1)
+	white := d2dat.DATColor{R: 255, G: 255, B: 255}
+	mr.mapEngine.AddEntity(createTestAnimatedEntity(2, 2, 4, 4, white))
+	mr.mapEngine.AddEntity(createTestAnimatedEntity(12, 2, 6, 6, white))
+	mr.SetRevealMask(0.5, 0.5, 1)
+	mr.SetRevealMaskColor(color.RGBA{A: 215})
+
+	target := createTestSoftwareSurface(800, 600)
+	mr.Render(target)
+	pixels := target.Screenshot()
+
+	// The animation inside the spotlight keeps its colors, and the one outside of it is darkened
+	assert.Equal(image.Pt(4, 4), findTestColorBounds(pixels, color.RGBA{R: 255, G: 255, B: 255, A: 255}).Size())
+	assert.Equal(image.Pt(6, 6), findTestColorBounds(pixels, color.RGBA{R: 40, G: 40, B: 40, A: 255}).Size())
+	assert.Equal(0, target.GetDepth())
+}
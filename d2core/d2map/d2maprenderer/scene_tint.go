@@ -0,0 +1,79 @@
+package d2maprenderer
+
+import (
+	"image/color"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// A full screen color overlay drawn after all of the render passes
+type sceneTint struct {
+	color     color.RGBA // The color of the overlay
+	strength  float64    // The current strength of the overlay (0=none, 1=opaque)
+	duration  float64    // The time it takes the tint to decay to nothing, or 0 if it does not decay
+	remaining float64    // The remaining decay time
+	initial   float64    // The strength the decay started from
+}
+
+// Sets a full screen tint that is drawn over the whole map (eg: red flash on damage)
+func (mr *MapRenderer) SetSceneTint(c color.RGBA, strength float64) {
+	strength = d2common.ClampFloat64(strength, 0, 1)
+	mr.sceneTint = sceneTint{
+		color:    c,
+		strength: strength,
+		initial:  strength,
+	}
+}
+
+// Makes the current scene tint decay linearly to nothing over the specified number of seconds
+func (mr *MapRenderer) SetSceneTintDuration(duration float64) {
+	mr.sceneTint.duration = duration
+	mr.sceneTint.remaining = duration
+	mr.sceneTint.initial = mr.sceneTint.strength
+}
+
+// Returns the current scene tint color and strength
+func (mr *MapRenderer) GetSceneTint() (color.RGBA, float64) {
+	return mr.sceneTint.color, mr.sceneTint.strength
+}
+
+// Removes the scene tint
+func (mr *MapRenderer) ClearSceneTint() {
+	mr.sceneTint = sceneTint{}
+}
+
+func (mr *MapRenderer) advanceSceneTint(elapsed float64) {
+	tint := &mr.sceneTint
+	if tint.duration <= 0 || tint.strength <= 0 {
+		return
+	}
+
+	tint.remaining -= elapsed
+	if tint.remaining <= 0 {
+		mr.ClearSceneTint()
+		return
+	}
+
+	tint.strength = tint.initial * (tint.remaining / tint.duration)
+}
+
+func (mr *MapRenderer) renderSceneTint(viewport *Viewport, target d2render.Surface) {
+	tint := mr.sceneTint
+	if tint.strength <= 0 {
+		return
+	}
+
+	// color.RGBA is alpha premultiplied, so every channel is scaled by the strength
+	overlayColor := color.RGBA{
+		R: uint8(float64(tint.color.R) * tint.strength),
+		G: uint8(float64(tint.color.G) * tint.strength),
+		B: uint8(float64(tint.color.B) * tint.strength),
+		A: uint8(float64(tint.color.A) * tint.strength),
+	}
+
+	target.PushTranslation(viewport.screenRect.Left, viewport.screenRect.Top)
+	defer target.Pop()
+
+	target.DrawRect(viewport.screenRect.Width, viewport.screenRect.Height, overlayColor)
+}
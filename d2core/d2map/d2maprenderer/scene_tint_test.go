@@ -0,0 +1,64 @@
+package d2maprenderer
+
+import (
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestSceneTintRenderedAfterPasses(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	target := createTestSurface(800, 600)
+
+	mr.Render(target)
+	assert.Empty(target.callsOf("rect"))
+
+	mr.SetSceneTint(color.RGBA{R: 200, A: 200}, 0.5)
+	mr.Render(target)
+
+	calls := target.calls
+	assert.NotEmpty(calls)
+	last := calls[len(calls)-1]
+	assert.Equal("rect", last.op)
+	assert.Equal(800, last.width)
+	assert.Equal(600, last.height)
+	assert.Equal(color.RGBA{R: 100, A: 100}, last.color)
+	assert.Equal(0, target.GetDepth())
+}
+
+func TestSceneTintDecays(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	mr.SetSceneTint(color.RGBA{B: 255, A: 255}, 1)
+	mr.SetSceneTintDuration(1)
+
+	mr.Advance(0.25)
+	_, strength := mr.GetSceneTint()
+	assert.InDelta(0.75, strength, 0.0001)
+
+	mr.Advance(0.5)
+	_, strength = mr.GetSceneTint()
+	assert.InDelta(0.25, strength, 0.0001)
+
+	mr.Advance(0.5)
+	_, strength = mr.GetSceneTint()
+	assert.Equal(0.0, strength)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Empty(target.callsOf("rect"))
+}
+
+func TestSceneTintWithoutDurationPersists(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	mr.SetSceneTint(color.RGBA{R: 255, A: 255}, 2)
+	mr.Advance(10)
+
+	_, strength := mr.GetSceneTint()
+	assert.Equal(1.0, strength)
+}
@@ -0,0 +1,57 @@
+package d2maprenderer
+
+import (
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+// ShadowOrder determines whether the shadows of a tile are drawn over or under its floors
+type ShadowOrder int
+
+const (
+	ShadowsAboveFloors ShadowOrder = iota // Shadows are cast onto the floors (the default)
+	ShadowsBelowFloors                    // Floors are drawn over the shadows (eg: translucent water floors)
+)
+
+// The shadow order of each region type
+type regionShadowOrders map[d2enum.RegionIdType]ShadowOrder
+
+// Sets the order shadows and floors are drawn in, for regions without an order of their own
+func (mr *MapRenderer) SetShadowOrder(order ShadowOrder) {
+	mr.shadowOrder = order
+}
+
+// Returns the order shadows and floors are drawn in, for regions without an order of their own
+func (mr *MapRenderer) GetShadowOrder() ShadowOrder {
+	return mr.shadowOrder
+}
+
+// Sets the order shadows and floors are drawn in for the tiles of a region type, to match the original game per area
+func (mr *MapRenderer) SetRegionShadowOrder(regionType d2enum.RegionIdType, order ShadowOrder) {
+	if mr.regionShadows == nil {
+		mr.regionShadows = make(regionShadowOrders)
+	}
+	mr.regionShadows[regionType] = order
+}
+
+// Removes the shadow orders of all region types, so every region uses the renderer's shadow order
+func (mr *MapRenderer) ClearRegionShadowOrders() {
+	mr.regionShadows = nil
+}
+
+// Returns the order the shadows and floors of a tile are drawn in. Tiles without a region type use the region of the
+// map.
+func (mr *MapRenderer) tileShadowOrder(tile *d2ds1.TileRecord) ShadowOrder {
+	if len(mr.regionShadows) == 0 {
+		return mr.shadowOrder
+	}
+
+	regionType := tile.RegionType
+	if regionType == d2enum.RegionNone {
+		regionType = d2enum.RegionIdType(mr.mapEngine.LevelType().Id)
+	}
+	if order, found := mr.regionShadows[regionType]; found {
+		return order
+	}
+	return mr.shadowOrder
+}
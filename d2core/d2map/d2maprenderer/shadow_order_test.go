@@ -0,0 +1,74 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// Returns the layers of the images rendered onto the target, in the order they were drawn
+func renderedLayers(target *testSurface, images map[LayerType]d2render.Surface) []LayerType {
+	var result []LayerType
+	for _, call := range target.callsOf("render") {
+		for layer, image := range images {
+			if call.source == image {
+				result = append(result, layer)
+			}
+		}
+	}
+	return result
+}
+
+func TestShadowOrderAboveFloors(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr, images := createTestLayeredMap()
+	assert.Equal(ShadowsAboveFloors, mr.GetShadowOrder())
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	layers := renderedLayers(target, images)
+	assert.Equal([]LayerType{LayerLowerWalls, LayerFloors, LayerShadows}, layers[:3])
+}
+
+func TestShadowOrderBelowFloors(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr, images := createTestLayeredMap()
+	mr.SetShadowOrder(ShadowsBelowFloors)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	layers := renderedLayers(target, images)
+	assert.Equal([]LayerType{LayerLowerWalls, LayerShadows, LayerFloors}, layers[:3])
+}
+
+func TestShadowOrderPerRegion(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr, images := createTestLayeredMap()
+	mr.mapEngine.TileAt(0, 0).RegionType = d2enum.RegionAct1Wilderness
+	mr.mapEngine.TileAt(1, 0).RegionType = d2enum.RegionAct3Jungle
+	mr.SetRegionShadowOrder(d2enum.RegionAct3Jungle, ShadowsBelowFloors)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	// Pass 1 draws the first tile with the default order, then the jungle tile with its own
+	layers := renderedLayers(target, images)
+	assert.Equal([]LayerType{
+		LayerLowerWalls, LayerFloors, LayerShadows,
+		LayerLowerWalls, LayerShadows, LayerFloors,
+	}, layers[:6])
+
+	mr.ClearRegionShadowOrders()
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+	layers = renderedLayers(target, images)
+	assert.Equal([]LayerType{LayerFloors, LayerShadows}, layers[4:6])
+}
@@ -1,6 +1,7 @@
 package d2maprenderer
 
 import (
+	"image"
 	"log"
 
 	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
@@ -11,27 +12,48 @@ import (
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
 )
 
+// Creates the surfaces tile images are drawn to (replaced in tests, which run without a renderer)
+var newTileSurface = d2render.NewSurface
+
 func (mr *MapRenderer) generateTileCache() {
-	mr.palette, _ = loadPaletteForAct(d2enum.RegionIdType(mr.mapEngine.LevelType().Id))
+	if mr.tilesDeferred {
+		return
+	}
+
+	mr.paletteCycles.base, _ = loadTilePaletteForAct(d2enum.RegionIdType(mr.mapEngine.LevelType().Id))
+	mr.palette = mr.cyclePalette(mr.paletteCycles.base)
 	mapEngineSize := mr.mapEngine.Size()
 
-	for idx, tile := range *mr.mapEngine.Tiles() {
+	for idx := range *mr.mapEngine.Tiles() {
 		tileX := idx % mapEngineSize.Width
 		tileY := (idx - tileX) / mapEngineSize.Width
-		for i := range tile.Floors {
-			if !tile.Floors[i].Hidden && tile.Floors[i].Prop1 != 0 {
-				mr.generateFloorCache(&tile.Floors[i], tileX, tileY)
-			}
+		mr.generateTileCacheAt(tileX, tileY)
+	}
+}
+
+// Caches the images of a single tile (eg: after the map engine replaced it)
+func (mr *MapRenderer) generateTileCacheAt(tileX, tileY int) {
+	if mr.tilesDeferred {
+		return
+	}
+
+	mr.cachingTile = &image.Point{X: tileX, Y: tileY}
+	defer func() { mr.cachingTile = nil }()
+
+	tile := mr.mapEngine.TileAt(tileX, tileY)
+	for i := range tile.Floors {
+		if !tile.Floors[i].Hidden && tile.Floors[i].Prop1 != 0 {
+			mr.generateFloorCache(&tile.Floors[i], tileX, tileY)
 		}
-		for i := range tile.Shadows {
-			if !tile.Shadows[i].Hidden && tile.Shadows[i].Prop1 != 0 {
-				mr.generateShadowCache(&tile.Shadows[i], tileX, tileY)
-			}
+	}
+	for i := range tile.Shadows {
+		if !tile.Shadows[i].Hidden && tile.Shadows[i].Prop1 != 0 {
+			mr.generateShadowCache(&tile.Shadows[i], tileX, tileY)
 		}
-		for i := range tile.Walls {
-			if !tile.Walls[i].Hidden && tile.Walls[i].Prop1 != 0 {
-				mr.generateWallCache(&tile.Walls[i], tileX, tileY)
-			}
+	}
+	for i := range tile.Walls {
+		if !tile.Walls[i].Hidden && tile.Walls[i].Prop1 != 0 {
+			mr.generateWallCache(&tile.Walls[i], tileX, tileY)
 		}
 	}
 }
@@ -52,6 +74,7 @@ func (mr *MapRenderer) generateFloorCache(tile *d2ds1.FloorShadowRecord, tileX,
 			tileData = append(tileData, &tileOptions[tileIndex])
 		} else {
 			tile.Animated = true
+			tile.FrameCount = byte(len(tileOptions))
 			for i := range tileOptions {
 				tileData = append(tileData, &tileOptions[i])
 			}
@@ -62,7 +85,8 @@ func (mr *MapRenderer) generateFloorCache(tile *d2ds1.FloorShadowRecord, tileX,
 		if !tileData[i].MaterialFlags.Lava {
 			tile.RandomIndex = tileIndex
 		} else {
-			tileIndex = byte(tileData[i].RarityFrameIndex)
+			// Animated frames are cached by their position in the animation
+			tileIndex = byte(i)
 		}
 		cachedImage := mr.getImageCacheRecord(tile.Style, tile.Sequence, 0, tileIndex)
 		if cachedImage != nil {
@@ -74,9 +98,9 @@ func (mr *MapRenderer) generateFloorCache(tile *d2ds1.FloorShadowRecord, tileX,
 		}
 		tileYOffset := d2common.AbsInt32(tileYMinimum)
 		tileHeight := d2common.AbsInt32(tileData[i].Height)
-		image, _ := d2render.NewSurface(int(tileData[i].Width), int(tileHeight), d2render.FilterNearest)
+		image, _ := newTileSurface(int(tileData[i].Width), int(tileHeight), d2render.FilterNearest)
 		pixels := make([]byte, 4*tileData[i].Width*tileHeight)
-		mr.decodeTileGfxData(tileData[i].Blocks, &pixels, tileYOffset, tileData[i].Width)
+		mr.decodeTileGfxData(tileData[i].Blocks, &pixels, 0, tileYOffset, tileData[i].Width)
 		image.ReplacePixels(pixels)
 		mr.setImageCacheRecord(tile.Style, tile.Sequence, 0, tileIndex, image)
 	}
@@ -113,25 +137,19 @@ func (mr *MapRenderer) generateShadowCache(tile *d2ds1.FloorShadowRecord, tileX,
 		return
 	}
 
-	image, _ := d2render.NewSurface(int(tileData.Width), tileHeight, d2render.FilterNearest)
+	image, _ := newTileSurface(int(tileData.Width), tileHeight, d2render.FilterNearest)
 	pixels := make([]byte, 4*tileData.Width*int32(tileHeight))
-	mr.decodeTileGfxData(tileData.Blocks, &pixels, tileYOffset, tileData.Width)
+	mr.decodeTileGfxData(tileData.Blocks, &pixels, 0, tileYOffset, tileData.Width)
 	image.ReplacePixels(pixels)
 	mr.setImageCacheRecord(tile.Style, tile.Sequence, 13, tileIndex, image)
 }
 
 func (mr *MapRenderer) generateWallCache(tile *d2ds1.WallRecord, tileX, tileY int) {
 	tileOptions := mr.mapEngine.GetTiles(int32(tile.Style), int32(tile.Sequence), int32(tile.Type))
-	var tileIndex byte
-	var tileData *d2dt1.Tile
 	if tileOptions == nil {
 		return
-	} else {
-		tileIndex = mr.getRandomTile(tileOptions, tileX, tileY, mr.mapEngine.Seed())
-		tileData = &tileOptions[tileIndex]
 	}
 
-	tile.RandomIndex = tileIndex
 	var newTileData *d2dt1.Tile = nil
 
 	if tile.Type == 3 {
@@ -140,29 +158,35 @@ func (mr *MapRenderer) generateWallCache(tile *d2ds1.WallRecord, tileX, tileY in
 		newTileData = &newTileOptions[newTileIndex]
 	}
 
-	tileMinY := int32(0)
-	tileMaxY := int32(0)
+	if tileOptions[0].MaterialFlags.Lava {
+		// Animated walls (eg: waterfalls) cache every frame, indexed by their position in the animation
+		tile.Animated = true
+		tile.FrameCount = byte(len(tileOptions))
+		for i := range tileOptions {
+			mr.generateWallImage(tile, &tileOptions[i], newTileData, byte(i))
+		}
+		return
+	}
+
+	tileIndex := mr.getRandomTile(tileOptions, tileX, tileY, mr.mapEngine.Seed())
+	tile.RandomIndex = tileIndex
+	mr.generateWallImage(tile, &tileOptions[tileIndex], newTileData, tileIndex)
+}
 
+func (mr *MapRenderer) generateWallImage(tile *d2ds1.WallRecord, tileData, newTileData *d2dt1.Tile, tileIndex byte) {
 	target := tileData
 
 	if newTileData != nil && newTileData.Height < tileData.Height {
 		target = newTileData
 	}
 
-	for _, block := range target.Blocks {
-		tileMinY = d2common.MinInt32(tileMinY, int32(block.Y))
-		tileMaxY = d2common.MaxInt32(tileMaxY, int32(block.Y+32))
-	}
+	bounds := wallBlockBounds(target, tileData, newTileData)
+	realWidth := int32(bounds.Dx())
+	realHeight := d2common.MaxInt32(d2common.AbsInt32(tileData.Height), int32(bounds.Dy()))
+	tileXOffset := int32(-bounds.Min.X)
+	tileYOffset := int32(-bounds.Min.Y)
 
-	realHeight := d2common.MaxInt32(d2common.AbsInt32(tileData.Height), tileMaxY-tileMinY)
-	tileYOffset := -tileMinY
-	//tileHeight := int(tileMaxY - tileMinY)
-
-	if tile.Type == 15 {
-		tile.YAdjust = -int(tileData.RoofHeight)
-	} else {
-		tile.YAdjust = int(tileMinY) + 80
-	}
+	tile.XAdjust, tile.YAdjust = wallOrthoOffset(tile.Type, tileData, bounds)
 
 	cachedImage := mr.getImageCacheRecord(tile.Style, tile.Sequence, tile.Type, tileIndex)
 	if cachedImage != nil {
@@ -174,12 +198,12 @@ func (mr *MapRenderer) generateWallCache(tile *d2ds1.WallRecord, tileX, tileY in
 		return
 	}
 
-	image, _ := d2render.NewSurface(160, int(realHeight), d2render.FilterNearest)
-	pixels := make([]byte, 4*160*realHeight)
-	mr.decodeTileGfxData(tileData.Blocks, &pixels, tileYOffset, 160)
+	image, _ := newTileSurface(int(realWidth), int(realHeight), d2render.FilterNearest)
+	pixels := make([]byte, 4*realWidth*realHeight)
+	mr.decodeTileGfxData(tileData.Blocks, &pixels, tileXOffset, tileYOffset, realWidth)
 
 	if newTileData != nil {
-		mr.decodeTileGfxData(newTileData.Blocks, &pixels, tileYOffset, 160)
+		mr.decodeTileGfxData(newTileData.Blocks, &pixels, tileXOffset, tileYOffset, realWidth)
 	}
 
 	if err := image.ReplacePixels(pixels); err != nil {
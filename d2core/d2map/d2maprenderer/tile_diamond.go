@@ -0,0 +1,37 @@
+package d2maprenderer
+
+import "math"
+
+// PointInTileDiamond returns true if the screen point is within the isometric diamond of a standard 160x80 tile whose
+// top corner is drawn at the specified screen position (as returned by Viewport.WorldToScreen). Points exactly on the
+// edge of the diamond are considered inside.
+func PointInTileDiamond(px, py, tileScreenX, tileScreenY int) bool {
+	return pointInDiamond(px, py, tileScreenX, tileScreenY, defaultTileWidth/2, defaultTileHeight/2)
+}
+
+// Returns half the size of a tile's isometric diamond, in whole pixels at native scale
+func (v *Viewport) tileDiamondHalfSize() (halfWidth, halfHeight int) {
+	return int(math.Round(v.tileHalfWidth)), int(math.Round(v.tileHalfHeight))
+}
+
+// PointInTileDiamond returns true if the point is within the isometric diamond of a tile of the viewport's tile size,
+// like the package level PointInTileDiamond. Both positions are in pixels at native scale (eg: as returned by
+// Viewport.WorldToScreen without a zoom).
+func (v *Viewport) PointInTileDiamond(px, py, tileScreenX, tileScreenY int) bool {
+	halfWidth, halfHeight := v.tileDiamondHalfSize()
+	return pointInDiamond(px, py, tileScreenX, tileScreenY, halfWidth, halfHeight)
+}
+
+// Returns true if the point is within the diamond of the half size whose top corner is at the specified position
+func pointInDiamond(px, py, topX, topY, halfWidth, halfHeight int) bool {
+	dx := px - topX
+	dy := py - (topY + halfHeight)
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+
+	return dx*halfHeight+dy*halfWidth <= halfWidth*halfHeight
+}
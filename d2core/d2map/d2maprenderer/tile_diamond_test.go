@@ -0,0 +1,53 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestPointInTileDiamondInside(t *testing.T) {
+	assert := testify.New(t)
+	assert.True(PointInTileDiamond(100, 240, 100, 200))
+	assert.True(PointInTileDiamond(130, 230, 100, 200))
+	assert.True(PointInTileDiamond(70, 250, 100, 200))
+}
+
+func TestPointInTileDiamondEdges(t *testing.T) {
+	assert := testify.New(t)
+
+	// Corners
+	assert.True(PointInTileDiamond(100, 200, 100, 200))
+	assert.True(PointInTileDiamond(180, 240, 100, 200))
+	assert.True(PointInTileDiamond(100, 280, 100, 200))
+	assert.True(PointInTileDiamond(20, 240, 100, 200))
+
+	// Midpoints of each edge
+	assert.True(PointInTileDiamond(140, 220, 100, 200))
+	assert.True(PointInTileDiamond(140, 260, 100, 200))
+	assert.True(PointInTileDiamond(60, 260, 100, 200))
+	assert.True(PointInTileDiamond(60, 220, 100, 200))
+}
+
+func TestPointInTileDiamondOutside(t *testing.T) {
+	assert := testify.New(t)
+	assert.False(PointInTileDiamond(100, 199, 100, 200))
+	assert.False(PointInTileDiamond(181, 240, 100, 200))
+	assert.False(PointInTileDiamond(100, 281, 100, 200))
+	assert.False(PointInTileDiamond(19, 240, 100, 200))
+	assert.False(PointInTileDiamond(142, 220, 100, 200))
+	assert.False(PointInTileDiamond(175, 205, 100, 200))
+}
+
+func TestViewportPointInTileDiamondFollowsTileSize(t *testing.T) {
+	assert := testify.New(t)
+	viewport := NewViewport(0, 0, 800, 600)
+	assert.True(viewport.PointInTileDiamond(180, 240, 100, 200))
+	assert.False(viewport.PointInTileDiamond(181, 240, 100, 200))
+
+	viewport.SetTileSize(96, 64)
+	assert.True(viewport.PointInTileDiamond(148, 232, 100, 200))
+	assert.True(viewport.PointInTileDiamond(100, 264, 100, 200))
+	assert.False(viewport.PointInTileDiamond(149, 232, 100, 200))
+	assert.False(viewport.PointInTileDiamond(100, 265, 100, 200))
+}
@@ -0,0 +1,139 @@
+package d2maprenderer
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// The most point lights that light a single tile. When more reach a tile, the nearest ones are used.
+const maxTileLights = 4
+
+// tileLighting is the per frame state used to light the tiles with the point lights on the map
+type tileLighting struct {
+	lights []*d2mapentity.Light // The lights that reach the visible tiles this frame
+	tints  map[int]color.RGBA   // The color each tile is lit with this frame, by tile index
+	tile   *image.Point         // The tile being rendered (nil=tiles are only lit by the global light)
+	nearby []nearbyLight        // The lights reaching the tile being lit (reused between tiles)
+}
+
+type nearbyLight struct {
+	light    *d2mapentity.Light
+	distance float64
+}
+
+// Sets the brightness every floor and wall is drawn at, from 0 (black) to 1 (full brightness), eg: to dim the map at
+// night. The tile colors are multiplied by the level, so the art keeps its hue. Entities are not affected.
+func (mr *MapRenderer) SetGlobalLight(level float64) {
+	mr.globalLight = d2common.ClampFloat64(level, 0, 1)
+}
+
+// Returns the brightness every floor and wall is drawn at
+func (mr *MapRenderer) GetGlobalLight() float64 {
+	return mr.globalLight
+}
+
+// Adds a white point light to the map at the world position, in tiles (eg: a torch). The intensity (0-1) is the
+// brightness added to the global light at the center of the light, and it fades out linearly to nothing at the
+// radius. The light belongs to the map engine, so lights attached to entities with AttachLight are drawn the same way.
+func (mr *MapRenderer) AddLight(x, y, radius, intensity float64) *d2mapentity.Light {
+	level := uint8(255 * d2common.ClampFloat64(intensity, 0, 1))
+	return mr.mapEngine.AddLight(x, y, radius, color.RGBA{R: level, G: level, B: level, A: level})
+}
+
+// Removes a point light added with AddLight
+func (mr *MapRenderer) RemoveLight(light *d2mapentity.Light) {
+	mr.mapEngine.RemoveLight(light)
+}
+
+// Collects the point lights that reach the visible tiles, and clears the tile colors of the previous frame
+func (mr *MapRenderer) prepareTileLights(viewport *Viewport) {
+	mr.lighting.lights = mr.lighting.lights[:0]
+	for key := range mr.lighting.tints {
+		delete(mr.lighting.tints, key)
+	}
+
+	minX, minY, maxX, maxY := mr.visibleTileBounds(viewport)
+	for _, light := range mr.mapEngine.Lights() {
+		if light.Radius <= 0 || light.X+light.Radius < float64(minX) || light.X-light.Radius > float64(maxX+1) ||
+			light.Y+light.Radius < float64(minY) || light.Y-light.Radius > float64(maxY+1) {
+			continue
+		}
+		mr.lighting.lights = append(mr.lighting.lights, light)
+	}
+}
+
+// Lights the floors and walls drawn until the returned function is called with the color of the tile
+func (mr *MapRenderer) lightTile(tileX, tileY int) func() {
+	previous := mr.lighting.tile
+	mr.lighting.tile = &image.Point{X: tileX, Y: tileY}
+	return func() { mr.lighting.tile = previous }
+}
+
+// Pushes the color a tile image is lit with onto the target, where it multiplies with the colors already pushed.
+// Returns false, without pushing a color, when tiles are drawn at full brightness.
+func (mr *MapRenderer) pushTileLight(target d2render.Surface) bool {
+	light := mr.tileLightColor()
+	if light.R == 255 && light.G == 255 && light.B == 255 {
+		return false
+	}
+
+	target.PushColor(light)
+	return true
+}
+
+// Returns the color the tile being rendered is lit with, from the global light and the nearest point lights
+func (mr *MapRenderer) tileLightColor() color.RGBA {
+	if mr.lighting.tile == nil || len(mr.lighting.lights) == 0 {
+		level := uint8(255 * mr.globalLight)
+		return color.RGBA{R: level, G: level, B: level, A: 255}
+	}
+
+	tileX, tileY := mr.lighting.tile.X, mr.lighting.tile.Y
+	index := tileY*mr.mapEngine.Size().Width + tileX
+	if light, ok := mr.lighting.tints[index]; ok {
+		return light
+	}
+
+	if mr.lighting.tints == nil {
+		mr.lighting.tints = make(map[int]color.RGBA)
+	}
+	light := mr.computeTileLight(float64(tileX)+0.5, float64(tileY)+0.5)
+	mr.lighting.tints[index] = light
+	return light
+}
+
+// Returns the color of the light at the world position, in tiles
+func (mr *MapRenderer) computeTileLight(x, y float64) color.RGBA {
+	nearby := mr.lighting.nearby[:0]
+	for _, light := range mr.lighting.lights {
+		if distance := math.Hypot(light.X-x, light.Y-y); distance < light.Radius {
+			nearby = append(nearby, nearbyLight{light: light, distance: distance})
+		}
+	}
+	sort.Slice(nearby, func(i, j int) bool { return nearby[i].distance < nearby[j].distance })
+	if len(nearby) > maxTileLights {
+		nearby = nearby[:maxTileLights]
+	}
+	mr.lighting.nearby = nearby
+
+	r, g, b := mr.globalLight, mr.globalLight, mr.globalLight
+	for _, near := range nearby {
+		falloff := 1 - near.distance/near.light.Radius
+		r += falloff * float64(near.light.Color.R) / 255
+		g += falloff * float64(near.light.Color.G) / 255
+		b += falloff * float64(near.light.Color.B) / 255
+	}
+
+	return color.RGBA{
+		R: uint8(255 * math.Min(r, 1)),
+		G: uint8(255 * math.Min(g, 1)),
+		B: uint8(255 * math.Min(b, 1)),
+		A: 255,
+	}
+}
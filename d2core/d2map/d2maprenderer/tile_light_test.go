@@ -0,0 +1,167 @@
+package d2maprenderer
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2map/d2mapentity"
+)
+
+// createTestLitRenderer creates a renderer for a single tile with a floor, a wall, a shadow and an entity
+func createTestLitRenderer() (mr *MapRenderer, floor, wall, shadow *testSurface) {
+	mr = createTestMapRenderer(1, 1)
+	floor = createTestSurface(160, 80)
+	wall = createTestSurface(160, 200)
+	shadow = createTestSurface(160, 80)
+	mr.setImageCacheRecord(1, 1, d2enum.Floor, 0, floor)
+	mr.setImageCacheRecord(2, 1, d2enum.LeftWall, 0, wall)
+	mr.setImageCacheRecord(3, 1, d2enum.Shadow, 0, shadow)
+
+	tile := mr.mapEngine.TileAt(0, 0)
+	tile.Floors = []d2ds1.FloorShadowRecord{{Style: 1, Sequence: 1, Prop1: 1}}
+	tile.Shadows = []d2ds1.FloorShadowRecord{{Style: 3, Sequence: 1, Prop1: 1}}
+	tile.Walls = []d2ds1.WallRecord{{Type: d2enum.LeftWall, Style: 2, Sequence: 1}}
+	mr.mapEngine.AddEntity(createTestEntity("player", 0, 0))
+	return mr, floor, wall, shadow
+}
+
+func TestGlobalLightDimsTiles(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr, floor, wall, shadow := createTestLitRenderer()
+	assert.Equal(1.0, mr.GetGlobalLight())
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Empty(target.calls[indexOfRender(target, floor)].colors)
+	assert.Empty(target.calls[indexOfRender(target, wall)].colors)
+
+	mr.SetGlobalLight(0.5)
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+
+	dimmed := []color.Color{color.RGBA{R: 127, G: 127, B: 127, A: 255}}
+	assert.Equal(dimmed, target.calls[indexOfRender(target, floor)].colors)
+	assert.Equal(dimmed, target.calls[indexOfRender(target, wall)].colors)
+
+	// Shadows keep their own color, and entities are not dimmed
+	assert.Equal([]color.Color{color.RGBA{R: 160, G: 160, B: 160, A: 160}},
+		target.calls[indexOfRender(target, shadow)].colors)
+	assert.Empty(target.calls[indexOfText(target, "entity:player")].colors)
+
+	// Every pushed color is popped
+	assert.Equal(0, target.GetDepth())
+}
+
+func TestGlobalLightIsClamped(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	mr.SetGlobalLight(-1)
+	assert.Equal(0.0, mr.GetGlobalLight())
+	mr.SetGlobalLight(3)
+	assert.Equal(1.0, mr.GetGlobalLight())
+}
+
+// createTestLightRowRenderer creates a renderer for a row of three tiles, each with its own floor image
+func createTestLightRowRenderer() (*MapRenderer, []*testSurface) {
+	mr := createTestMapRenderer(3, 1)
+	floors := make([]*testSurface, 3)
+	for tileX := range floors {
+		floors[tileX] = createTestSurface(160, 80)
+		mr.setImageCacheRecord(byte(tileX+1), 1, d2enum.Floor, 0, floors[tileX])
+		mr.mapEngine.TileAt(tileX, 0).Floors = []d2ds1.FloorShadowRecord{{Style: byte(tileX + 1), Sequence: 1, Prop1: 1}}
+	}
+	return mr, floors
+}
+
+func TestPointLightFadesOutToRadius(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr, floors := createTestLightRowRenderer()
+	mr.SetGlobalLight(0.2)
+	mr.AddLight(0.5, 0.5, 2, 0.8)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	// The light's tile is at full brightness, the next is half lit, and the last is out of reach
+	assert.Empty(target.calls[indexOfRender(target, floors[0])].colors)
+	assert.Equal([]color.Color{color.RGBA{R: 153, G: 153, B: 153, A: 255}},
+		target.calls[indexOfRender(target, floors[1])].colors)
+	assert.Equal([]color.Color{color.RGBA{R: 51, G: 51, B: 51, A: 255}},
+		target.calls[indexOfRender(target, floors[2])].colors)
+	assert.Equal(0, target.GetDepth())
+}
+
+func TestPointLightsUseTheirColor(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr, floors := createTestLightRowRenderer()
+	mr.SetGlobalLight(0)
+	mr.mapEngine.AddLight(1.5, 0.5, 1, color.RGBA{R: 255, G: 128, A: 255})
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Equal([]color.Color{color.RGBA{R: 255, G: 128, B: 0, A: 255}},
+		target.calls[indexOfRender(target, floors[1])].colors)
+}
+
+func TestRemovedPointLightNoLongerLights(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr, floors := createTestLightRowRenderer()
+	mr.SetGlobalLight(0.2)
+	light := mr.AddLight(0.5, 0.5, 2, 0.8)
+	mr.Render(createTestSurface(800, 600))
+
+	mr.RemoveLight(light)
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Equal([]color.Color{color.RGBA{R: 51, G: 51, B: 51, A: 255}},
+		target.calls[indexOfRender(target, floors[0])].colors)
+}
+
+func TestPointLightsOutsideTheScreenAreIgnored(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr, _ := createTestLightRowRenderer()
+	near := mr.AddLight(0.5, 0.5, 2, 1)
+	mr.AddLight(500, 500, 2, 1)
+
+	mr.Render(createTestSurface(800, 600))
+	assert.Equal([]*d2mapentity.Light{near}, mr.lighting.lights)
+
+	// Each lit tile's color is computed once per frame, and forgotten on the next
+	assert.Len(mr.lighting.tints, 3)
+	mr.RemoveLight(near)
+	mr.Render(createTestSurface(800, 600))
+	assert.Empty(mr.lighting.tints)
+}
+
+func TestTileLightIsDarkenedByRevealMask(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr := createTestMapRenderer(1, 1)
+	floor := createTestSoftwareSurface(160, 80)
+	_ = floor.Clear(color.White)
+	mr.setImageCacheRecord(1, 1, d2enum.Floor, 0, floor)
+	mr.mapEngine.TileAt(0, 0).Floors = []d2ds1.FloorShadowRecord{{Style: 1, Sequence: 1, Prop1: 1}}
+	mr.SetGlobalLight(0.5)
+	mr.SetRevealMask(10, 10, 1)
+	mr.SetRevealMaskColor(color.RGBA{A: 215})
+
+	target := createTestSoftwareSurface(800, 600)
+	_ = target.Clear(color.Black)
+	mr.Render(target)
+
+	// The floor outside the spotlight is both dimmed and darkened by the mask
+	lit := findTestColorBounds(target.Screenshot(), color.RGBA{R: 19, G: 19, B: 19, A: 255})
+	assert.Equal(image.Pt(160, 80), lit.Size())
+	assert.Equal(0, target.GetDepth())
+}
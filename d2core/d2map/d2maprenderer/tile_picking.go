@@ -0,0 +1,59 @@
+package d2maprenderer
+
+import (
+	"math"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+)
+
+// The number of sub-tiles along each edge of a tile, as laid out by the sub-tile debug visualization
+const subTilesPerTile = 5
+
+// Returns the tile and the sub-tile within it under a screen position (eg: the mouse cursor), and whether the tile is
+// on the map. The edges of a tile's diamond are whole world coordinates, so a point just across an edge picks the
+// neighboring tile. Positions off the map still return the tile they would be over.
+func (mr *MapRenderer) ScreenToSubTile(x, y int) (tileX, tileY, subTileX, subTileY int, onMap bool) {
+	worldX, worldY := mr.ScreenToWorld(x, y)
+	tileX, tileY = mr.tileAtScreen(x, y)
+	subTileX = subTileWithin(worldX, tileX)
+	subTileY = subTileWithin(worldY, tileY)
+
+	mapSize := mr.mapEngine.Size()
+	onMap = tileX >= 0 && tileY >= 0 && tileX < mapSize.Width && tileY < mapSize.Height
+	return tileX, tileY, subTileX, subTileY, onMap
+}
+
+// The tiles whose diamonds share an edge with a tile's diamond
+var neighborTileOffsets = [...][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}}
+
+// Returns the tile whose isometric diamond contains a screen position. The tile the world coordinates fall on is
+// checked against the point in native scale pixels, and a point rounded across the edge of its diamond picks the
+// neighboring tile that contains it instead.
+func (mr *MapRenderer) tileAtScreen(x, y int) (tileX, tileY int) {
+	worldX, worldY := mr.viewport.ScreenToWorld(x, y)
+	tileX, tileY = int(math.Floor(worldX)), int(math.Floor(worldY))
+
+	orthoX, orthoY := mr.viewport.ScreenToOrtho(x, y)
+	px, py := int(math.Round(orthoX)), int(math.Round(orthoY))
+	inDiamond := func(tileX, tileY int) bool {
+		cornerX, cornerY := mr.viewport.WorldToOrtho(float64(tileX), float64(tileY))
+		return mr.viewport.PointInTileDiamond(px, py, int(math.Round(cornerX)), int(math.Round(cornerY)))
+	}
+
+	if inDiamond(tileX, tileY) {
+		return tileX, tileY
+	}
+	for _, offset := range neighborTileOffsets {
+		if inDiamond(tileX+offset[0], tileY+offset[1]) {
+			return tileX + offset[0], tileY + offset[1]
+		}
+	}
+	return tileX, tileY
+}
+
+// Returns the sub-tile a world coordinate falls on within a tile, clamped to the tile's sub-tiles
+func subTileWithin(world float64, tile int) int {
+	subTile := int(math.Floor((world - float64(tile)) * subTilesPerTile))
+	// A coordinate a rounding error outside the tile must not spill over into a neighboring sub-tile
+	return d2common.MaxInt(0, d2common.MinInt(subTile, subTilesPerTile-1))
+}
@@ -0,0 +1,92 @@
+package d2maprenderer
+
+import (
+	"math"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestScreenToSubTileAcrossDiamondEdge(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+
+	// The screen center is the top corner of tile 0,0, and the edge it shares with tile 1,0 runs through 440,360
+	tileX, tileY, subTileX, subTileY, onMap := mr.ScreenToSubTile(438, 360)
+	assert.Equal([]int{0, 0, 4, 2}, []int{tileX, tileY, subTileX, subTileY})
+	assert.True(onMap)
+
+	tileX, tileY, subTileX, subTileY, onMap = mr.ScreenToSubTile(442, 360)
+	assert.Equal([]int{1, 0, 0, 2}, []int{tileX, tileY, subTileX, subTileY})
+	assert.True(onMap)
+
+	tileX, tileY, subTileX, subTileY, _ = mr.ScreenToSubTile(400, 302)
+	assert.Equal([]int{0, 0, 0, 0}, []int{tileX, tileY, subTileX, subTileY})
+}
+
+func TestScreenToSubTileMatchesDebugLayout(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+	mr.MoveCameraTo(mr.WorldToOrtho(4, 4))
+
+	// The center of each sub-tile diamond is 8 pixels below its top corner
+	originX, originY := mr.viewport.WorldToScreen(2, 3)
+	for yy := 0; yy < subTilesPerTile; yy++ {
+		for xx := 0; xx < subTilesPerTile; xx++ {
+			isoX, isoY := mr.viewport.subTileIsoOffset(xx, yy)
+			tileX, tileY, subTileX, subTileY, onMap := mr.ScreenToSubTile(originX+isoX, originY+isoY+8)
+			assert.Equal([]int{2, 3, xx, yy}, []int{tileX, tileY, subTileX, subTileY})
+			assert.True(onMap)
+		}
+	}
+}
+
+func TestScreenToSubTileOffMap(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(2, 2)
+
+	// Just above the top corner of the map
+	tileX, tileY, subTileX, subTileY, onMap := mr.ScreenToSubTile(400, 290)
+	assert.Equal([]int{-1, -1, 4, 4}, []int{tileX, tileY, subTileX, subTileY})
+	assert.False(onMap)
+
+	// Past the bottom corner of the map, at world 2,2
+	_, _, _, _, onMap = mr.ScreenToSubTile(400, 462)
+	assert.False(onMap)
+	tileX, tileY, _, _, onMap = mr.ScreenToSubTile(400, 458)
+	assert.Equal([]int{1, 1}, []int{tileX, tileY})
+	assert.True(onMap)
+}
+
+func TestPickedTileDiamondContainsPoint(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+	mr.SetTileSize(96, 40)
+	mr.MoveCameraTo(mr.WorldToOrtho(4.13, 4.29))
+
+	// Every screen point picks a tile whose diamond contains it, including the points whose world coordinates are
+	// rounded across the edge of a diamond by the camera's fractional offset
+	for y := 250; y < 350; y++ {
+		for x := 350; x < 450; x++ {
+			tileX, tileY := mr.tileAtScreen(x, y)
+			orthoX, orthoY := mr.viewport.ScreenToOrtho(x, y)
+			cornerX, cornerY := mr.viewport.WorldToOrtho(float64(tileX), float64(tileY))
+			inside := mr.viewport.PointInTileDiamond(int(math.Round(orthoX)), int(math.Round(orthoY)),
+				int(math.Round(cornerX)), int(math.Round(cornerY)))
+			assert.True(inside, "%d,%d picked %d,%d", x, y, tileX, tileY)
+		}
+	}
+}
+
+func TestScreenSelectionsPickTheSameTile(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+	mr.MoveCameraTo(mr.WorldToOrtho(4, 4))
+
+	tileX, tileY, _, _, _ := mr.ScreenToSubTile(442, 360)
+	mr.SelectDebugTileAt(442, 360)
+	debugX, debugY, _ := mr.GetDebugTile()
+	ghostX, ghostY := mr.SetPlacementGhostAtScreen(createTestSurface(10, 10), 442, 360)
+	assert.Equal([]int{tileX, tileY}, []int{debugX, debugY})
+	assert.Equal([]int{tileX, tileY}, []int{ghostX, ghostY})
+}
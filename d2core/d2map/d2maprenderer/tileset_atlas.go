@@ -0,0 +1,141 @@
+package d2maprenderer
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// The height of the label drawn above each tile of a tileset atlas, in pixels
+const atlasLabelHeight = 14
+
+// The space between the cells of a tileset atlas, in pixels
+const atlasPadding = 4
+
+// Creates the surface a tileset atlas is rendered onto
+var newAtlasSurface = func(width, height int) (d2render.Surface, error) {
+	return d2render.NewSurface(width, height, d2render.FilterNearest)
+}
+
+// atlasTile is a distinct tile image referenced by the map, and the key it is cached under
+type atlasTile struct {
+	tileType        d2enum.TileType
+	style, sequence byte
+	index           byte // The random variation of the tile, or the frame of an animated tile
+	image           d2render.Surface
+}
+
+// Renders every distinct tile referenced by the loaded region into a labeled grid (eg: for auditing a region's art),
+// with one cell per tile type, style, sequence and variation. The tiles are the images decoded for the map's tile
+// cache, ordered by type, style, sequence and variation, and each is labeled "type: style-sequence #variation".
+// The region must be the one the renderer has loaded.
+func (mr *MapRenderer) ExportTilesetAtlas(regionType d2enum.RegionIdType) (image.Image, error) {
+	if loaded := d2enum.RegionIdType(mr.mapEngine.LevelType().Id); loaded != regionType {
+		return nil, fmt.Errorf("region %d is not loaded (the renderer has region %d)", regionType, loaded)
+	}
+
+	tiles := mr.atlasTiles()
+	if len(tiles) == 0 {
+		return nil, fmt.Errorf("region %d has no tile images", regionType)
+	}
+
+	cellWidth, cellHeight := 0, 0
+	for _, tile := range tiles {
+		width, height := tile.image.GetSize()
+		cellWidth = int(math.Max(float64(cellWidth), float64(width)))
+		cellHeight = int(math.Max(float64(cellHeight), float64(height)))
+	}
+	cellWidth += atlasPadding
+	cellHeight += atlasLabelHeight + atlasPadding
+
+	columns := int(math.Ceil(math.Sqrt(float64(len(tiles)))))
+	rows := (len(tiles) + columns - 1) / columns
+	width, height := columns*cellWidth-atlasPadding, rows*cellHeight-atlasPadding
+	if width > maxExportImageSize || height > maxExportImageSize {
+		return nil, fmt.Errorf("the atlas of region %d is too large to export", regionType)
+	}
+
+	target, err := newAtlasSurface(width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, tile := range tiles {
+		target.PushTranslation((i%columns)*cellWidth, (i/columns)*cellHeight)
+		target.DrawText("%d: %d-%d #%d", tile.tileType, tile.style, tile.sequence, tile.index)
+		target.PushTranslation(0, atlasLabelHeight)
+		err := target.Render(tile.image)
+		target.PopN(2)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return target.Screenshot(), nil
+}
+
+// Returns the distinct cached tile images referenced by the map's visible floors, shadows and walls, in atlas order
+func (mr *MapRenderer) atlasTiles() []atlasTile {
+	var tiles []atlasTile
+	found := make(map[uint32]bool)
+	add := func(tileType d2enum.TileType, style, sequence, index byte) {
+		lookupIndex := imageCacheLookupIndex(style, sequence, tileType, index)
+		if found[lookupIndex] {
+			return
+		}
+		if image := mr.getImageCacheRecord(style, sequence, tileType, index); image != nil {
+			found[lookupIndex] = true
+			tiles = append(tiles, atlasTile{tileType, style, sequence, index, image})
+		}
+	}
+	addVariations := func(tileType d2enum.TileType, style, sequence, index byte, animated bool,
+		frameCount byte) {
+		if !animated {
+			add(tileType, style, sequence, index)
+			return
+		}
+		for frame := byte(0); frame < frameCount; frame++ {
+			add(tileType, style, sequence, frame)
+		}
+	}
+
+	for _, tile := range *mr.mapEngine.Tiles() {
+		for _, floor := range tile.Floors {
+			if !floor.Hidden && floor.Prop1 != 0 {
+				addVariations(d2enum.Floor, floor.Style, floor.Sequence, floor.RandomIndex, floor.Animated,
+					floor.FrameCount)
+			}
+		}
+		for _, shadow := range tile.Shadows {
+			if !shadow.Hidden && shadow.Prop1 != 0 {
+				add(d2enum.Shadow, shadow.Style, shadow.Sequence, shadow.RandomIndex)
+			}
+		}
+		for _, wall := range tile.Walls {
+			if !wall.Hidden && wall.Prop1 != 0 {
+				addVariations(wall.Type, wall.Style, wall.Sequence, wall.RandomIndex, wall.Animated,
+					wall.FrameCount)
+			}
+		}
+	}
+
+	sort.Slice(tiles, func(i, j int) bool {
+		a, b := tiles[i], tiles[j]
+		if a.tileType != b.tileType {
+			return a.tileType < b.tileType
+		}
+		if a.style != b.style {
+			return a.style < b.style
+		}
+		if a.sequence != b.sequence {
+			return a.sequence < b.sequence
+		}
+		return a.index < b.index
+	})
+	return tiles
+}
@@ -0,0 +1,103 @@
+package d2maprenderer
+
+import (
+	"image"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+// Makes tileset atlases render onto test surfaces, returning a function that restores the renderer surfaces. The
+// surfaces are also sent to the channel, so tests can inspect the draw calls.
+func useTestAtlasSurfaces(surfaces chan<- *testSurface) func() {
+	previous := newAtlasSurface
+	newAtlasSurface = func(width, height int) (d2render.Surface, error) {
+		surface := createTestSurface(width, height)
+		surfaces <- surface
+		return surface, nil
+	}
+	return func() { newAtlasSurface = previous }
+}
+
+func TestExportTilesetAtlasHasOneCellPerDistinctTile(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	surfaces := make(chan *testSurface, 1)
+	defer useTestAtlasSurfaces(surfaces)()
+
+	mr := createTestMapRenderer(3, 2)
+	floor := createTestSurface(160, 80)
+	floorVariation := createTestSurface(160, 80)
+	shadow := createTestSurface(160, 80)
+	wall := createTestSurface(160, 200)
+	mr.setImageCacheRecord(1, 1, d2enum.Floor, 0, floor)
+	mr.setImageCacheRecord(1, 1, d2enum.Floor, 2, floorVariation)
+	mr.setImageCacheRecord(3, 0, d2enum.Shadow, 0, shadow)
+	mr.setImageCacheRecord(2, 4, d2enum.LeftWall, 0, wall)
+	for frame := byte(0); frame < 3; frame++ {
+		mr.setImageCacheRecord(5, 0, d2enum.Floor, frame, createTestSurface(160, 80))
+	}
+
+	// The same floor on several tiles, with one variation, an animated floor, a shadow, a wall,
+	// and a hidden floor that is not drawn
+	set := func(tileX, tileY int, floors ...d2ds1.FloorShadowRecord) {
+		mr.mapEngine.TileAt(tileX, tileY).Floors = floors
+	}
+	set(0, 0, d2ds1.FloorShadowRecord{Style: 1, Sequence: 1, Prop1: 1})
+	set(1, 0, d2ds1.FloorShadowRecord{Style: 1, Sequence: 1, Prop1: 1})
+	set(2, 0, d2ds1.FloorShadowRecord{Style: 1, Sequence: 1, Prop1: 1, RandomIndex: 2})
+	set(0, 1, d2ds1.FloorShadowRecord{Style: 5, Prop1: 1, Animated: true, FrameCount: 3})
+	set(1, 1, d2ds1.FloorShadowRecord{Style: 1, Sequence: 1, Prop1: 1},
+		d2ds1.FloorShadowRecord{Style: 9, Sequence: 9, Prop1: 1, Hidden: true})
+	mr.mapEngine.TileAt(1, 1).Shadows = []d2ds1.FloorShadowRecord{{Style: 3, Prop1: 1}}
+	mr.mapEngine.TileAt(2, 1).Walls = []d2ds1.WallRecord{{Type: d2enum.LeftWall, Style: 2, Sequence: 4, Prop1: 1}}
+
+	result, err := mr.ExportTilesetAtlas(d2enum.RegionNone)
+	if !assert.NoError(err) {
+		return
+	}
+	target := <-surfaces
+
+	// 7 cells (2 floor variations, 3 animation frames, the wall and the shadow) fit a 3x3 grid of the largest tile
+	cellWidth, cellHeight := 160+atlasPadding, 200+atlasLabelHeight+atlasPadding
+	assert.Equal(image.Rect(0, 0, 3*cellWidth-atlasPadding, 3*cellHeight-atlasPadding), result.Bounds())
+	assert.Len(target.callsOf("render"), 7)
+
+	labels := target.callsOf("text")
+	if !assert.Len(labels, 7) {
+		return
+	}
+	texts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		texts = append(texts, label.text)
+	}
+	assert.Equal([]string{"0: 1-1 #0", "0: 1-1 #2", "0: 5-0 #0", "0: 5-0 #1", "0: 5-0 #2", "1: 2-4 #0", "13: 3-0 #0"},
+		texts)
+
+	// The cells are laid out in rows, with each tile below its label
+	assert.Equal(0, labels[0].x)
+	assert.Equal(cellWidth, labels[1].x)
+	assert.Equal(cellHeight, labels[3].y)
+	floorCall := target.calls[indexOfRender(target, floor)]
+	assert.Equal(atlasLabelHeight, floorCall.y)
+	wallCall := target.calls[indexOfRender(target, wall)]
+	assert.Equal(2*cellWidth, wallCall.x)
+	assert.Equal(cellHeight+atlasLabelHeight, wallCall.y)
+}
+
+func TestExportTilesetAtlasRequiresLoadedRegion(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+	mr := createTestMapRenderer(1, 1)
+
+	_, err := mr.ExportTilesetAtlas(d2enum.RegionAct1Town)
+	assert.Error(err)
+
+	// The loaded region has no tile images
+	_, err = mr.ExportTilesetAtlas(d2enum.RegionNone)
+	assert.Error(err)
+}
@@ -17,6 +17,15 @@ const (
 	right  = 2
 )
 
+const (
+	defaultTileWidth  = 160 // The width of a standard isometric tile, in pixels
+	defaultTileHeight = 80  // The height of a standard isometric tile, in pixels
+)
+
+// The number of tiles a tile's culling rectangle extends along the world X axis either side of the tile, so that the
+// walls drawn above its floor diamond are included
+const tileVisibilityPadding = 3
+
 type Viewport struct {
 	defaultScreenRect d2common.Rectangle
 	screenRect        d2common.Rectangle
@@ -24,6 +33,10 @@ type Viewport struct {
 	transCurrent      worldTrans
 	camera            *Camera
 	align             int
+	cullMargin        int     // The number of tiles beyond the screen edges that are still considered visible
+	tileHalfWidth     float64 // Half of the projected tile width, in pixels
+	tileHalfHeight    float64 // Half of the projected tile height, in pixels
+	renderSpace       bool    // Whether the viewport is in the unzoomed coordinates the map is drawn in
 }
 
 func NewViewport(x, y, width, height int) *Viewport {
@@ -40,6 +53,8 @@ func NewViewport(x, y, width, height int) *Viewport {
 			Width:  width,
 			Height: height,
 		},
+		tileHalfWidth:  defaultTileWidth / 2,
+		tileHalfHeight: defaultTileHeight / 2,
 	}
 }
 
@@ -56,49 +71,177 @@ func (v *Viewport) ScreenToWorld(x, y int) (float64, float64) {
 }
 
 func (v *Viewport) OrthoToWorld(x, y float64) (float64, float64) {
-	worldX := (x/80 + y/40) / 2
-	worldY := (y/40 - x/80) / 2
+	worldX := (x/v.tileHalfWidth + y/v.tileHalfHeight) / 2
+	worldY := (y/v.tileHalfHeight - x/v.tileHalfWidth) / 2
 	return worldX, worldY
 }
 
 func (v *Viewport) WorldToOrtho(x, y float64) (float64, float64) {
-	orthoX := (x - y) * 80
-	orthoY := (x + y) * 40
+	orthoX := (x - y) * v.tileHalfWidth
+	orthoY := (x + y) * v.tileHalfHeight
 	return orthoX, orthoY
 }
 
 func (v *Viewport) ScreenToOrtho(x, y int) (float64, float64) {
 	camX, camY := v.getCameraOffset()
-	screenX := float64(x) + camX - float64(v.screenRect.Left)
-	screenY := float64(y) + camY - float64(v.screenRect.Top)
+	zoom := v.projectionZoom()
+	screenX := float64(x-v.screenRect.Left)/zoom + camX
+	screenY := float64(y-v.screenRect.Top)/zoom + camY
 	return screenX, screenY
 }
 
 func (v *Viewport) OrthoToScreen(x, y float64) (int, int) {
 	camOrthoX, camOrthoY := v.getCameraOffset()
-	orthoX := int(math.Floor(x - camOrthoX + float64(v.screenRect.Left)))
-	orthoY := int(math.Floor(y - camOrthoY + float64(v.screenRect.Top)))
+	zoom := v.projectionZoom()
+	orthoX := int(math.Floor((x-camOrthoX)*zoom + float64(v.screenRect.Left)))
+	orthoY := int(math.Floor((y-camOrthoY)*zoom + float64(v.screenRect.Top)))
 	return orthoX, orthoY
 }
 
 func (v *Viewport) IsTileVisible(x, y float64) bool {
-	orthoX1, orthoY1 := v.WorldToOrtho(x-3, y)
-	orthoX2, orthoY2 := v.WorldToOrtho(x+3, y)
+	orthoX1, orthoY1 := v.WorldToOrtho(x-tileVisibilityPadding, y)
+	orthoX2, orthoY2 := v.WorldToOrtho(x+tileVisibilityPadding, y)
 	return v.IsOrthoRectVisible(orthoX1, orthoY1, orthoX2, orthoY2)
 }
 
+// Returns the range of tiles (inclusive) that may be visible, found from the world positions of the screen corners
+// (including the cull margin). The screen covers a diagonal band of the isometric world, so the range is the world
+// aligned rectangle around it, padded so that tiles below the screen whose walls rise above its top edge are included.
+// The range contains every tile IsTileVisible accepts, but not only those, so callers still check each tile.
+func (v *Viewport) GetVisibleTileBounds() (minX, minY, maxX, maxY int) {
+	marginX := int(float64(v.cullMargin) * v.tileHalfWidth * v.projectionZoom())
+	marginY := int(float64(v.cullMargin) * v.tileHalfHeight * v.projectionZoom())
+	right := v.defaultScreenRect.Width + marginX
+	bottom := v.defaultScreenRect.Height + marginY
+
+	minWorldX, minWorldY := math.Inf(1), math.Inf(1)
+	maxWorldX, maxWorldY := math.Inf(-1), math.Inf(-1)
+	for _, corner := range [4][2]int{{-marginX, -marginY}, {right, -marginY}, {-marginX, bottom}, {right, bottom}} {
+		worldX, worldY := v.ScreenToWorld(corner[0], corner[1])
+		minWorldX, maxWorldX = math.Min(minWorldX, worldX), math.Max(maxWorldX, worldX)
+		minWorldY, maxWorldY = math.Min(minWorldY, worldY), math.Max(maxWorldY, worldY)
+	}
+
+	// The extra tile covers the rounding of screen positions to whole pixels
+	padding := tileVisibilityPadding + 1
+	return int(math.Floor(minWorldX)) - padding, int(math.Floor(minWorldY)) - padding,
+		int(math.Floor(maxWorldX)) + padding, int(math.Floor(maxWorldY)) + padding
+}
+
 func (v *Viewport) IsTileRectVisible(rect d2common.Rectangle) bool {
-	left := float64((rect.Left - rect.Bottom()) * 80)
-	top := float64((rect.Left + rect.Top) * 40)
-	right := float64((rect.Right() - rect.Top) * 80)
-	bottom := float64((rect.Right() + rect.Bottom()) * 40)
+	left := float64(rect.Left-rect.Bottom()) * v.tileHalfWidth
+	top := float64(rect.Left+rect.Top) * v.tileHalfHeight
+	right := float64(rect.Right()-rect.Top) * v.tileHalfWidth
+	bottom := float64(rect.Right()+rect.Bottom()) * v.tileHalfHeight
 	return v.IsOrthoRectVisible(left, top, right, bottom)
 }
 
 func (v *Viewport) IsOrthoRectVisible(x1, y1, x2, y2 float64) bool {
 	screenX1, screenY1 := v.OrthoToScreen(x1, y1)
 	screenX2, screenY2 := v.OrthoToScreen(x2, y2)
-	return !(screenX1 >= v.defaultScreenRect.Width || screenX2 < 0 || screenY1 >= v.defaultScreenRect.Height || screenY2 < 0)
+	marginX := int(float64(v.cullMargin) * v.tileHalfWidth * v.projectionZoom())
+	marginY := int(float64(v.cullMargin) * v.tileHalfHeight * v.projectionZoom())
+	return !(screenX1 >= v.defaultScreenRect.Width+marginX || screenX2 < -marginX ||
+		screenY1 >= v.defaultScreenRect.Height+marginY || screenY2 < -marginY)
+}
+
+// Sets the number of tiles beyond the screen edges that are still considered visible. A larger margin renders tiles
+// and entities just off screen, trading overdraw for less pop-in at the edges.
+func (v *Viewport) SetCullMargin(tiles int) {
+	v.cullMargin = d2common.MaxInt(tiles, 0)
+}
+
+// Returns the culling margin, in tiles
+func (v *Viewport) GetCullMargin() int {
+	return v.cullMargin
+}
+
+// Sets the projected size of an isometric tile, in pixels, for tile art that does not use the standard 160x80 (2:1)
+// proportions. Non-positive sizes are ignored.
+func (v *Viewport) SetTileSize(width, height float64) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+	v.tileHalfWidth = width / 2
+	v.tileHalfHeight = height / 2
+}
+
+// Returns the projected size of an isometric tile, in pixels
+func (v *Viewport) GetTileSize() (width, height float64) {
+	return v.tileHalfWidth * 2, v.tileHalfHeight * 2
+}
+
+// Sets the zoom of the viewport's camera, which scales the map around the center of the viewport
+func (v *Viewport) SetZoom(zoom float64) {
+	if v.camera != nil {
+		v.camera.SetZoom(zoom)
+	}
+}
+
+// Returns the scale the map is drawn at
+func (v *Viewport) GetZoom() float64 {
+	return v.projectionZoom()
+}
+
+// Returns the scale of the projection from ortho to screen coordinates: the camera zoom, or 1 while the viewport is
+// in render space (where the target is scaled instead)
+func (v *Viewport) projectionZoom() float64 {
+	if v.renderSpace || v.camera == nil {
+		return 1
+	}
+	return v.camera.GetZoom()
+}
+
+// Switches the viewport to the unzoomed coordinates the map is drawn in while the target is scaled by the zoom, so
+// the viewport covers the zoomed area of the screen. Returns a function that switches the viewport back.
+func (v *Viewport) enterRenderSpace() func() {
+	screenRect, defaultScreenRect, zoom := v.screenRect, v.defaultScreenRect, v.projectionZoom()
+	v.screenRect = scaleRectangle(screenRect, 1/zoom)
+	v.defaultScreenRect = scaleRectangle(defaultScreenRect, 1/zoom)
+	v.renderSpace = true
+
+	return func() {
+		v.screenRect, v.defaultScreenRect, v.renderSpace = screenRect, defaultScreenRect, false
+	}
+}
+
+// Resizes the screen area the viewport covers, keeping its top left corner and its split screen alignment. The camera
+// stays centered on the same position, as the camera offset is found from the size.
+func (v *Viewport) SetSize(width, height int) {
+	v.defaultScreenRect.Width, v.defaultScreenRect.Height = width, height
+	v.screenRect = v.defaultScreenRect
+	switch v.align {
+	case left:
+		v.screenRect.Width = width / 2
+	case right:
+		v.screenRect.Width = width / 2
+		v.screenRect.Left = v.defaultScreenRect.Left + width/2
+	}
+}
+
+// Returns the size of the screen area the viewport covers
+func (v *Viewport) GetSize() (width, height int) {
+	return v.defaultScreenRect.Width, v.defaultScreenRect.Height
+}
+
+// Returns a copy of the viewport covering a screen of the specified size at the origin, with the same camera and
+// projection settings
+func (v *Viewport) resized(width, height int) *Viewport {
+	result := *v
+	rect := d2common.Rectangle{Width: width, Height: height}
+	result.screenRect, result.defaultScreenRect = rect, rect
+	result.align = center
+	result.transStack, result.transCurrent = nil, worldTrans{}
+	return &result
+}
+
+func scaleRectangle(rect d2common.Rectangle, scale float64) d2common.Rectangle {
+	return d2common.Rectangle{
+		Left:   int(float64(rect.Left) * scale),
+		Top:    int(float64(rect.Top) * scale),
+		Width:  int(float64(rect.Width) * scale),
+		Height: int(float64(rect.Height) * scale),
+	}
 }
 
 func (v *Viewport) GetTranslationOrtho() (float64, float64) {
@@ -140,8 +283,8 @@ func (v *Viewport) getCameraOffset() (float64, float64) {
 		camX, camY = v.camera.GetPosition()
 	}
 
-	camX -= float64(v.screenRect.Width / 2)
-	camY -= float64(v.screenRect.Height / 2)
+	camX -= float64(v.screenRect.Width/2) / v.projectionZoom()
+	camY -= float64(v.screenRect.Height/2) / v.projectionZoom()
 
 	return camX, camY
 }
@@ -0,0 +1,422 @@
+package d2maprenderer
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+)
+
+func TestCullMarginIncludesTilesBeyondScreen(t *testing.T) {
+	assert := testify.New(t)
+	viewport := NewViewport(0, 0, 800, 600)
+	viewport.SetCamera(&Camera{})
+
+	assert.True(viewport.IsTileVisible(7, 0))
+	assert.False(viewport.IsTileVisible(8, 0))
+	assert.False(viewport.IsTileVisible(9, 0))
+
+	viewport.SetCullMargin(1)
+	assert.True(viewport.IsTileVisible(8, 0))
+	assert.False(viewport.IsTileVisible(9, 0))
+
+	viewport.SetCullMargin(2)
+	assert.True(viewport.IsTileVisible(9, 0))
+
+	viewport.SetCullMargin(-1)
+	assert.Equal(0, viewport.GetCullMargin())
+	assert.False(viewport.IsTileVisible(8, 0))
+}
+
+func TestCullMarginAppliesToEntities(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(9, 1)
+	mr.mapEngine.AddEntity(createTestEntity("offscreen", 8, 0))
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Equal(-1, indexOfText(target, "entity:offscreen"))
+
+	mr.SetCullMargin(1)
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+	assert.NotEqual(-1, indexOfText(target, "entity:offscreen"))
+}
+
+func TestTileSizeScalesProjection(t *testing.T) {
+	assert := testify.New(t)
+	viewport := NewViewport(0, 0, 800, 600)
+	viewport.SetCamera(&Camera{})
+
+	width, height := viewport.GetTileSize()
+	assert.Equal(160.0, width)
+	assert.Equal(80.0, height)
+
+	x, y := viewport.WorldToScreen(3, 1)
+	assert.Equal(400+160, x)
+	assert.Equal(300+160, y)
+
+	viewport.SetTileSize(320, 80)
+	x, y = viewport.WorldToScreen(3, 1)
+	assert.Equal(400+320, x)
+	assert.Equal(300+160, y)
+
+	viewport.SetTileSize(128, 96)
+	x, y = viewport.WorldToScreen(3, 1)
+	assert.Equal(400+128, x)
+	assert.Equal(300+192, y)
+
+	viewport.SetTileSize(0, 96)
+	width, height = viewport.GetTileSize()
+	assert.Equal(128.0, width)
+	assert.Equal(96.0, height)
+}
+
+func TestTileSizeRoundTrips(t *testing.T) {
+	assert := testify.New(t)
+	viewport := NewViewport(0, 0, 800, 600)
+	viewport.SetCamera(&Camera{})
+
+	for _, size := range [][2]float64{{160, 80}, {320, 80}, {128, 96}, {64, 64}} {
+		viewport.SetTileSize(size[0], size[1])
+		for _, world := range [][2]float64{{0, 0}, {3, 1}, {-2, 5}, {7, 7}} {
+			x, y := viewport.ScreenToWorld(viewport.WorldToScreen(world[0], world[1]))
+			assert.InDelta(world[0], x, 0.0001, "tile size %v", size)
+			assert.InDelta(world[1], y, 0.0001, "tile size %v", size)
+
+			orthoX, orthoY := viewport.OrthoToWorld(viewport.WorldToOrtho(world[0], world[1]))
+			assert.InDelta(world[0], orthoX, 0.0001)
+			assert.InDelta(world[1], orthoY, 0.0001)
+		}
+	}
+}
+
+func TestTileSizeAppliesToCulling(t *testing.T) {
+	assert := testify.New(t)
+	viewport := NewViewport(0, 0, 800, 600)
+	viewport.SetCamera(&Camera{})
+
+	assert.False(viewport.IsTileVisible(8, 0))
+
+	viewport.SetTileSize(80, 40)
+	assert.True(viewport.IsTileVisible(8, 0))
+	assert.True(viewport.IsTileVisible(12, 0))
+	assert.False(viewport.IsTileVisible(13, 0))
+}
+
+func TestCameraMovesTriggerRegions(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+	var events []string
+	mr.mapEngine.AddTriggerRegion(d2common.Rectangle{Left: 2, Top: 2, Width: 2, Height: 2},
+		func() { events = append(events, "enter") },
+		func() { events = append(events, "leave") },
+	)
+
+	mr.MoveCameraTo(mr.viewport.WorldToOrtho(3, 3))
+	assert.Equal([]string{"enter"}, events)
+
+	mr.MoveCameraBy(mr.viewport.WorldToOrtho(0.5, 0))
+	assert.Equal([]string{"enter"}, events)
+
+	mr.MoveCameraBy(mr.viewport.WorldToOrtho(1, 0))
+	assert.Equal([]string{"enter", "leave"}, events)
+}
+
+func TestPanByScreenMovesCameraWithDrag(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+	mr.MoveCameraTo(mr.viewport.WorldToOrtho(5, 5))
+
+	// The point under the cursor stays under the cursor while dragging
+	startX, startY := mr.ScreenToWorld(300, 200)
+	mr.PanByScreen(160, 0)
+	endX, endY := mr.ScreenToWorld(300+160, 200)
+	assert.InDelta(startX, endX, 0.0001)
+	assert.InDelta(startY, endY, 0.0001)
+
+	// A 160 pixel horizontal drag is one tile width, moving the camera one tile back along each world axis
+	cameraX, cameraY := mr.viewport.OrthoToWorld(mr.camera.GetPosition())
+	assert.InDelta(4.0, cameraX, 0.0001)
+	assert.InDelta(6.0, cameraY, 0.0001)
+}
+
+func TestPanByScreenScalesWithTileSize(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+	mr.SetTileSize(320, 160)
+	mr.MoveCameraTo(mr.viewport.WorldToOrtho(5, 5))
+
+	// At twice the tile size the same drag covers half of the world distance
+	mr.PanByScreen(160, 0)
+	cameraX, cameraY := mr.viewport.OrthoToWorld(mr.camera.GetPosition())
+	assert.InDelta(4.5, cameraX, 0.0001)
+	assert.InDelta(5.5, cameraY, 0.0001)
+
+	mr.PanByScreen(0, -80)
+	cameraX, cameraY = mr.viewport.OrthoToWorld(mr.camera.GetPosition())
+	assert.InDelta(5.0, cameraX, 0.0001)
+	assert.InDelta(6.0, cameraY, 0.0001)
+}
+
+func TestZoomAtKeepsWorldPointUnderCursor(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+	mr.MoveCameraTo(mr.viewport.WorldToOrtho(5, 5))
+
+	for _, delta := range []float64{1, -0.5, -1.5, 0.25} {
+		startX, startY := mr.ScreenToWorld(620, 130)
+		mr.ZoomAt(620, 130, delta)
+		endX, endY := mr.ScreenToWorld(620, 130)
+		assert.InDelta(startX, endX, 0.0001)
+		assert.InDelta(startY, endY, 0.0001)
+	}
+
+	assert.InDelta(math.Pow(2, 1-0.5-1.5+0.25), mr.GetZoom(), 0.0001)
+}
+
+func TestZoomAtScreenCenterKeepsCamera(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+	mr.MoveCameraTo(mr.viewport.WorldToOrtho(5, 5))
+
+	mr.ZoomAt(400, 300, 1)
+	assert.Equal(2.0, mr.GetZoom())
+	cameraX, cameraY := mr.viewport.OrthoToWorld(mr.camera.GetPosition())
+	assert.InDelta(5.0, cameraX, 0.0001)
+	assert.InDelta(5.0, cameraY, 0.0001)
+
+	// A tile is drawn twice as large, so the neighbouring tile corner is twice as far from the center
+	screenX, screenY := mr.viewport.WorldToScreen(6, 5)
+	assert.Equal(400+160, screenX)
+	assert.Equal(300+80, screenY)
+}
+
+func TestZoomIsClamped(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+
+	mr.ZoomAt(400, 300, 10)
+	assert.Equal(float64(maxZoom), mr.GetZoom())
+
+	mr.SetZoom(0.01)
+	assert.Equal(float64(minZoom), mr.GetZoom())
+}
+
+func TestRenderScalesTargetByZoom(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(10, 10)
+	mr.SetZoom(0.5)
+	mr.SetSceneTint(color.RGBA{R: 255, A: 255}, 1)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	// The screen space overlay covers the zoomed out area, which is scaled back down to the screen
+	rects := target.callsOf("rect")
+	assert.NotEmpty(rects)
+	tint := rects[len(rects)-1]
+	assert.Equal(0.5, tint.scale)
+	assert.Equal(1600, tint.width)
+	assert.Equal(1200, tint.height)
+	assert.Equal(0, target.GetDepth())
+
+	// The viewport is restored to screen space after rendering
+	assert.Equal(800, mr.viewport.screenRect.Width)
+	assert.Equal(0.5, mr.GetZoom())
+}
+
+func TestVisibleTileBoundsContainVisibleTiles(t *testing.T) {
+	assert := testify.New(t)
+	for _, zoom := range []float64{0.5, 1, 2} {
+		for _, margin := range []int{0, 2} {
+			viewport := NewViewport(0, 0, 800, 600)
+			camera := &Camera{}
+			camera.MoveTo(viewport.WorldToOrtho(30.5, 20.25))
+			viewport.SetCamera(camera)
+			viewport.SetZoom(zoom)
+			viewport.SetCullMargin(margin)
+
+			minX, minY, maxX, maxY := viewport.GetVisibleTileBounds()
+			for tileY := -40; tileY < 100; tileY++ {
+				for tileX := -40; tileX < 100; tileX++ {
+					if !viewport.IsTileVisible(float64(tileX), float64(tileY)) {
+						continue
+					}
+					inside := tileX >= minX && tileX <= maxX && tileY >= minY && tileY <= maxY
+					assert.True(inside, "zoom %v margin %d: tile %d,%d outside %d,%d-%d,%d",
+						zoom, margin, tileX, tileY, minX, minY, maxX, maxY)
+				}
+			}
+		}
+	}
+}
+
+func TestVisibleTileBoundsCoverScreenOnly(t *testing.T) {
+	assert := testify.New(t)
+	viewport := NewViewport(0, 0, 800, 600)
+	viewport.SetCamera(&Camera{})
+
+	// The corners of an 800x600 screen centered on the origin are 6.25 tiles out along each world axis
+	minX, minY, maxX, maxY := viewport.GetVisibleTileBounds()
+	assert.Equal([4]int{-11, -11, 10, 10}, [4]int{minX, minY, maxX, maxY})
+}
+
+func TestVisibleTileBoundsIncludeWallsBelowScreen(t *testing.T) {
+	assert := testify.New(t)
+	viewport := NewViewport(0, 0, 800, 600)
+	viewport.SetCamera(&Camera{})
+
+	// The floor of tile 5,5 is below the bottom edge of the screen, but the walls standing on it reach into view
+	_, screenY := viewport.WorldToScreen(5, 5)
+	assert.True(screenY > 600)
+	assert.True(viewport.IsTileVisible(5, 5))
+
+	_, _, maxX, maxY := viewport.GetVisibleTileBounds()
+	assert.True(maxX >= 5 && maxY >= 5)
+}
+
+func TestRenderOnlyVisitsVisibleTileBounds(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(200, 200)
+	mr.MoveCameraTo(mr.WorldToOrtho(100, 100))
+
+	minX, minY, maxX, maxY := mr.visibleTileBounds(mr.viewport)
+	assert.True(minX > 0 && minY > 0)
+	assert.True(maxX < 199 && maxY < 199)
+	assert.True((maxX-minX+1)*(maxY-minY+1) < 1000)
+}
+
+func TestCameraZoomDefaultsToNativeSize(t *testing.T) {
+	assert := testify.New(t)
+	camera := &Camera{}
+	assert.Equal(1.0, camera.GetZoom())
+
+	camera.SetZoom(10)
+	assert.Equal(float64(maxZoom), camera.GetZoom())
+	camera.SetZoom(0)
+	assert.Equal(float64(minZoom), camera.GetZoom())
+}
+
+func TestCameraZoomScalesProjection(t *testing.T) {
+	assert := testify.New(t)
+	viewport := NewViewport(0, 0, 800, 600)
+	camera := &Camera{}
+	viewport.SetCamera(camera)
+
+	camera.SetZoom(2)
+	assert.Equal(2.0, viewport.GetZoom())
+
+	// One tile along the world X axis is half a tile across and down, doubled
+	screenX, screenY := viewport.WorldToScreen(1, 0)
+	assert.Equal(400+160, screenX)
+	assert.Equal(300+80, screenY)
+
+	worldX, worldY := viewport.ScreenToWorld(screenX, screenY)
+	assert.InDelta(1, worldX, 0.0001)
+	assert.InDelta(0, worldY, 0.0001)
+}
+
+func TestZoomedOutCullingKeepsOnScreenTiles(t *testing.T) {
+	assert := testify.New(t)
+	viewport := NewViewport(0, 0, 800, 600)
+	camera := &Camera{}
+	viewport.SetCamera(camera)
+	camera.SetZoom(minZoom)
+
+	for tileY := -40; tileY < 40; tileY++ {
+		for tileX := -40; tileX < 40; tileX++ {
+			_, top := viewport.WorldToScreen(float64(tileX), float64(tileY))
+			right, _ := viewport.WorldToScreen(float64(tileX+1), float64(tileY))
+			left, _ := viewport.WorldToScreen(float64(tileX), float64(tileY+1))
+			_, bottom := viewport.WorldToScreen(float64(tileX+1), float64(tileY+1))
+			if right < 0 || left >= 800 || bottom < 0 || top >= 600 {
+				continue
+			}
+			assert.True(viewport.IsTileVisible(float64(tileX), float64(tileY)), "tile %d,%d", tileX, tileY)
+		}
+	}
+}
+
+func TestViewportSizeAppliesToCullingAndPicking(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(20, 20)
+	mr.MoveCameraTo(0, 0)
+	mr.mapEngine.AddEntity(createTestEntity("corner", 12, 0))
+
+	// The tile is beyond the bottom right corner of an 800x600 screen
+	assert.False(mr.viewport.IsTileVisible(12, 0))
+	target := createTestSurface(1920, 1080)
+	mr.Render(target)
+	assert.Equal(-1, indexOfText(target, "entity:corner"))
+
+	mr.SetViewportSize(1920, 1080)
+	width, height := mr.viewport.GetSize()
+	assert.Equal(1920, width)
+	assert.Equal(1080, height)
+	assert.True(mr.viewport.IsTileVisible(12, 0))
+
+	worldX, worldY := mr.ScreenToWorld(1920, 1080)
+	assert.InDelta(12.75, worldX, 0.001)
+	assert.InDelta(0.75, worldY, 0.001)
+
+	// The camera stays on the center of the larger screen
+	centerX, centerY := mr.ScreenToWorld(960, 540)
+	assert.InDelta(0, centerX, 0.001)
+	assert.InDelta(0, centerY, 0.001)
+
+	target = createTestSurface(1920, 1080)
+	mr.Render(target)
+	assert.NotEqual(-1, indexOfText(target, "entity:corner"))
+}
+
+func TestViewportSizeKeepsSplitScreenAlignment(t *testing.T) {
+	assert := testify.New(t)
+	viewport := NewViewport(0, 0, 800, 600)
+	viewport.toRight()
+
+	viewport.SetSize(1920, 1080)
+	assert.Equal(d2common.Rectangle{Left: 960, Width: 960, Height: 1080}, viewport.screenRect)
+
+	viewport.resetAlign()
+	assert.Equal(d2common.Rectangle{Width: 1920, Height: 1080}, viewport.screenRect)
+}
+
+func TestViewportSizeIgnoresNonPositiveSizes(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	mr.SetViewportSize(0, 1080)
+	mr.SetViewportSize(1920, -1)
+
+	width, height := mr.viewport.GetSize()
+	assert.Equal(800, width)
+	assert.Equal(600, height)
+}
+
+func TestTileArtFollowsTileSize(t *testing.T) {
+	assert := testify.New(t)
+	defer InvalidateImageCache()
+
+	mr := createTestMapRenderer(1, 1)
+	mr.SetTileSize(96, 48)
+	mr.setImageCacheRecord(1, 1, 0, 0, createTestSurface(96, 48))
+	mr.mapEngine.TileAt(0, 0).Floors = []d2ds1.FloorShadowRecord{{Style: 1, Sequence: 1, Prop1: 1}}
+
+	// The floor image is centered on the top corner of the tile's diamond
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	tileX, tileY := mr.viewport.WorldToScreen(0, 0)
+	renders := target.callsOf("render")
+	assert.Len(renders, 1)
+	assert.Equal([]int{tileX - 48, tileY}, []int{renders[0].x, renders[0].y})
+
+	// The sub tiles divide the tile's diamond
+	isoX, isoY := mr.viewport.subTileIsoOffset(5, 0)
+	assert.Equal([]int{48, 24}, []int{isoX, isoY})
+	width, height := mr.viewport.subTileDiamondSize()
+	assert.Equal([]int{19, 10}, []int{width, height})
+}
@@ -0,0 +1,66 @@
+package d2maprenderer
+
+import (
+	"image"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dt1"
+)
+
+const (
+	wallOrthoOffsetX = -80 // Walls are drawn from the left corner of their tile
+	wallOrthoOffsetY = -8  // Wall images are drawn this far above the offset found from their blocks
+)
+
+// Returns the ortho offset of a wall's image from the top corner of its tile (the wall's XAdjust and YAdjust, before
+// the wall ortho offset), given the bounds of the DT1 blocks the image is decoded from. The image is cropped to the
+// blocks, so each orientation group is placed where its art is:
+//   - upper walls (left, right, end and corner walls, doors, pillars and trees) stand on the tile, with their blocks
+//     placed relative to its bottom corner. Left walls only have blocks over the left half of the tile, right walls
+//     over the right half and corners around the corner they turn, so each starts where its blocks start.
+//   - lower walls (orientations 16-19) hang beneath the bottom corner of the tile, under the upper wall they continue,
+//     wherever their DT1 file stores their blocks.
+//   - roofs are raised by the roof height of their tile.
+func wallOrthoOffset(wallType d2enum.TileType, tileData *d2dt1.Tile, bounds image.Rectangle) (x, y int) {
+	switch {
+	case wallType == d2enum.Roof:
+		return bounds.Min.X, -int(tileData.RoofHeight)
+	case wallType.LowerWall():
+		return bounds.Min.X, defaultTileHeight
+	default:
+		return bounds.Min.X, bounds.Min.Y + defaultTileHeight
+	}
+}
+
+// Returns the bounds of the blocks a wall image is decoded from: the horizontal span of every block, and the vertical
+// span of the blocks of the tile that sets the height, from the bottom corner of the tile at least
+func wallBlockBounds(target *d2dt1.Tile, tiles ...*d2dt1.Tile) image.Rectangle {
+	bounds := image.Rect(0, 0, 0, 0)
+	for _, block := range target.Blocks {
+		if int(block.Y) < bounds.Min.Y {
+			bounds.Min.Y = int(block.Y)
+		}
+		if int(block.Y)+32 > bounds.Max.Y {
+			bounds.Max.Y = int(block.Y) + 32
+		}
+	}
+
+	bounds.Min.X, bounds.Max.X = defaultTileWidth, 0
+	for _, tile := range tiles {
+		if tile == nil {
+			continue
+		}
+		for _, block := range tile.Blocks {
+			if int(block.X) < bounds.Min.X {
+				bounds.Min.X = int(block.X)
+			}
+			if int(block.X)+32 > bounds.Max.X {
+				bounds.Max.X = int(block.X) + 32
+			}
+		}
+	}
+	if bounds.Min.X >= bounds.Max.X {
+		bounds.Min.X, bounds.Max.X = 0, defaultTileWidth
+	}
+	return bounds
+}
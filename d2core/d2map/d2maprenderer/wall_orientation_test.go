@@ -0,0 +1,91 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2enum"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2ds1"
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2fileformats/d2dt1"
+)
+
+// Returns the blocks of a wall tile, with one row of 32 pixel tall blocks from fromX to toX at each of the given block
+// positions.
+func wallBlockRows(fromX, toX int16, rows ...int16) []d2dt1.Block {
+	var blocks []d2dt1.Block
+	for _, y := range rows {
+		for x := fromX; x < toX; x += 32 {
+			blocks = append(blocks, d2dt1.Block{X: x, Y: y, GridX: byte(x / 32), GridY: byte((y + 128) / 32)})
+		}
+	}
+	return blocks
+}
+
+func TestWallsRenderAtOrientationOffset(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestTileSurfaces()()
+
+	// One wall of each orientation, all on the tile at the center of the screen (top corner at 400,300, bottom
+	// corner at 400,380), with the block layout of the DT1 art for that orientation. Left walls have blocks over the
+	// left half of the tile and right walls over the right half, and the first lower wall stores its blocks above
+	// the tile like the upper wall it continues.
+	walls := []struct {
+		wallType d2enum.TileType
+		tile     d2dt1.Tile
+		x, y     int
+	}{
+		{d2enum.LeftWall, d2dt1.Tile{Height: -96, Blocks: wallBlockRows(0, 96, -96, -64, -32)}, 320, 276},
+		{d2enum.RightWall, d2dt1.Tile{Height: -128, Blocks: wallBlockRows(64, 160, -128, -96, -64, -32)}, 384, 244},
+		{d2enum.LeftPartOfNorthCornerWall, d2dt1.Tile{Height: -96, Blocks: wallBlockRows(32, 128, -96, -64, -32)},
+			352, 276},
+		{d2enum.SouthCornerWall, d2dt1.Tile{Height: -64, Blocks: wallBlockRows(0, 160, -64, -32)}, 320, 308},
+		{d2enum.LeftWallWithDoor, d2dt1.Tile{Height: -128, Blocks: wallBlockRows(0, 96, -128, -96)}, 320, 244},
+		{d2enum.Roof, d2dt1.Tile{Height: 32, RoofHeight: 104, Blocks: wallBlockRows(0, 160, 0)}, 320, 188},
+		{d2enum.LowerWallsEquivalentToLeftWall, d2dt1.Tile{Height: -96, Blocks: wallBlockRows(0, 96, -96, -64, -32)},
+			320, 372},
+		{d2enum.LowerWallsEquivalentToRightWall, d2dt1.Tile{Height: 96, Blocks: wallBlockRows(64, 160, 0, 32, 64)},
+			384, 372},
+		{d2enum.LowerWallsEquivalentToSouthCornerwall, d2dt1.Tile{Height: 64, Blocks: wallBlockRows(0, 160, 0, 32)},
+			320, 372},
+	}
+
+	mr := createTestMapRenderer(1, 1)
+	tile := mr.mapEngine.TileAt(0, 0)
+	images := make([]*testSurface, len(walls))
+	for i, wall := range walls {
+		record := d2ds1.WallRecord{Type: wall.wallType, Style: byte(i), Sequence: 1, Prop1: 1}
+		mr.generateWallImage(&record, &walls[i].tile, nil, 0)
+		images[i], _ = mr.getImageCacheRecord(record.Style, record.Sequence, record.Type, 0).(*testSurface)
+		if !assert.NotNil(images[i], "wall type %d", wall.wallType) {
+			return
+		}
+		tile.Walls = append(tile.Walls, record)
+	}
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	positions := make([][2]int, len(walls))
+	for i, wall := range walls {
+		index := indexOfRender(target, images[i])
+		if !assert.NotEqual(-1, index, "wall type %d", wall.wallType) {
+			continue
+		}
+		call := target.calls[index]
+		positions[i] = [2]int{call.x, call.y}
+		assert.Equal([2]int{wall.x, wall.y}, positions[i], "wall type %d", wall.wallType)
+	}
+
+	// Images are cropped to their blocks
+	assert.Equal(96, images[0].width)
+	assert.Equal(160, images[3].width)
+
+	// Left and right walls start over their own half of the tile, and the same blocks are placed differently for an
+	// upper and a lower wall
+	assert.NotEqual(positions[0][0], positions[1][0])
+	assert.NotEqual(positions[0][1], positions[6][1])
+
+	// A lower wall continues the upper wall above it without a gap or an overlap
+	assert.Equal(positions[0][1]+images[0].height, positions[6][1])
+}
@@ -0,0 +1,49 @@
+package d2maprenderer
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2data/d2datadict"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+var (
+	warpDebugColor      = color.RGBA{R: 64, G: 224, B: 255, A: 255} // The color of the lines from the start to each warp
+	warpDebugBackground = color.RGBA{A: 192}                        // The color drawn behind the destination labels
+)
+
+// Sets whether each warp on the map is drawn with a line from the start position of the map and a label naming the
+// level it leads to, to show how the level's exits connect to other levels
+func (mr *MapRenderer) SetWarpDebug(enabled bool) {
+	mr.warpDebug = enabled
+}
+
+// Returns the label of a warp leading to the level, named from Levels.txt when it has been loaded
+func warpDestinationLabel(level int) string {
+	if record, found := d2datadict.LevelDetails[level]; found && record.LevelDisplayName != "" {
+		return fmt.Sprintf("-> %s", record.LevelDisplayName)
+	}
+	return fmt.Sprintf("-> level %d", level)
+}
+
+func (mr *MapRenderer) renderWarpDebug(target d2render.Surface) {
+	startX, startY := mr.mapEngine.GetStartPosition()
+	screenStartX, screenStartY := mr.viewport.WorldToScreen(startX, startY)
+
+	for _, warp := range mr.mapEngine.Warps() {
+		warpX, warpY := float64(warp.TileX)+0.5, float64(warp.TileY)+0.5
+		screenX, screenY := mr.viewport.WorldToScreen(warpX, warpY)
+
+		target.PushTranslation(screenStartX, screenStartY)
+		target.DrawLine(screenX-screenStartX, screenY-screenStartY, warpDebugColor)
+		target.Pop()
+
+		mr.renderWorldTextLabel(worldTextLabel{
+			worldX:  warpX,
+			worldY:  warpY,
+			text:    warpDestinationLabel(warp.DestinationLevel),
+			options: WorldTextOptions{Anchor: WorldTextAnchorCenter, Background: warpDebugBackground},
+		}, target)
+	}
+}
@@ -0,0 +1,51 @@
+package d2maprenderer
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2common/d2data/d2datadict"
+)
+
+// Replaces the level records, returning a function that restores them
+func useTestLevelDetails(levels map[int]*d2datadict.LevelDetailsRecord) func() {
+	previous := d2datadict.LevelDetails
+	d2datadict.LevelDetails = levels
+	return func() { d2datadict.LevelDetails = previous }
+}
+
+func TestWarpDebugLabelsDestinations(t *testing.T) {
+	assert := testify.New(t)
+	defer useTestLevelDetails(map[int]*d2datadict.LevelDetailsRecord{2: {LevelDisplayName: "Blood Moor"}})()
+	mr := createTestMapRenderer(4, 4)
+	mr.mapEngine.AddWarp(1, 0, 2)
+	mr.mapEngine.AddWarp(3, 2, 8)
+	mr.SetWarpDebug(true)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	assert.True(indexOfText(target, "-> Blood Moor") >= 0)
+	assert.True(indexOfText(target, "-> level 8") >= 0)
+
+	// A line is drawn from the center of the map (which has no start marker) to the center of each warp tile
+	lines := target.callsOf("line")
+	assert.Len(lines, 2)
+	startX, startY := mr.viewport.WorldToScreen(2, 2)
+	warpX, warpY := mr.viewport.WorldToScreen(3.5, 2.5)
+	assert.Equal(warpX-startX, lines[1].width)
+	assert.Equal(warpY-startY, lines[1].height)
+}
+
+func TestWarpDebugDisabledByDefault(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(4, 4)
+	mr.mapEngine.AddWarp(1, 0, 2)
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	assert.Equal(-1, indexOfText(target, "-> level 2"))
+	assert.Empty(target.callsOf("line"))
+}
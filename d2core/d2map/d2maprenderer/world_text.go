@@ -0,0 +1,83 @@
+package d2maprenderer
+
+import (
+	"image/color"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+const (
+	worldTextCharWidth  = 6  // The width of a character drawn by DrawText, in pixels
+	worldTextLineHeight = 16 // The height of a line drawn by DrawText, in pixels
+	worldTextPadding    = 2  // The padding around the text when drawn over a background, in pixels
+)
+
+// WorldTextAnchor determines which point of a world text label is placed at its world position
+type WorldTextAnchor int
+
+const (
+	WorldTextAnchorTopLeft      WorldTextAnchor = iota // The top left corner of the label is at the world position
+	WorldTextAnchorCenter                              // The center of the label is at the world position
+	WorldTextAnchorBottomCenter                        // The bottom center of the label is at the world position (eg: markers above a unit)
+)
+
+// WorldTextOptions are the optional settings of a world text label
+type WorldTextOptions struct {
+	Anchor     WorldTextAnchor
+	Background color.Color // The color drawn behind the text, or nil for no background
+}
+
+type worldTextLabel struct {
+	worldX, worldY float64
+	text           string
+	options        WorldTextOptions
+}
+
+// Queues a text label at the specified world position to be drawn over the map on the next Render
+func (mr *MapRenderer) DrawWorldText(worldX, worldY float64, text string) {
+	mr.DrawWorldTextWithOptions(worldX, worldY, text, WorldTextOptions{})
+}
+
+// Queues a text label at the specified world position, with an anchor and background, to be drawn on the next Render
+func (mr *MapRenderer) DrawWorldTextWithOptions(worldX, worldY float64, text string, options WorldTextOptions) {
+	mr.worldText = append(mr.worldText, worldTextLabel{worldX: worldX, worldY: worldY, text: text, options: options})
+}
+
+// Returns the screen position of the top left corner of the label text
+func (mr *MapRenderer) worldTextScreenPosition(label worldTextLabel) (int, int) {
+	screenX, screenY := mr.viewport.WorldToScreen(label.worldX, label.worldY)
+	width := len(label.text) * worldTextCharWidth
+
+	switch label.options.Anchor {
+	case WorldTextAnchorCenter:
+		return screenX - width/2, screenY - worldTextLineHeight/2
+	case WorldTextAnchorBottomCenter:
+		return screenX - width/2, screenY - worldTextLineHeight
+	default:
+		return screenX, screenY
+	}
+}
+
+// Draws and clears the queued world text labels
+func (mr *MapRenderer) renderWorldText(target d2render.Surface) {
+	for _, label := range mr.worldText {
+		mr.renderWorldTextLabel(label, target)
+	}
+
+	mr.worldText = mr.worldText[:0]
+}
+
+func (mr *MapRenderer) renderWorldTextLabel(label worldTextLabel, target d2render.Surface) {
+	x, y := mr.worldTextScreenPosition(label)
+	target.PushTranslation(x, y)
+	defer target.Pop()
+
+	if label.options.Background != nil {
+		target.PushTranslation(-worldTextPadding, -worldTextPadding)
+		target.DrawRect(len(label.text)*worldTextCharWidth+worldTextPadding*2, worldTextLineHeight+worldTextPadding*2,
+			label.options.Background)
+		target.Pop()
+	}
+
+	target.DrawText("%s", label.text)
+}
@@ -0,0 +1,63 @@
+package d2maprenderer
+
+import (
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestWorldTextFollowsCamera(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	mr.DrawWorldText(2, 1, "quest")
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	label := target.calls[indexOfText(target, "quest")]
+	assert.Equal(480, label.x)
+	assert.Equal(420, label.y)
+
+	mr.MoveCameraBy(100, 50)
+	mr.DrawWorldText(2, 1, "quest")
+	target = createTestSurface(800, 600)
+	mr.Render(target)
+
+	label = target.calls[indexOfText(target, "quest")]
+	assert.Equal(380, label.x)
+	assert.Equal(370, label.y)
+	assert.Equal(0, target.GetDepth())
+}
+
+func TestWorldTextIsClearedAfterRender(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+
+	mr.DrawWorldText(0, 0, "once")
+	mr.Render(createTestSurface(800, 600))
+
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+	assert.Equal(-1, indexOfText(target, "once"))
+}
+
+func TestWorldTextAnchorAndBackground(t *testing.T) {
+	assert := testify.New(t)
+	mr := createTestMapRenderer(1, 1)
+	background := color.RGBA{A: 128}
+
+	mr.DrawWorldTextWithOptions(0, 0, "abcd", WorldTextOptions{Anchor: WorldTextAnchorBottomCenter, Background: background})
+	target := createTestSurface(800, 600)
+	mr.Render(target)
+
+	label := target.calls[indexOfText(target, "abcd")]
+	assert.Equal(400-2*worldTextCharWidth, label.x)
+	assert.Equal(300-worldTextLineHeight, label.y)
+
+	rects := target.callsOf("rect")
+	assert.Len(rects, 1)
+	assert.Equal(background, rects[0].color)
+	assert.Equal(label.x-worldTextPadding, rects[0].x)
+	assert.Equal(4*worldTextCharWidth+worldTextPadding*2, rects[0].width)
+}
@@ -19,6 +19,7 @@ import (
 // Represents a pre-fabricated map stamp that can be placed on a map
 type Stamp struct {
 	regionPath  string                       // The file path of the region
+	regionType  d2enum.RegionIdType          // The region type of this stamp
 	levelType   d2datadict.LevelTypeRecord   // The level type id for this stamp
 	levelPreset d2datadict.LevelPresetRecord // The level preset id for this stamp
 	tiles       []d2dt1.Tile                 // The tiles contained on this stamp
@@ -28,6 +29,7 @@ type Stamp struct {
 // Loads a stamp based on the supplied parameters
 func LoadStamp(seed int64, levelType d2enum.RegionIdType, levelPreset int, fileIndex int) *Stamp {
 	stamp := &Stamp{
+		regionType:  levelType,
 		levelType:   d2datadict.LevelTypes[levelType],
 		levelPreset: d2datadict.LevelPresets[levelPreset],
 	}
@@ -93,6 +95,11 @@ func (mr *Stamp) LevelPreset() d2datadict.LevelPresetRecord {
 	return mr.levelPreset
 }
 
+// Returns the region type of the stamp
+func (mr *Stamp) RegionType() d2enum.RegionIdType {
+	return mr.regionType
+}
+
 // Returns the level type id
 func (mr *Stamp) LevelType() d2datadict.LevelTypeRecord {
 	return mr.levelType
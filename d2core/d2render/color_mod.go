@@ -0,0 +1,24 @@
+package d2render
+
+import "image/color"
+
+// ComposeColor returns the color images are modulated with after a color is pushed over the current one: the product
+// of the two premultiplied colors, so pushing a color never undoes the colors pushed before it (eg: an animation's
+// color mod keeps the fade of the corpse it belongs to). A nil color leaves the other color unchanged.
+func ComposeColor(current, pushed color.Color) color.Color {
+	if current == nil {
+		return pushed
+	}
+	if pushed == nil {
+		return current
+	}
+
+	r1, g1, b1, a1 := current.RGBA()
+	r2, g2, b2, a2 := pushed.RGBA()
+	return color.RGBA64{
+		R: uint16(r1 * r2 / 0xffff),
+		G: uint16(g1 * g2 / 0xffff),
+		B: uint16(b1 * b2 / 0xffff),
+		A: uint16(a1 * a2 / 0xffff),
+	}
+}
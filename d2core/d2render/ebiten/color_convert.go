@@ -73,3 +73,16 @@ func ColorToColorM(clr color.Color) ebiten.ColorM {
 
 	return e.m
 }
+
+// SilhouetteColorM returns a color matrix that replaces the colors of an image with a solid color, keeping its alpha
+func SilhouetteColorM(clr color.Color) ebiten.ColorM {
+	cr, cg, cb, ca := clr.RGBA()
+	cm := ebiten.ColorM{}
+	if ca == 0 {
+		return emptyColorM
+	}
+
+	cm.Scale(0, 0, 0, float64(ca)/0xffff)
+	cm.Translate(float64(cr)/float64(ca), float64(cg)/float64(ca), float64(cb)/float64(ca), 0)
+	return cm
+}
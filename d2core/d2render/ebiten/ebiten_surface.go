@@ -1,6 +1,7 @@
 package ebiten
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
@@ -15,12 +16,28 @@ type ebitenSurface struct {
 	stateStack   []surfaceState
 	stateCurrent surfaceState
 	image        *ebiten.Image
+	layers       []*ebiten.Image // The images drawn into while masks are pushed, by the number of masks
+}
+
+// Returns the image drawn into: the layer of the last mask pushed, or the image itself
+func (s *ebitenSurface) target() *ebiten.Image {
+	if s.stateCurrent.layer == 0 {
+		return s.image
+	}
+	return s.layers[s.stateCurrent.layer-1]
 }
 
 func (s *ebitenSurface) PushTranslation(x, y int) {
 	s.stateStack = append(s.stateStack, s.stateCurrent)
-	s.stateCurrent.x += x
-	s.stateCurrent.y += y
+	scale := s.stateCurrent.getScale()
+	s.stateCurrent.x += int(float64(x) * scale)
+	s.stateCurrent.y += int(float64(y) * scale)
+}
+
+// PushScale scales everything drawn afterwards around the current translation
+func (s *ebitenSurface) PushScale(scale float64) {
+	s.stateStack = append(s.stateStack, s.stateCurrent)
+	s.stateCurrent.scale = s.stateCurrent.getScale() * scale
 }
 
 func (s *ebitenSurface) PushCompositeMode(mode d2render.CompositeMode) {
@@ -33,9 +50,35 @@ func (s *ebitenSurface) PushFilter(filter d2render.Filter) {
 	s.stateCurrent.filter = d2ToEbitenFilter(filter)
 }
 
+func (s *ebitenSurface) PushMask(mask d2render.Surface) error {
+	maskSurface, ok := mask.(*ebitenSurface)
+	if !ok {
+		return errors.New("ebiten surfaces can only be masked by other ebiten surfaces")
+	}
+
+	if s.stateCurrent.layer == len(s.layers) {
+		width, height := s.image.Size()
+		layer, err := ebiten.NewImage(width, height, ebiten.FilterDefault)
+		if err != nil {
+			return err
+		}
+		s.layers = append(s.layers, layer)
+	}
+
+	s.stateStack = append(s.stateStack, s.stateCurrent)
+	s.stateCurrent.mask = maskSurface.image
+	s.stateCurrent.layer++
+	return s.target().Clear()
+}
+
 func (s *ebitenSurface) PushColor(color color.Color) {
 	s.stateStack = append(s.stateStack, s.stateCurrent)
-	s.stateCurrent.color = color
+	s.stateCurrent.color = d2render.ComposeColor(s.stateCurrent.color, color)
+}
+
+func (s *ebitenSurface) PushSilhouette(color color.Color) {
+	s.stateStack = append(s.stateStack, s.stateCurrent)
+	s.stateCurrent.silhouette = color
 }
 
 func (s *ebitenSurface) Pop() {
@@ -44,8 +87,20 @@ func (s *ebitenSurface) Pop() {
 		panic("empty stack")
 	}
 
+	popped := s.stateCurrent
 	s.stateCurrent = s.stateStack[count-1]
 	s.stateStack = s.stateStack[:count-1]
+	if popped.layer != s.stateCurrent.layer {
+		s.drawLayer(popped)
+	}
+}
+
+// Draws the layer of a popped mask through the mask, onto the image below it. The mask is drawn source atop so it
+// only covers the opaque pixels of the layer.
+func (s *ebitenSurface) drawLayer(state surfaceState) {
+	layer := s.layers[state.layer-1]
+	_ = layer.DrawImage(state.mask, &ebiten.DrawImageOptions{CompositeMode: ebiten.CompositeModeSourceAtop})
+	_ = s.target().DrawImage(layer, &ebiten.DrawImageOptions{})
 }
 
 func (s *ebitenSurface) PopN(n int) {
@@ -56,38 +111,44 @@ func (s *ebitenSurface) PopN(n int) {
 
 func (s *ebitenSurface) Render(sfc d2render.Surface) error {
 	opts := &ebiten.DrawImageOptions{CompositeMode: s.stateCurrent.mode}
+	if scale := s.stateCurrent.getScale(); scale != 1 {
+		opts.GeoM.Scale(scale, scale)
+	}
 	opts.GeoM.Translate(float64(s.stateCurrent.x), float64(s.stateCurrent.y))
 	opts.Filter = s.stateCurrent.filter
+	if s.stateCurrent.silhouette != nil {
+		opts.ColorM = SilhouetteColorM(s.stateCurrent.silhouette)
+	}
 	if s.stateCurrent.color != nil {
-		opts.ColorM = ColorToColorM(s.stateCurrent.color)
+		opts.ColorM.Concat(ColorToColorM(s.stateCurrent.color))
 	}
 
 	var img = sfc.(*ebitenSurface).image
-	return s.image.DrawImage(img, opts)
+	return s.target().DrawImage(img, opts)
 }
 
 func (s *ebitenSurface) DrawText(format string, params ...interface{}) {
-	ebitenutil.DebugPrintAt(s.image, fmt.Sprintf(format, params...), s.stateCurrent.x, s.stateCurrent.y)
+	ebitenutil.DebugPrintAt(s.target(), fmt.Sprintf(format, params...), s.stateCurrent.x, s.stateCurrent.y)
 }
 
 func (s *ebitenSurface) DrawLine(x, y int, color color.Color) {
 	ebitenutil.DrawLine(
-		s.image,
+		s.target(),
 		float64(s.stateCurrent.x),
 		float64(s.stateCurrent.y),
-		float64(s.stateCurrent.x+x),
-		float64(s.stateCurrent.y+y),
+		float64(s.stateCurrent.x)+float64(x)*s.stateCurrent.getScale(),
+		float64(s.stateCurrent.y)+float64(y)*s.stateCurrent.getScale(),
 		color,
 	)
 }
 
 func (s *ebitenSurface) DrawRect(width, height int, color color.Color) {
 	ebitenutil.DrawRect(
-		s.image,
+		s.target(),
 		float64(s.stateCurrent.x),
 		float64(s.stateCurrent.y),
-		float64(width),
-		float64(height),
+		float64(width)*s.stateCurrent.getScale(),
+		float64(height)*s.stateCurrent.getScale(),
 		color,
 	)
 }
@@ -104,6 +165,14 @@ func (s *ebitenSurface) GetDepth() int {
 	return len(s.stateStack)
 }
 
+func (s *ebitenSurface) Dispose() error {
+	for _, layer := range s.layers {
+		_ = layer.Dispose()
+	}
+	s.layers = nil
+	return s.image.Dispose()
+}
+
 func (s *ebitenSurface) ReplacePixels(pixels []byte) error {
 	return s.image.ReplacePixels(pixels)
 }
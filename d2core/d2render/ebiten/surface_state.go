@@ -7,9 +7,20 @@ import (
 )
 
 type surfaceState struct {
-	x      int
-	y      int
-	mode   ebiten.CompositeMode
-	filter ebiten.Filter
-	color  color.Color
+	x          int
+	y          int
+	mode       ebiten.CompositeMode
+	filter     ebiten.Filter
+	color      color.Color   // The product of the pushed colors (nil=unmodulated)
+	silhouette color.Color   // The solid color images are drawn in (nil=their own colors)
+	scale      float64       // 0 is treated as unscaled
+	mask       *ebiten.Image // The mask the layer is drawn through when popped
+	layer      int           // The number of masks pushed, drawn into layers[layer-1] (0=drawn directly to the image)
+}
+
+func (s surfaceState) getScale() float64 {
+	if s.scale == 0 {
+		return 1
+	}
+	return s.scale
 }
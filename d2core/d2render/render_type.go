@@ -4,5 +4,6 @@ package d2render
 type RenderType int
 
 const (
-	Ebiten = RenderType(1)
+	Ebiten   = RenderType(1)
+	Software = RenderType(2)
 )
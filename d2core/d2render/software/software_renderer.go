@@ -0,0 +1,75 @@
+package software
+
+import (
+	"errors"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+var errForeignSurface = errors.New("surface was not created by the software renderer")
+
+// Renderer is a headless rendering system that draws into in-memory images (eg: for tests and tooling)
+type Renderer struct {
+	MaxFrames int // The number of frames Run renders before returning (0=until the callback fails)
+
+	vsync bool
+}
+
+func CreateRenderer() (*Renderer, error) {
+	return &Renderer{}, nil
+}
+
+func (*Renderer) GetRendererName() string {
+	return "Software"
+}
+
+func (*Renderer) SetWindowIcon(fileName string) {}
+
+func (r *Renderer) IsDrawingSkipped() bool {
+	return false
+}
+
+// Run renders frames onto an offscreen surface of the specified size, without opening a window
+func (r *Renderer) Run(f func(surface d2render.Surface) error, width, height int, title string) error {
+	screen := newSoftwareSurface(width, height)
+	for frame := 0; r.MaxFrames == 0 || frame < r.MaxFrames; frame++ {
+		if err := f(screen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Renderer) CreateSurface(surface d2render.Surface) (d2render.Surface, error) {
+	source, ok := surface.(*softwareSurface)
+	if !ok {
+		return nil, errForeignSurface
+	}
+	return &softwareSurface{image: source.image}, nil
+}
+
+func (r *Renderer) NewSurface(width, height int, filter d2render.Filter) (d2render.Surface, error) {
+	return newSoftwareSurface(width, height), nil
+}
+
+func (r *Renderer) IsFullScreen() bool {
+	return false
+}
+
+func (r *Renderer) SetFullScreen(fullScreen bool) {}
+
+func (r *Renderer) SetVSyncEnabled(vsync bool) {
+	r.vsync = vsync
+}
+
+func (r *Renderer) GetVSyncEnabled() bool {
+	return r.vsync
+}
+
+func (r *Renderer) GetCursorPos() (int, int) {
+	return 0, 0
+}
+
+func (r *Renderer) CurrentFPS() float64 {
+	return 0
+}
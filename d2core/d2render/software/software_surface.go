@@ -0,0 +1,290 @@
+package software
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+type surfaceState struct {
+	x          int
+	y          int
+	mode       d2render.CompositeMode
+	color      color.Color // The product of the pushed colors (nil=unmodulated)
+	silhouette color.Color // The solid color images are drawn in (nil=their own colors)
+	scale      float64     // 0 is treated as unscaled
+	mask       *image.RGBA // The mask the layer is drawn through when popped
+	layer      int         // The number of masks pushed, drawn into layers[layer-1] (0=drawn directly to the image)
+}
+
+func (s surfaceState) getScale() float64 {
+	if s.scale == 0 {
+		return 1
+	}
+	return s.scale
+}
+
+// softwareSurface is a surface backed by a premultiplied RGBA image. Images are scaled with nearest neighbour
+// sampling regardless of the filter, and text is not rasterized.
+type softwareSurface struct {
+	stateStack   []surfaceState
+	stateCurrent surfaceState
+	image        *image.RGBA
+	layers       []*image.RGBA // The images drawn into while masks are pushed, by the number of masks
+}
+
+func newSoftwareSurface(width, height int) *softwareSurface {
+	return &softwareSurface{image: image.NewRGBA(image.Rect(0, 0, width, height))}
+}
+
+// Returns the image drawn into: the layer of the last mask pushed, or the image itself
+func (s *softwareSurface) target() *image.RGBA {
+	if s.stateCurrent.layer == 0 {
+		return s.image
+	}
+	return s.layers[s.stateCurrent.layer-1]
+}
+
+func (s *softwareSurface) PushTranslation(x, y int) {
+	s.stateStack = append(s.stateStack, s.stateCurrent)
+	scale := s.stateCurrent.getScale()
+	s.stateCurrent.x += int(float64(x) * scale)
+	s.stateCurrent.y += int(float64(y) * scale)
+}
+
+// PushScale scales everything drawn afterwards around the current translation
+func (s *softwareSurface) PushScale(scale float64) {
+	s.stateStack = append(s.stateStack, s.stateCurrent)
+	s.stateCurrent.scale = s.stateCurrent.getScale() * scale
+}
+
+func (s *softwareSurface) PushCompositeMode(mode d2render.CompositeMode) {
+	s.stateStack = append(s.stateStack, s.stateCurrent)
+	s.stateCurrent.mode = mode
+}
+
+func (s *softwareSurface) PushFilter(filter d2render.Filter) {
+	s.stateStack = append(s.stateStack, s.stateCurrent)
+}
+
+func (s *softwareSurface) PushMask(mask d2render.Surface) error {
+	maskSurface, ok := mask.(*softwareSurface)
+	if !ok {
+		return errors.New("software surfaces can only be masked by other software surfaces")
+	}
+
+	if s.stateCurrent.layer == len(s.layers) {
+		s.layers = append(s.layers, image.NewRGBA(s.image.Rect))
+	}
+
+	s.stateStack = append(s.stateStack, s.stateCurrent)
+	s.stateCurrent.mask = maskSurface.image
+	s.stateCurrent.layer++
+	layer := s.target()
+	for i := range layer.Pix {
+		layer.Pix[i] = 0
+	}
+	return nil
+}
+
+func (s *softwareSurface) PushColor(color color.Color) {
+	s.stateStack = append(s.stateStack, s.stateCurrent)
+	s.stateCurrent.color = d2render.ComposeColor(s.stateCurrent.color, color)
+}
+
+func (s *softwareSurface) PushSilhouette(color color.Color) {
+	s.stateStack = append(s.stateStack, s.stateCurrent)
+	s.stateCurrent.silhouette = color
+}
+
+func (s *softwareSurface) Pop() {
+	count := len(s.stateStack)
+	if count == 0 {
+		panic("empty stack")
+	}
+
+	popped := s.stateCurrent
+	s.stateCurrent = s.stateStack[count-1]
+	s.stateStack = s.stateStack[:count-1]
+	if popped.layer != s.stateCurrent.layer {
+		s.drawLayer(popped)
+	}
+}
+
+// Draws the layer of a popped mask through the mask, onto the image below it. The mask is drawn source atop so it
+// only covers the opaque pixels of the layer.
+func (s *softwareSurface) drawLayer(state surfaceState) {
+	layer := s.layers[state.layer-1]
+	for y := layer.Rect.Min.Y; y < layer.Rect.Max.Y; y++ {
+		for x := layer.Rect.Min.X; x < layer.Rect.Max.X; x++ {
+			offset := layer.PixOffset(x, y)
+			alpha := uint32(layer.Pix[offset+3])
+			if alpha == 0 {
+				continue
+			}
+
+			var pixel [4]uint32
+			for i := 0; i < 4; i++ {
+				pixel[i] = uint32(layer.Pix[offset+i])
+			}
+			if (image.Point{X: x, Y: y}).In(state.mask.Rect) {
+				maskOffset := state.mask.PixOffset(x, y)
+				maskAlpha := uint32(state.mask.Pix[maskOffset+3])
+				for i := 0; i < 3; i++ {
+					pixel[i] = uint32(state.mask.Pix[maskOffset+i])*alpha/0xff + pixel[i]*(0xff-maskAlpha)/0xff
+				}
+			}
+			s.blend(x, y, pixel, d2render.CompositeModeSourceOver)
+		}
+	}
+}
+
+func (s *softwareSurface) PopN(n int) {
+	for i := 0; i < n; i++ {
+		s.Pop()
+	}
+}
+
+// Render draws the surface at the current translation, scaled, drawn as the current silhouette, and modulated by the
+// current color
+func (s *softwareSurface) Render(sfc d2render.Surface) error {
+	source, ok := sfc.(*softwareSurface)
+	if !ok {
+		return errors.New("software surfaces can only render other software surfaces")
+	}
+
+	modulate := [4]uint32{0xff, 0xff, 0xff, 0xff}
+	if s.stateCurrent.color != nil {
+		r, g, b, a := s.stateCurrent.color.RGBA()
+		modulate = [4]uint32{r >> 8, g >> 8, b >> 8, a >> 8}
+	}
+
+	var silhouette *[4]uint32
+	if s.stateCurrent.silhouette != nil {
+		r, g, b, a := s.stateCurrent.silhouette.RGBA()
+		silhouette = &[4]uint32{r >> 8, g >> 8, b >> 8, a >> 8}
+	}
+
+	scale := s.stateCurrent.getScale()
+	sourceWidth, sourceHeight := source.GetSize()
+	width := int(float64(sourceWidth) * scale)
+	height := int(float64(sourceHeight) * scale)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sourceOffset := source.image.PixOffset(int(float64(x)/scale), int(float64(y)/scale))
+			var pixel [4]uint32
+			for i := 0; i < 4; i++ {
+				value := uint32(source.image.Pix[sourceOffset+i])
+				if silhouette != nil {
+					value = silhouette[i] * uint32(source.image.Pix[sourceOffset+3]) / 0xff
+				}
+				pixel[i] = value * modulate[i] / 0xff
+			}
+			s.blend(s.stateCurrent.x+x, s.stateCurrent.y+y, pixel, s.stateCurrent.mode)
+		}
+	}
+
+	return nil
+}
+
+// Blends a premultiplied pixel onto the image drawn into using the composite mode
+func (s *softwareSurface) blend(x, y int, pixel [4]uint32, mode d2render.CompositeMode) {
+	target := s.target()
+	if !(image.Point{X: x, Y: y}.In(target.Rect)) {
+		return
+	}
+
+	offset := target.PixOffset(x, y)
+	for i := 0; i < 4; i++ {
+		destination := uint32(target.Pix[offset+i])
+		var result uint32
+		switch mode {
+		case d2render.CompositeModeCopy:
+			result = pixel[i]
+		case d2render.CompositeModeLighter:
+			result = pixel[i] + destination
+		default:
+			result = pixel[i] + destination*(0xff-pixel[3])/0xff
+		}
+		if result > 0xff {
+			result = 0xff
+		}
+		target.Pix[offset+i] = uint8(result)
+	}
+}
+
+func (s *softwareSurface) blendColor(x, y int, c color.Color) {
+	r, g, b, a := c.RGBA()
+	s.blend(x, y, [4]uint32{r >> 8, g >> 8, b >> 8, a >> 8}, d2render.CompositeModeSourceOver)
+}
+
+func (s *softwareSurface) DrawText(format string, params ...interface{}) {}
+
+func (s *softwareSurface) DrawLine(x, y int, color color.Color) {
+	dx := float64(x) * s.stateCurrent.getScale()
+	dy := float64(y) * s.stateCurrent.getScale()
+	steps := int(math.Max(math.Abs(dx), math.Abs(dy)))
+	for i := 0; i <= steps; i++ {
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+		s.blendColor(s.stateCurrent.x+int(math.Round(dx*t)), s.stateCurrent.y+int(math.Round(dy*t)), color)
+	}
+}
+
+func (s *softwareSurface) DrawRect(width, height int, color color.Color) {
+	scaledWidth := int(float64(width) * s.stateCurrent.getScale())
+	scaledHeight := int(float64(height) * s.stateCurrent.getScale())
+	for y := 0; y < scaledHeight; y++ {
+		for x := 0; x < scaledWidth; x++ {
+			s.blendColor(s.stateCurrent.x+x, s.stateCurrent.y+y, color)
+		}
+	}
+}
+
+func (s *softwareSurface) Clear(c color.Color) error {
+	r, g, b, a := c.RGBA()
+	for offset := 0; offset < len(s.image.Pix); offset += 4 {
+		s.image.Pix[offset] = uint8(r >> 8)
+		s.image.Pix[offset+1] = uint8(g >> 8)
+		s.image.Pix[offset+2] = uint8(b >> 8)
+		s.image.Pix[offset+3] = uint8(a >> 8)
+	}
+	return nil
+}
+
+func (s *softwareSurface) GetSize() (int, int) {
+	size := s.image.Bounds().Size()
+	return size.X, size.Y
+}
+
+func (s *softwareSurface) GetDepth() int {
+	return len(s.stateStack)
+}
+
+// Dispose releases the pixels of the surface, leaving it empty
+func (s *softwareSurface) Dispose() error {
+	s.image = image.NewRGBA(image.Rectangle{})
+	s.layers = nil
+	return nil
+}
+
+// ReplacePixels replaces the image with premultiplied RGBA pixel data
+func (s *softwareSurface) ReplacePixels(pixels []byte) error {
+	if len(pixels) != len(s.image.Pix) {
+		return errors.New("pixel data does not match the surface size")
+	}
+	copy(s.image.Pix, pixels)
+	return nil
+}
+
+func (s *softwareSurface) Screenshot() *image.RGBA {
+	result := image.NewRGBA(s.image.Bounds())
+	copy(result.Pix, s.image.Pix)
+	return result
+}
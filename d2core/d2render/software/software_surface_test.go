@@ -0,0 +1,154 @@
+package software
+
+import (
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
+)
+
+func TestSoftwareSurfaceDrawSequence(t *testing.T) {
+	assert := testify.New(t)
+
+	renderer, _ := CreateRenderer()
+	target, _ := renderer.NewSurface(8, 8, d2render.FilterNearest)
+	sprite, _ := renderer.NewSurface(2, 2, d2render.FilterNearest)
+
+	white := []byte{0xff, 0xff, 0xff, 0xff}
+	var spritePixels []byte
+	for i := 0; i < 4; i++ {
+		spritePixels = append(spritePixels, white...)
+	}
+	assert.Nil(sprite.ReplacePixels(spritePixels))
+
+	assert.Nil(target.Clear(color.RGBA{A: 0xff}))
+
+	target.PushTranslation(1, 1)
+	target.DrawRect(2, 2, color.RGBA{R: 0xff, A: 0xff})
+	target.Pop()
+
+	target.PushTranslation(4, 4)
+	target.PushScale(2)
+	target.PushColor(color.RGBA{G: 0xff, A: 0xff})
+	assert.Nil(target.Render(sprite))
+	target.PopN(3)
+	assert.Equal(0, target.GetDepth())
+
+	target.PushCompositeMode(d2render.CompositeModeLighter)
+	assert.Nil(target.Render(sprite))
+	target.Pop()
+
+	image := target.Screenshot()
+	assert.Equal(color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, image.RGBAAt(0, 0))
+	assert.Equal(color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, image.RGBAAt(1, 1))
+	assert.Equal(color.RGBA{R: 0xff, A: 0xff}, image.RGBAAt(2, 2))
+	assert.Equal(color.RGBA{A: 0xff}, image.RGBAAt(3, 3))
+	assert.Equal(color.RGBA{G: 0xff, A: 0xff}, image.RGBAAt(4, 4))
+	assert.Equal(color.RGBA{G: 0xff, A: 0xff}, image.RGBAAt(7, 7))
+	assert.Equal(color.RGBA{A: 0xff}, image.RGBAAt(7, 3))
+}
+
+func TestSoftwareSurfaceTranslucentColor(t *testing.T) {
+	assert := testify.New(t)
+
+	target := newSoftwareSurface(1, 1)
+	sprite := newSoftwareSurface(1, 1)
+	assert.Nil(sprite.ReplacePixels([]byte{0xff, 0xff, 0xff, 0xff}))
+	assert.Nil(target.Clear(color.RGBA{B: 0xff, A: 0xff}))
+
+	target.PushColor(color.RGBA{R: 0x80, A: 0x80})
+	assert.Nil(target.Render(sprite))
+	target.Pop()
+
+	assert.Equal(color.RGBA{R: 0x80, B: 0x7f, A: 0xff}, target.Screenshot().RGBAAt(0, 0))
+}
+
+func TestSoftwareSurfacePushedColorsCompose(t *testing.T) {
+	assert := testify.New(t)
+
+	target := newSoftwareSurface(1, 1)
+	sprite := newSoftwareSurface(1, 1)
+	assert.Nil(sprite.ReplacePixels([]byte{0xff, 0xff, 0xff, 0xff}))
+
+	target.PushColor(color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x80})
+	target.PushColor(color.RGBA{R: 0xff, G: 0x80, A: 0xff})
+	assert.Nil(target.Render(sprite))
+	target.PopN(2)
+
+	assert.Equal(color.RGBA{R: 0x80, G: 0x40, A: 0x80}, target.Screenshot().RGBAAt(0, 0))
+}
+
+func TestSoftwareSurfaceSilhouette(t *testing.T) {
+	assert := testify.New(t)
+
+	target := newSoftwareSurface(2, 1)
+	sprite := newSoftwareSurface(2, 1)
+	assert.Nil(sprite.ReplacePixels([]byte{0x20, 0x40, 0x60, 0xff, 0, 0, 0, 0}))
+
+	// The shape of the sprite is kept, in the silhouette color, and pushed colors still apply
+	target.PushSilhouette(color.RGBA{R: 0xff, G: 0xff, A: 0xff})
+	target.PushColor(color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x80})
+	assert.Nil(target.Render(sprite))
+	target.PopN(2)
+
+	image := target.Screenshot()
+	assert.Equal(color.RGBA{R: 0x80, G: 0x80, A: 0x80}, image.RGBAAt(0, 0))
+	assert.Equal(color.RGBA{}, image.RGBAAt(1, 0))
+}
+
+func TestSoftwareSurfaceMask(t *testing.T) {
+	assert := testify.New(t)
+
+	target := newSoftwareSurface(3, 1)
+	sprite := newSoftwareSurface(2, 1)
+	mask := newSoftwareSurface(3, 1)
+	assert.Nil(target.Clear(color.RGBA{B: 0xff, A: 0xff}))
+	assert.Nil(sprite.Clear(color.White))
+	assert.Nil(mask.ReplacePixels([]byte{0, 0, 0, 0, 0, 0, 0, 0xc0, 0, 0, 0, 0xff}))
+
+	// The mask darkens the sprite where it is translucent, and covers nothing where the sprite isn't drawn
+	assert.Nil(target.PushMask(mask))
+	assert.Nil(target.Render(sprite))
+	assert.Equal(color.RGBA{B: 0xff, A: 0xff}, target.Screenshot().RGBAAt(0, 0))
+	target.Pop()
+	assert.Equal(0, target.GetDepth())
+
+	image := target.Screenshot()
+	assert.Equal(color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, image.RGBAAt(0, 0))
+	assert.Equal(color.RGBA{R: 0x3f, G: 0x3f, B: 0x3f, A: 0xff}, image.RGBAAt(1, 0))
+	assert.Equal(color.RGBA{B: 0xff, A: 0xff}, image.RGBAAt(2, 0))
+}
+
+func TestSoftwareRendererRunStopsAfterMaxFrames(t *testing.T) {
+	assert := testify.New(t)
+
+	renderer, _ := CreateRenderer()
+	renderer.MaxFrames = 3
+
+	frames := 0
+	err := renderer.Run(func(surface d2render.Surface) error {
+		width, height := surface.GetSize()
+		assert.Equal(800, width)
+		assert.Equal(600, height)
+		frames++
+		return nil
+	}, 800, 600, "test")
+
+	assert.Nil(err)
+	assert.Equal(3, frames)
+}
+
+type foreignSurface struct {
+	d2render.Surface
+}
+
+func TestSoftwareCreateSurfaceRejectsForeignSurface(t *testing.T) {
+	assert := testify.New(t)
+
+	renderer, _ := CreateRenderer()
+	surface, err := renderer.CreateSurface(foreignSurface{})
+	assert.Nil(surface)
+	assert.NotNil(err)
+}
@@ -10,13 +10,27 @@ type Surface interface {
 	DrawRect(width, height int, color color.Color)
 	DrawLine(x, y int, color color.Color)
 	DrawText(format string, params ...interface{})
+	// Dispose releases the image of the surface (eg: a cached image that was dropped). The surface must not be drawn
+	// to or rendered afterwards.
+	Dispose() error
 	GetSize() (width, height int)
 	GetDepth() int
 	Pop()
 	PopN(n int)
+	// PushColor multiplies the colors of the images rendered afterwards by the color, on top of the colors already
+	// pushed
 	PushColor(color color.Color)
 	PushCompositeMode(mode CompositeMode)
 	PushFilter(filter Filter)
+	// PushMask draws everything drawn afterwards into a layer, which is drawn onto the surface through the mask when
+	// popped. The mask covers the surface from its top left corner, and is drawn over the layer only where the layer
+	// is opaque (eg: a mask that is transparent inside a spotlight and black outside of it hides everything outside of
+	// the spotlight). Nothing is pushed if the mask can't be used by the surface.
+	PushMask(mask Surface) error
+	PushScale(scale float64)
+	// PushSilhouette draws the images rendered afterwards in a solid color, keeping only their shape (eg: for an
+	// outline). The pushed colors still apply on top of it.
+	PushSilhouette(color color.Color)
 	PushTranslation(x, y int)
 	Render(surface Surface) error
 	ReplacePixels(pixels []byte) error
@@ -127,6 +127,10 @@ func (s *Sprite) PlayBackward() {
 	s.animation.PlayBackward()
 }
 
+func (s *Sprite) PlayPingPong() {
+	s.animation.PlayPingPong()
+}
+
 func (s *Sprite) Pause() {
 	s.animation.Pause()
 }
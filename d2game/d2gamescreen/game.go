@@ -54,6 +54,7 @@ func (v *Game) Render(screen d2render.Surface) error {
 		v.mapRenderer.RegenerateTileCache()
 	}
 	screen.Clear(color.Black)
+	v.mapRenderer.SetViewportSize(screen.GetSize())
 	v.mapRenderer.Render(screen)
 	if v.gameControls != nil {
 		v.gameControls.Render(screen)
@@ -74,7 +75,8 @@ func (v *Game) Advance(tickTime float64) error {
 	if v.ticksSinceLevelCheck > 1.0 {
 		v.ticksSinceLevelCheck = 0
 		if v.localPlayer != nil {
-			tile := v.gameClient.MapEngine.TileAt(v.localPlayer.TileX, v.localPlayer.TileY)
+			tileX, tileY := v.localPlayer.GetPosition()
+			tile := v.gameClient.MapEngine.TileAt(int(tileX), int(tileY))
 			if tile != nil {
 				switch tile.RegionType {
 				case 1: // Rogue encampent
@@ -105,14 +107,16 @@ func (v *Game) Advance(tickTime float64) error {
 
 	// Update the camera to focus on the player
 	if v.localPlayer != nil && !v.gameControls.FreeCam {
-		rx, ry := v.mapRenderer.WorldToOrtho(v.localPlayer.AnimatedComposite.LocationX/5, v.localPlayer.AnimatedComposite.LocationY/5)
+		heroX, heroY := v.localPlayer.AnimatedComposite.GetLocation()
+		rx, ry := v.mapRenderer.WorldToOrtho(heroX/5, heroY/5)
 		v.mapRenderer.MoveCameraTo(rx, ry)
 	}
 	return nil
 }
 
 func (v *Game) OnPlayerMove(x, y float64) {
-	heroPosX := v.localPlayer.AnimatedComposite.LocationX / 5.0
-	heroPosY := v.localPlayer.AnimatedComposite.LocationY / 5.0
+	heroX, heroY := v.localPlayer.AnimatedComposite.GetLocation()
+	heroPosX := heroX / 5.0
+	heroPosY := heroY / 5.0
 	v.gameClient.SendPacketToServer(d2netpacket.CreateMovePlayerPacket(v.gameClient.PlayerId, heroPosX, heroPosY, x, y))
 }
@@ -138,7 +138,6 @@ func (met *MapEngineTest) LoadRegionByIndex(n int, levelPreset, fileIndex int) {
 		met.mapEngine.RegenerateWalkPaths()
 	}
 	met.mapRenderer.SetMapEngine(met.mapEngine)
-	met.mapRenderer.MoveCameraTo(met.mapRenderer.WorldToOrtho(met.mapEngine.GetCenterPosition()))
 }
 
 func (met *MapEngineTest) OnLoad() error {
@@ -156,6 +155,7 @@ func (met *MapEngineTest) OnUnload() error {
 }
 
 func (met *MapEngineTest) Render(screen d2render.Surface) error {
+	met.mapRenderer.SetViewportSize(screen.GetSize())
 	met.mapRenderer.Render(screen)
 
 	//screenX, screenY := d2render.GetCursorPos()
@@ -143,9 +143,10 @@ func (g *GameControls) OnMouseButtonDown(event d2input.MouseEvent) bool {
 	}
 
 	if event.Button == d2input.MouseButtonRight {
+		heroX, heroY := g.hero.AnimatedComposite.GetLocation()
 		missile, err := d2mapentity.CreateMissile(
-			int(g.hero.AnimatedComposite.LocationX),
-			int(g.hero.AnimatedComposite.LocationY),
+			int(heroX),
+			int(heroY),
 			d2datadict.Missiles[missileID],
 		)
 		if err != nil {
@@ -153,8 +154,8 @@ func (g *GameControls) OnMouseButtonDown(event d2input.MouseEvent) bool {
 		}
 
 		rads := d2common.GetRadiansBetween(
-			g.hero.AnimatedComposite.LocationX,
-			g.hero.AnimatedComposite.LocationY,
+			heroX,
+			heroY,
 			px*5,
 			py*5,
 		)
@@ -91,7 +91,8 @@ func (g *GameClient) OnPacketReceived(packet d2netpacket.NetPacket) error {
 		path, _, found := g.MapEngine.PathFind(movePlayer.StartX, movePlayer.StartY, movePlayer.DestX, movePlayer.DestY)
 		if found {
 			player.AnimatedComposite.SetPath(path, func() {
-				tile := g.MapEngine.TileAt(player.TileX, player.TileY)
+				tileX, tileY := player.GetPosition()
+				tile := g.MapEngine.TileAt(int(tileX), int(tileY))
 				if tile == nil {
 					return
 				}
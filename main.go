@@ -38,6 +38,7 @@ import (
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2input"
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render"
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render/ebiten"
+	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2render/software"
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2term"
 	"github.com/OpenDiablo2/OpenDiablo2/d2core/d2ui"
 )
@@ -76,12 +77,18 @@ func main() {
 
 	region := kingpin.Arg("region", "Region type id").Int()
 	preset := kingpin.Arg("preset", "Level preset").Int()
+	rendererName := kingpin.Flag("renderer", "Rendering backend").Default("ebiten").Enum("ebiten", "software")
 	kingpin.Parse()
 
 	log.SetFlags(log.Lshortfile)
 	log.Println("OpenDiablo2 - Open source Diablo 2 engine")
 
-	if err := initialize(); err != nil {
+	renderType := d2render.Ebiten
+	if *rendererName == "software" {
+		renderType = d2render.Software
+	}
+
+	if err := initialize(renderType); err != nil {
 		log.Fatal(err)
 	}
 
@@ -97,7 +104,16 @@ func main() {
 	}
 }
 
-func initialize() error {
+func createRenderer(renderType d2render.RenderType) (d2render.Renderer, error) {
+	switch renderType {
+	case d2render.Software:
+		return software.CreateRenderer()
+	default:
+		return ebiten.CreateRenderer()
+	}
+}
+
+func initialize(renderType d2render.RenderType) error {
 	singleton.timeScale = 1.0
 	singleton.lastTime = d2common.Now()
 	singleton.lastScreenAdvance = singleton.lastTime
@@ -109,7 +125,7 @@ func initialize() error {
 	config := d2config.Get()
 	d2resource.LanguageCode = config.Language
 
-	renderer, err := ebiten.CreateRenderer()
+	renderer, err := createRenderer(renderType)
 	if err != nil {
 		return err
 	}
@@ -438,20 +454,20 @@ func loadDataDict() error {
 }
 
 func loadStrings() error {
+	// The later tables override the strings of the earlier
 	tablePaths := []string{
-		d2resource.PatchStringTable,
-		d2resource.ExpansionStringTable,
 		d2resource.StringTable,
+		d2resource.ExpansionStringTable,
+		d2resource.PatchStringTable,
 	}
 
+	stringTables := d2common.CreateStringTableManager(d2asset.LoadFile)
 	for _, tablePath := range tablePaths {
-		data, err := d2asset.LoadFile(tablePath)
-		if err != nil {
+		if err := stringTables.LoadTable(tablePath); err != nil {
 			return err
 		}
-
-		d2common.LoadDictionary(data)
 	}
 
+	d2common.SetStringTableManager(stringTables)
 	return nil
 }